@@ -217,8 +217,12 @@ func (c *Consume) executeTask(faultCenter models.FaultCenter, taskChan chan stru
 		return
 	}
 
+	// 刷新告警抖动状态
+	c.processFlapping(faultCenter, data)
 	// 事件过滤
 	filterEvents := c.filterAlertEvents(faultCenter, data)
+	// 同步故障
+	process.SyncIncidents(c.ctx, faultCenter, filterEvents)
 	// 事件分组
 	var alertGroups AlertGroups
 	c.alarmGrouping(faultCenter, &alertGroups, filterEvents)
@@ -239,6 +243,14 @@ func (c *Consume) filterAlertEvents(faultCenter models.FaultCenter, alerts map[s
 		// 恢复事件特殊处理：如果恢复通知还未发送（LastSendTime == 0），需要发送恢复通知
 		// 恢复通知发送后，才会从缓存中移除
 		if event.Status == models.StateRecovered {
+			// 抖动中的恢复事件不发送恢复通知, 直接记录历史告警
+			if c.isFlappingEvent(event, faultCenter) {
+				c.removeAlertFromCache(event)
+				if err := process.RecordAlertHisEvent(c.ctx, *event); err != nil {
+					logc.Error(c.ctx.Ctx, fmt.Sprintf("Failed to record alert history: %v", err))
+				}
+				continue
+			}
 			// 检查是否需要发送恢复通知
 			if event.IsRecovered && event.LastSendTime == 0 {
 				// 恢复通知还未发送，允许通过过滤，进入发送流程
@@ -261,6 +273,10 @@ func (c *Consume) filterAlertEvents(faultCenter models.FaultCenter, alerts map[s
 			continue
 		}
 
+		if c.isFlappingEvent(event, faultCenter) {
+			continue
+		}
+
 		if valid := c.validateEvent(event, faultCenter); valid {
 			newEvents = append(newEvents, event)
 		}
@@ -345,8 +361,14 @@ func (c *Consume) sendAlerts(faultCenter models.FaultCenter, aggEvents *AlertGro
 
 // processAlertGroup 处理告警组
 func (c *Consume) processAlertGroup(faultCenter models.FaultCenter, noticeId string, alerts []*models.AlertCurEvent) {
+	// 首次通知需在 HandleAlert 更新 LastSendTime 前判断
+	firstNotice := make(map[string]bool, len(alerts))
+	for _, alert := range alerts {
+		firstNotice[alert.EventId] = process.IsFirstNotice(alert)
+	}
+
 	g := new(errgroup.Group)
-	g.Go(func() error { return c.handleSubscribe(alerts) })
+	g.Go(func() error { return c.handleSubscribe(alerts, firstNotice) })
 	g.Go(func() error { return process.HandleAlert(c.ctx, "alarm", faultCenter, noticeId, alerts) })
 
 	if err := g.Wait(); err != nil {
@@ -355,12 +377,12 @@ func (c *Consume) processAlertGroup(faultCenter models.FaultCenter, noticeId str
 }
 
 // handleSubscribe 处理订阅逻辑
-func (c *Consume) handleSubscribe(alerts []*models.AlertCurEvent) error {
+func (c *Consume) handleSubscribe(alerts []*models.AlertCurEvent, firstNotice map[string]bool) error {
 	g := new(errgroup.Group)
 	for _, event := range alerts {
 		event := event
 		g.Go(func() error {
-			if err := processSubscribe(c.ctx, event); err != nil {
+			if err := processSubscribe(c.ctx, event, firstNotice[event.EventId]); err != nil {
 				return fmt.Errorf("failed to process subscribe: %v", err)
 			}
 
@@ -390,7 +412,7 @@ func (c *Consume) RestartAllConsumers() {
 }
 
 func (c *Consume) processSilenceRule(faultCenter models.FaultCenter) {
-	currentTime := time.Now().Unix()
+	currentTime := time.Now()
 	silenceCtx := c.ctx.Redis.Silence()
 	// 获取静默列表中所有的id
 	silenceIds, err := silenceCtx.GetAlertMutes(faultCenter.TenantId, faultCenter.ID)
@@ -407,20 +429,12 @@ func (c *Consume) processSilenceRule(faultCenter models.FaultCenter) {
 			return
 		}
 
-		// 如果当前状态为「未生效」，并且生效时间大于等于当前时间，则标记为「生效中」状态
-		if muteRule.Status == 0 && currentTime >= muteRule.StartsAt {
-			muteRule.Status = 1
-			err := c.ctx.DB.Silence().Update(*muteRule)
-			if err != nil {
-				logc.Error(c.ctx.Ctx, fmt.Sprintf("Update silence rule failed, err: %s", err.Error()))
-				return
-			}
-		}
-
-		// 如果到达失效日期，则标记「已失效」状态
-		if muteRule.EndsAt <= currentTime {
-			muteRule.Status = 2
-			err := c.ctx.DB.Silence().Update(*muteRule)
+		// 计算当前状态, 单次静默: 未生效 → 生效中 → 已失效;
+		// 周期静默: 在每个静默窗口内为「生效中」, 窗口之间为「未生效」, 超过有效期后为「已失效」
+		status := muteRule.EvalStatus(currentTime)
+		if status != muteRule.Status {
+			muteRule.Status = status
+			err := c.ctx.DB.Silence().UpdateStatus(muteRule.TenantId, muteRule.ID, muteRule.Status)
 			if err != nil {
 				logc.Error(c.ctx.Ctx, fmt.Sprintf("Update silence rule failed, err: %s", err.Error()))
 				return
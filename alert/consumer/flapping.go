@@ -0,0 +1,82 @@
+package consumer
+
+import (
+	"fmt"
+	"time"
+	"watchAlert/alert/process"
+	"watchAlert/internal/models"
+
+	"github.com/zeromicro/go-zero/core/logc"
+)
+
+// processFlapping 刷新故障中心下所有事件的抖动分值, 并在开始/停止抖动时发送一次通知
+func (c *Consume) processFlapping(faultCenter models.FaultCenter, events map[string]*models.AlertCurEvent) {
+	cfg := faultCenter.FlapDetection
+	if !cfg.GetEnabled() {
+		return
+	}
+
+	var (
+		now     = time.Now().Unix()
+		notices []models.FlapState
+	)
+
+	c.ctx.Mux.Lock()
+	for _, state := range c.ctx.Redis.Flapping().List(faultCenter.TenantId, faultCenter.ID) {
+		state.Evaluate(now, cfg)
+
+		event, active := events[state.Fingerprint]
+		// 事件已不在故障中心且窗口内无状态变化时清理抖动状态
+		if !active && state.IsExpired() {
+			c.ctx.Redis.Flapping().Delete(state.TenantId, state.FaultCenterId, state.Fingerprint)
+			continue
+		}
+
+		if !state.Notified {
+			state.Notified = true
+			notices = append(notices, state)
+		}
+		c.ctx.Redis.Flapping().Set(state)
+
+		// 同步事件的抖动状态, 以缓存中最新的事件为准
+		if active && (event.Flapping != state.Flapping || event.FlapScore != state.Score) {
+			latest, err := c.ctx.Redis.Alert().GetEventFromCache(state.TenantId, state.FaultCenterId, state.Fingerprint)
+			if err == nil && latest.Fingerprint != "" {
+				latest.Flapping = state.Flapping
+				latest.FlapScore = state.Score
+				c.ctx.Redis.Alert().PushAlertEvent(&latest)
+				event.Flapping, event.FlapScore = state.Flapping, state.Score
+			}
+		}
+	}
+	c.ctx.Mux.Unlock()
+
+	for _, state := range notices {
+		c.sendFlappingNotice(faultCenter, state)
+	}
+}
+
+// sendFlappingNotice 发送开始抖动/停止抖动通知
+func (c *Consume) sendFlappingNotice(faultCenter models.FaultCenter, state models.FlapState) {
+	event := state.Event
+	event.Flapping = state.Flapping
+	event.FlapScore = state.Score
+	event.IsRecovered = state.LastState == models.StateRecovered
+
+	if state.Flapping {
+		event.Annotations = fmt.Sprintf("告警开始抖动, 抖动分值: %.2f%%, 抖动期间暂停该告警的触发及恢复通知。\n%s", state.Score, event.Annotations)
+	} else {
+		event.Annotations = fmt.Sprintf("告警停止抖动, 抖动分值: %.2f%%, 恢复该告警的触发及恢复通知。\n%s", state.Score, event.Annotations)
+	}
+
+	for _, noticeId := range new(AlertGroups).getNoticeId(&event, faultCenter) {
+		if err := process.HandleAlert(c.ctx, "flapping", faultCenter, noticeId, []*models.AlertCurEvent{&event}); err != nil {
+			logc.Error(c.ctx.Ctx, fmt.Sprintf("发送抖动通知失败, fingerprint: %s, err: %s", state.Fingerprint, err.Error()))
+		}
+	}
+}
+
+// isFlappingEvent 抖动中的事件不发送触发及恢复通知
+func (c *Consume) isFlappingEvent(event *models.AlertCurEvent, faultCenter models.FaultCenter) bool {
+	return faultCenter.FlapDetection.GetEnabled() && event.Flapping
+}
@@ -17,8 +17,8 @@ type toUser struct {
 	NoticeTemplateId string
 }
 
-// 向已订阅的用户中发送告警消息
-func processSubscribe(ctx *ctx.Context, alert *models.AlertCurEvent) error {
+// 向已订阅的用户中发送告警消息, firstNotice 为首次通知时邮件作为会话的首封邮件
+func processSubscribe(ctx *ctx.Context, alert *models.AlertCurEvent, firstNotice bool) error {
 	var toUsers []toUser
 
 	// 获取所有用户订阅列表
@@ -58,7 +58,7 @@ func processSubscribe(ctx *ctx.Context, alert *models.AlertCurEvent) error {
 		})
 	}
 
-	return sendToSubscribeUser(ctx, *alert, toUsers)
+	return sendToSubscribeUser(ctx, *alert, firstNotice, toUsers)
 }
 
 func getSubscribes(alert *models.AlertCurEvent) ([]models.AlertSubscribe, error) {
@@ -70,7 +70,7 @@ func getSubscribes(alert *models.AlertCurEvent) ([]models.AlertSubscribe, error)
 	return list, nil
 }
 
-func sendToSubscribeUser(ctx *ctx.Context, alert models.AlertCurEvent, toUsers []toUser) error {
+func sendToSubscribeUser(ctx *ctx.Context, alert models.AlertCurEvent, firstNotice bool, toUsers []toUser) error {
 	if len(toUsers) <= 0 {
 		return nil
 	}
@@ -87,7 +87,9 @@ func sendToSubscribeUser(ctx *ctx.Context, alert models.AlertCurEvent, toUsers [
 			}()
 			emailTemp := templates.NewTemplate(ctx, alert, models.AlertNotice{NoticeType: "Email", NoticeTmplId: u.NoticeTemplateId})
 			err := sender.NewEmailSender().Send(sender.SendParams{
-				IsRecovered: alert.IsRecovered,
+				EventId:       alert.EventId,
+				IsRecovered:   alert.IsRecovered,
+				IsFirstNotice: firstNotice,
 				Email: models.Email{
 					Subject: u.NoticeSubject,
 					To:      []string{u.Email},
@@ -3,12 +3,13 @@ package eval
 import (
 	"context"
 	"fmt"
-	"runtime/debug"
 	"slices"
 	"strings"
 	"sync"
 	"time"
+	"watchAlert/alert/process"
 	"watchAlert/internal/ctx"
+	"watchAlert/internal/global"
 	"watchAlert/internal/models"
 	"watchAlert/pkg/provider"
 	"watchAlert/pkg/tools"
@@ -36,9 +37,6 @@ const (
 
 	// 默认恢复等待时间
 	DefaultRecoverWaitTime = 1
-
-	// 任务通道缓冲区大小
-	TaskChannelBufferSize = 1
 )
 
 // 数据源处理器映射
@@ -68,14 +66,26 @@ type (
 
 	// AlertRule 告警规则
 	AlertRule struct {
-		ctx *ctx.Context
+		ctx       *ctx.Context
+		scheduler *Scheduler
 	}
 )
 
 func NewAlertRuleEval(ctx *ctx.Context) AlertRuleEval {
-	return &AlertRule{
+	t := &AlertRule{
 		ctx: ctx,
 	}
+
+	cfg := global.Config.Eval
+	t.scheduler = NewScheduler(SchedulerOptions{
+		Workers:               cfg.Workers,
+		DatasourceMaxInflight: cfg.DatasourceMaxInflight,
+		DatasourceQueueDepth:  cfg.DatasourceQueueDepth,
+	}, t.Eval)
+	t.scheduler.Start(ctx.Ctx)
+	queryCache = NewQueryCache(time.Duration(cfg.QueryCacheTTL) * time.Second)
+
+	return t
 }
 
 func (t *AlertRule) Submit(rule models.AlertRule) {
@@ -83,8 +93,11 @@ func (t *AlertRule) Submit(rule models.AlertRule) {
 	defer t.ctx.Mux.Unlock()
 
 	c, cancel := context.WithCancel(context.Background())
-	t.ctx.ContextMap[rule.RuleId] = cancel
-	go t.Eval(c, rule)
+	t.ctx.ContextMap[rule.RuleId] = func() {
+		cancel()
+		t.scheduler.Remove(rule.RuleId)
+	}
+	t.scheduler.Add(c, rule, t.getEvalTimeDuration(rule.EvalTimeType, rule.EvalInterval))
 }
 
 func (t *AlertRule) Stop(ruleId string) {
@@ -97,52 +110,19 @@ func (t *AlertRule) Stop(ruleId string) {
 	}
 }
 
-func (t *AlertRule) Restart(rule models.AlertRule) {
-	t.Stop(rule.RuleId)
-	t.Submit(rule)
-}
-
+// Eval 执行一次规则评估, 由调度器的工作协程调用
 func (t *AlertRule) Eval(ctx context.Context, rule models.AlertRule) {
-	taskChan := make(chan struct{}, TaskChannelBufferSize)
-	timer := time.NewTicker(t.getEvalTimeDuration(rule.EvalTimeType, rule.EvalInterval))
-	defer func() {
-		timer.Stop()
-		if r := recover(); r != nil {
-			// 获取调用栈信息
-			stack := debug.Stack()
-			logc.Error(t.ctx.Ctx, fmt.Sprintf("Recovered from rule eval goroutine panic: %s, RuleName: %s, RuleId: %s\n%s", r, rule.RuleName, rule.RuleId, stack))
-			t.Restart(rule)
-		}
-	}()
-
-	for {
-		select {
-		case <-timer.C:
-			// 处理任务信号量
-			taskChan <- struct{}{}
-			t.executeTask(rule, taskChan)
-		case <-ctx.Done():
-			logc.Infof(t.ctx.Ctx, fmt.Sprintf("停止 RuleId: %v, RuleName: %s 的 Watch 协程", rule.RuleId, rule.RuleName))
-			return
-		}
-		timer.Reset(t.getEvalTimeDuration(rule.EvalTimeType, rule.EvalInterval))
-	}
-}
-
-// executeTask 执行评估任务
-func (t *AlertRule) executeTask(rule models.AlertRule, taskChan chan struct{}) {
-	defer func() {
-		// 释放任务信号量
-		<-taskChan
-	}()
-
 	// 在规则评估前检查是否仍然启用
 	if !t.isRuleEnabled(rule.RuleId) {
 		return
 	}
 
 	// 并发处理数据源
-	curFingerprints := t.processDatasources(rule)
+	curFingerprints := t.processDatasources(ctx, rule)
+	if ctx.Err() != nil {
+		logc.Infof(t.ctx.Ctx, "RuleId: %v, RuleName: %s 已停止, 跳过恢复处理", rule.RuleId, rule.RuleName)
+		return
+	}
 
 	// 处理恢复逻辑
 	t.Recover(rule.TenantId, rule.RuleId,
@@ -152,7 +132,7 @@ func (t *AlertRule) executeTask(rule models.AlertRule, taskChan chan struct{}) {
 }
 
 // processDatasources 处理数据源
-func (t *AlertRule) processDatasources(rule models.AlertRule) []string {
+func (t *AlertRule) processDatasources(ctx context.Context, rule models.AlertRule) []string {
 	var (
 		curFingerprints []string
 		fingerprintChan = make(chan []string, len(rule.DatasourceIdList))
@@ -164,7 +144,7 @@ func (t *AlertRule) processDatasources(rule models.AlertRule) []string {
 		wg.Add(1)
 		go func(dsId string) {
 			defer wg.Done()
-			fingerprints := t.processSingleDatasource(dsId, rule)
+			fingerprints := t.processSingleDatasource(ctx, dsId, rule)
 			if len(fingerprints) > 0 {
 				fingerprintChan <- fingerprints
 			}
@@ -184,7 +164,7 @@ func (t *AlertRule) processDatasources(rule models.AlertRule) []string {
 }
 
 // processSingleDatasource 处理单个数据源
-func (t *AlertRule) processSingleDatasource(dsId string, rule models.AlertRule) []string {
+func (t *AlertRule) processSingleDatasource(ctx context.Context, dsId string, rule models.AlertRule) []string {
 	instance, err := t.ctx.DB.Datasource().GetInstance(dsId)
 	if err != nil {
 		logc.Errorf(t.ctx.Ctx, fmt.Sprintf("Failed to get datasource instance %s: %v", dsId, err))
@@ -210,6 +190,14 @@ func (t *AlertRule) processSingleDatasource(dsId string, rule models.AlertRule)
 		return nil
 	}
 
+	// 限制单个数据源的并发查询数, 排队已满时丢弃本轮查询
+	limiter := t.scheduler.Limiter()
+	if !limiter.Acquire(ctx, dsId) {
+		logc.Errorf(t.ctx.Ctx, "Datasource %s query queue is full or rule stopped, skip RuleId: %s", dsId, rule.RuleId)
+		return nil
+	}
+	defer limiter.Release(dsId)
+
 	return handler(t.ctx, dsId, instance.Type, rule)
 }
 
@@ -350,6 +338,7 @@ func (t *AlertRule) Recover(tenantId, ruleId string, eventCacheKey models.AlertE
 			if newEvent.LastSendTime != 0 {
 				newEvent.LastSendTime = 0
 			}
+			process.RecordFlapStateChange(t.ctx, t.ctx.Redis.FaultCenter().GetFaultCenterInfo(faultCenterInfoKey), newEvent)
 			// 记录恢复事件推送日志
 			logc.Infof(t.ctx.Ctx, "[普通告警恢复] 推送恢复事件: ruleId=%s, fingerprint=%s, ruleName=%s",
 				newEvent.RuleId, newEvent.Fingerprint, newEvent.RuleName)
@@ -386,27 +375,11 @@ func (t *AlertRule) RestartAllEvals() {
 
 	logc.Info(t.ctx.Ctx, fmt.Sprintf("获取到 %d 个状态为启用的规则", count))
 
-	// 使用工作池限制并发数量
-	const maxWorkers = 10
-	wg := sync.WaitGroup{}
-	semaphore := make(chan struct{}, maxWorkers)
-
-	wg.Add(count)
+	// 规则仅注册到调度器, 由调度器按各自的相位错峰执行
 	for _, rule := range ruleList {
-		rule := rule
-		go func() {
-			semaphore <- struct{}{}
-			defer func() {
-				wg.Done()
-				<-semaphore
-			}()
-
-			t.Submit(rule)
-		}()
+		t.Submit(rule)
 	}
 
-	wg.Wait()
-	close(semaphore)
 	logc.Info(t.ctx.Ctx, "所有规则评估器启动成功！")
 }
 
@@ -38,10 +38,10 @@ func metrics(ctx *ctx.Context, datasourceId, datasourceType string, rule models.
 	// 处理 PromQL 中的变量：如果包含 $instance 或 $ifName 等变量，替换为通配符以查询所有匹配的指标
 	// 告警规则执行时应该监控所有匹配的指标，而不是只监控特定的 instance 或 ifName
 	promQL := tools.ReplacePromQLVariablesForAlert(rule.PrometheusConfig.PromQL, nil)
-	
+
 	switch datasourceType {
 	case provider.PrometheusDsProvider:
-		resQuery, err = cli.(provider.PrometheusProvider).Query(promQL)
+		resQuery, err = queryMetrics(datasourceId, promQL, cli.(provider.PrometheusProvider))
 		if err != nil {
 			logc.Error(ctx.Ctx, err.Error())
 			return nil
@@ -49,7 +49,7 @@ func metrics(ctx *ctx.Context, datasourceId, datasourceType string, rule models.
 
 		externalLabels = cli.(provider.PrometheusProvider).GetExternalLabels()
 	case provider.VictoriaMetricsDsProvider:
-		resQuery, err = cli.(provider.VictoriaMetricsProvider).Query(promQL)
+		resQuery, err = queryMetrics(datasourceId, promQL, cli.(provider.VictoriaMetricsProvider))
 		if err != nil {
 			logc.Error(ctx.Ctx, err.Error())
 			return nil
@@ -187,6 +187,41 @@ func getPriorityValue(severity string) int {
 	}
 }
 
+// queryMetrics 执行指标查询, 同一时间窗口内相同数据源的相同 PromQL 共享查询结果
+func queryMetrics(datasourceId, promQL string, cli provider.MetricsFactoryProvider) ([]provider.Metrics, error) {
+	res, err := queryCache.Do(datasourceId, promQL, func() (interface{}, error) {
+		return cli.Query(promQL)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res.([]provider.Metrics), nil
+}
+
+// logsResult 日志查询结果
+type logsResult struct {
+	log   provider.Logs
+	count int
+}
+
+// queryLogs 执行日志查询, 同一时间窗口内相同数据源的相同查询条件共享查询结果
+func queryLogs(datasourceId string, options provider.LogQueryOptions, cli provider.LogsFactoryProvider) (provider.Logs, int, error) {
+	res, err := queryCache.Do(datasourceId, tools.JsonMarshalToString(options), func() (interface{}, error) {
+		log, count, err := cli.Query(options)
+		if err != nil {
+			return nil, err
+		}
+		return logsResult{log: log, count: count}, nil
+	})
+	if err != nil {
+		return provider.Logs{}, 0, err
+	}
+
+	r := res.(logsResult)
+	return r.log, r.count, nil
+}
+
 // Logs 包含 AliSLS、Loki、ElasticSearch 数据源
 func logs(ctx *ctx.Context, datasourceId, datasourceType string, rule models.AlertRule) []string {
 	var (
@@ -198,8 +233,8 @@ func logs(ctx *ctx.Context, datasourceId, datasourceType string, rule models.Ale
 		evalOptions models.EvalCondition
 		// 额外的标签
 		externalLabels map[string]interface{}
-		// 当前时间
-		curAt = time.Now()
+		// 当前时间, 对齐到查询缓存窗口以便相同查询共享结果
+		curAt = queryCache.Align(time.Now())
 	)
 
 	pools := ctx.Redis.ProviderPools()
@@ -219,7 +254,7 @@ func logs(ctx *ctx.Context, datasourceId, datasourceType string, rule models.Ale
 			StartAt: startsAt.Unix(),
 			EndAt:   curAt.Unix(),
 		}
-		log, count, err = cli.(provider.LokiProvider).Query(queryOptions)
+		log, count, err = queryLogs(datasourceId, queryOptions, cli.(provider.LokiProvider))
 		if err != nil {
 			logc.Error(ctx.Ctx, err.Error())
 			return []string{}
@@ -248,7 +283,7 @@ func logs(ctx *ctx.Context, datasourceId, datasourceType string, rule models.Ale
 			StartAt: int32(startsAt.Unix()),
 			EndAt:   int32(curAt.Unix()),
 		}
-		log, count, err = cli.(provider.AliCloudSlsDsProvider).Query(queryOptions)
+		log, count, err = queryLogs(datasourceId, queryOptions, cli.(provider.AliCloudSlsDsProvider))
 		if err != nil {
 			logc.Error(ctx.Ctx, err.Error())
 			return []string{}
@@ -277,7 +312,7 @@ func logs(ctx *ctx.Context, datasourceId, datasourceType string, rule models.Ale
 				RawJson:              rule.ElasticSearchConfig.RawJson,
 			},
 		}
-		log, count, err = cli.(provider.ElasticSearchDsProvider).Query(queryOptions)
+		log, count, err = queryLogs(datasourceId, queryOptions, cli.(provider.ElasticSearchDsProvider))
 		if err != nil {
 			logc.Error(ctx.Ctx, err.Error())
 			return []string{}
@@ -305,7 +340,7 @@ func logs(ctx *ctx.Context, datasourceId, datasourceType string, rule models.Ale
 			StartAt: int32(startsAt.Unix()),
 			EndAt:   int32(curAt.Unix()),
 		}
-		log, count, err = cli.(provider.VictoriaLogsProvider).Query(queryOptions)
+		log, count, err = queryLogs(datasourceId, queryOptions, cli.(provider.VictoriaLogsProvider))
 		if err != nil {
 			logc.Error(ctx.Ctx, err.Error())
 			return []string{}
@@ -329,7 +364,7 @@ func logs(ctx *ctx.Context, datasourceId, datasourceType string, rule models.Ale
 				Query: rule.ClickHouseConfig.LogQL,
 			},
 		}
-		log, count, err = cli.(provider.ClickHouseProvider).Query(queryOptions)
+		log, count, err = queryLogs(datasourceId, queryOptions, cli.(provider.ClickHouseProvider))
 		if err != nil {
 			logc.Error(ctx.Ctx, err.Error())
 			return []string{}
@@ -0,0 +1,227 @@
+package eval
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// 默认查询缓存时间窗口
+	DefaultQueryCacheTTL = 5 * time.Second
+)
+
+var (
+	queryCacheRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "watchalert_query_cache_requests_total",
+		Help: "评估查询缓存的请求次数, result 为 hit 或 miss",
+	}, []string{"result"})
+)
+
+// queryCache 规则评估共享的查询缓存, 由 NewAlertRuleEval 初始化
+var queryCache *QueryCache
+
+func init() {
+	prometheus.MustRegister(
+		queryCacheRequestsTotal,
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "watchalert_query_cache_hit_ratio",
+			Help: "评估查询缓存命中率",
+		}, func() float64 {
+			return queryCache.Stats().HitRate
+		}),
+	)
+}
+
+type (
+	// QueryCache 评估周期内的查询结果缓存
+	// 相同数据源、相同查询语句在同一时间窗口内只会真正执行一次, 并发的相同查询会等待首个查询的结果。
+	QueryCache struct {
+		ttl       time.Duration
+		mux       sync.Mutex
+		entries   map[string]*queryCacheEntry
+		lastSweep time.Time
+		hits      atomic.Int64
+		misses    atomic.Int64
+	}
+
+	queryCacheEntry struct {
+		done     chan struct{}
+		value    interface{}
+		err      error
+		expireAt time.Time
+	}
+
+	// QueryCacheStats 查询缓存统计
+	QueryCacheStats struct {
+		Enabled bool    `json:"enabled"`
+		TTL     int64   `json:"ttl"`
+		Entries int     `json:"entries"`
+		Hits    int64   `json:"hits"`
+		Misses  int64   `json:"misses"`
+		HitRate float64 `json:"hitRate"`
+	}
+)
+
+// NewQueryCache 创建查询缓存, ttl 小于 0 时关闭缓存, 等于 0 时使用默认值
+func NewQueryCache(ttl time.Duration) *QueryCache {
+	if ttl == 0 {
+		ttl = DefaultQueryCacheTTL
+	}
+
+	return &QueryCache{
+		ttl:     ttl,
+		entries: make(map[string]*queryCacheEntry),
+	}
+}
+
+// Enabled 缓存是否启用
+func (c *QueryCache) Enabled() bool {
+	return c != nil && c.ttl > 0
+}
+
+// Align 将时间对齐到缓存窗口, 使同一窗口内的查询拥有相同的时间范围
+func (c *QueryCache) Align(t time.Time) time.Time {
+	if !c.Enabled() {
+		return t
+	}
+	return t.Truncate(c.ttl)
+}
+
+// Do 执行查询, 同一窗口内的相同查询共享结果, 查询失败的结果不会被缓存
+func (c *QueryCache) Do(datasourceId, query string, fn func() (interface{}, error)) (interface{}, error) {
+	if !c.Enabled() {
+		return fn()
+	}
+
+	now := time.Now()
+	key := c.buildKey(datasourceId, query, now)
+
+	c.mux.Lock()
+	c.sweep(now)
+	if entry, ok := c.entries[key]; ok {
+		c.mux.Unlock()
+		<-entry.done
+		c.hits.Add(1)
+		queryCacheRequestsTotal.WithLabelValues("hit").Inc()
+		return entry.value, entry.err
+	}
+
+	entry := &queryCacheEntry{
+		done:     make(chan struct{}),
+		expireAt: c.Align(now).Add(c.ttl),
+	}
+	c.entries[key] = entry
+	c.mux.Unlock()
+
+	c.misses.Add(1)
+	queryCacheRequestsTotal.WithLabelValues("miss").Inc()
+
+	func() {
+		defer close(entry.done)
+		defer func() {
+			if r := recover(); r != nil {
+				entry.err = fmt.Errorf("query panic: %v", r)
+			}
+		}()
+		entry.value, entry.err = fn()
+	}()
+
+	if entry.err != nil {
+		c.mux.Lock()
+		if c.entries[key] == entry {
+			delete(c.entries, key)
+		}
+		c.mux.Unlock()
+	}
+
+	return entry.value, entry.err
+}
+
+// Stats 获取缓存统计信息
+func (c *QueryCache) Stats() QueryCacheStats {
+	if c == nil {
+		return QueryCacheStats{}
+	}
+
+	c.mux.Lock()
+	entries := len(c.entries)
+	c.mux.Unlock()
+
+	hits, misses := c.hits.Load(), c.misses.Load()
+	stats := QueryCacheStats{
+		Enabled: c.Enabled(),
+		TTL:     int64(c.ttl / time.Second),
+		Entries: entries,
+		Hits:    hits,
+		Misses:  misses,
+	}
+	if total := hits + misses; total > 0 {
+		stats.HitRate = float64(hits) / float64(total)
+	}
+
+	return stats
+}
+
+// buildKey 缓存 Key = 数据源 + 规范化后的查询语句 + 对齐后的时间窗口
+func (c *QueryCache) buildKey(datasourceId, query string, now time.Time) string {
+	return fmt.Sprintf("%s|%s|%d", datasourceId, normalizeQuery(query), c.Align(now).Unix())
+}
+
+// sweep 清理过期的缓存, 调用方需持有锁
+func (c *QueryCache) sweep(now time.Time) {
+	if now.Sub(c.lastSweep) < c.ttl {
+		return
+	}
+	c.lastSweep = now
+
+	for key, entry := range c.entries {
+		if !now.Before(entry.expireAt) {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// normalizeQuery 规范化查询语句, 忽略字符串字面量以外多余的空白字符
+// 引号内的标签值、日志关键字等保持原样, 避免不同的查询共用缓存
+func normalizeQuery(query string) string {
+	var (
+		b      strings.Builder
+		quote  rune
+		space  bool
+		escape bool
+	)
+	for _, r := range strings.TrimSpace(query) {
+		if quote != 0 {
+			b.WriteRune(r)
+			switch {
+			case escape:
+				escape = false
+			case r == '\\' && quote != '`':
+				escape = true
+			case r == quote:
+				quote = 0
+			}
+			continue
+		}
+
+		if unicode.IsSpace(r) {
+			space = true
+			continue
+		}
+		if space {
+			b.WriteByte(' ')
+			space = false
+		}
+		if r == '"' || r == '\'' || r == '`' {
+			quote = r
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,104 @@
+package eval
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestQueryCacheSharesIdenticalQueries(t *testing.T) {
+	c := NewQueryCache(time.Minute)
+
+	var (
+		calls atomic.Int32
+		wg    sync.WaitGroup
+	)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			// 多余的空白不影响缓存命中
+			query := "up{job=\"node\"}"
+			if i%2 == 0 {
+				query = "  up{job=\"node\"} "
+			}
+			res, err := c.Do("ds-1", query, func() (interface{}, error) {
+				calls.Add(1)
+				time.Sleep(10 * time.Millisecond)
+				return 42, nil
+			})
+			if err != nil || res.(int) != 42 {
+				t.Errorf("unexpected result %v, %v", res, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if calls.Load() != 1 {
+		t.Fatalf("expected 1 query execution, got %d", calls.Load())
+	}
+
+	stats := c.Stats()
+	if stats.Hits != 9 || stats.Misses != 1 || stats.HitRate != 0.9 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+
+	// 不同数据源不共享结果
+	_, _ = c.Do("ds-2", "up{job=\"node\"}", func() (interface{}, error) {
+		calls.Add(1)
+		return 0, nil
+	})
+	if calls.Load() != 2 {
+		t.Fatalf("different datasource should execute query")
+	}
+}
+
+func TestQueryCacheDoesNotCacheErrors(t *testing.T) {
+	c := NewQueryCache(time.Minute)
+
+	var calls int
+	fn := func() (interface{}, error) {
+		calls++
+		return nil, errors.New("boom")
+	}
+	_, _ = c.Do("ds", "q", fn)
+	_, _ = c.Do("ds", "q", fn)
+
+	if calls != 2 {
+		t.Fatalf("failed queries should not be cached, calls=%d", calls)
+	}
+}
+
+func TestQueryCacheDisabled(t *testing.T) {
+	c := NewQueryCache(-1)
+
+	var calls int
+	for i := 0; i < 3; i++ {
+		_, _ = c.Do("ds", "q", func() (interface{}, error) {
+			calls++
+			return nil, nil
+		})
+	}
+
+	if calls != 3 || c.Enabled() {
+		t.Fatalf("disabled cache should execute every query, calls=%d", calls)
+	}
+}
+
+func TestNormalizeQuery(t *testing.T) {
+	if got := normalizeQuery("  sum(rate(http_requests_total[5m]))\n  by (job) "); got != "sum(rate(http_requests_total[5m])) by (job)" {
+		t.Fatalf("normalizeQuery() = %q", got)
+	}
+
+	// 字符串字面量内的空白字符不做处理
+	a := normalizeQuery(`{app="web"} |= "connection  refused"`)
+	b := normalizeQuery(`{app="web"} |= "connection refused"`)
+	if a == b {
+		t.Fatalf("queries with different literals share key %q", a)
+	}
+	if got := normalizeQuery(`up{job="a \" b  c",  env='x  y'}`); got != `up{job="a \" b  c", env='x  y'}` {
+		t.Fatalf("normalizeQuery() = %q", got)
+	}
+}
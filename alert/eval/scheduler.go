@@ -0,0 +1,425 @@
+package eval
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"hash/fnv"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+	"watchAlert/internal/models"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/zeromicro/go-zero/core/logc"
+)
+
+const (
+	// 默认评估工作协程数量
+	DefaultSchedulerWorkers = 50
+	// 默认单个数据源最大并发查询数
+	DefaultDatasourceMaxInflight = 10
+	// 默认单个数据源最大排队查询数
+	DefaultDatasourceQueueDepth = 100
+
+	// 跳过评估的原因
+	SkipReasonStillRunning = "still_running"
+	SkipReasonWorkerBusy   = "worker_busy"
+	SkipReasonQueueFull    = "datasource_queue_full"
+)
+
+var (
+	evalSkippedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "watchalert_rule_eval_skipped_total",
+		Help: "规则评估被跳过的次数",
+	}, []string{"reason"})
+	evalLateTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "watchalert_rule_eval_late_total",
+		Help: "规则评估开始时间晚于计划时间超过半个评估周期的次数",
+	})
+	evalDelaySeconds = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "watchalert_rule_eval_delay_seconds",
+		Help:    "规则评估实际开始时间与计划时间的差值",
+		Buckets: []float64{0.01, 0.05, 0.1, 0.5, 1, 5, 10, 30, 60},
+	})
+	evalDurationSeconds = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "watchalert_rule_eval_duration_seconds",
+		Help:    "单次规则评估耗时",
+		Buckets: prometheus.DefBuckets,
+	})
+	evalScheduledRules = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "watchalert_rule_eval_scheduled_rules",
+		Help: "调度器中的规则数量",
+	})
+	datasourceInflight = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "watchalert_datasource_inflight_queries",
+		Help: "数据源当前正在执行的查询数",
+	}, []string{"datasource_id"})
+	datasourceQueued = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "watchalert_datasource_queued_queries",
+		Help: "数据源当前排队等待执行的查询数",
+	}, []string{"datasource_id"})
+)
+
+func init() {
+	prometheus.MustRegister(
+		evalSkippedTotal,
+		evalLateTotal,
+		evalDelaySeconds,
+		evalDurationSeconds,
+		evalScheduledRules,
+		datasourceInflight,
+		datasourceQueued,
+	)
+}
+
+type (
+	// SchedulerOptions 调度器配置
+	SchedulerOptions struct {
+		Workers               int
+		DatasourceMaxInflight int
+		DatasourceQueueDepth  int
+	}
+
+	// Scheduler 规则评估调度器
+	// 所有规则共享一个调度协程和固定大小的工作池, 每条规则根据 RuleId 计算出固定的相位偏移,
+	// 避免大量规则在同一时刻集中查询数据源。
+	Scheduler struct {
+		mux     sync.Mutex
+		rules   map[string]*scheduledRule
+		queue   ruleQueue
+		wake    chan struct{}
+		jobs    chan *scheduledJob
+		run     func(ctx context.Context, rule models.AlertRule)
+		limiter *DatasourceLimiter
+		workers int
+	}
+
+	scheduledRule struct {
+		ctx      context.Context
+		rule     models.AlertRule
+		interval time.Duration
+		next     time.Time
+		running  atomic.Bool
+		index    int
+	}
+
+	scheduledJob struct {
+		entry       *scheduledRule
+		scheduledAt time.Time
+	}
+)
+
+// NewScheduler 创建调度器, run 为单次规则评估的执行函数
+func NewScheduler(opts SchedulerOptions, run func(ctx context.Context, rule models.AlertRule)) *Scheduler {
+	if opts.Workers <= 0 {
+		opts.Workers = DefaultSchedulerWorkers
+	}
+
+	return &Scheduler{
+		rules:   make(map[string]*scheduledRule),
+		wake:    make(chan struct{}, 1),
+		jobs:    make(chan *scheduledJob, opts.Workers),
+		run:     run,
+		limiter: NewDatasourceLimiter(opts.DatasourceMaxInflight, opts.DatasourceQueueDepth),
+		workers: opts.Workers,
+	}
+}
+
+// Start 启动调度协程与工作池
+func (s *Scheduler) Start(ctx context.Context) {
+	for i := 0; i < s.workers; i++ {
+		go s.worker(ctx)
+	}
+	go s.dispatch(ctx)
+}
+
+// Limiter 获取数据源并发限制器
+func (s *Scheduler) Limiter() *DatasourceLimiter {
+	return s.limiter
+}
+
+// Add 添加规则到调度器, 已存在的同 ID 规则会被替换
+func (s *Scheduler) Add(ctx context.Context, rule models.AlertRule, interval time.Duration) {
+	if interval <= 0 {
+		logc.Errorf(ctx, "规则评估周期无效, RuleId: %s, RuleName: %s", rule.RuleId, rule.RuleName)
+		return
+	}
+
+	s.mux.Lock()
+	if old, ok := s.rules[rule.RuleId]; ok {
+		heap.Remove(&s.queue, old.index)
+	}
+	entry := &scheduledRule{
+		ctx:      ctx,
+		rule:     rule,
+		interval: interval,
+		next:     firstRunTime(time.Now(), rule.RuleId, interval),
+	}
+	s.rules[rule.RuleId] = entry
+	heap.Push(&s.queue, entry)
+	evalScheduledRules.Set(float64(len(s.rules)))
+	s.mux.Unlock()
+
+	s.notify()
+}
+
+// Remove 从调度器中移除规则, 正在执行的评估不会被中断
+func (s *Scheduler) Remove(ruleId string) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	entry, ok := s.rules[ruleId]
+	if !ok {
+		return
+	}
+	heap.Remove(&s.queue, entry.index)
+	delete(s.rules, ruleId)
+	evalScheduledRules.Set(float64(len(s.rules)))
+}
+
+// Len 获取调度中的规则数量
+func (s *Scheduler) Len() int {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	return len(s.rules)
+}
+
+func (s *Scheduler) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// dispatch 按计划时间将到期的规则投递到工作池
+func (s *Scheduler) dispatch(ctx context.Context) {
+	timer := time.NewTimer(time.Hour)
+	defer timer.Stop()
+
+	for {
+		wait := s.dispatchDue(time.Now())
+
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(wait)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.wake:
+		case <-timer.C:
+		}
+	}
+}
+
+// dispatchDue 投递所有到期的规则, 返回距离下一次到期的等待时间
+func (s *Scheduler) dispatchDue(now time.Time) time.Duration {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	for s.queue.Len() > 0 {
+		entry := s.queue[0]
+		if entry.next.After(now) {
+			return entry.next.Sub(now)
+		}
+
+		scheduledAt := entry.next
+		entry.next = nextRunTime(entry.next, now, entry.interval)
+		heap.Fix(&s.queue, entry.index)
+
+		if entry.ctx.Err() != nil {
+			heap.Remove(&s.queue, entry.index)
+			delete(s.rules, entry.rule.RuleId)
+			evalScheduledRules.Set(float64(len(s.rules)))
+			continue
+		}
+
+		// 上一轮评估仍在执行, 跳过本轮
+		if !entry.running.CompareAndSwap(false, true) {
+			evalSkippedTotal.WithLabelValues(SkipReasonStillRunning).Inc()
+			continue
+		}
+
+		select {
+		case s.jobs <- &scheduledJob{entry: entry, scheduledAt: scheduledAt}:
+		default:
+			entry.running.Store(false)
+			evalSkippedTotal.WithLabelValues(SkipReasonWorkerBusy).Inc()
+		}
+	}
+
+	return time.Hour
+}
+
+func (s *Scheduler) worker(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case job := <-s.jobs:
+			s.execute(job)
+		}
+	}
+}
+
+func (s *Scheduler) execute(job *scheduledJob) {
+	entry := job.entry
+	startAt := time.Now()
+	defer func() {
+		entry.running.Store(false)
+		evalDurationSeconds.Observe(time.Since(startAt).Seconds())
+		if r := recover(); r != nil {
+			logc.Error(entry.ctx, fmt.Sprintf("Recovered from rule eval panic: %s, RuleName: %s, RuleId: %s\n%s", r, entry.rule.RuleName, entry.rule.RuleId, debug.Stack()))
+		}
+	}()
+
+	delay := startAt.Sub(job.scheduledAt)
+	evalDelaySeconds.Observe(delay.Seconds())
+	if delay > entry.interval/2 {
+		evalLateTotal.Inc()
+	}
+
+	if entry.ctx.Err() != nil {
+		return
+	}
+
+	s.run(entry.ctx, entry.rule)
+}
+
+// ruleOffset 根据 RuleId 计算规则在评估周期内的固定偏移量
+func ruleOffset(ruleId string, interval time.Duration) time.Duration {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(ruleId))
+	return time.Duration(h.Sum64() % uint64(interval))
+}
+
+// firstRunTime 计算规则的首次执行时间, 同一规则在不同节点及重启后保持相同的相位
+func firstRunTime(now time.Time, ruleId string, interval time.Duration) time.Time {
+	base := now.Truncate(interval)
+	first := base.Add(ruleOffset(ruleId, interval))
+	if !first.After(now) {
+		first = first.Add(interval)
+	}
+	return first
+}
+
+// nextRunTime 计算下一次执行时间, 落后超过一个周期时直接对齐到当前时间之后的下一个相位
+func nextRunTime(prev, now time.Time, interval time.Duration) time.Time {
+	next := prev.Add(interval)
+	if !next.Before(now) {
+		return next
+	}
+	missed := now.Sub(prev) / interval
+	return prev.Add((missed + 1) * interval)
+}
+
+// ruleQueue 按下一次执行时间排序的最小堆
+type ruleQueue []*scheduledRule
+
+func (q ruleQueue) Len() int           { return len(q) }
+func (q ruleQueue) Less(i, j int) bool { return q[i].next.Before(q[j].next) }
+func (q ruleQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *ruleQueue) Push(x interface{}) {
+	entry := x.(*scheduledRule)
+	entry.index = len(*q)
+	*q = append(*q, entry)
+}
+
+func (q *ruleQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	entry := old[n-1]
+	old[n-1] = nil
+	entry.index = -1
+	*q = old[:n-1]
+	return entry
+}
+
+type (
+	// DatasourceLimiter 数据源查询并发限制器
+	DatasourceLimiter struct {
+		mux         sync.Mutex
+		slots       map[string]*datasourceSlot
+		maxInflight int
+		queueDepth  int
+	}
+
+	datasourceSlot struct {
+		sem     chan struct{}
+		waiting atomic.Int64
+	}
+)
+
+// NewDatasourceLimiter 创建数据源并发限制器
+func NewDatasourceLimiter(maxInflight, queueDepth int) *DatasourceLimiter {
+	if maxInflight <= 0 {
+		maxInflight = DefaultDatasourceMaxInflight
+	}
+	if queueDepth <= 0 {
+		queueDepth = DefaultDatasourceQueueDepth
+	}
+
+	return &DatasourceLimiter{
+		slots:       make(map[string]*datasourceSlot),
+		maxInflight: maxInflight,
+		queueDepth:  queueDepth,
+	}
+}
+
+func (l *DatasourceLimiter) slot(datasourceId string) *datasourceSlot {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	s, ok := l.slots[datasourceId]
+	if !ok {
+		s = &datasourceSlot{sem: make(chan struct{}, l.maxInflight)}
+		l.slots[datasourceId] = s
+	}
+	return s
+}
+
+// Acquire 获取数据源查询名额, 排队数超过上限或 ctx 取消时返回 false
+func (l *DatasourceLimiter) Acquire(ctx context.Context, datasourceId string) bool {
+	s := l.slot(datasourceId)
+
+	if s.waiting.Add(1) > int64(l.queueDepth) {
+		s.waiting.Add(-1)
+		evalSkippedTotal.WithLabelValues(SkipReasonQueueFull).Inc()
+		return false
+	}
+	datasourceQueued.WithLabelValues(datasourceId).Inc()
+	defer func() {
+		s.waiting.Add(-1)
+		datasourceQueued.WithLabelValues(datasourceId).Dec()
+	}()
+
+	select {
+	case s.sem <- struct{}{}:
+		datasourceInflight.WithLabelValues(datasourceId).Inc()
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// Release 释放数据源查询名额
+func (l *DatasourceLimiter) Release(datasourceId string) {
+	s := l.slot(datasourceId)
+	select {
+	case <-s.sem:
+		datasourceInflight.WithLabelValues(datasourceId).Dec()
+	default:
+	}
+}
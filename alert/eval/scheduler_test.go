@@ -0,0 +1,89 @@
+package eval
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+	"watchAlert/internal/models"
+)
+
+func TestFirstRunTimeIsDeterministic(t *testing.T) {
+	interval := time.Minute
+	now := time.Unix(1700000000, 0)
+
+	a := firstRunTime(now, "a-rule", interval)
+	b := firstRunTime(now.Add(3*time.Second), "a-rule", interval)
+	if a.Sub(a.Truncate(interval)) != b.Sub(b.Truncate(interval)) {
+		t.Fatalf("offset changed between calls: %v vs %v", a, b)
+	}
+	if !a.After(now) || a.Sub(now) > interval {
+		t.Fatalf("first run %v not within one interval after %v", a, now)
+	}
+	if ruleOffset("a-rule", interval) == ruleOffset("b-rule", interval) {
+		t.Fatalf("different rules should be spread across the interval")
+	}
+}
+
+func TestNextRunTimeSkipsMissedSlots(t *testing.T) {
+	interval := 10 * time.Second
+	prev := time.Unix(1700000000, 0)
+
+	if got := nextRunTime(prev, prev.Add(time.Second), interval); !got.Equal(prev.Add(interval)) {
+		t.Fatalf("expected next slot, got %v", got)
+	}
+	if got := nextRunTime(prev, prev.Add(35*time.Second), interval); !got.Equal(prev.Add(40 * time.Second)) {
+		t.Fatalf("expected aligned slot after now, got %v", got)
+	}
+}
+
+func TestSchedulerSkipsWhilePreviousRunIsExecuting(t *testing.T) {
+	var (
+		runs    atomic.Int32
+		release = make(chan struct{})
+	)
+	s := NewScheduler(SchedulerOptions{Workers: 2}, func(ctx context.Context, rule models.AlertRule) {
+		runs.Add(1)
+		<-release
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s.Start(ctx)
+	s.Add(ctx, models.AlertRule{RuleId: "slow"}, 20*time.Millisecond)
+
+	time.Sleep(200 * time.Millisecond)
+	close(release)
+
+	if n := runs.Load(); n != 1 {
+		t.Fatalf("expected exactly one concurrent run, got %d", n)
+	}
+
+	s.Remove("slow")
+	if s.Len() != 0 {
+		t.Fatalf("rule not removed from scheduler")
+	}
+}
+
+func TestDatasourceLimiterQueueDepth(t *testing.T) {
+	l := NewDatasourceLimiter(1, 1)
+	ctx := context.Background()
+
+	if !l.Acquire(ctx, "ds") {
+		t.Fatal("first acquire should succeed")
+	}
+
+	waiting := make(chan bool)
+	go func() { waiting <- l.Acquire(ctx, "ds") }()
+	time.Sleep(20 * time.Millisecond)
+
+	if l.Acquire(ctx, "ds") {
+		t.Fatal("acquire beyond queue depth should be rejected")
+	}
+
+	l.Release("ds")
+	if !<-waiting {
+		t.Fatal("queued acquire should succeed after release")
+	}
+	l.Release("ds")
+}
@@ -0,0 +1,150 @@
+package mute
+
+import (
+	"fmt"
+	"regexp"
+	"sync"
+	"watchAlert/internal/models"
+)
+
+// 静默匹配操作符
+const (
+	MatchEqual     = "="
+	MatchNotEqual  = "!="
+	MatchRegexp    = "=~"
+	MatchNotRegexp = "!~"
+	// 旧版本的操作符, 按正则部分匹配, 保持升级前已保存的静默规则行为不变
+	MatchLegacy = "=="
+)
+
+// regexpCache 已编译的正则表达式, 避免每次匹配时重复编译
+var regexpCache, legacyRegexpCache sync.Map
+
+// compileMatcherRegexp 编译并缓存正则, 与 Prometheus 一致, 正则需要完整匹配标签值
+func compileMatcherRegexp(pattern string) (*regexp.Regexp, error) {
+	if re, ok := regexpCache.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+
+	re, err := regexp.Compile("^(?:" + pattern + ")$")
+	if err != nil {
+		return nil, err
+	}
+	regexpCache.Store(pattern, re)
+
+	return re, nil
+}
+
+// ValidateMatchers 校验静默匹配条件, 保存静默规则前调用
+func ValidateMatchers(labels []models.SilenceLabel) error {
+	if len(labels) == 0 {
+		return fmt.Errorf("静默规则至少需要一个匹配条件")
+	}
+
+	for _, label := range labels {
+		if label.Key == "" {
+			return fmt.Errorf("匹配条件的标签名不能为空")
+		}
+
+		switch label.Operator {
+		case MatchEqual, MatchNotEqual:
+		case MatchLegacy:
+			if _, err := regexp.Compile(label.Value); err != nil {
+				return fmt.Errorf("标签 %s 的正则表达式 %s 无效, err: %s", label.Key, label.Value, err.Error())
+			}
+		case MatchRegexp, MatchNotRegexp:
+			if _, err := compileMatcherRegexp(label.Value); err != nil {
+				return fmt.Errorf("标签 %s 的正则表达式 %s 无效, err: %s", label.Key, label.Value, err.Error())
+			}
+		default:
+			return fmt.Errorf("标签 %s 使用了不支持的操作符 %s", label.Key, label.Operator)
+		}
+	}
+
+	return nil
+}
+
+// matchLabel 判断单个匹配条件, 不存在的标签按空字符串处理
+func matchLabel(label models.SilenceLabel, val string) bool {
+	switch label.Operator {
+	case MatchEqual:
+		return val == label.Value
+	case MatchNotEqual:
+		return val != label.Value
+	case MatchRegexp, MatchNotRegexp:
+		re, err := compileMatcherRegexp(label.Value)
+		if err != nil {
+			return false
+		}
+		return re.MatchString(val) == (label.Operator == MatchRegexp)
+	case MatchLegacy:
+		return matchLegacy(label.Value, val)
+	default:
+		return false
+	}
+}
+
+// matchLegacy 旧版本 == 操作符的语义, 值作为不锚定的正则匹配, 无效正则时按相等处理
+func matchLegacy(pattern, val string) bool {
+	if re, ok := legacyRegexpCache.Load(pattern); ok {
+		return re.(*regexp.Regexp).MatchString(val)
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return val == pattern
+	}
+	legacyRegexpCache.Store(pattern, re)
+	return re.MatchString(val)
+}
+
+// MigrateLegacyMatchers 将升级前保存的 = / != 转换为等价的 =~ / !~
+// 升级前 = / != 的值按不锚定的正则匹配, 转换时在两侧补齐 .*; 无效的正则升级前按相等处理, 保持不变
+func MigrateLegacyMatchers(labels []models.SilenceLabel) ([]models.SilenceLabel, bool) {
+	var changed bool
+	result := make([]models.SilenceLabel, len(labels))
+	for i, label := range labels {
+		result[i] = label
+		if label.Operator != MatchEqual && label.Operator != MatchNotEqual {
+			continue
+		}
+		if _, err := regexp.Compile(label.Value); err != nil {
+			continue
+		}
+
+		result[i].Value = ".*(?:" + label.Value + ").*"
+		result[i].Operator = MatchRegexp
+		if label.Operator == MatchNotEqual {
+			result[i].Operator = MatchNotRegexp
+		}
+		changed = true
+	}
+
+	return result, changed
+}
+
+// labelValue 获取标签值, 非字符串类型的标签值转换为字符串后参与匹配
+func labelValue(labels map[string]interface{}, key string) string {
+	value, ok := labels[key]
+	if !ok || value == nil {
+		return ""
+	}
+
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		// JSON 反序列化后的数字类型, 整数不带小数位
+		if v == float64(int64(v)) {
+			return fmt.Sprintf("%d", int64(v))
+		}
+		return fmt.Sprintf("%v", v)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// MatchLabels 判断事件是否满足静默规则的所有匹配条件
+func MatchLabels(labels map[string]interface{}, fingerprint string, muteLabels []models.SilenceLabel) bool {
+	return evalCondition(labels, fingerprint, muteLabels)
+}
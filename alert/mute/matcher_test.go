@@ -0,0 +1,93 @@
+package mute
+
+import (
+	"testing"
+	"watchAlert/internal/models"
+)
+
+func TestEvalConditionOperators(t *testing.T) {
+	labels := map[string]interface{}{
+		"instance": "db-01:9100",
+		"port":     float64(3306),
+		"env":      "prod",
+	}
+
+	cases := []struct {
+		name    string
+		matcher []models.SilenceLabel
+		want    bool
+	}{
+		{"equal is exact", []models.SilenceLabel{{Key: "instance", Operator: "=", Value: "db-01"}}, false},
+		{"legacy double equal", []models.SilenceLabel{{Key: "env", Operator: "==", Value: "prod"}}, true},
+		{"legacy double equal is regex", []models.SilenceLabel{{Key: "instance", Operator: "==", Value: "db-0[0-9]"}}, true},
+		{"legacy double equal is partial", []models.SilenceLabel{{Key: "instance", Operator: "==", Value: "db"}}, true},
+		{"regex is anchored", []models.SilenceLabel{{Key: "instance", Operator: "=~", Value: "db-.*"}}, true},
+		{"regex partial does not match", []models.SilenceLabel{{Key: "instance", Operator: "=~", Value: "db"}}, false},
+		{"negative regex", []models.SilenceLabel{{Key: "env", Operator: "!~", Value: "test|dev"}}, true},
+		{"non string value", []models.SilenceLabel{{Key: "port", Operator: "=", Value: "3306"}}, true},
+		{"absent label not equal", []models.SilenceLabel{{Key: "team", Operator: "!=", Value: "dba"}}, true},
+		{"absent label equal", []models.SilenceLabel{{Key: "team", Operator: "=", Value: "dba"}}, false},
+		{"fingerprint", []models.SilenceLabel{{Key: "fingerprint", Operator: "=", Value: "abc"}}, true},
+	}
+
+	for _, c := range cases {
+		if got := evalCondition(labels, "abc", c.matcher); got != c.want {
+			t.Errorf("%s: got %v, want %v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestValidateMatchers(t *testing.T) {
+	if err := ValidateMatchers([]models.SilenceLabel{{Key: "a", Operator: "=~", Value: "("}}); err == nil {
+		t.Fatal("expected invalid regex to be rejected")
+	}
+	if err := ValidateMatchers([]models.SilenceLabel{{Key: "a", Operator: "~", Value: "x"}}); err == nil {
+		t.Fatal("expected unknown operator to be rejected")
+	}
+	if err := ValidateMatchers([]models.SilenceLabel{{Key: "a", Operator: "!=", Value: "x"}}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMigrateLegacyMatchers(t *testing.T) {
+	labels := []models.SilenceLabel{
+		{Key: "instance", Operator: "=", Value: "db-0[0-9]"},
+		{Key: "env", Operator: "!=", Value: "test|dev"},
+		{Key: "job", Operator: "=", Value: "("},
+		{Key: "region", Operator: "=~", Value: "cn-.*"},
+		{Key: "team", Operator: "==", Value: "dba"},
+	}
+	migrated, changed := MigrateLegacyMatchers(labels)
+	if !changed {
+		t.Fatal("expected legacy matchers to be migrated")
+	}
+	if err := ValidateMatchers(migrated); err != nil {
+		t.Fatal(err)
+	}
+	if migrated[0].Operator != MatchRegexp || migrated[1].Operator != MatchNotRegexp {
+		t.Fatalf("unexpected operators: %+v", migrated)
+	}
+	// 无效的正则升级前按相等处理, 其余操作符不受影响
+	for i := 2; i < len(labels); i++ {
+		if migrated[i] != labels[i] {
+			t.Fatalf("matcher %d should be kept, got %+v", i, migrated[i])
+		}
+	}
+	if labels[0].Operator != "=" {
+		t.Fatal("input should not be modified")
+	}
+
+	// 迁移后的匹配结果与升级前按不锚定正则匹配一致
+	for _, value := range []string{"db-01:9100", "pre-db-02", "db-x", "dev", "prod", "testing", ""} {
+		for i := 0; i < 2; i++ {
+			legacy := matchLegacy(labels[i].Value, value) == (labels[i].Operator == MatchEqual)
+			if got := matchLabel(migrated[i], value); got != legacy {
+				t.Errorf("%s %q: got %v, want %v", migrated[i].Operator, value, got, legacy)
+			}
+		}
+	}
+
+	if _, changed := MigrateLegacyMatchers([]models.SilenceLabel{{Key: "a", Operator: "=~", Value: "x"}}); changed {
+		t.Fatal("matchers without legacy operators should not change")
+	}
+}
@@ -2,7 +2,6 @@ package mute
 
 import (
 	"github.com/zeromicro/go-zero/core/logc"
-	"regexp"
 	"time"
 	"watchAlert/internal/ctx"
 	models "watchAlert/internal/models"
@@ -98,39 +97,14 @@ func GetMatchedSilenceRule(mute MuteParams) *models.AlertSilences {
 func evalCondition(metrics map[string]interface{}, fingerprint string, muteLabels []models.SilenceLabel) bool {
 	for _, muteLabel := range muteLabels {
 		var val string
-		var exists bool
-
 		// 特殊处理 fingerprint 标签：直接使用参数中的 fingerprint
 		if muteLabel.Key == "fingerprint" {
 			val = fingerprint
-			exists = fingerprint != ""
 		} else {
-			// 其他标签从 metrics 中获取
-			value, ok := metrics[muteLabel.Key]
-			if !ok {
-				return false
-			}
-			val, exists = value.(string)
-			if !exists {
-				continue
-			}
-		}
-
-		if !exists {
-			return false
-		}
-
-		var matched bool
-		switch muteLabel.Operator {
-		case "==", "=":
-			matched = regexp.MustCompile(muteLabel.Value).MatchString(val)
-		case "!=":
-			matched = !regexp.MustCompile(muteLabel.Value).MatchString(val)
-		default:
-			matched = false
+			val = labelValue(metrics, muteLabel.Key)
 		}
 
-		if !matched {
+		if !matchLabel(muteLabel, val) {
 			return false // 只要有一个不匹配，就不静默
 		}
 	}
@@ -126,18 +126,27 @@ func (m *ConsumeProbing) sendAlert(alert models.ProbingEvent) {
 		return
 	}
 
-	alert.DutyUser = strings.Join(process.GetDutyUsers(m.ctx, noticeData), " ")
+	dutyUsers, dutyPhones := process.GetDutyUsers(m.ctx, noticeData, process.DutyTime(alert.IsRecovered, alert.RecoverTime, alert.LastEvalTime, alert.FirstTriggerTime))
+	alert.DutyUser = strings.Join(dutyUsers, " ")
+	phoneNumber := noticeData.PhoneNumber
+	if len(phoneNumber) == 0 {
+		phoneNumber = dutyPhones
+	}
 	err = sender.Sender(m.ctx, sender.SendParams{
-		RuleName:    alert.RuleName,
-		TenantId:    alert.TenantId,
-		NoticeType:  noticeData.NoticeType,
-		NoticeId:    noticeData.Uuid,
-		NoticeName:  noticeData.Name,
-		IsRecovered: alert.IsRecovered,
-		Hook:        noticeData.DefaultHook,
-		Email:       noticeData.Email,
-		Content:     m.getContent(alert, noticeData),
-		Sign:        noticeData.DefaultSign,
+		RuleName:      alert.RuleName,
+		TenantId:      alert.TenantId,
+		EventId:       alert.GetEventId(),
+		NoticeType:    noticeData.NoticeType,
+		NoticeId:      noticeData.Uuid,
+		NoticeName:    noticeData.Name,
+		IsRecovered:   alert.IsRecovered,
+		IsFirstNotice: !alert.IsRecovered && alert.LastSendTime == 0,
+		Hook:          noticeData.DefaultHook,
+		Email:         noticeData.Email,
+		Content:       m.getContent(alert, noticeData),
+		PhoneNumber:   phoneNumber,
+		Sign:          noticeData.DefaultSign,
+		Webhook:       noticeData.Webhook,
 	})
 	if err != nil {
 		logc.Errorf(ctx.Ctx, err.Error())
@@ -0,0 +1,245 @@
+package process
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"watchAlert/internal/ctx"
+	"watchAlert/internal/models"
+	"watchAlert/pkg/ai"
+	"watchAlert/pkg/tools"
+
+	"github.com/zeromicro/go-zero/core/logc"
+)
+
+// 提示词中附带的关联告警及历史记录数量
+const (
+	aiRelatedEventLimit = 10
+	aiHistoryEventLimit = 5
+)
+
+// 告警通知附带的 Ai 分析在后台执行, 同一事件推送给多个通知对象时共用一次分析;
+// 分析耗时超过等待时间时先发送不带分析结果的通知, 结果在后续的重复通知及告警详情中展示。
+// 单次分析超过 aiAutoAnalysisTimeout 时放弃, 同一事件在 aiAutoAnalysisInterval 内不再重复发起分析
+const (
+	aiAutoAnalysisWait     = 15 * time.Second
+	aiAutoAnalysisTimeout  = 60 * time.Second
+	aiAutoAnalysisInterval = 10 * time.Minute
+)
+
+// aiAnalysisJobs 正在分析及最近完成分析(含失败)的告警事件
+var aiAnalysisJobs sync.Map
+
+type aiAnalysisJob struct {
+	startAt time.Time
+	done    chan struct{}
+	content string
+}
+
+// AiEventContentHash 告警事件分析内容的哈希, 同一告警的描述或查询变化后需要重新分析
+func AiEventContentHash(event models.AlertCurEvent) string {
+	return models.BuildAiContentHash(event.RuleId, event.Fingerprint, event.Annotations, event.SearchQL)
+}
+
+// GetAiAnalysisCache 获取未过期的分析结果
+func GetAiAnalysisCache(ctx *ctx.Context, ruleId, fingerprint, contentHash string) (models.AiContentRecord, bool) {
+	record, exist, err := ctx.DB.Ai().Get(ruleId, fingerprint, contentHash)
+	if err != nil || !exist || record.IsExpired(time.Now().Unix()) {
+		return record, false
+	}
+
+	return record, true
+}
+
+// SaveAiAnalysis 按缓存策略保存分析结果, 缓存时长为 0 时不保存
+func SaveAiAnalysis(ctx *ctx.Context, cfg models.AiConfig, record models.AiContentRecord) error {
+	ttl := cfg.GetCacheTTL()
+	if ttl == 0 {
+		return nil
+	}
+
+	record.CreateAt = time.Now().Unix()
+	record.ExpireAt = record.CreateAt + ttl
+	return ctx.DB.Ai().Save(record)
+}
+
+// BuildEventAnalysisPrompt 使用系统设置的提示词模版渲染告警内容, 并附带标签、首次及当前值、关联告警和历史记录
+func BuildEventAnalysisPrompt(ctx *ctx.Context, cfg models.AiConfig, event models.AlertCurEvent) string {
+	var b strings.Builder
+	b.WriteString(RenderAiPrompt(cfg, event.RuleName, event.Annotations, event.SearchQL))
+
+	b.WriteString("\n\n以下是该告警的上下文信息, 请结合分析:\n")
+	fmt.Fprintf(&b, "- 告警等级: %s, 当前状态: %s\n", event.Severity, event.Status)
+	fmt.Fprintf(&b, "- 首次触发: %s\n", time.Unix(event.FirstTriggerTime, 0).Format("2006-01-02 15:04:05"))
+	if current, ok := event.Labels["value"]; ok {
+		fmt.Fprintf(&b, "- 首次触发值: %v, 当前值: %v\n", event.GetFirstValue(current), current)
+	}
+	fmt.Fprintf(&b, "- 标签: %s\n", tools.JsonMarshalToString(event.Labels))
+
+	if related := relatedFiringEvents(ctx, event); len(related) > 0 {
+		b.WriteString("\n同一故障中心内相关的告警(同规则或同实例):\n")
+		for _, e := range related {
+			fmt.Fprintf(&b, "- [%s] %s, 触发于 %s, 标签: %s\n", e.Severity, e.RuleName,
+				time.Unix(e.FirstTriggerTime, 0).Format("2006-01-02 15:04:05"), tools.JsonMarshalToString(e.Labels))
+		}
+	}
+
+	history, err := ctx.DB.Event().ListHistoryByFingerprint(event.TenantId, event.Fingerprint, aiHistoryEventLimit)
+	if err == nil && len(history) > 0 {
+		b.WriteString("\n该告警最近的历史记录:\n")
+		for _, h := range history {
+			fmt.Fprintf(&b, "- 触发于 %s, 恢复于 %s, 持续 %s\n",
+				time.Unix(h.FirstTriggerTime, 0).Format("2006-01-02 15:04:05"),
+				time.Unix(h.RecoverTime, 0).Format("2006-01-02 15:04:05"),
+				time.Duration(h.RecoverTime-h.FirstTriggerTime)*time.Second)
+		}
+	}
+
+	return b.String()
+}
+
+// RenderAiPrompt 渲染系统设置中的提示词模版
+func RenderAiPrompt(cfg models.AiConfig, ruleName, content, searchQL string) string {
+	prompt := cfg.Prompt
+	prompt = strings.ReplaceAll(prompt, "{{ RuleName }}", ruleName)
+	prompt = strings.ReplaceAll(prompt, "{{ Content }}", content)
+	prompt = strings.ReplaceAll(prompt, "{{ SearchQL }}", searchQL)
+	return prompt
+}
+
+// relatedFiringEvents 同一故障中心中与当前告警同规则或同实例的告警中事件
+func relatedFiringEvents(ctx *ctx.Context, event models.AlertCurEvent) []models.AlertCurEvent {
+	events, err := ctx.Redis.Alert().GetAllEvents(models.BuildAlertEventCacheKey(event.TenantId, event.FaultCenterId))
+	if err != nil {
+		return nil
+	}
+
+	instance := fmt.Sprint(event.Labels["instance"])
+	var related []models.AlertCurEvent
+	for _, e := range events {
+		if e.Fingerprint == event.Fingerprint || e.Status != models.StateAlerting {
+			continue
+		}
+		sameInstance := event.Labels["instance"] != nil && fmt.Sprint(e.Labels["instance"]) == instance
+		if e.RuleId != event.RuleId && !sameInstance {
+			continue
+		}
+		related = append(related, *e)
+	}
+
+	sort.Slice(related, func(i, j int) bool {
+		return related[i].FirstTriggerTime < related[j].FirstTriggerTime
+	})
+	if len(related) > aiRelatedEventLimit {
+		related = related[:aiRelatedEventLimit]
+	}
+	return related
+}
+
+// AnalyzeEvent 分析告警事件, 未过期的相同内容直接返回缓存结果, deep 为 true 时忽略缓存重新分析
+func AnalyzeEvent(ctx *ctx.Context, cfg models.AiConfig, event models.AlertCurEvent, deep bool) (string, error) {
+	return analyzeEvent(ctx, ctx.Ctx, cfg, event, deep)
+}
+
+func analyzeEvent(ctx *ctx.Context, reqCtx context.Context, cfg models.AiConfig, event models.AlertCurEvent, deep bool) (string, error) {
+	contentHash := AiEventContentHash(event)
+	if !deep {
+		if record, ok := GetAiAnalysisCache(ctx, event.RuleId, event.Fingerprint, contentHash); ok {
+			return record.Content, nil
+		}
+	}
+
+	client, err := ctx.Redis.ProviderPools().GetClient("AiClient")
+	if err != nil {
+		return "", err
+	}
+
+	prompt := BuildEventAnalysisPrompt(ctx, cfg, event)
+	if deep {
+		prompt = fmt.Sprintf("注意, 请深度思考下面的问题!\n%s", prompt)
+	}
+	completion, err := client.(ai.AiClient).ChatCompletion(ai.WithTenant(reqCtx, event.TenantId), prompt)
+	if err != nil {
+		return "", err
+	}
+
+	err = SaveAiAnalysis(ctx, cfg, models.AiContentRecord{
+		TenantId:    event.TenantId,
+		RuleId:      event.RuleId,
+		Fingerprint: event.Fingerprint,
+		EventId:     event.EventId,
+		ContentHash: contentHash,
+		Content:     completion,
+	})
+	if err != nil {
+		logc.Error(ctx.Ctx, fmt.Sprintf("保存 Ai 分析结果失败: %v", err))
+	}
+
+	return completion, nil
+}
+
+// attachAiAnalysis 按设置为指定等级的告警通知附带分析结果, 同一告警事件复用未过期的分析
+// 尚无分析结果时在后台发起分析, 并最多等待 aiAutoAnalysisWait, 超时后本次通知不附带分析结果
+func attachAiAnalysis(ctx *ctx.Context, event *models.AlertCurEvent) {
+	if event.IsRecovered {
+		return
+	}
+
+	setting, err := ctx.DB.Setting().Get()
+	if err != nil || !setting.AiConfig.GetEnable() || !setting.AiConfig.AutoAnalysis.Match(event.Severity) {
+		return
+	}
+
+	record, exist, err := ctx.DB.Ai().GetLatestByEvent(event.TenantId, event.EventId)
+	if err == nil && exist && !record.IsExpired(time.Now().Unix()) {
+		event.AiAnalysis = record.Content
+		return
+	}
+
+	job := loadAiAnalysisJob(ctx, setting.AiConfig, *event)
+	timer := time.NewTimer(aiAutoAnalysisWait)
+	defer timer.Stop()
+	select {
+	case <-job.done:
+		event.AiAnalysis = job.content
+	case <-timer.C:
+		logc.Info(ctx.Ctx, fmt.Sprintf("告警 Ai 分析未在 %s 内完成, 本次通知不附带分析结果, rule: %s", aiAutoAnalysisWait, event.RuleName))
+	}
+}
+
+// loadAiAnalysisJob 获取事件的分析任务, 不存在或已超过间隔时在后台发起分析
+func loadAiAnalysisJob(ctx *ctx.Context, cfg models.AiConfig, event models.AlertCurEvent) *aiAnalysisJob {
+	key := event.TenantId + ":" + event.EventId
+	now := time.Now()
+	if v, ok := aiAnalysisJobs.Load(key); ok && now.Sub(v.(*aiAnalysisJob).startAt) < aiAutoAnalysisInterval {
+		return v.(*aiAnalysisJob)
+	}
+
+	aiAnalysisJobs.Range(func(k, v interface{}) bool {
+		if now.Sub(v.(*aiAnalysisJob).startAt) >= aiAutoAnalysisInterval {
+			aiAnalysisJobs.CompareAndDelete(k, v)
+		}
+		return true
+	})
+	job := &aiAnalysisJob{startAt: now, done: make(chan struct{})}
+	if v, loaded := aiAnalysisJobs.LoadOrStore(key, job); loaded {
+		return v.(*aiAnalysisJob)
+	}
+
+	go func() {
+		defer close(job.done)
+
+		c, cancel := context.WithTimeout(ctx.Ctx, aiAutoAnalysisTimeout)
+		defer cancel()
+		content, err := analyzeEvent(ctx, c, cfg, event, false)
+		if err != nil {
+			logc.Error(ctx.Ctx, fmt.Sprintf("告警通知附带 Ai 分析失败, rule: %s, err: %v", event.RuleName, err))
+			return
+		}
+		job.content = content
+	}()
+	return job
+}
@@ -0,0 +1,326 @@
+package process
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+	"watchAlert/internal/ctx"
+	"watchAlert/internal/models"
+)
+
+// ClusterEvents 对活跃告警聚类
+// 触发时间相差在关联窗口内, 且关联标签取值相同或不同规则的告警详情相似时, 两条告警归为同一聚类, 关联关系可传递。
+func ClusterEvents(cfg models.CorrelationConfig, events []*models.AlertCurEvent) []models.EventCluster {
+	if len(events) == 0 {
+		return nil
+	}
+
+	sorted := make([]*models.AlertCurEvent, len(events))
+	copy(sorted, events)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].FirstTriggerTime != sorted[j].FirstTriggerTime {
+			return sorted[i].FirstTriggerTime < sorted[j].FirstTriggerTime
+		}
+		return sorted[i].Fingerprint < sorted[j].Fingerprint
+	})
+
+	var (
+		window     = cfg.GetWindow()
+		similarity = cfg.GetTextSimilarity()
+		labelKeys  = cfg.GetLabelKeys()
+		tokens     = make([]map[string]struct{}, len(sorted))
+		parent     = make([]int, len(sorted))
+		shared     = make(map[int]map[string]string)
+	)
+	for i, event := range sorted {
+		parent[i] = i
+		if similarity > 0 {
+			tokens[i] = textTokens(event.Annotations)
+		}
+	}
+
+	var find func(i int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	union := func(i, j int, labels map[string]string) {
+		ri, rj := find(i), find(j)
+		if ri != rj {
+			// 以更早触发的告警作为聚类的根
+			if rj < ri {
+				ri, rj = rj, ri
+			}
+			parent[rj] = ri
+			for k, v := range shared[rj] {
+				labels[k] = v
+			}
+			delete(shared, rj)
+		}
+		if len(labels) == 0 {
+			return
+		}
+		if shared[ri] == nil {
+			shared[ri] = map[string]string{}
+		}
+		for k, v := range labels {
+			shared[ri][k] = v
+		}
+	}
+
+	for i := range sorted {
+		for j := i + 1; j < len(sorted); j++ {
+			if sorted[j].FirstTriggerTime-sorted[i].FirstTriggerTime > window {
+				break
+			}
+
+			labels := sharedLabels(sorted[i], sorted[j], labelKeys)
+			if len(labels) > 0 {
+				union(i, j, labels)
+				continue
+			}
+			if similarity > 0 && sorted[i].RuleId != sorted[j].RuleId && jaccard(tokens[i], tokens[j]) >= similarity {
+				union(i, j, map[string]string{})
+			}
+		}
+	}
+
+	var (
+		order    []int
+		clusters = make(map[int]*models.EventCluster)
+	)
+	for i, event := range sorted {
+		root := find(i)
+		cluster, ok := clusters[root]
+		if !ok {
+			cluster = &models.EventCluster{
+				ClusterId:        sorted[root].Fingerprint,
+				Severity:         event.Severity,
+				FirstTriggerTime: event.FirstTriggerTime,
+				SharedLabels:     shared[root],
+			}
+			clusters[root] = cluster
+			order = append(order, root)
+		}
+
+		if event.Severity < cluster.Severity {
+			cluster.Severity = event.Severity
+		}
+		if !slices.Contains(cluster.RuleNames, event.RuleName) {
+			cluster.RuleNames = append(cluster.RuleNames, event.RuleName)
+		}
+		e := *event
+		e.ClusterId = cluster.ClusterId
+		cluster.Events = append(cluster.Events, e)
+	}
+
+	result := make([]models.EventCluster, 0, len(order))
+	for _, root := range order {
+		result = append(result, *clusters[root])
+	}
+	return result
+}
+
+// ClusterFaultCenterEvents 对故障中心的活跃告警聚类, events 中的事件覆盖缓存中同指纹的事件
+func ClusterFaultCenterEvents(ctx *ctx.Context, faultCenter models.FaultCenter, events ...*models.AlertCurEvent) ([]models.EventCluster, error) {
+	cached, err := ctx.Redis.Alert().GetAllEvents(models.BuildAlertEventCacheKey(faultCenter.TenantId, faultCenter.ID))
+	if err != nil {
+		return nil, err
+	}
+
+	active := make(map[string]*models.AlertCurEvent, len(cached))
+	for _, event := range cached {
+		if isClusterCandidate(event) {
+			active[event.Fingerprint] = event
+		}
+	}
+	for _, event := range events {
+		if isClusterCandidate(event) {
+			active[event.Fingerprint] = event
+		}
+	}
+
+	list := make([]*models.AlertCurEvent, 0, len(active))
+	for _, event := range active {
+		list = append(list, event)
+	}
+	return ClusterEvents(faultCenter.Correlation, list), nil
+}
+
+// isClusterCandidate 仅告警中及待恢复的事件参与聚类
+func isClusterCandidate(event *models.AlertCurEvent) bool {
+	return !event.IsRecovered && (event.Status == models.StateAlerting || event.Status == models.StatePendingRecovery)
+}
+
+// withClusterGroupByAlerts 按聚类聚合告警通知
+// 同一聚类只由一条告警发送通知, 聚类中已有告警发送过通知时, 其余告警不再单独通知, 由其代为通知的告警也不再发送恢复通知。
+func withClusterGroupByAlerts(ctx *ctx.Context, timeInt int64, faultCenter models.FaultCenter, alertGroups map[string][]*models.AlertCurEvent) map[string][]*models.AlertCurEvent {
+	var (
+		batch  = make(map[string]*models.AlertCurEvent)
+		firing []*models.AlertCurEvent
+	)
+	for _, events := range alertGroups {
+		for _, event := range events {
+			if !event.IsRecovered {
+				batch[event.Fingerprint] = event
+				firing = append(firing, event)
+			}
+		}
+	}
+
+	clusters, err := ClusterFaultCenterEvents(ctx, faultCenter, firing...)
+	if err != nil {
+		return alertGroups
+	}
+
+	leaderOf := make(map[string]string)
+	for _, cluster := range clusters {
+		var (
+			notified string
+			members  []*models.AlertCurEvent
+		)
+		for _, event := range cluster.Events {
+			if e, ok := batch[event.Fingerprint]; ok {
+				e.ClusterId = cluster.ClusterId
+				members = append(members, e)
+				continue
+			}
+			if notified == "" && event.LastSendTime > 0 && event.ClusterLeader == "" {
+				notified = event.Fingerprint
+			}
+		}
+		if len(members) == 0 {
+			continue
+		}
+
+		sort.Slice(members, func(i, j int) bool {
+			if members[i].Severity != members[j].Severity {
+				return members[i].Severity < members[j].Severity
+			}
+			return members[i].FirstTriggerTime < members[j].FirstTriggerTime
+		})
+		leader := notified
+		if leader == "" {
+			leader = members[0].Fingerprint
+			members[0].ClusterLeader = ""
+			members[0].ClusterNote = ""
+			if len(cluster.Events) > 1 {
+				members[0].ClusterNote = fmt.Sprintf("关联告警 %d 条: %s", len(cluster.Events), strings.Join(cluster.RuleNames, ", "))
+			}
+		}
+		for _, member := range members {
+			if member.Fingerprint != leader {
+				leaderOf[member.Fingerprint] = leader
+			}
+		}
+	}
+
+	newAlertGroups := make(map[string][]*models.AlertCurEvent, len(alertGroups))
+	for severity, events := range alertGroups {
+		for _, event := range events {
+			if event.IsRecovered {
+				if event.ClusterLeader != "" {
+					continue
+				}
+				newAlertGroups[severity] = append(newAlertGroups[severity], event)
+				continue
+			}
+
+			if leader, ok := leaderOf[event.Fingerprint]; ok {
+				event.ClusterLeader = leader
+				event.LastSendTime = timeInt
+				ctx.Redis.Alert().PushAlertEvent(event)
+				continue
+			}
+			newAlertGroups[severity] = append(newAlertGroups[severity], event)
+		}
+	}
+
+	return newAlertGroups
+}
+
+func sharedLabels(a, b *models.AlertCurEvent, keys []string) map[string]string {
+	var labels map[string]string
+	for _, key := range keys {
+		va, ok := a.Labels[key]
+		if !ok || va == nil {
+			continue
+		}
+		vb, ok := b.Labels[key]
+		if !ok || vb == nil {
+			continue
+		}
+
+		value := fmt.Sprint(va)
+		if value == "" || value != fmt.Sprint(vb) {
+			continue
+		}
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		labels[key] = value
+	}
+	return labels
+}
+
+// textTokens 英文按单词切分, 中文按相邻两字切分, 忽略纯数字以免指标值影响相似度
+func textTokens(text string) map[string]struct{} {
+	tokens := make(map[string]struct{})
+	var (
+		word []rune
+		prev rune
+	)
+	flush := func() {
+		if len(word) >= 2 && !isDigits(word) {
+			tokens[string(word)] = struct{}{}
+		}
+		word = word[:0]
+	}
+
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.Is(unicode.Han, r):
+			flush()
+			if prev != 0 {
+				tokens[string([]rune{prev, r})] = struct{}{}
+			}
+			prev = r
+			continue
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			word = append(word, r)
+		default:
+			flush()
+		}
+		prev = 0
+	}
+	flush()
+
+	return tokens
+}
+
+func isDigits(word []rune) bool {
+	for _, r := range word {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func jaccard(a, b map[string]struct{}) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+
+	var inter int
+	for token := range a {
+		if _, ok := b[token]; ok {
+			inter++
+		}
+	}
+	return float64(inter) / float64(len(a)+len(b)-inter)
+}
@@ -0,0 +1,79 @@
+package process
+
+import (
+	"testing"
+	"watchAlert/internal/models"
+)
+
+func newClusterTestEvent(fingerprint, ruleId, severity string, at int64, labels map[string]interface{}, annotations string) *models.AlertCurEvent {
+	return &models.AlertCurEvent{
+		Fingerprint:      fingerprint,
+		RuleId:           ruleId,
+		RuleName:         ruleId,
+		Severity:         severity,
+		FirstTriggerTime: at,
+		Labels:           labels,
+		Annotations:      annotations,
+		Status:           models.StateAlerting,
+	}
+}
+
+func TestClusterEvents(t *testing.T) {
+	events := []*models.AlertCurEvent{
+		newClusterTestEvent("latency", "latency", "P1", 1000, map[string]interface{}{"service": "order"}, "order 服务 P99 延迟 2.3s"),
+		newClusterTestEvent("5xx", "5xx", "P0", 1060, map[string]interface{}{"service": "order"}, "order 服务 5xx 比例 12%"),
+		newClusterTestEvent("restart", "restart", "P2", 1120, map[string]interface{}{"pod": "order-7d9f", "service": "order"}, "Pod 重启"),
+		// 与聚类中最晚触发的告警相差超出关联窗口
+		newClusterTestEvent("late", "latency", "P1", 1120+11*60, map[string]interface{}{"service": "order"}, "order 服务 P99 延迟 3.1s"),
+		// 标签不同, 告警详情相似
+		newClusterTestEvent("disk-a", "disk-a", "P1", 1000, map[string]interface{}{"instance": "db-1"}, "数据库磁盘空间不足, 使用率 91%"),
+		newClusterTestEvent("disk-b", "disk-b", "P2", 1030, map[string]interface{}{"instance": "db-2"}, "数据库磁盘空间不足, 使用率 88%"),
+		// 同一规则不按告警详情关联
+		newClusterTestEvent("cpu-1", "cpu", "P2", 1000, map[string]interface{}{"instance": "node-1"}, "CPU 使用率过高"),
+		newClusterTestEvent("cpu-2", "cpu", "P2", 1000, map[string]interface{}{"instance": "node-2"}, "CPU 使用率过高"),
+	}
+
+	clusters := ClusterEvents(models.CorrelationConfig{}, events)
+	byId := make(map[string]models.EventCluster)
+	for _, cluster := range clusters {
+		byId[cluster.ClusterId] = cluster
+	}
+	if len(clusters) != 5 {
+		t.Fatalf("expected 5 clusters, got %d", len(clusters))
+	}
+
+	order, ok := byId["latency"]
+	if !ok || len(order.Events) != 3 {
+		t.Fatalf("unexpected order cluster: %+v", order)
+	}
+	if order.Severity != "P0" || order.SharedLabels["service"] != "order" || order.FirstTriggerTime != 1000 {
+		t.Fatalf("unexpected order cluster summary: %+v", order)
+	}
+	for _, event := range order.Events {
+		if event.ClusterId != "latency" {
+			t.Fatalf("event %s has cluster id %s", event.Fingerprint, event.ClusterId)
+		}
+	}
+
+	if disk := byId["disk-a"]; len(disk.Events) != 2 {
+		t.Fatalf("expected similar annotations to be clustered: %+v", disk)
+	}
+	if cpu := byId["cpu-1"]; len(cpu.Events) != 1 {
+		t.Fatalf("events of the same rule should not be clustered by text: %+v", cpu)
+	}
+	if late := byId["late"]; len(late.Events) != 1 {
+		t.Fatalf("event outside the window should not be clustered: %+v", late)
+	}
+}
+
+func TestClusterEventsDisableTextSimilarity(t *testing.T) {
+	events := []*models.AlertCurEvent{
+		newClusterTestEvent("disk-a", "disk-a", "P1", 1000, nil, "数据库磁盘空间不足"),
+		newClusterTestEvent("disk-b", "disk-b", "P1", 1000, nil, "数据库磁盘空间不足"),
+	}
+
+	clusters := ClusterEvents(models.CorrelationConfig{TextSimilarity: -1}, events)
+	if len(clusters) != 2 {
+		t.Fatalf("expected 2 clusters, got %+v", clusters)
+	}
+}
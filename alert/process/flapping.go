@@ -0,0 +1,52 @@
+package process
+
+import (
+	"time"
+	"watchAlert/internal/ctx"
+	"watchAlert/internal/models"
+)
+
+// RecordFlapStateChange 记录事件的告警/恢复状态变化, 并同步事件的抖动状态
+func RecordFlapStateChange(ctx *ctx.Context, faultCenter models.FaultCenter, event *models.AlertCurEvent) {
+	ctx.Mux.Lock()
+	defer ctx.Mux.Unlock()
+
+	recordFlapStateChange(ctx, faultCenter, event)
+}
+
+// recordFlapStateChange 调用方需持有 ctx.Mux
+func recordFlapStateChange(ctx *ctx.Context, faultCenter models.FaultCenter, event *models.AlertCurEvent) {
+	cfg := faultCenter.FlapDetection
+	if !cfg.GetEnabled() || event.Fingerprint == "" {
+		return
+	}
+
+	// 只有告警中和已恢复之间的切换才计为一次状态变化
+	if event.Status != models.StateAlerting && event.Status != models.StateRecovered {
+		return
+	}
+
+	state, ok := ctx.Redis.Flapping().Get(event.TenantId, event.FaultCenterId, event.Fingerprint)
+	if !ok {
+		state = models.FlapState{
+			TenantId:      event.TenantId,
+			FaultCenterId: event.FaultCenterId,
+			Fingerprint:   event.Fingerprint,
+			Notified:      true,
+		}
+	}
+
+	if state.LastState == event.Status && ok {
+		event.Flapping = state.Flapping
+		event.FlapScore = state.Score
+		return
+	}
+
+	state.RuleName = event.RuleName
+	state.RecordChange(event.Status, time.Now().Unix(), cfg)
+	state.Event = *event
+	ctx.Redis.Flapping().Set(state)
+
+	event.Flapping = state.Flapping
+	event.FlapScore = state.Score
+}
@@ -0,0 +1,250 @@
+package process
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"watchAlert/internal/ctx"
+	"watchAlert/internal/models"
+	"watchAlert/pkg/blob"
+	"watchAlert/pkg/chart"
+	"watchAlert/pkg/provider"
+	"watchAlert/pkg/tools"
+
+	"github.com/zeromicro/go-zero/core/logc"
+)
+
+// GraphPath 趋势图的访问路径, 由 WatchAlert 提供
+const GraphPath = "/api/w8t/event/graph/"
+
+// graphNoticeTypes 支持附带趋势图的通知类型
+var graphNoticeTypes = map[string]struct{}{
+	"Email":    {},
+	"FeiShu":   {},
+	"DingDing": {},
+	"Slack":    {},
+	"Teams":    {},
+}
+
+// 同一事件在一轮发送中会推送给多个通知对象, 趋势图只生成一次并在短时间内复用;
+// 生成耗时超过等待时间时先发送不带趋势图的通知, 生成结果留给后续通知使用;
+// 后台生成(查询、绘制及上传)超过 graphRenderDeadline 时放弃, 避免数据源或存储无响应时堆积
+const (
+	graphRenderTimeout  = 10 * time.Second
+	graphRenderDeadline = 30 * time.Second
+	graphCacheTTL       = time.Minute
+)
+
+// graphRenders 最近生成(含正在生成)的趋势图, 按事件及恢复状态区分
+var graphRenders sync.Map
+
+type graphRender struct {
+	startAt time.Time
+	done    chan struct{}
+	data    []byte
+	url     string
+}
+
+// attachGraph 为指标告警生成趋势图并保存, 设置事件的趋势图地址, 返回 PNG 用于邮件内联图片
+func attachGraph(ctx *ctx.Context, event *models.AlertCurEvent, noticeType string) []byte {
+	if _, ok := graphNoticeTypes[noticeType]; !ok {
+		return nil
+	}
+	if event.DatasourceType != provider.PrometheusDsProvider && event.DatasourceType != provider.VictoriaMetricsDsProvider {
+		return nil
+	}
+
+	setting, err := ctx.DB.Setting().Get()
+	if err != nil || !setting.GraphConfig.GetEnable() {
+		return nil
+	}
+
+	r := loadGraphRender(ctx, setting, *event)
+	c, cancel := context.WithTimeout(ctx.Ctx, graphRenderTimeout)
+	defer cancel()
+	select {
+	case <-r.done:
+	case <-c.Done():
+		logc.Error(ctx.Ctx, fmt.Sprintf("生成告警趋势图超时, 本次通知不附带趋势图, rule: %s", event.RuleName))
+		return nil
+	}
+
+	if r.url != "" {
+		event.GraphUrl = r.url
+	}
+	return r.data
+}
+
+// loadGraphRender 获取事件的趋势图, 不存在或已过期时在后台生成
+func loadGraphRender(ctx *ctx.Context, setting models.Settings, event models.AlertCurEvent) *graphRender {
+	key := fmt.Sprintf("%s:%t", event.EventId, event.IsRecovered)
+	now := time.Now()
+	if v, ok := graphRenders.Load(key); ok && now.Sub(v.(*graphRender).startAt) < graphCacheTTL {
+		return v.(*graphRender)
+	}
+
+	graphRenders.Range(func(k, v interface{}) bool {
+		if now.Sub(v.(*graphRender).startAt) >= graphCacheTTL {
+			graphRenders.CompareAndDelete(k, v)
+		}
+		return true
+	})
+	r := &graphRender{startAt: now, done: make(chan struct{})}
+	if v, loaded := graphRenders.LoadOrStore(key, r); loaded {
+		return v.(*graphRender)
+	}
+
+	go func() {
+		defer close(r.done)
+		r.data, r.url = renderGraph(ctx, setting, event)
+	}()
+	return r
+}
+
+// renderGraph 绘制趋势图并上传, 返回 PNG 及访问地址
+func renderGraph(ctx *ctx.Context, setting models.Settings, event models.AlertCurEvent) ([]byte, string) {
+	c, cancel := context.WithTimeout(ctx.Ctx, graphRenderDeadline)
+	defer cancel()
+
+	config := setting.GraphConfig
+	data, err := renderEventGraphWithContext(c, func() ([]byte, error) {
+		return RenderEventGraph(ctx, config, event)
+	})
+	if err != nil {
+		logc.Error(ctx.Ctx, fmt.Sprintf("生成告警趋势图失败, rule: %s, err: %v", event.RuleName, err))
+		return nil, ""
+	}
+
+	store, err := blob.NewStore(config)
+	if err != nil {
+		logc.Error(ctx.Ctx, fmt.Sprintf("趋势图存储配置错误: %v", err))
+		return data, ""
+	}
+	key, err := newGraphKey()
+	if err != nil {
+		return data, ""
+	}
+	if err := store.Put(c, key, data, "image/png"); err != nil {
+		logc.Error(ctx.Ctx, fmt.Sprintf("保存告警趋势图失败: %v", err))
+		return data, ""
+	}
+
+	baseUrl := config.BaseUrl
+	if baseUrl == "" {
+		baseUrl = setting.QuickActionConfig.ApiUrl
+	}
+	if baseUrl == "" {
+		return data, ""
+	}
+	return data, strings.TrimSuffix(baseUrl, "/") + GraphPath + key
+}
+
+// renderEventGraphWithContext 数据源查询不支持取消, 超时后不再等待查询结果
+func renderEventGraphWithContext(ctx context.Context, render func() ([]byte, error)) ([]byte, error) {
+	type result struct {
+		data []byte
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		data, err := render()
+		done <- result{data: data, err: err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.data, r.err
+	case <-ctx.Done():
+		return nil, fmt.Errorf("生成趋势图超时或已取消: %w", ctx.Err())
+	}
+}
+
+// RenderEventGraph 查询告警规则在触发时间前后的数据, 绘制该告警对应的曲线及各等级的阈值线
+func RenderEventGraph(ctx *ctx.Context, config models.GraphConfig, event models.AlertCurEvent) ([]byte, error) {
+	rule, err := ctx.DB.Rule().Get(event.TenantId, event.RuleGroupId, event.RuleId)
+	if err != nil {
+		return nil, err
+	}
+
+	cli, err := ctx.Redis.ProviderPools().GetClient(event.DatasourceId)
+	if err != nil {
+		return nil, err
+	}
+	metricsCli, ok := cli.(provider.MetricsFactoryProvider)
+	if !ok {
+		return nil, fmt.Errorf("数据源 %s 不是指标类型", event.DatasourceId)
+	}
+
+	window := time.Duration(config.GetWindowMinutes()) * time.Minute
+	start, end, step := models.GraphWindow(event.FirstTriggerTime, event.RecoverTime, event.IsRecovered, window, time.Now())
+	promQL := tools.ReplacePromQLVariablesForAlert(rule.PrometheusConfig.PromQL, nil)
+	res, err := metricsCli.QueryRange(promQL, start, end, step)
+	if err != nil {
+		return nil, err
+	}
+
+	// 区间查询结果为逐个数据点, 按标签归并为曲线
+	seriesMap := make(map[string]*chart.Series)
+	for _, m := range res {
+		if !models.GraphSeriesMatch(event.Labels, m.Metric) {
+			continue
+		}
+		name := models.GraphSeriesName(m.Metric)
+		s, ok := seriesMap[name]
+		if !ok {
+			s = &chart.Series{Name: name}
+			seriesMap[name] = s
+		}
+		s.Points = append(s.Points, chart.Point{Time: metricsTime(m.Timestamp), Value: m.Value})
+	}
+	if len(seriesMap) == 0 {
+		return nil, errors.New("未查询到该告警对应的数据")
+	}
+
+	var series []chart.Series
+	for _, s := range seriesMap {
+		sort.Slice(s.Points, func(i, j int) bool { return s.Points[i].Time.Before(s.Points[j].Time) })
+		series = append(series, *s)
+	}
+	sort.Slice(series, func(i, j int) bool { return series[i].Name < series[j].Name })
+
+	var thresholds []chart.Threshold
+	for _, r := range rule.PrometheusConfig.Rules {
+		operator, value, err := tools.ProcessRuleExpr(r.Expr)
+		if err != nil {
+			continue
+		}
+		thresholds = append(thresholds, chart.Threshold{Name: fmt.Sprintf("%s %s %v", r.Severity, operator, value), Value: value})
+	}
+
+	return chart.Chart{
+		Start:      start,
+		End:        end,
+		Series:     series,
+		Thresholds: thresholds,
+		Marker:     time.Unix(event.FirstTriggerTime, 0),
+	}.Render()
+}
+
+// metricsTime Prometheus 区间查询的时间戳为毫秒, VictoriaMetrics 为秒
+func metricsTime(ts float64) time.Time {
+	if ts > 1e11 {
+		return time.UnixMilli(int64(ts))
+	}
+	return time.Unix(int64(ts), 0)
+}
+
+// newGraphKey 按日期分目录, 文件名随机生成, 避免被猜测
+func newGraphKey() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s/%s.png", time.Now().Format("20060102"), hex.EncodeToString(b)), nil
+}
@@ -0,0 +1,31 @@
+package process
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRenderEventGraphWithContext(t *testing.T) {
+	data, err := renderEventGraphWithContext(context.Background(), func() ([]byte, error) {
+		return []byte("png"), nil
+	})
+	if err != nil || string(data) != "png" {
+		t.Fatalf("unexpected result: %q, %v", data, err)
+	}
+
+	release := make(chan struct{})
+	defer close(release)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err = renderEventGraphWithContext(ctx, func() ([]byte, error) {
+		// 模拟无响应的数据源
+		<-release
+		return []byte("png"), nil
+	})
+	if !errors.Is(err, context.DeadlineExceeded) || time.Since(start) > time.Second {
+		t.Fatalf("slow render should time out, got %v after %v", err, time.Since(start))
+	}
+}
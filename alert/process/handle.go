@@ -26,10 +26,14 @@ func HandleAlert(ctx *ctx.Context, processType string, faultCenter models.FaultC
 		return err
 	}
 
-	// 按告警等级分组
+	// 按告警等级分组, 并记录首次通知的事件(聚合时会更新 LastSendTime, 需提前记录)
 	severityGroups := make(map[string][]*models.AlertCurEvent)
+	firstNotice := make(map[string]bool)
 	for _, alert := range alerts {
 		severityGroups[alert.Severity] = append(severityGroups[alert.Severity], alert)
+		if IsFirstNotice(alert) {
+			firstNotice[alert.EventId] = true
+		}
 	}
 
 	// 告警聚合
@@ -56,33 +60,38 @@ func HandleAlert(ctx *ctx.Context, processType string, faultCenter models.FaultC
 					ctx.Redis.Alert().PushAlertEvent(event)
 				}
 
-				phoneNumber := func() []string {
-					if len(event.DutyUserPhoneNumber) > 0 {
-						return event.DutyUserPhoneNumber
-					}
-					if len(noticeData.PhoneNumber) > 0 {
-						return noticeData.PhoneNumber
-					}
-					return []string{}
-				}()
-
-				event.DutyUser = strings.Join(GetDutyUsers(ctx, noticeData), " ")
-				event.DutyUserPhoneNumber = GetDutyUserPhoneNumber(ctx, noticeData)
+				dutyUsers, dutyPhones := GetDutyUsers(ctx, noticeData, eventTime(event))
+				event.DutyUser = strings.Join(dutyUsers, " ")
+				event.DutyUserPhoneNumber = dutyPhones
+				// 通知对象未配置电话号码时, 使用当班人员的电话号码
+				phoneNumber := noticeData.PhoneNumber
+				if len(phoneNumber) == 0 {
+					phoneNumber = dutyPhones
+				}
+				var graph []byte
+				if processType == "alarm" {
+					attachAiAnalysis(ctx, event)
+					graph = attachGraph(ctx, event, noticeData.NoticeType)
+				}
 				content := generateAlertContent(ctx, event, noticeData)
 				err := sender.Sender(ctx, sender.SendParams{
-					TenantId:    event.TenantId,
-					EventId:     event.EventId,
-					RuleName:    event.RuleName,
-					Severity:    event.Severity,
-					NoticeType:  noticeData.NoticeType,
-					NoticeId:    noticeId,
-					NoticeName:  noticeData.Name,
-					IsRecovered: event.IsRecovered,
-					Hook:        Hook,
-					Email:       getNoticeEmail(noticeData, severity),
-					Content:     content,
-					PhoneNumber: phoneNumber,
-					Sign:        Sign,
+					TenantId:      event.TenantId,
+					EventId:       event.EventId,
+					Fingerprint:   event.Fingerprint,
+					RuleName:      event.RuleName,
+					Severity:      event.Severity,
+					NoticeType:    noticeData.NoticeType,
+					NoticeId:      noticeId,
+					NoticeName:    noticeData.Name,
+					IsRecovered:   event.IsRecovered,
+					IsFirstNotice: processType == "alarm" && firstNotice[event.EventId],
+					Hook:          Hook,
+					Email:         getNoticeEmail(noticeData, severity),
+					Content:       content,
+					Graph:         graph,
+					PhoneNumber:   phoneNumber,
+					Sign:          Sign,
+					Webhook:       noticeData.Webhook,
 				})
 				if err != nil {
 					logc.Error(ctx.Ctx, fmt.Sprintf("Failed to send alert: %v", err))
@@ -115,6 +124,8 @@ func alarmAggregation(ctx *ctx.Context, processType string, faultCenter models.F
 		for severity, events := range alertGroups {
 			newAlertGroups[severity] = withRuleGroupByAlerts(ctx, curTime, events)
 		}
+	case models.AggregationTypeCluster:
+		return withClusterGroupByAlerts(ctx, curTime, faultCenter, alertGroups)
 	default:
 		return alertGroups
 	}
@@ -168,9 +179,10 @@ func getNoticeEmail(notice models.AlertNotice, severity string) models.Email {
 		for _, route := range notice.Routes {
 			if route.Severity == severity {
 				return models.Email{
-					Subject: notice.Email.Subject,
-					To:      route.To,
-					CC:      route.CC,
+					Subject:     notice.Email.Subject,
+					To:          route.To,
+					CC:          route.CC,
+					SmtpProfile: notice.Email.SmtpProfile,
 				}
 			}
 		}
@@ -183,10 +195,28 @@ type WebhookContent struct {
 	DutyUsers []models.DutyUser     `json:"dutyUsers"`
 }
 
+// eventTime 解析当班人员的时间
+func eventTime(event *models.AlertCurEvent) time.Time {
+	return DutyTime(event.IsRecovered, event.RecoverTime, event.LastEvalTime, event.FirstTriggerTime)
+}
+
+// DutyTime 解析当班人员的时间, 告警及重复通知按事件的评估时间(未评估时按首次触发时间), 恢复通知按恢复时间
+func DutyTime(isRecovered bool, recoverTime, evalTime, firstTriggerTime int64) time.Time {
+	switch {
+	case isRecovered && recoverTime > 0:
+		return time.Unix(recoverTime, 0)
+	case evalTime > 0:
+		return time.Unix(evalTime, 0)
+	case firstTriggerTime > 0:
+		return time.Unix(firstTriggerTime, 0)
+	}
+	return time.Now()
+}
+
 // generateAlertContent 生成告警内容
 func generateAlertContent(ctx *ctx.Context, alert *models.AlertCurEvent, noticeData models.AlertNotice) string {
 	if noticeData.NoticeType == "CustomHook" {
-		users, ok := ctx.DB.DutyCalendar().GetDutyUserInfo(*noticeData.GetDutyId(), time.Now().Format("2006-1-2"))
+		users, ok := ctx.DB.DutyCalendar().GetOnCallUsers(*noticeData.GetDutyId(), eventTime(alert))
 		if !ok || len(users) == 0 {
 			logc.Error(ctx.Ctx, "Failed to get duty users, noticeName: ", noticeData.Name)
 		}
@@ -207,5 +237,19 @@ func generateAlertContent(ctx *ctx.Context, alert *models.AlertCurEvent, noticeD
 
 		return tools.JsonMarshalToString(content)
 	}
-	return templates.NewTemplate(ctx, *alert, noticeData).CardContentMsg
+
+	// 附带的关联告警概要及 Ai 分析结果追加到告警详情, 无需修改通知模版
+	event := *alert
+	if event.ClusterNote != "" {
+		event.Annotations = fmt.Sprintf("%s\n%s\n", event.Annotations, event.ClusterNote)
+	}
+	if event.AiAnalysis != "" {
+		event.Annotations = fmt.Sprintf("%s\n\nAi 分析:\n%s", event.Annotations, event.AiAnalysis)
+	}
+	return templates.NewTemplate(ctx, event, noticeData).CardContentMsg
+}
+
+// IsFirstNotice 告警事件是否为首次通知, 需在发送前判断(发送时会更新 LastSendTime)
+func IsFirstNotice(event *models.AlertCurEvent) bool {
+	return !event.IsRecovered && event.LastSendTime == 0
 }
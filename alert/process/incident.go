@@ -0,0 +1,275 @@
+package process
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"watchAlert/internal/ctx"
+	"watchAlert/internal/models"
+	"watchAlert/pkg/sender"
+	"watchAlert/pkg/tools"
+
+	"github.com/zeromicro/go-zero/core/logc"
+)
+
+// 系统自动产生的时间线操作人
+const IncidentSystemOperator = "system"
+
+// AddIncidentTimeline 添加故障时间线
+func AddIncidentTimeline(ctx *ctx.Context, tenantId, incidentId, timelineType, content, operator string) {
+	err := ctx.DB.Incident().AddTimeline(models.IncidentTimeline{
+		TenantId:   tenantId,
+		ID:         "tl-" + tools.RandId(),
+		IncidentId: incidentId,
+		Type:       timelineType,
+		Content:    content,
+		Operator:   operator,
+		CreateAt:   time.Now().Unix(),
+	})
+	if err != nil {
+		logc.Errorf(ctx.Ctx, "添加故障时间线失败, incidentId: %s, err: %s", incidentId, err.Error())
+	}
+}
+
+// LinkIncidentEvent 将告警事件关联到故障, 已关联的告警仅同步状态, 不重复写入时间线
+func LinkIncidentEvent(ctx *ctx.Context, incident models.Incident, event models.AlertCurEvent, operator string) error {
+	_, err := linkIncidentEvent(ctx, newIncidentEvent(incident, event, operator))
+	return err
+}
+
+func newIncidentEvent(incident models.Incident, event models.AlertCurEvent, operator string) models.IncidentEvent {
+	return models.IncidentEvent{
+		TenantId:    incident.TenantId,
+		IncidentId:  incident.ID,
+		Fingerprint: event.Fingerprint,
+		EventId:     event.EventId,
+		RuleId:      event.RuleId,
+		RuleName:    event.RuleName,
+		Severity:    event.Severity,
+		Status:      string(event.Status),
+		LinkedAt:    time.Now().Unix(),
+		LinkedBy:    operator,
+	}
+}
+
+func linkIncidentEvent(ctx *ctx.Context, e models.IncidentEvent) (models.IncidentEvent, error) {
+	alreadyLinked, err := ctx.DB.Incident().LinkEvent(e)
+	if err != nil {
+		return e, err
+	}
+	if alreadyLinked {
+		return e, nil
+	}
+
+	AddIncidentTimeline(ctx, e.TenantId, e.IncidentId, models.TimelineEventLinked,
+		fmt.Sprintf("关联告警「%s」, 等级: %s, 指纹: %s", e.RuleName, e.Severity, e.Fingerprint), e.LinkedBy)
+	return e, nil
+}
+
+// SyncIncidents 根据故障中心的告警分组同步故障
+// 触发中的告警按「故障中心 + 规则」自动创建或关联到未解决的故障, 恢复的告警记录到关联故障的时间线中。
+// 启用告警关联时, 与已关联故障的告警属于同一聚类的告警合并到该故障, 避免重复创建。
+// 每次同步只查询一次未解决的故障及其关联的告警, 状态未变化的告警不再写入数据库。
+func SyncIncidents(ctx *ctx.Context, faultCenter models.FaultCenter, events []*models.AlertCurEvent) {
+	cfg := faultCenter.IncidentConfig
+	var fingerprints []string
+	for _, event := range events {
+		if event.IsRecovered || (cfg.GetAutoCreate() && cfg.Match(event.Severity)) {
+			fingerprints = append(fingerprints, event.Fingerprint)
+		}
+	}
+	if len(fingerprints) == 0 {
+		return
+	}
+
+	incidents, linked, err := ctx.DB.Incident().ListOpenWithEvents(faultCenter.TenantId, faultCenter.ID, fingerprints)
+	if err != nil {
+		logc.Errorf(ctx.Ctx, "获取未解决的故障失败, faultCenterId: %s, err: %s", faultCenter.ID, err.Error())
+		return
+	}
+	open := models.NewOpenIncidents(incidents, linked)
+
+	var clusters []models.EventCluster
+	for _, event := range events {
+		if event.IsRecovered {
+			recordIncidentEventRecovered(ctx, open, event)
+			continue
+		}
+
+		if !cfg.GetAutoCreate() || !cfg.Match(event.Severity) {
+			continue
+		}
+
+		groupKey := models.IncidentGroupKey(faultCenter.ID, event.RuleId)
+		incident, ok := open.ByGroupKey(groupKey)
+		if !ok && faultCenter.GetCorrelationEnabled() {
+			if clusters == nil {
+				clusters, _ = ClusterFaultCenterEvents(ctx, faultCenter, events...)
+			}
+			incident, ok = findClusterIncident(ctx, open, faultCenter, clusters, event)
+		}
+		if !ok {
+			now := time.Now().Unix()
+			incident = models.Incident{
+				TenantId:      event.TenantId,
+				ID:            "inc-" + tools.RandId(),
+				Title:         fmt.Sprintf("[%s] %s", faultCenter.Name, event.RuleName),
+				Status:        models.IncidentTriggered,
+				Severity:      event.Severity,
+				FaultCenterId: faultCenter.ID,
+				Source:        models.IncidentSourceAuto,
+				GroupKey:      groupKey,
+				NoticeIds:     cfg.NoticeIds,
+				CreateBy:      IncidentSystemOperator,
+				CreateAt:      now,
+				UpdateAt:      now,
+			}
+			if err := ctx.DB.Incident().Create(incident); err != nil {
+				logc.Errorf(ctx.Ctx, "自动创建故障失败, groupKey: %s, err: %s", groupKey, err.Error())
+				continue
+			}
+			open.Add(incident)
+			AddIncidentTimeline(ctx, incident.TenantId, incident.ID, models.TimelineStatusChange,
+				fmt.Sprintf("故障中心「%s」根据告警分组自动创建故障", faultCenter.Name), IncidentSystemOperator)
+		}
+
+		// 已关联且状态未变化的告警无需更新
+		if e, ok := open.LinkedEvent(incident.ID, event.Fingerprint); ok && e.Status == string(event.Status) && e.EventId == event.EventId {
+			continue
+		}
+		e, err := linkIncidentEvent(ctx, newIncidentEvent(incident, *event, IncidentSystemOperator))
+		if err != nil {
+			logc.Errorf(ctx.Ctx, "关联告警到故障失败, incidentId: %s, err: %s", incident.ID, err.Error())
+			continue
+		}
+		open.SetEvent(e)
+	}
+}
+
+// findClusterIncident 查找同一聚类中其他告警已关联的未解决故障
+func findClusterIncident(ctx *ctx.Context, open *models.OpenIncidents, faultCenter models.FaultCenter, clusters []models.EventCluster, event *models.AlertCurEvent) (models.Incident, bool) {
+	if incidents := open.ByFingerprint(event.Fingerprint, faultCenter.ID); len(incidents) > 0 {
+		return incidents[0], true
+	}
+
+	for _, cluster := range clusters {
+		inCluster := slices.ContainsFunc(cluster.Events, func(e models.AlertCurEvent) bool {
+			return e.Fingerprint == event.Fingerprint
+		})
+		if !inCluster {
+			continue
+		}
+
+		for _, peer := range cluster.Events {
+			if peer.Fingerprint == event.Fingerprint {
+				continue
+			}
+			if incidents := open.ByFingerprint(peer.Fingerprint, faultCenter.ID); len(incidents) > 0 {
+				AddIncidentTimeline(ctx, incidents[0].TenantId, incidents[0].ID, models.TimelineEventLinked,
+					fmt.Sprintf("告警「%s」与「%s」属于同一关联聚类, 合并到当前故障", event.RuleName, peer.RuleName), IncidentSystemOperator)
+				return incidents[0], true
+			}
+		}
+		break
+	}
+
+	return models.Incident{}, false
+}
+
+// recordIncidentEventRecovered 关联的告警恢复时记录到故障时间线
+func recordIncidentEventRecovered(ctx *ctx.Context, open *models.OpenIncidents, event *models.AlertCurEvent) {
+	for _, incident := range open.ByFingerprint(event.Fingerprint, "") {
+		err := ctx.DB.Incident().UpdateEventStatus(incident.TenantId, incident.ID, event.Fingerprint, string(models.StateRecovered))
+		if err != nil {
+			logc.Errorf(ctx.Ctx, "更新故障关联告警状态失败, incidentId: %s, err: %s", incident.ID, err.Error())
+			continue
+		}
+		AddIncidentTimeline(ctx, incident.TenantId, incident.ID, models.TimelineEventTransition,
+			fmt.Sprintf("告警「%s」已恢复, 指纹: %s", event.RuleName, event.Fingerprint), IncidentSystemOperator)
+
+		e, _ := open.LinkedEvent(incident.ID, event.Fingerprint)
+		e.Status = string(models.StateRecovered)
+		open.SetEvent(e)
+		if open.AllRecovered(incident.ID) {
+			AddIncidentTimeline(ctx, incident.TenantId, incident.ID, models.TimelineEventTransition,
+				"所有关联告警均已恢复", IncidentSystemOperator)
+		}
+	}
+}
+
+// RecordIncidentComment 告警事件的评论同步到关联故障的时间线
+func RecordIncidentComment(ctx *ctx.Context, tenantId, fingerprint, username, content string) {
+	incidents, err := ctx.DB.Incident().GetOpenIncidentsByFingerprint(tenantId, fingerprint)
+	if err != nil {
+		return
+	}
+
+	for _, incident := range incidents {
+		AddIncidentTimeline(ctx, tenantId, incident.ID, models.TimelineComment,
+			fmt.Sprintf("[告警评论 %s] %s", fingerprint, content), username)
+	}
+}
+
+// BroadcastIncident 向通知对象发送故障状态通报
+func BroadcastIncident(ctx *ctx.Context, incident models.Incident, noticeIds []string, message, operator string) error {
+	title := fmt.Sprintf("【故障通报】%s", incident.Title)
+	text := strings.Join([]string{
+		fmt.Sprintf("故障 ID: %s", incident.ID),
+		fmt.Sprintf("当前状态: %s", incident.Status),
+		fmt.Sprintf("故障等级: %s", incident.Severity),
+		fmt.Sprintf("指挥官: %s", incident.Commander),
+		fmt.Sprintf("响应人: %s", strings.Join(incident.Responders, ", ")),
+		fmt.Sprintf("通报人: %s", operator),
+		fmt.Sprintf("通报内容: %s", message),
+	}, "\n")
+
+	var failed []string
+	for _, noticeId := range noticeIds {
+		if err := SendTextNotice(ctx, incident.TenantId, noticeId, incident.ID, incident.Title, incident.Severity, title, text); err != nil {
+			logc.Errorf(ctx.Ctx, "故障通报发送失败, incidentId: %s, noticeId: %s, err: %s", incident.ID, noticeId, err.Error())
+			failed = append(failed, noticeId)
+		}
+	}
+
+	AddIncidentTimeline(ctx, incident.TenantId, incident.ID, models.TimelineBroadcast, message, operator)
+	if len(failed) > 0 {
+		return fmt.Errorf("部分通知对象发送失败: %s", strings.Join(failed, ", "))
+	}
+
+	return nil
+}
+
+// SendTextNotice 向通知对象发送纯文本消息, 使用通知对象的默认 Hook
+func SendTextNotice(ctx *ctx.Context, tenantId, noticeId, eventId, ruleName, severity, title, text string) error {
+	noticeData, err := getNoticeData(ctx, tenantId, noticeId)
+	if err != nil {
+		return err
+	}
+
+	if sender.IsPagingNotice(noticeData.NoticeType) {
+		return fmt.Errorf("%s 类型的通知对象不支持发送文本消息", noticeData.NoticeType)
+	}
+
+	hook, sign := getNoticeHookUrlAndSign(noticeData, severity)
+	email := getNoticeEmail(noticeData, severity)
+	if title != "" {
+		email.Subject = title
+	}
+
+	return sender.Sender(ctx, sender.SendParams{
+		TenantId:    tenantId,
+		EventId:     eventId,
+		RuleName:    ruleName,
+		Severity:    severity,
+		NoticeType:  noticeData.NoticeType,
+		NoticeId:    noticeId,
+		NoticeName:  noticeData.Name,
+		Hook:        hook,
+		Email:       email,
+		Content:     sender.BuildTextContent(noticeData.NoticeType, title, text),
+		PhoneNumber: noticeData.PhoneNumber,
+		Sign:        sign,
+		Webhook:     noticeData.Webhook,
+	})
+}
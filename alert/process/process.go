@@ -2,6 +2,10 @@ package process
 
 import (
 	"fmt"
+	"html"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 	"watchAlert/alert/mute"
 	"watchAlert/internal/ctx"
@@ -65,6 +69,9 @@ func PushEventToFaultCenter(ctx *ctx.Context, event *models.AlertCurEvent) {
 	event.LastSendTime = cacheEvent.GetLastSendTime()
 	event.ConfirmState = cacheEvent.GetLastConfirmState()
 	event.EventId = cacheEvent.GetEventId()
+	event.FirstValue = cacheEvent.GetFirstValue(event.Labels["value"])
+	event.ClusterId = cacheEvent.ClusterId
+	event.ClusterLeader = cacheEvent.ClusterLeader
 	event.FaultCenter = cache.FaultCenter().GetFaultCenterInfo(models.BuildFaultCenterInfoCacheKey(event.TenantId, event.FaultCenterId))
 
 	// 如果是恢复事件，重置 LastSendTime 为 0，确保恢复通知能够发送
@@ -90,11 +97,13 @@ func PushEventToFaultCenter(ctx *ctx.Context, event *models.AlertCurEvent) {
 	// 如果匹配到静默规则，设置静默信息
 	if isSilenced {
 		now := time.Now().Unix()
+		// 周期静默使用当前生效的静默窗口
+		startsAt, endsAt := matchedSilence.GetWindow()
 		event.SilenceInfo = &models.SilenceInfo{
 			SilenceId:     matchedSilence.ID,
-			StartsAt:      matchedSilence.StartsAt,
-			EndsAt:        matchedSilence.EndsAt,
-			RemainingTime: matchedSilence.EndsAt - now,
+			StartsAt:      startsAt,
+			EndsAt:        endsAt,
+			RemainingTime: endsAt - now,
 			Comment:       matchedSilence.Comment,
 		}
 	} else {
@@ -162,6 +171,9 @@ func PushEventToFaultCenter(ctx *ctx.Context, event *models.AlertCurEvent) {
 		return
 	}
 
+	// 记录抖动状态
+	recordFlapStateChange(ctx, event.FaultCenter, event)
+
 	// 更新缓存
 	cache.Alert().PushAlertEvent(event)
 }
@@ -191,51 +203,73 @@ func GetMatchedSilenceRule(event *models.AlertCurEvent) *models.AlertSilences {
 	})
 }
 
-func GetDutyUsers(ctx *ctx.Context, noticeData models.AlertNotice) []string {
+// GetDutyUsers 获取 at 时刻的当班人员, 返回按通知类型格式化的提及（@）内容, 以及电话、短信通知使用的手机号(主值班在前)
+func GetDutyUsers(ctx *ctx.Context, noticeData models.AlertNotice, at time.Time) ([]string, []string) {
+	users, ok := ctx.DB.DutyCalendar().GetOnCallUsers(*noticeData.GetDutyId(), at)
+	if !ok {
+		return []string{"暂无"}, []string{}
+	}
+
+	var phones = []string{}
+	for _, user := range users {
+		if user.Phone != "" && !slices.Contains(phones, user.Phone) {
+			phones = append(phones, user.Phone)
+		}
+	}
+	return formatDutyUsers(noticeData.NoticeType, users), phones
+}
+
+// formatDutyUsers 按通知类型将当班人员格式化为提及（@）格式
+func formatDutyUsers(noticeType string, users []models.Member) []string {
 	var us []string
-	users, ok := ctx.DB.DutyCalendar().GetDutyUserInfo(*noticeData.GetDutyId(), time.Now().Format("2006-1-2"))
-	if ok {
-		switch noticeData.NoticeType {
-		case "FeiShu":
-			for _, user := range users {
-				us = append(us, fmt.Sprintf("<at id=%s></at>", user.DutyUserId))
-			}
-			return us
-		case "DingDing":
-			for _, user := range users {
-				us = append(us, fmt.Sprintf("@%s", user.DutyUserId))
-			}
-			return us
-		case "Email", "WeChat", "CustomHook":
-			for _, user := range users {
-				us = append(us, fmt.Sprintf("@%s", user.UserName))
+	switch noticeType {
+	case "FeiShu":
+		for _, user := range users {
+			us = append(us, fmt.Sprintf("<at id=%s></at>", user.DutyUserId))
+		}
+		return us
+	case "DingDing":
+		for _, user := range users {
+			us = append(us, fmt.Sprintf("@%s", user.DutyUserId))
+		}
+		return us
+	case "Email", "WeChat", "CustomHook":
+		for _, user := range users {
+			us = append(us, fmt.Sprintf("@%s", user.UserName))
+		}
+		return us
+	case "Slack":
+		for _, user := range users {
+			us = append(us, fmt.Sprintf("<@%s>", user.DutyUserId))
+		}
+		return us
+	case "Teams":
+		// Teams 提及使用邮箱（UPN）, 未配置邮箱时使用值班用户 ID
+		for _, user := range users {
+			id := user.Email
+			if id == "" {
+				id = user.DutyUserId
 			}
-			return us
-		case "Slack":
-			for _, user := range users {
-				us = append(us, fmt.Sprintf("<@%s>", user.DutyUserId))
+			us = append(us, fmt.Sprintf("@%s", id))
+		}
+		return us
+	case "Telegram":
+		// 值班用户 ID 为 Telegram 数字 ID 时使用链接提及, 否则视为 Telegram 用户名
+		for _, user := range users {
+			if _, err := strconv.ParseInt(user.DutyUserId, 10, 64); err == nil {
+				us = append(us, fmt.Sprintf(`<a href="tg://user?id=%s">@%s</a>`, user.DutyUserId, html.EscapeString(user.UserName)))
+			} else if user.DutyUserId != "" {
+				us = append(us, "@"+strings.TrimPrefix(html.EscapeString(user.DutyUserId), "@"))
+			} else {
+				us = append(us, "@"+html.EscapeString(user.UserName))
 			}
-			return us
 		}
+		return us
 	}
 
 	return []string{"暂无"}
 }
 
-// GetDutyUserPhoneNumber 获取当班人员手机号
-func GetDutyUserPhoneNumber(ctx *ctx.Context, noticeData models.AlertNotice) []string {
-	//user, ok := ctx.DB.DutyCalendar().GetDutyUserInfo(*noticeData.GetDutyId(), time.Now().Format("2006-1-2"))
-	//if ok {
-	//	switch noticeData.NoticeType {
-	//	case "PhoneCall":
-	//		if len(user.DutyUserId) > 1 {
-	//			return []string{user.Phone}
-	//		}
-	//	}
-	//}
-	return []string{}
-}
-
 // RecordAlertHisEvent 记录历史告警
 func RecordAlertHisEvent(ctx *ctx.Context, alert models.AlertCurEvent) error {
 	hisData := models.AlertHisEvent{
@@ -5,6 +5,7 @@ import (
 	"watchAlert/internal/middleware"
 	"watchAlert/internal/services"
 	"watchAlert/internal/types"
+	"watchAlert/pkg/response"
 )
 
 type aiController struct{}
@@ -19,6 +20,31 @@ func (aiController aiController) API(gin *gin.RouterGroup) {
 	)
 	{
 		a.POST("chat", aiController.Chat)
+		a.POST("chatStream", aiController.ChatStream)
+	}
+
+	b := gin.Group("ai")
+	b.Use(
+		middleware.Cors(),
+		middleware.Auth(),
+		middleware.Permission(),
+		middleware.ParseTenant(),
+		middleware.AuditingLog(),
+	)
+	{
+		b.POST("agent", aiController.Agent)
+		b.POST("ruleDraft", aiController.RuleDraft)
+	}
+
+	c := gin.Group("ai")
+	c.Use(
+		middleware.Cors(),
+		middleware.Auth(),
+		middleware.Permission(),
+		middleware.ParseTenant(),
+	)
+	{
+		c.GET("usage", aiController.Usage)
 	}
 }
 
@@ -29,8 +55,91 @@ func (aiController aiController) Chat(ctx *gin.Context) {
 	r.RuleName = ctx.PostForm("rule_name")
 	r.Deep = ctx.PostForm("deep")
 	r.SearchQL = ctx.PostForm("search_ql")
+	r.FaultCenterId = ctx.PostForm("fault_center_id")
+	r.Fingerprint = ctx.PostForm("fingerprint")
+	r.TenantId = ctx.Request.Header.Get(middleware.TenantIDHeaderKey)
 
 	Service(ctx, func() (interface{}, interface{}) {
 		return services.AiService.Chat(r)
 	})
 }
+
+// ChatStream 以 Server-Sent Events 逐段返回分析内容
+// 事件类型: message 为内容片段, done 为结束, error 为生成过程中的错误
+func (aiController aiController) ChatStream(ctx *gin.Context) {
+	r := new(types.RequestAiChatContent)
+	r.Content = ctx.PostForm("content")
+	r.RuleId = ctx.PostForm("rule_id")
+	r.RuleName = ctx.PostForm("rule_name")
+	r.Deep = ctx.PostForm("deep")
+	r.SearchQL = ctx.PostForm("search_ql")
+	r.FaultCenterId = ctx.PostForm("fault_center_id")
+	r.Fingerprint = ctx.PostForm("fingerprint")
+	r.TenantId = ctx.Request.Header.Get(middleware.TenantIDHeaderKey)
+
+	var started bool
+	_, err := services.AiService.StreamChat(ctx.Request.Context(), r, func(chunk string) {
+		if !started {
+			started = true
+			ctx.Header("Content-Type", "text/event-stream")
+			ctx.Header("Cache-Control", "no-cache")
+			ctx.Header("Connection", "keep-alive")
+			ctx.Header("X-Accel-Buffering", "no")
+		}
+		ctx.SSEvent("message", chunk)
+		ctx.Writer.Flush()
+	})
+
+	// 客户端已断开, 无需再写入
+	if ctx.Request.Context().Err() != nil {
+		return
+	}
+	if err != nil {
+		if !started {
+			response.Fail(ctx, err.(error).Error(), "failed")
+			return
+		}
+		ctx.SSEvent("error", err.(error).Error())
+		ctx.Writer.Flush()
+		return
+	}
+
+	ctx.SSEvent("done", "")
+	ctx.Writer.Flush()
+}
+
+func (aiController aiController) Agent(ctx *gin.Context) {
+	r := new(types.RequestAiAgent)
+	BindJson(ctx, r)
+
+	tid, _ := ctx.Get("TenantID")
+	r.TenantId = tid.(string)
+
+	Service(ctx, func() (interface{}, interface{}) {
+		return services.AiService.Agent(ctx.Request.Context(), r)
+	})
+}
+
+func (aiController aiController) Usage(ctx *gin.Context) {
+	r := new(types.RequestAiUsageQuery)
+	BindQuery(ctx, r)
+
+	tid, _ := ctx.Get("TenantID")
+	r.TenantId = tid.(string)
+
+	Service(ctx, func() (interface{}, interface{}) {
+		return services.AiService.Usage(r)
+	})
+}
+
+func (aiController aiController) RuleDraft(ctx *gin.Context) {
+	r := new(types.RequestAiRuleDraft)
+	BindJson(ctx, r)
+
+	tid, _ := ctx.Get("TenantID")
+	r.TenantId = tid.(string)
+
+	Service(ctx, func() (interface{}, interface{}) {
+		return services.AiService.RuleDraft(r)
+	})
+}
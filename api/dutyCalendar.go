@@ -1,10 +1,14 @@
 package api
 
 import (
+	"fmt"
 	"github.com/gin-gonic/gin"
+	"net/http"
+	"strings"
 	middleware "watchAlert/internal/middleware"
 	"watchAlert/internal/services"
 	"watchAlert/internal/types"
+	jwtUtils "watchAlert/pkg/tools"
 )
 
 type dutyCalendarController struct{}
@@ -26,6 +30,10 @@ func (dutyCalendarController dutyCalendarController) API(gin *gin.RouterGroup) {
 	{
 		a.POST("calendarCreate", dutyCalendarController.Create)
 		a.POST("calendarUpdate", dutyCalendarController.Update)
+		a.POST("holidayImport", dutyCalendarController.ImportHolidays)
+		a.POST("holidayDelete", dutyCalendarController.DeleteHolidays)
+		a.POST("feedCreate", dutyCalendarController.CreateFeed)
+		a.POST("feedDelete", dutyCalendarController.DeleteFeed)
 	}
 
 	b := gin.Group("calendar")
@@ -36,6 +44,11 @@ func (dutyCalendarController dutyCalendarController) API(gin *gin.RouterGroup) {
 	)
 	{
 		b.GET("calendarSearch", dutyCalendarController.Search)
+		b.GET("onCall", dutyCalendarController.OnCall)
+		b.GET("onCallHistory", dutyCalendarController.OnCallHistory)
+		b.GET("onCallAnalytics", dutyCalendarController.OnCallAnalytics)
+		b.GET("holidayList", dutyCalendarController.ListHolidays)
+		b.GET("feedList", dutyCalendarController.ListFeeds)
 	}
 
 	c := gin.Group("calendar")
@@ -46,6 +59,12 @@ func (dutyCalendarController dutyCalendarController) API(gin *gin.RouterGroup) {
 	{
 		c.GET("getCalendarUsers", dutyCalendarController.GetCalendarUsers)
 	}
+
+	// 日历订阅由日历客户端拉取, 通过 Token 鉴权
+	d := gin.Group("calendar")
+	{
+		d.GET("feed/:token", dutyCalendarController.Feed)
+	}
 }
 
 func (dutyCalendarController dutyCalendarController) Create(ctx *gin.Context) {
@@ -95,3 +114,131 @@ func (dutyCalendarController dutyCalendarController) GetCalendarUsers(ctx *gin.C
 		return services.DutyCalendarService.GetCalendarUsers(r)
 	})
 }
+
+func (dutyCalendarController dutyCalendarController) OnCall(ctx *gin.Context) {
+	r := new(types.RequestDutyOnCallQuery)
+	BindQuery(ctx, r)
+
+	tid, _ := ctx.Get("TenantID")
+	r.TenantId = tid.(string)
+
+	Service(ctx, func() (interface{}, interface{}) {
+		return services.DutyCalendarService.OnCall(r)
+	})
+}
+
+func (dutyCalendarController dutyCalendarController) OnCallHistory(ctx *gin.Context) {
+	r := new(types.RequestDutyOnCallHistory)
+	BindQuery(ctx, r)
+
+	tid, _ := ctx.Get("TenantID")
+	r.TenantId = tid.(string)
+
+	Service(ctx, func() (interface{}, interface{}) {
+		return services.DutyCalendarService.OnCallHistory(r)
+	})
+}
+
+func (dutyCalendarController dutyCalendarController) OnCallAnalytics(ctx *gin.Context) {
+	r := new(types.RequestOnCallAnalytics)
+	BindQuery(ctx, r)
+
+	tid, _ := ctx.Get("TenantID")
+	r.TenantId = tid.(string)
+
+	Service(ctx, func() (interface{}, interface{}) {
+		return services.DutyCalendarService.OnCallAnalytics(r)
+	})
+}
+
+func (dutyCalendarController dutyCalendarController) ImportHolidays(ctx *gin.Context) {
+	r := new(types.RequestHolidayImport)
+	BindJson(ctx, r)
+
+	tid, _ := ctx.Get("TenantID")
+	r.TenantId = tid.(string)
+
+	Service(ctx, func() (interface{}, interface{}) {
+		return services.DutyCalendarService.ImportHolidays(r)
+	})
+}
+
+func (dutyCalendarController dutyCalendarController) DeleteHolidays(ctx *gin.Context) {
+	r := new(types.RequestHolidayQuery)
+	BindJson(ctx, r)
+
+	tid, _ := ctx.Get("TenantID")
+	r.TenantId = tid.(string)
+
+	Service(ctx, func() (interface{}, interface{}) {
+		return services.DutyCalendarService.DeleteHolidays(r)
+	})
+}
+
+func (dutyCalendarController dutyCalendarController) ListHolidays(ctx *gin.Context) {
+	r := new(types.RequestHolidayQuery)
+	BindQuery(ctx, r)
+
+	tid, _ := ctx.Get("TenantID")
+	r.TenantId = tid.(string)
+
+	Service(ctx, func() (interface{}, interface{}) {
+		return services.DutyCalendarService.ListHolidays(r)
+	})
+}
+
+func (dutyCalendarController dutyCalendarController) CreateFeed(ctx *gin.Context) {
+	r := new(types.RequestCalendarFeedCreate)
+	BindJson(ctx, r)
+
+	tid, _ := ctx.Get("TenantID")
+	r.TenantId = tid.(string)
+	uid, _ := ctx.Get("UserId")
+	r.UserId = uid.(string)
+	r.CreateBy = jwtUtils.GetUser(ctx.Request.Header.Get("Authorization"))
+
+	Service(ctx, func() (interface{}, interface{}) {
+		return services.DutyCalendarService.CreateFeed(r)
+	})
+}
+
+func (dutyCalendarController dutyCalendarController) DeleteFeed(ctx *gin.Context) {
+	r := new(types.RequestCalendarFeedQuery)
+	BindJson(ctx, r)
+
+	tid, _ := ctx.Get("TenantID")
+	r.TenantId = tid.(string)
+	uid, _ := ctx.Get("UserId")
+	r.UserId = uid.(string)
+
+	Service(ctx, func() (interface{}, interface{}) {
+		return services.DutyCalendarService.DeleteFeed(r)
+	})
+}
+
+func (dutyCalendarController dutyCalendarController) ListFeeds(ctx *gin.Context) {
+	r := new(types.RequestCalendarFeedQuery)
+	BindQuery(ctx, r)
+
+	tid, _ := ctx.Get("TenantID")
+	r.TenantId = tid.(string)
+	uid, _ := ctx.Get("UserId")
+	r.UserId = uid.(string)
+
+	Service(ctx, func() (interface{}, interface{}) {
+		return services.DutyCalendarService.ListFeeds(r)
+	})
+}
+
+func (dutyCalendarController dutyCalendarController) Feed(ctx *gin.Context) {
+	r := &types.RequestCalendarFeedQuery{
+		Token: strings.TrimSuffix(ctx.Param("token"), ".ics"),
+	}
+
+	data, err := services.DutyCalendarService.Feed(r)
+	if err != nil {
+		ctx.String(http.StatusNotFound, fmt.Sprint(err))
+		return
+	}
+	ctx.Data(http.StatusOK, "text/calendar; charset=utf-8", data.([]byte))
+}
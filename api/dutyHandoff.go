@@ -0,0 +1,78 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	middleware "watchAlert/internal/middleware"
+	"watchAlert/internal/services"
+	"watchAlert/internal/types"
+	jwtUtils "watchAlert/pkg/tools"
+)
+
+type dutyHandoffController struct{}
+
+var DutyHandoffController = new(dutyHandoffController)
+
+/*
+值班交接 API
+/api/w8t/calendar
+*/
+func (dutyHandoffController dutyHandoffController) API(gin *gin.RouterGroup) {
+	a := gin.Group("calendar")
+	a.Use(
+		middleware.Auth(),
+		middleware.Permission(),
+		middleware.ParseTenant(),
+		middleware.AuditingLog(),
+	)
+	{
+		a.POST("handoffCreate", dutyHandoffController.Create)
+	}
+
+	b := gin.Group("calendar")
+	b.Use(
+		middleware.Auth(),
+		middleware.Permission(),
+		middleware.ParseTenant(),
+	)
+	{
+		b.GET("handoffList", dutyHandoffController.List)
+		b.GET("handoffGet", dutyHandoffController.Get)
+	}
+}
+
+func (dutyHandoffController dutyHandoffController) Create(ctx *gin.Context) {
+	r := new(types.RequestDutyHandoffCreate)
+	BindJson(ctx, r)
+
+	tid, _ := ctx.Get("TenantID")
+	r.TenantId = tid.(string)
+	r.CreateBy = jwtUtils.GetUser(ctx.Request.Header.Get("Authorization"))
+
+	Service(ctx, func() (interface{}, interface{}) {
+		return services.DutyHandoffService.Create(r)
+	})
+}
+
+func (dutyHandoffController dutyHandoffController) List(ctx *gin.Context) {
+	r := new(types.RequestDutyHandoffQuery)
+	BindQuery(ctx, r)
+
+	tid, _ := ctx.Get("TenantID")
+	r.TenantId = tid.(string)
+
+	Service(ctx, func() (interface{}, interface{}) {
+		return services.DutyHandoffService.List(r)
+	})
+}
+
+func (dutyHandoffController dutyHandoffController) Get(ctx *gin.Context) {
+	r := new(types.RequestDutyHandoffQuery)
+	BindQuery(ctx, r)
+
+	tid, _ := ctx.Get("TenantID")
+	r.TenantId = tid.(string)
+
+	Service(ctx, func() (interface{}, interface{}) {
+		return services.DutyHandoffService.Get(r)
+	})
+}
@@ -0,0 +1,137 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	middleware "watchAlert/internal/middleware"
+	"watchAlert/internal/services"
+	"watchAlert/internal/types"
+	jwtUtils "watchAlert/pkg/tools"
+)
+
+type dutyOverrideController struct{}
+
+var DutyOverrideController = new(dutyOverrideController)
+
+/*
+覆盖值班及换班 API
+/api/w8t/calendar
+*/
+func (dutyOverrideController dutyOverrideController) API(gin *gin.RouterGroup) {
+	a := gin.Group("calendar")
+	a.Use(
+		middleware.Auth(),
+		middleware.Permission(),
+		middleware.ParseTenant(),
+		middleware.AuditingLog(),
+	)
+	{
+		a.POST("overrideCreate", dutyOverrideController.Create)
+		a.POST("overrideCancel", dutyOverrideController.Cancel)
+		a.POST("swapCreate", dutyOverrideController.CreateSwap)
+		a.POST("swapAccept", dutyOverrideController.AcceptSwap)
+		a.POST("swapReject", dutyOverrideController.RejectSwap)
+	}
+
+	b := gin.Group("calendar")
+	b.Use(
+		middleware.Auth(),
+		middleware.Permission(),
+		middleware.ParseTenant(),
+	)
+	{
+		b.GET("overrideList", dutyOverrideController.List)
+		b.GET("swapList", dutyOverrideController.ListSwap)
+	}
+}
+
+func (dutyOverrideController dutyOverrideController) Create(ctx *gin.Context) {
+	r := new(types.RequestDutyOverrideCreate)
+	BindJson(ctx, r)
+
+	tid, _ := ctx.Get("TenantID")
+	r.TenantId = tid.(string)
+	r.CreateBy = jwtUtils.GetUser(ctx.Request.Header.Get("Authorization"))
+
+	Service(ctx, func() (interface{}, interface{}) {
+		return services.DutyOverrideService.Create(r)
+	})
+}
+
+func (dutyOverrideController dutyOverrideController) Cancel(ctx *gin.Context) {
+	r := new(types.RequestDutyOverrideCancel)
+	BindJson(ctx, r)
+
+	tid, _ := ctx.Get("TenantID")
+	r.TenantId = tid.(string)
+	r.CanceledBy = jwtUtils.GetUser(ctx.Request.Header.Get("Authorization"))
+
+	Service(ctx, func() (interface{}, interface{}) {
+		return services.DutyOverrideService.Cancel(r)
+	})
+}
+
+func (dutyOverrideController dutyOverrideController) List(ctx *gin.Context) {
+	r := new(types.RequestDutyOverrideQuery)
+	BindQuery(ctx, r)
+
+	tid, _ := ctx.Get("TenantID")
+	r.TenantId = tid.(string)
+
+	Service(ctx, func() (interface{}, interface{}) {
+		return services.DutyOverrideService.List(r)
+	})
+}
+
+func (dutyOverrideController dutyOverrideController) CreateSwap(ctx *gin.Context) {
+	r := new(types.RequestDutySwapCreate)
+	BindJson(ctx, r)
+
+	tid, _ := ctx.Get("TenantID")
+	r.TenantId = tid.(string)
+	uid, _ := ctx.Get("UserId")
+	r.UserId = uid.(string)
+
+	Service(ctx, func() (interface{}, interface{}) {
+		return services.DutyOverrideService.CreateSwap(r)
+	})
+}
+
+func (dutyOverrideController dutyOverrideController) AcceptSwap(ctx *gin.Context) {
+	r := new(types.RequestDutySwapAction)
+	BindJson(ctx, r)
+
+	tid, _ := ctx.Get("TenantID")
+	r.TenantId = tid.(string)
+	uid, _ := ctx.Get("UserId")
+	r.UserId = uid.(string)
+
+	Service(ctx, func() (interface{}, interface{}) {
+		return services.DutyOverrideService.AcceptSwap(r)
+	})
+}
+
+func (dutyOverrideController dutyOverrideController) RejectSwap(ctx *gin.Context) {
+	r := new(types.RequestDutySwapAction)
+	BindJson(ctx, r)
+
+	tid, _ := ctx.Get("TenantID")
+	r.TenantId = tid.(string)
+	uid, _ := ctx.Get("UserId")
+	r.UserId = uid.(string)
+
+	Service(ctx, func() (interface{}, interface{}) {
+		return services.DutyOverrideService.RejectSwap(r)
+	})
+}
+
+func (dutyOverrideController dutyOverrideController) ListSwap(ctx *gin.Context) {
+	r := new(types.RequestDutySwapQuery)
+	BindQuery(ctx, r)
+
+	tid, _ := ctx.Get("TenantID")
+	r.TenantId = tid.(string)
+
+	Service(ctx, func() (interface{}, interface{}) {
+		return services.DutyOverrideService.ListSwap(r)
+	})
+}
@@ -1,11 +1,15 @@
 package api
 
 import (
+	"errors"
 	"github.com/gin-gonic/gin"
+	"net/http"
+	"strings"
 	"time"
 	"watchAlert/internal/middleware"
 	"watchAlert/internal/services"
 	"watchAlert/internal/types"
+	"watchAlert/pkg/blob"
 	"watchAlert/pkg/response"
 	utils "watchAlert/pkg/tools"
 )
@@ -40,6 +44,14 @@ func (alertEventController alertEventController) API(gin *gin.RouterGroup) {
 	{
 		b.GET("curEvent", alertEventController.ListCurrentEvent)
 		b.GET("hisEvent", alertEventController.ListHistoryEvent)
+		b.GET("flapState", alertEventController.ListFlapState)
+		b.GET("curEventClusters", alertEventController.ListClusters)
+	}
+
+	// 通知附带的趋势图由 IM 及邮件客户端拉取, 地址随机生成, 无需登录
+	c := gin.Group("event")
+	{
+		c.GET("graph/*key", alertEventController.Graph)
 	}
 }
 
@@ -76,6 +88,30 @@ func (alertEventController alertEventController) ListCurrentEvent(ctx *gin.Conte
 	})
 }
 
+func (alertEventController alertEventController) ListFlapState(ctx *gin.Context) {
+	r := new(types.RequestAlertFlapStateQuery)
+	BindQuery(ctx, r)
+
+	tid, _ := ctx.Get("TenantID")
+	r.TenantId = tid.(string)
+
+	Service(ctx, func() (interface{}, interface{}) {
+		return services.EventService.ListFlapState(r)
+	})
+}
+
+func (alertEventController alertEventController) ListClusters(ctx *gin.Context) {
+	r := new(types.RequestAlertClusterQuery)
+	BindQuery(ctx, r)
+
+	tid, _ := ctx.Get("TenantID")
+	r.TenantId = tid.(string)
+
+	Service(ctx, func() (interface{}, interface{}) {
+		return services.EventService.ListClusters(r)
+	})
+}
+
 func (alertEventController alertEventController) ListHistoryEvent(ctx *gin.Context) {
 	r := new(types.RequestAlertHisEventQuery)
 	BindQuery(ctx, r)
@@ -127,3 +163,21 @@ func (alertEventController alertEventController) DeleteComment(ctx *gin.Context)
 		return services.EventService.DeleteComment(r)
 	})
 }
+
+func (alertEventController alertEventController) Graph(ctx *gin.Context) {
+	r := &types.RequestEventGraph{
+		Key: strings.TrimPrefix(ctx.Param("key"), "/"),
+	}
+
+	data, err := services.EventService.GetGraph(r)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if e, ok := err.(error); ok && errors.Is(e, blob.ErrNotFound) {
+			status = http.StatusNotFound
+		}
+		ctx.String(status, "%v", err)
+		return
+	}
+	ctx.Header("Cache-Control", "public, max-age=86400")
+	ctx.Data(http.StatusOK, "image/png", data.([]byte))
+}
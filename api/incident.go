@@ -0,0 +1,176 @@
+package api
+
+import (
+	"watchAlert/internal/middleware"
+	"watchAlert/internal/services"
+	"watchAlert/internal/types"
+	"watchAlert/pkg/tools"
+
+	"github.com/gin-gonic/gin"
+)
+
+type incidentController struct{}
+
+var IncidentController = new(incidentController)
+
+/*
+故障管理 API
+/api/w8t/incident
+*/
+func (incidentController incidentController) API(gin *gin.RouterGroup) {
+	a := gin.Group("incident")
+	a.Use(
+		middleware.Auth(),
+		middleware.Permission(),
+		middleware.ParseTenant(),
+		middleware.AuditingLog(),
+	)
+	{
+		a.POST("incidentCreate", incidentController.Create)
+		a.POST("incidentUpdate", incidentController.Update)
+		a.POST("incidentDelete", incidentController.Delete)
+		a.POST("incidentStatus", incidentController.ChangeStatus)
+		a.POST("incidentLinkEvents", incidentController.LinkEvents)
+		a.POST("incidentUnlinkEvents", incidentController.UnlinkEvents)
+		a.POST("incidentComment", incidentController.AddComment)
+		a.POST("incidentBroadcast", incidentController.Broadcast)
+	}
+
+	b := gin.Group("incident")
+	b.Use(
+		middleware.Auth(),
+		middleware.Permission(),
+		middleware.ParseTenant(),
+	)
+	{
+		b.GET("incidentList", incidentController.List)
+		b.GET("incidentGet", incidentController.Get)
+	}
+}
+
+func (incidentController incidentController) Create(ctx *gin.Context) {
+	r := new(types.RequestIncidentCreate)
+	BindJson(ctx, r)
+
+	tid, _ := ctx.Get("TenantID")
+	r.TenantId = tid.(string)
+	r.CreateBy = tools.GetUser(ctx.Request.Header.Get("Authorization"))
+
+	Service(ctx, func() (interface{}, interface{}) {
+		return services.IncidentService.Create(r)
+	})
+}
+
+func (incidentController incidentController) Update(ctx *gin.Context) {
+	r := new(types.RequestIncidentUpdate)
+	BindJson(ctx, r)
+
+	tid, _ := ctx.Get("TenantID")
+	r.TenantId = tid.(string)
+	r.UpdateBy = tools.GetUser(ctx.Request.Header.Get("Authorization"))
+
+	Service(ctx, func() (interface{}, interface{}) {
+		return services.IncidentService.Update(r)
+	})
+}
+
+func (incidentController incidentController) Delete(ctx *gin.Context) {
+	r := new(types.RequestIncidentQuery)
+	BindJson(ctx, r)
+
+	tid, _ := ctx.Get("TenantID")
+	r.TenantId = tid.(string)
+
+	Service(ctx, func() (interface{}, interface{}) {
+		return services.IncidentService.Delete(r)
+	})
+}
+
+func (incidentController incidentController) List(ctx *gin.Context) {
+	r := new(types.RequestIncidentQuery)
+	BindQuery(ctx, r)
+
+	tid, _ := ctx.Get("TenantID")
+	r.TenantId = tid.(string)
+
+	Service(ctx, func() (interface{}, interface{}) {
+		return services.IncidentService.List(r)
+	})
+}
+
+func (incidentController incidentController) Get(ctx *gin.Context) {
+	r := new(types.RequestIncidentQuery)
+	BindQuery(ctx, r)
+
+	tid, _ := ctx.Get("TenantID")
+	r.TenantId = tid.(string)
+
+	Service(ctx, func() (interface{}, interface{}) {
+		return services.IncidentService.Get(r)
+	})
+}
+
+func (incidentController incidentController) ChangeStatus(ctx *gin.Context) {
+	r := new(types.RequestIncidentStatusChange)
+	BindJson(ctx, r)
+
+	tid, _ := ctx.Get("TenantID")
+	r.TenantId = tid.(string)
+	r.Username = tools.GetUser(ctx.Request.Header.Get("Authorization"))
+
+	Service(ctx, func() (interface{}, interface{}) {
+		return services.IncidentService.ChangeStatus(r)
+	})
+}
+
+func (incidentController incidentController) LinkEvents(ctx *gin.Context) {
+	r := new(types.RequestIncidentLinkEvents)
+	BindJson(ctx, r)
+
+	tid, _ := ctx.Get("TenantID")
+	r.TenantId = tid.(string)
+	r.Username = tools.GetUser(ctx.Request.Header.Get("Authorization"))
+
+	Service(ctx, func() (interface{}, interface{}) {
+		return services.IncidentService.LinkEvents(r)
+	})
+}
+
+func (incidentController incidentController) UnlinkEvents(ctx *gin.Context) {
+	r := new(types.RequestIncidentLinkEvents)
+	BindJson(ctx, r)
+
+	tid, _ := ctx.Get("TenantID")
+	r.TenantId = tid.(string)
+	r.Username = tools.GetUser(ctx.Request.Header.Get("Authorization"))
+
+	Service(ctx, func() (interface{}, interface{}) {
+		return services.IncidentService.UnlinkEvents(r)
+	})
+}
+
+func (incidentController incidentController) AddComment(ctx *gin.Context) {
+	r := new(types.RequestIncidentComment)
+	BindJson(ctx, r)
+
+	tid, _ := ctx.Get("TenantID")
+	r.TenantId = tid.(string)
+	r.Username = tools.GetUser(ctx.Request.Header.Get("Authorization"))
+
+	Service(ctx, func() (interface{}, interface{}) {
+		return services.IncidentService.AddComment(r)
+	})
+}
+
+func (incidentController incidentController) Broadcast(ctx *gin.Context) {
+	r := new(types.RequestIncidentBroadcast)
+	BindJson(ctx, r)
+
+	tid, _ := ctx.Get("TenantID")
+	r.TenantId = tid.(string)
+	r.Username = tools.GetUser(ctx.Request.Header.Get("Authorization"))
+
+	Service(ctx, func() (interface{}, interface{}) {
+		return services.IncidentService.Broadcast(r)
+	})
+}
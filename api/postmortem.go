@@ -0,0 +1,120 @@
+package api
+
+import (
+	"watchAlert/internal/middleware"
+	"watchAlert/internal/services"
+	"watchAlert/internal/types"
+	"watchAlert/pkg/tools"
+
+	"github.com/gin-gonic/gin"
+)
+
+type postmortemController struct{}
+
+var PostmortemController = new(postmortemController)
+
+/*
+故障复盘 API
+/api/w8t/postmortem
+*/
+func (postmortemController postmortemController) API(gin *gin.RouterGroup) {
+	a := gin.Group("postmortem")
+	a.Use(
+		middleware.Auth(),
+		middleware.Permission(),
+		middleware.ParseTenant(),
+		middleware.AuditingLog(),
+	)
+	{
+		a.POST("postmortemGenerate", postmortemController.Generate)
+		a.POST("postmortemUpdate", postmortemController.Update)
+		a.POST("postmortemFinalize", postmortemController.Finalize)
+		a.POST("postmortemDelete", postmortemController.Delete)
+	}
+
+	b := gin.Group("postmortem")
+	b.Use(
+		middleware.Auth(),
+		middleware.Permission(),
+		middleware.ParseTenant(),
+	)
+	{
+		b.GET("postmortemList", postmortemController.List)
+		b.GET("postmortemGet", postmortemController.Get)
+	}
+}
+
+func (postmortemController postmortemController) Generate(ctx *gin.Context) {
+	r := new(types.RequestPostmortemGenerate)
+	BindJson(ctx, r)
+
+	tid, _ := ctx.Get("TenantID")
+	r.TenantId = tid.(string)
+	r.Username = tools.GetUser(ctx.Request.Header.Get("Authorization"))
+
+	Service(ctx, func() (interface{}, interface{}) {
+		return services.PostmortemService.Generate(r)
+	})
+}
+
+func (postmortemController postmortemController) Update(ctx *gin.Context) {
+	r := new(types.RequestPostmortemUpdate)
+	BindJson(ctx, r)
+
+	tid, _ := ctx.Get("TenantID")
+	r.TenantId = tid.(string)
+	r.Username = tools.GetUser(ctx.Request.Header.Get("Authorization"))
+
+	Service(ctx, func() (interface{}, interface{}) {
+		return services.PostmortemService.Update(r)
+	})
+}
+
+func (postmortemController postmortemController) Finalize(ctx *gin.Context) {
+	r := new(types.RequestPostmortemFinalize)
+	BindJson(ctx, r)
+
+	tid, _ := ctx.Get("TenantID")
+	r.TenantId = tid.(string)
+	r.Username = tools.GetUser(ctx.Request.Header.Get("Authorization"))
+
+	Service(ctx, func() (interface{}, interface{}) {
+		return services.PostmortemService.Finalize(r)
+	})
+}
+
+func (postmortemController postmortemController) Delete(ctx *gin.Context) {
+	r := new(types.RequestPostmortemQuery)
+	BindJson(ctx, r)
+
+	tid, _ := ctx.Get("TenantID")
+	r.TenantId = tid.(string)
+
+	Service(ctx, func() (interface{}, interface{}) {
+		return services.PostmortemService.Delete(r)
+	})
+}
+
+func (postmortemController postmortemController) List(ctx *gin.Context) {
+	r := new(types.RequestPostmortemQuery)
+	BindQuery(ctx, r)
+
+	tid, _ := ctx.Get("TenantID")
+	r.TenantId = tid.(string)
+
+	Service(ctx, func() (interface{}, interface{}) {
+		return services.PostmortemService.List(r)
+	})
+}
+
+func (postmortemController postmortemController) Get(ctx *gin.Context) {
+	r := new(types.RequestPostmortemQuery)
+	BindQuery(ctx, r)
+
+	tid, _ := ctx.Get("TenantID")
+	r.TenantId = tid.(string)
+
+	Service(ctx, func() (interface{}, interface{}) {
+		return services.PostmortemService.Get(r)
+	})
+}
@@ -6,9 +6,11 @@ import (
 	"watchAlert/internal/services"
 	"watchAlert/internal/types"
 	"watchAlert/pkg/response"
+	"watchAlert/pkg/sender/twilio"
 	"watchAlert/pkg/templates"
 
 	"github.com/gin-gonic/gin"
+	"github.com/zeromicro/go-zero/core/logc"
 )
 
 type quickActionController struct{}
@@ -43,6 +45,19 @@ func (q quickActionController) API(gin *gin.RouterGroup) {
 		authGroup.GET("quick-silence", q.QuickSilenceForm) // 自定义静默表单
 		authGroup.POST("quick-silence", q.QuickSilence)    // 提交自定义静默
 	}
+
+	// Telegram 内联按钮回调（通过 X-Telegram-Bot-Api-Secret-Token 校验）
+	telegram := gin.Group("telegram")
+	telegram.POST("webhook", q.TelegramWebhook)
+
+	// PagerDuty、Opsgenie 认领状态回传（分别通过签名及令牌校验）
+	gin.Group("pagerduty").POST("webhook/:tenantId", q.PagerDutyWebhook)
+	gin.Group("opsgenie").POST("webhook/:tenantId", q.OpsgenieWebhook)
+
+	// 语音通知按键认领（通过通知时签发的令牌校验）
+	phone := gin.Group("phone")
+	phone.POST("ack/twilio", q.TwilioAck)
+	phone.POST("ack/tencent", q.TencentAck)
 }
 
 // QuickAction 快捷操作接口
@@ -226,3 +241,83 @@ func (q quickActionController) DoQuickLogin(ctx *gin.Context) {
 	// 登录成功,返回token
 	response.Success(ctx, result, "登录成功")
 }
+
+// TelegramWebhook 接收 Telegram Webhook 推送的更新
+// 需通过 setWebhook 设置 secret_token, 与系统设置中的 Webhook 密钥一致
+func (q quickActionController) TelegramWebhook(ctx *gin.Context) {
+	var update types.TelegramUpdate
+	if err := ctx.ShouldBindJSON(&update); err != nil {
+		ctx.JSON(400, gin.H{"ok": false, "description": err.Error()})
+		return
+	}
+
+	err := services.QuickActionService.TelegramCallback(ctx.GetHeader("X-Telegram-Bot-Api-Secret-Token"), update)
+	if err != nil {
+		ctx.JSON(401, gin.H{"ok": false, "description": err.Error()})
+		return
+	}
+
+	ctx.JSON(200, gin.H{"ok": true})
+}
+
+// PagerDutyWebhook 接收 PagerDuty Webhook v3 推送的故障事件
+func (q quickActionController) PagerDutyWebhook(ctx *gin.Context) {
+	body, err := ctx.GetRawData()
+	if err != nil {
+		ctx.JSON(400, gin.H{"message": err.Error()})
+		return
+	}
+
+	err = services.QuickActionService.PagerDutyCallback(ctx.Param("tenantId"), ctx.GetHeader("X-PagerDuty-Signature"), body)
+	if err != nil {
+		ctx.JSON(401, gin.H{"message": err.Error()})
+		return
+	}
+
+	ctx.JSON(200, gin.H{"message": "ok"})
+}
+
+// OpsgenieWebhook 接收 Opsgenie Webhook 集成推送的告警操作
+func (q quickActionController) OpsgenieWebhook(ctx *gin.Context) {
+	body, err := ctx.GetRawData()
+	if err != nil {
+		ctx.JSON(400, gin.H{"message": err.Error()})
+		return
+	}
+
+	err = services.QuickActionService.OpsgenieCallback(ctx.Param("tenantId"), ctx.GetHeader("X-Webhook-Token"), body)
+	if err != nil {
+		ctx.JSON(401, gin.H{"message": err.Error()})
+		return
+	}
+
+	ctx.JSON(200, gin.H{"message": "ok"})
+}
+
+// TwilioAck 接收 Twilio <Gather> 的按键结果, 返回 TwiML 播报认领结果
+func (q quickActionController) TwilioAck(ctx *gin.Context) {
+	message := "告警已认领"
+	err := services.QuickActionService.PhoneCallAck(ctx.Query("token"), ctx.PostForm("To"), ctx.PostForm("Digits"))
+	if err != nil {
+		message = err.Error()
+	}
+
+	ctx.Data(200, "text/xml; charset=utf-8", []byte(twilio.AckResponse(message, "")))
+}
+
+// TencentAck 接收腾讯云语音消息的按键回调
+func (q quickActionController) TencentAck(ctx *gin.Context) {
+	var callback types.TencentVoiceCallback
+	if err := ctx.ShouldBindJSON(&callback); err != nil {
+		ctx.JSON(200, gin.H{"result": 1, "errmsg": err.Error()})
+		return
+	}
+
+	if key := callback.VoiceKeyCallback; key != nil && key.Ext != "" {
+		if err := services.QuickActionService.PhoneCallAck(key.Ext, key.Mobile, key.Keypress); err != nil {
+			logc.Infof(ctx.Request.Context(), "语音按键认领失败: %s", err.Error())
+		}
+	}
+
+	ctx.JSON(200, gin.H{"result": 0, "errmsg": "OK"})
+}
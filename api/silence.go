@@ -38,6 +38,8 @@ func (silenceController silenceController) API(gin *gin.RouterGroup) {
 	)
 	{
 		b.GET("silenceList", silenceController.List)
+		b.GET("silenceOccurrences", silenceController.Occurrences)
+		b.POST("silencePreview", silenceController.Preview)
 	}
 }
 
@@ -94,3 +96,27 @@ func (silenceController silenceController) List(ctx *gin.Context) {
 		return services.SilenceService.List(r)
 	})
 }
+
+func (silenceController silenceController) Occurrences(ctx *gin.Context) {
+	r := new(types.RequestSilenceOccurrences)
+	BindQuery(ctx, r)
+
+	tid, _ := ctx.Get("TenantID")
+	r.TenantId = tid.(string)
+
+	Service(ctx, func() (interface{}, interface{}) {
+		return services.SilenceService.Occurrences(r)
+	})
+}
+
+func (silenceController silenceController) Preview(ctx *gin.Context) {
+	r := new(types.RequestSilencePreview)
+	BindJson(ctx, r)
+
+	tid, _ := ctx.Get("TenantID")
+	r.TenantId = tid.(string)
+
+	Service(ctx, func() (interface{}, interface{}) {
+		return services.SilenceService.Preview(r)
+	})
+}
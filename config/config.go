@@ -7,11 +7,13 @@ import (
 )
 
 type App struct {
-	Server Server `json:"Server"`
-	MySQL  MySQL  `json:"MySQL"`
-	Redis  Redis  `json:"Redis"`
-	Jwt    Jwt    `json:"Jwt"`
-	Jaeger Jaeger `json:"Jaeger"`
+	Server  Server  `json:"Server"`
+	MySQL   MySQL   `json:"MySQL"`
+	Redis   Redis   `json:"Redis"`
+	Jwt     Jwt     `json:"Jwt"`
+	Jaeger  Jaeger  `json:"Jaeger"`
+	Eval    Eval    `json:"Eval"`
+	Metrics Metrics `json:"Metrics"`
 }
 
 type Server struct {
@@ -44,6 +46,27 @@ type Jaeger struct {
 	URL string `json:"url"`
 }
 
+// Eval 规则评估调度配置
+type Eval struct {
+	// 评估工作协程数量
+	Workers int `json:"workers"`
+	// 单个数据源最大并发查询数
+	DatasourceMaxInflight int `json:"datasourceMaxInflight"`
+	// 单个数据源最大排队查询数, 超出后本轮查询将被丢弃
+	DatasourceQueueDepth int `json:"datasourceQueueDepth"`
+	// 相同查询结果的共享时间窗口(秒), 0 使用默认值, 小于 0 关闭
+	QueryCacheTTL int `json:"queryCacheTTL"`
+}
+
+// Metrics 内部指标接口 /metrics 配置, 未开启时不注册该接口
+type Metrics struct {
+	Enable bool `json:"enable"`
+	// 访问令牌, 不为空时需携带 Authorization: Bearer <token>
+	Token string `json:"token"`
+	// 允许访问的来源 IP 或网段, 为空时不限制
+	AllowIPs []string `json:"allowIPs"`
+}
+
 var (
 	configFile = "config/config.yaml"
 )
@@ -4,7 +4,9 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"time"
 	"watchAlert/alert"
+	"watchAlert/alert/mute"
 	"watchAlert/config"
 	"watchAlert/internal/cache"
 	"watchAlert/internal/ctx"
@@ -13,6 +15,7 @@ import (
 	"watchAlert/internal/repo"
 	"watchAlert/internal/services"
 	"watchAlert/pkg/ai"
+	"watchAlert/pkg/blob"
 	"watchAlert/pkg/templates"
 	"watchAlert/pkg/tools"
 
@@ -49,8 +52,22 @@ func InitBasic() {
 	// 定时任务，每年12月1日自动生成次年值班表
 	go autoGenerateNextYearDutySchedule(ctx)
 
-	// 加载静默规则
-	go pushMuteRuleToRedis()
+	// 定时任务，在值班班次边界生成交接报告
+	go dutyHandoffCheck(ctx)
+
+	// 定时任务，每小时将告警通知归属到当班人员, 用于值班负载统计
+	go dutyPageAttribute(ctx)
+
+	// 迁移旧版本静默规则的匹配条件后加载静默规则
+	go func() {
+		migrateSilenceMatchers(ctx)
+		pushMuteRuleToRedis()
+	}()
+
+	// 记录 Ai 调用的 Token 用量
+	ai.RegisterUsageHook(func(_ context.Context, usage ai.Usage) {
+		recordAiUsage(ctx, usage)
+	})
 
 	r, err := ctx.DB.Setting().Get()
 	if err != nil {
@@ -78,6 +95,24 @@ func InitBasic() {
 	}
 }
 
+func recordAiUsage(ctx *ctx.Context, usage ai.Usage) {
+	success := usage.Success
+	err := ctx.DB.Ai().AddUsage(models.AiUsage{
+		TenantId:         usage.TenantId,
+		Profile:          usage.Profile,
+		Provider:         usage.Provider,
+		Model:            usage.Model,
+		PromptTokens:     usage.PromptTokens,
+		CompletionTokens: usage.CompletionTokens,
+		TotalTokens:      usage.PromptTokens + usage.CompletionTokens,
+		Success:          &success,
+		CreateAt:         time.Now().Unix(),
+	})
+	if err != nil {
+		logc.Error(ctx.Ctx, fmt.Sprintf("记录 Ai 用量失败: %s", err.Error()))
+	}
+}
+
 func importClientPools(ctx *ctx.Context) {
 	list, err := ctx.DB.Datasource().List("", "", "", "")
 	if err != nil {
@@ -118,9 +153,38 @@ func gcHistoryData(ctx *ctx.Context) {
 		} else {
 			logc.Info(ctx.Ctx, "success delete notice history record")
 		}
+
+		err = ctx.DB.Ai().DeleteExpired(time.Now().Unix())
+		if err != nil {
+			logc.Errorf(ctx.Ctx, "fail to delete expired ai content record, %s", err.Error())
+		} else {
+			logc.Info(ctx.Ctx, "success delete expired ai content record")
+		}
+
+		gcGraphSnapshot(ctx)
 	})
 }
 
+// gcGraphSnapshot 清理超过保留天数的告警趋势图
+func gcGraphSnapshot(ctx *ctx.Context) {
+	setting, err := ctx.DB.Setting().Get()
+	if err != nil || !setting.GraphConfig.GetEnable() {
+		return
+	}
+
+	store, err := blob.NewStore(setting.GraphConfig)
+	if err != nil {
+		logc.Errorf(ctx.Ctx, "fail to create graph store, %s", err.Error())
+		return
+	}
+	count, err := store.Cleanup(time.Now().AddDate(0, 0, -setting.GraphConfig.GetRetentionDays()))
+	if err != nil {
+		logc.Errorf(ctx.Ctx, "fail to delete expired graph snapshot, %s", err.Error())
+	} else {
+		logc.Infof(ctx.Ctx, "success delete %d expired graph snapshot", count)
+	}
+}
+
 // autoGenerateNextYearDutySchedule 自动生成次年值班表
 // 定时任务：每年12月1日凌晨00:00触发
 func autoGenerateNextYearDutySchedule(ctx *ctx.Context) {
@@ -139,6 +203,50 @@ func autoGenerateNextYearDutySchedule(ctx *ctx.Context) {
 	})
 }
 
+// dutyHandoffCheck 每分钟检查值班组是否发生主值班人员交接, 仅由 Leader 节点执行
+func dutyHandoffCheck(ctx *ctx.Context) {
+	tools.NewCronjob("* * * * *", func() {
+		if !alert.IsLeader() {
+			return
+		}
+		services.DutyHandoffService.CheckShiftBoundary(time.Now())
+	})
+}
+
+// dutyPageAttribute 每小时归属告警通知到当班人员, 仅由 Leader 节点执行
+func dutyPageAttribute(ctx *ctx.Context) {
+	tools.NewCronjob("5 * * * *", func() {
+		if !alert.IsLeader() {
+			return
+		}
+		services.DutyCalendarService.AttributePages(time.Now())
+	})
+}
+
+// migrateSilenceMatchers 将升级前保存的 = / != 匹配条件转换为等价的正则操作符, 保持已有静默规则的匹配范围不变
+func migrateSilenceMatchers(ctx *ctx.Context) {
+	list, err := ctx.DB.Silence().ListLegacyMatchers()
+	if err != nil {
+		logc.Errorf(ctx.Ctx, "获取待迁移的静默规则失败, err: %s", err.Error())
+		return
+	}
+
+	var count int
+	for _, silence := range list {
+		labels, changed := mute.MigrateLegacyMatchers(silence.Labels)
+		if err := ctx.DB.Silence().UpdateMatchers(silence.TenantId, silence.ID, labels); err != nil {
+			logc.Errorf(ctx.Ctx, "迁移静默规则 %s 的匹配条件失败, err: %s", silence.ID, err.Error())
+			continue
+		}
+		if changed {
+			count++
+		}
+	}
+	if count > 0 {
+		logc.Infof(ctx.Ctx, "已将 %d 个静默规则的 = / != 匹配条件迁移为正则匹配", count)
+	}
+}
+
 func pushMuteRuleToRedis() {
 	list, _, err := ctx.DB.Silence().List("", "", "", models.Page{
 		Index: 0,
@@ -19,6 +19,7 @@ type (
 		ProviderPools() *ProviderPoolStore
 		FaultCenter() FaultCenterCacheInterface
 		PendingRecover() PendingRecoverCacheInterface
+		Flapping() FlappingCacheInterface
 	}
 )
 
@@ -43,3 +44,5 @@ func (e entryCache) FaultCenter() FaultCenterCacheInterface {
 func (e entryCache) PendingRecover() PendingRecoverCacheInterface {
 	return newPendingRecoverCacheInterface(e.redis)
 }
+
+func (e entryCache) Flapping() FlappingCacheInterface { return newFlappingCacheInterface(e.redis) }
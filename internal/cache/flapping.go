@@ -0,0 +1,76 @@
+package cache
+
+import (
+	"github.com/bytedance/sonic"
+	"github.com/go-redis/redis"
+	"github.com/zeromicro/go-zero/core/logc"
+	"golang.org/x/net/context"
+	"watchAlert/internal/models"
+	"watchAlert/pkg/tools"
+)
+
+type (
+	// FlappingCache 用于管理告警抖动状态
+	FlappingCache struct {
+		rc *redis.Client
+	}
+
+	// FlappingCacheInterface 定义了告警抖动状态缓存的操作接口
+	FlappingCacheInterface interface {
+		Set(state models.FlapState)
+		Get(tenantId, faultCenterId, fingerprint string) (models.FlapState, bool)
+		Delete(tenantId, faultCenterId, fingerprint string)
+		List(tenantId, faultCenterId string) []models.FlapState
+	}
+)
+
+// newFlappingCacheInterface 创建一个新的 FlappingCache 实例
+func newFlappingCacheInterface(r *redis.Client) FlappingCacheInterface {
+	return &FlappingCache{
+		rc: r,
+	}
+}
+
+func (f *FlappingCache) Set(state models.FlapState) {
+	key := string(models.BuildAlertFlapCacheKey(state.TenantId, state.FaultCenterId))
+	err := f.rc.HSet(key, state.Fingerprint, tools.JsonMarshalToString(state)).Err()
+	if err != nil {
+		logc.Errorf(context.Background(), "设置抖动状态失败, err: %s", err.Error())
+	}
+}
+
+func (f *FlappingCache) Get(tenantId, faultCenterId, fingerprint string) (models.FlapState, bool) {
+	result, err := f.rc.HGet(string(models.BuildAlertFlapCacheKey(tenantId, faultCenterId)), fingerprint).Result()
+	if err != nil {
+		return models.FlapState{}, false
+	}
+
+	var state models.FlapState
+	if err := sonic.Unmarshal([]byte(result), &state); err != nil {
+		return models.FlapState{}, false
+	}
+
+	return state, true
+}
+
+func (f *FlappingCache) Delete(tenantId, faultCenterId, fingerprint string) {
+	f.rc.HDel(string(models.BuildAlertFlapCacheKey(tenantId, faultCenterId)), fingerprint)
+}
+
+func (f *FlappingCache) List(tenantId, faultCenterId string) []models.FlapState {
+	result, err := f.rc.HGetAll(string(models.BuildAlertFlapCacheKey(tenantId, faultCenterId))).Result()
+	if err != nil {
+		return nil
+	}
+
+	var states []models.FlapState
+	for _, v := range result {
+		var state models.FlapState
+		if err := sonic.Unmarshal([]byte(v), &state); err != nil {
+			continue
+		}
+		states = append(states, state)
+	}
+
+	return states
+}
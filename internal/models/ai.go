@@ -1,11 +1,139 @@
 package models
 
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// AiContentRecord Ai 分析结果缓存, 按 规则 + 指纹 + 内容哈希 区分
 type AiContentRecord struct {
-	RuleId string `json:"RuleId" form:"ruleId"`
+	TenantId    string `json:"tenantId" form:"tenantId"`
+	RuleId      string `json:"RuleId" form:"ruleId"`
+	Fingerprint string `json:"fingerprint" form:"fingerprint"`
+	EventId     string `json:"eventId" form:"eventId"`
+	ContentHash string `json:"contentHash" form:"contentHash"`
 	// Ai 分析后的内容
-	Content string `json:"content" form:"content"`
+	Content  string `json:"content" form:"content" gorm:"type:text"`
+	CreateAt int64  `json:"createAt"`
+	ExpireAt int64  `json:"expireAt"` // 过期时间, 0 表示不过期
 }
 
 func (a AiContentRecord) TableName() string {
 	return "w8t_ai_content_record"
 }
+
+// IsExpired 分析结果是否已过期
+func (a AiContentRecord) IsExpired(now int64) bool {
+	return a.ExpireAt > 0 && now >= a.ExpireAt
+}
+
+// BuildAiContentHash 计算分析内容的哈希, 告警内容变化后不再命中旧的分析结果
+func BuildAiContentHash(parts ...string) string {
+	h := md5.New()
+	h.Write([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// AiUsage Ai 调用的 Token 用量记录
+type AiUsage struct {
+	TenantId         string `json:"tenantId"`
+	Profile          string `json:"profile"`
+	Provider         string `json:"provider"`
+	Model            string `json:"model"`
+	PromptTokens     int    `json:"promptTokens"`
+	CompletionTokens int    `json:"completionTokens"`
+	TotalTokens      int    `json:"totalTokens"`
+	Success          *bool  `json:"success"`
+	CreateAt         int64  `json:"createAt"`
+}
+
+func (a AiUsage) TableName() string {
+	return "w8t_ai_usage"
+}
+
+// AiUsageStat Token 用量统计
+type AiUsageStat struct {
+	Profile          string `json:"profile"`
+	Provider         string `json:"provider"`
+	Model            string `json:"model"`
+	Requests         int64  `json:"requests"`
+	FailedRequests   int64  `json:"failedRequests"`
+	PromptTokens     int64  `json:"promptTokens"`
+	CompletionTokens int64  `json:"completionTokens"`
+	TotalTokens      int64  `json:"totalTokens"`
+}
+
+// Ai 查询数据源时的范围限制, 避免模型生成的查询拖垮数据源
+const (
+	AiQueryMaxLookback = 24 * time.Hour
+	AiQueryMaxPoints   = 720
+	AiQueryMinStep     = 15 * time.Second
+)
+
+// AiQueryRange 计算区间查询的回溯时长及步长, 回溯不超过 AiQueryMaxLookback, 数据点不超过 AiQueryMaxPoints
+func AiQueryRange(lookbackMinutes, stepSeconds, defLookbackMinutes, defStepSeconds int) (time.Duration, time.Duration) {
+	lookback := AiQueryLookback(lookbackMinutes, defLookbackMinutes)
+
+	if stepSeconds <= 0 {
+		stepSeconds = defStepSeconds
+	}
+	step := time.Duration(stepSeconds) * time.Second
+	if minStep := lookback / AiQueryMaxPoints; step < minStep {
+		step = minStep.Truncate(time.Second) + time.Second
+	}
+	if step < AiQueryMinStep {
+		step = AiQueryMinStep
+	}
+	if step > lookback {
+		step = lookback
+	}
+
+	return lookback, step
+}
+
+// AiQueryLookback 计算查询的回溯时长, 不超过 AiQueryMaxLookback
+func AiQueryLookback(lookbackMinutes, defLookbackMinutes int) time.Duration {
+	if lookbackMinutes <= 0 {
+		lookbackMinutes = defLookbackMinutes
+	}
+	lookback := time.Duration(lookbackMinutes) * time.Minute
+	if lookback > AiQueryMaxLookback {
+		lookback = AiQueryMaxLookback
+	}
+	return lookback
+}
+
+// ValidateAiSQL 校验模型生成的 SQL, 仅允许单条 SELECT (含 WITH ... SELECT) 查询语句
+func ValidateAiSQL(query string) error {
+	q := strings.TrimSpace(query)
+	q = strings.TrimSpace(strings.TrimSuffix(q, ";"))
+	if q == "" {
+		return fmt.Errorf("SQL 不能为空")
+	}
+	if strings.Contains(q, ";") {
+		return fmt.Errorf("仅允许执行单条 SQL 语句")
+	}
+	if strings.Contains(q, "--") || strings.Contains(q, "/*") || strings.Contains(q, "#") {
+		return fmt.Errorf("SQL 中不允许包含注释")
+	}
+
+	words := strings.FieldsFunc(strings.ToUpper(q), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	})
+	if len(words) == 0 || (words[0] != "SELECT" && words[0] != "WITH") {
+		return fmt.Errorf("仅允许执行 SELECT 查询语句")
+	}
+	for _, w := range words {
+		switch w {
+		case "INSERT", "ALTER", "DROP", "TRUNCATE", "DELETE", "UPDATE", "CREATE", "RENAME", "ATTACH", "DETACH",
+			"GRANT", "REVOKE", "KILL", "OPTIMIZE", "SYSTEM", "OUTFILE", "SETTINGS":
+			return fmt.Errorf("SQL 中不允许包含 %s", w)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAiCachePolicy(t *testing.T) {
+	if ttl := (AiConfig{}).GetCacheTTL(); ttl != 1440*60 {
+		t.Fatalf("expected default ttl of one day, got %d", ttl)
+	}
+	if ttl := (AiConfig{CacheTTL: -1}).GetCacheTTL(); ttl != 0 {
+		t.Fatalf("expected caching disabled, got %d", ttl)
+	}
+
+	record := AiContentRecord{ExpireAt: 100}
+	if record.IsExpired(99) || !record.IsExpired(100) {
+		t.Fatalf("unexpected expiry check")
+	}
+	if (AiContentRecord{}).IsExpired(1 << 40) {
+		t.Fatalf("record without expiry should never expire")
+	}
+
+	if BuildAiContentHash("r1", "fp", "cpu 90%") == BuildAiContentHash("r1", "fp", "cpu 95%") {
+		t.Fatalf("content change should change the hash")
+	}
+
+	enabled := true
+	auto := AiAutoAnalysis{Enable: &enabled, Severities: []string{"P0"}}
+	if !auto.Match("P0") || auto.Match("P2") {
+		t.Fatalf("unexpected severity match")
+	}
+}
+
+func TestAiQueryRange(t *testing.T) {
+	lookback, step := AiQueryRange(0, 0, 60, 60)
+	if lookback != time.Hour || step != time.Minute {
+		t.Fatalf("unexpected defaults: %s %s", lookback, step)
+	}
+
+	lookback, step = AiQueryRange(7*24*60, 1, 60, 60)
+	if lookback != AiQueryMaxLookback {
+		t.Fatalf("lookback should be capped, got %s", lookback)
+	}
+	if points := int(lookback / step); points > AiQueryMaxPoints {
+		t.Fatalf("too many points: %d", points)
+	}
+
+	if _, step = AiQueryRange(10, 3600, 60, 60); step != 10*time.Minute {
+		t.Fatalf("step should not exceed lookback, got %s", step)
+	}
+}
+
+func TestValidateAiSQL(t *testing.T) {
+	allowed := []string{
+		"SELECT count() FROM logs WHERE level = 'error'",
+		"  select * from logs limit 10;",
+		"WITH t AS (SELECT 1) SELECT * FROM t",
+	}
+	for _, q := range allowed {
+		if err := ValidateAiSQL(q); err != nil {
+			t.Fatalf("%q should be allowed: %s", q, err)
+		}
+	}
+
+	rejected := []string{
+		"",
+		"DROP TABLE logs",
+		"SELECT 1; DROP TABLE logs",
+		"INSERT INTO logs SELECT * FROM logs",
+		"SELECT * FROM logs INTO OUTFILE '/tmp/x'",
+		"SELECT * FROM logs SETTINGS readonly=0",
+		"SELECT 1 -- comment",
+		"ALTER TABLE logs DELETE WHERE 1",
+	}
+	for _, q := range rejected {
+		if err := ValidateAiSQL(q); err == nil {
+			t.Fatalf("%q should be rejected", q)
+		}
+	}
+}
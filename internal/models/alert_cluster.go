@@ -0,0 +1,60 @@
+package models
+
+const (
+	// AggregationTypeCluster 按关联聚类聚合告警, 同一聚类只通知一次
+	AggregationTypeCluster = "Cluster"
+	// 默认关联时间窗口, 单位（分钟）
+	DefaultCorrelationWindow = 10
+	// 默认告警详情文本相似度阈值
+	DefaultCorrelationTextSimilarity = 0.6
+)
+
+// DefaultCorrelationLabelKeys 默认参与关联的标签, 不同规则的告警在这些标签上取值相同时视为同一故障
+var DefaultCorrelationLabelKeys = []string{"service", "app", "instance", "pod"}
+
+// CorrelationConfig 告警关联配置
+type CorrelationConfig struct {
+	Enabled        *bool    `json:"enabled"`        // 是否启用告警关联
+	LabelKeys      []string `json:"labelKeys"`      // 参与关联的标签
+	Window         int64    `json:"window"`         // 触发时间相差在窗口内的告警才会关联, 单位（分钟）
+	TextSimilarity float64  `json:"textSimilarity"` // 告警详情文本相似度达到该值时关联, 取值 0-1, 小于 0 时不按文本关联
+}
+
+func (c CorrelationConfig) GetEnabled() bool {
+	if c.Enabled == nil {
+		return false
+	}
+	return *c.Enabled
+}
+
+func (c CorrelationConfig) GetLabelKeys() []string {
+	if len(c.LabelKeys) == 0 {
+		return DefaultCorrelationLabelKeys
+	}
+	return c.LabelKeys
+}
+
+// GetWindow 关联时间窗口, 单位（秒）
+func (c CorrelationConfig) GetWindow() int64 {
+	if c.Window <= 0 {
+		return DefaultCorrelationWindow * 60
+	}
+	return c.Window * 60
+}
+
+func (c CorrelationConfig) GetTextSimilarity() float64 {
+	if c.TextSimilarity == 0 {
+		return DefaultCorrelationTextSimilarity
+	}
+	return c.TextSimilarity
+}
+
+// EventCluster 关联到同一故障的告警聚类
+type EventCluster struct {
+	ClusterId        string            `json:"clusterId"`        // 聚类中最早触发的告警指纹
+	Severity         string            `json:"severity"`         // 聚类中最高的告警等级
+	FirstTriggerTime int64             `json:"firstTriggerTime"` // 聚类中最早的触发时间
+	RuleNames        []string          `json:"ruleNames"`
+	SharedLabels     map[string]string `json:"sharedLabels"` // 关联依据的标签
+	Events           []AlertCurEvent   `json:"events"`
+}
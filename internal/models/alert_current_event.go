@@ -47,8 +47,16 @@ type AlertCurEvent struct {
 	FaultCenterId          string                 `json:"faultCenterId"`
 	FaultCenter            FaultCenter            `json:"faultCenter" gorm:"-"`
 	ConfirmState           ConfirmState           `json:"confirmState" gorm:"-"`
-	Status                 AlertStatus            `json:"status" gorm:"-"`      // 事件状态
-	SilenceInfo            *SilenceInfo           `json:"silenceInfo" gorm:"-"` // 静默信息
+	Status                 AlertStatus            `json:"status" gorm:"-"`        // 事件状态
+	SilenceInfo            *SilenceInfo           `json:"silenceInfo" gorm:"-"`   // 静默信息
+	Flapping               bool                   `json:"flapping" gorm:"-"`      // 是否处于抖动中
+	FlapScore              float64                `json:"flapScore" gorm:"-"`     // 抖动分值(%)
+	FirstValue             interface{}            `json:"firstValue" gorm:"-"`    // 首次触发时的值
+	AiAnalysis             string                 `json:"aiAnalysis" gorm:"-"`    // 通知时附带的 Ai 分析结果
+	GraphUrl               string                 `json:"graphUrl" gorm:"-"`      // 通知时附带的趋势图地址
+	ClusterId              string                 `json:"clusterId" gorm:"-"`     // 关联聚类 ID
+	ClusterLeader          string                 `json:"clusterLeader" gorm:"-"` // 按聚类聚合通知时, 代为发送通知的告警指纹
+	ClusterNote            string                 `json:"clusterNote" gorm:"-"`   // 按聚类聚合通知时, 通知附带的关联告警概要
 }
 
 // SilenceInfo 静默信息
@@ -184,6 +192,14 @@ func (alert *AlertCurEvent) GetFirstTime() int64 {
 	return alert.FirstTriggerTime
 }
 
+// GetFirstValue 获取首次触发时的值, 新事件使用当前值
+func (alert *AlertCurEvent) GetFirstValue(current interface{}) interface{} {
+	if alert.FirstValue == nil {
+		return current
+	}
+	return alert.FirstValue
+}
+
 // GetLastConfirmState 获取最新告警升级认领状态
 func (alert *AlertCurEvent) GetLastConfirmState() ConfirmState {
 	return alert.ConfirmState
@@ -0,0 +1,126 @@
+package models
+
+import "fmt"
+
+const (
+	// FlapMaxStateChanges 参考 Nagios, 保留 21 次状态, 即最多 20 次状态变化参与抖动分值计算
+	FlapMaxStateChanges = 20
+	// 默认统计窗口, 单位（分钟）
+	DefaultFlapWindow = 60
+	// 默认开始抖动阈值(%)
+	DefaultFlapHighThreshold = 50
+	// 默认停止抖动阈值(%)
+	DefaultFlapLowThreshold = 25
+)
+
+// FlapDetection 告警抖动检测配置
+type FlapDetection struct {
+	Enabled       *bool   `json:"enabled"`       // 是否启用抖动检测
+	Window        int64   `json:"window"`        // 统计窗口，单位（分钟）
+	HighThreshold float64 `json:"highThreshold"` // 抖动分值超过该值时标记为抖动(%)
+	LowThreshold  float64 `json:"lowThreshold"`  // 抖动分值低于该值时解除抖动(%)
+}
+
+func (f FlapDetection) GetEnabled() bool {
+	if f.Enabled == nil {
+		return false
+	}
+	return *f.Enabled
+}
+
+func (f FlapDetection) GetWindow() int64 {
+	if f.Window <= 0 {
+		return DefaultFlapWindow
+	}
+	return f.Window
+}
+
+func (f FlapDetection) GetHighThreshold() float64 {
+	if f.HighThreshold <= 0 {
+		return DefaultFlapHighThreshold
+	}
+	return f.HighThreshold
+}
+
+func (f FlapDetection) GetLowThreshold() float64 {
+	if f.LowThreshold <= 0 || f.LowThreshold > f.GetHighThreshold() {
+		return DefaultFlapLowThreshold
+	}
+	return f.LowThreshold
+}
+
+// FlapState 告警抖动状态, 按事件指纹记录在故障中心下
+type FlapState struct {
+	TenantId      string        `json:"tenantId"`
+	FaultCenterId string        `json:"faultCenterId"`
+	Fingerprint   string        `json:"fingerprint"`
+	RuleName      string        `json:"ruleName"`
+	LastState     AlertStatus   `json:"lastState"` // 最近一次记录的状态, alerting 或 recovered
+	Changes       []int64       `json:"changes"`   // 窗口内状态变化的时间
+	Score         float64       `json:"score"`     // 抖动分值(%)
+	Flapping      bool          `json:"flapping"`  // 是否处于抖动中
+	Since         int64         `json:"since"`     // 开始抖动的时间
+	Notified      bool          `json:"notified"`  // 最近一次抖动开始/停止的通知是否已发送
+	Event         AlertCurEvent `json:"event"`     // 最近一次状态变化时的事件快照, 用于发送抖动通知
+}
+
+// RecordChange 记录一次状态变化, 状态未变化时仅刷新分值
+func (f *FlapState) RecordChange(state AlertStatus, now int64, cfg FlapDetection) {
+	if f.LastState != "" && f.LastState != state {
+		f.Changes = append(f.Changes, now)
+	}
+	f.LastState = state
+	f.Evaluate(now, cfg)
+}
+
+// Evaluate 重新计算抖动分值并更新抖动状态, 返回抖动状态是否发生了变化
+// 分值计算参考 Nagios: 窗口内越新的状态变化权重越高(0.8 ~ 1.2), 分值 = 加权变化次数 / 最大变化次数
+func (f *FlapState) Evaluate(now int64, cfg FlapDetection) bool {
+	window := cfg.GetWindow() * 60
+	windowStart := now - window
+
+	var changes []int64
+	for _, t := range f.Changes {
+		if t > windowStart {
+			changes = append(changes, t)
+		}
+	}
+	if len(changes) > FlapMaxStateChanges {
+		changes = changes[len(changes)-FlapMaxStateChanges:]
+	}
+	f.Changes = changes
+
+	var weighted float64
+	for _, t := range changes {
+		weighted += 0.8 + 0.4*float64(t-windowStart)/float64(window)
+	}
+	f.Score = weighted / FlapMaxStateChanges * 100
+	if f.Score > 100 {
+		f.Score = 100
+	}
+
+	switch {
+	case !f.Flapping && f.Score >= cfg.GetHighThreshold():
+		f.Flapping = true
+		f.Since = now
+		f.Notified = false
+		return true
+	case f.Flapping && f.Score < cfg.GetLowThreshold():
+		f.Flapping = false
+		f.Notified = false
+		return true
+	}
+
+	return false
+}
+
+// IsExpired 窗口内没有任何状态变化且不处于抖动中时, 状态可以清理
+func (f *FlapState) IsExpired() bool {
+	return !f.Flapping && f.Notified && len(f.Changes) == 0
+}
+
+type AlertFlapCacheKey string
+
+func BuildAlertFlapCacheKey(tenantId, faultCenterId string) AlertFlapCacheKey {
+	return AlertFlapCacheKey(fmt.Sprintf("w8t:%s:%s:%s.flapping", tenantId, FaultCenterPrefix, faultCenterId))
+}
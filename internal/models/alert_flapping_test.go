@@ -0,0 +1,32 @@
+package models
+
+import "testing"
+
+func TestFlapStateHysteresis(t *testing.T) {
+	enabled := true
+	cfg := FlapDetection{Enabled: &enabled, Window: 60, HighThreshold: 50, LowThreshold: 25}
+
+	var (
+		state FlapState
+		now   int64 = 1700000000
+	)
+	states := []AlertStatus{StateAlerting, StateRecovered}
+	for i := 0; i < 12; i++ {
+		state.RecordChange(states[i%2], now, cfg)
+		now += 60
+	}
+	if !state.Flapping {
+		t.Fatalf("expected flapping after 11 changes, score %.2f", state.Score)
+	}
+
+	// 分值低于开始阈值但高于停止阈值时保持抖动
+	state.Evaluate(now+40*60, cfg)
+	if !state.Flapping || state.Score >= cfg.GetHighThreshold() {
+		t.Fatalf("expected to stay flapping between thresholds, score %.2f", state.Score)
+	}
+
+	state.Evaluate(now+2*60*60, cfg)
+	if state.Flapping || state.Score != 0 {
+		t.Fatalf("expected flapping to stop once window is empty, score %.2f", state.Score)
+	}
+}
@@ -0,0 +1,282 @@
+package models
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// 值班统计的周期
+	OnCallPeriodDay   = "day"
+	OnCallPeriodWeek  = "week"
+	OnCallPeriodMonth = "month"
+
+	// 值班统计排行榜的排序字段
+	OnCallSortPages       = "pages"
+	OnCallSortNightPages  = "nightPages"
+	OnCallSortOffDayPages = "offDayPages"
+	OnCallSortMTTA        = "mtta"
+	OnCallSortOnCall      = "onCall"
+
+	// 夜间时段, 按值班组时区计算, 22:00 至次日 08:00
+	OnCallNightStartHour = 22
+	OnCallNightEndHour   = 8
+
+	// OnCallAnalyticsMaxRange 值班统计的最大查询范围, 单位（天）
+	OnCallAnalyticsMaxRange = 92
+)
+
+// 非告警事件的通知记录, 如故障通报、值班交接, 不计入值班通知
+var nonAlertNoticePrefixes = []string{"inc-", "dh-"}
+
+// DutyPage 告警通知按发送时刻的当班人员归属后的明细, 一条通知记录按当班人员拆分为多条
+type DutyPage struct {
+	TenantId string `json:"tenantId"`
+	DutyId   string `json:"dutyId"`
+	NoticeId string `json:"noticeId"`
+	EventId  string `json:"eventId"`
+	RuleName string `json:"ruleName"`
+	Severity string `json:"severity"`
+	UserId   string `json:"userId"`
+	Username string `json:"username"`
+	Layer    string `json:"layer"`
+	Level    int    `json:"level"`
+	At       int64  `json:"at"`     // 通知时间
+	Night    bool   `json:"night"`  // 夜间通知
+	OffDay   bool   `json:"offDay"` // 周末或节假日通知
+}
+
+func (d *DutyPage) TableName() string {
+	return "w8t_duty_page"
+}
+
+// IsAlertNoticeRecord 通知记录是否为告警事件通知
+func IsAlertNoticeRecord(record NoticeRecord) bool {
+	for _, prefix := range nonAlertNoticePrefixes {
+		if strings.HasPrefix(record.EventId, prefix) {
+			return false
+		}
+	}
+	return record.EventId != ""
+}
+
+// NoticeIdFromRecord 从通知记录的通知对象 "名称 (ID)" 中解析通知对象 ID
+func NoticeIdFromRecord(record NoticeRecord) string {
+	start, end := strings.LastIndex(record.NObj, "("), strings.LastIndex(record.NObj, ")")
+	if start < 0 || end <= start {
+		return ""
+	}
+	return record.NObj[start+1 : end]
+}
+
+// IsOnCallNight 是否为夜间时段, t 需为值班组时区的时间
+func IsOnCallNight(t time.Time) bool {
+	return t.Hour() >= OnCallNightStartHour || t.Hour() < OnCallNightEndHour
+}
+
+// OnCallAck 告警事件的触发及认领时间, 用于计算 MTTA
+type OnCallAck struct {
+	FirstTriggerTime int64
+	AckAt            int64
+}
+
+// OnCallUserStats 值班人员在统计周期内的负载
+type OnCallUserStats struct {
+	UserId        string  `json:"userId"`
+	Username      string  `json:"username"`
+	Pages         int     `json:"pages"`         // 通知次数
+	Events        int     `json:"events"`        // 通知的告警事件数（去重）
+	NightPages    int     `json:"nightPages"`    // 夜间通知次数
+	OffDayPages   int     `json:"offDayPages"`   // 周末及节假日通知次数
+	Acked         int     `json:"acked"`         // 已认领的告警事件数
+	MTTA          float64 `json:"mtta"`          // 平均认领耗时, 单位（秒）
+	OnCallSeconds int64   `json:"onCallSeconds"` // 当班时长, 单位（秒）
+}
+
+// OnCallPeriodStats 单个统计周期的值班负载, 用于趋势图
+type OnCallPeriodStats struct {
+	Period  string            `json:"period"` // day、week 为周期首日 2006-01-02, month 为 2006-01
+	StartAt int64             `json:"startAt"`
+	EndAt   int64             `json:"endAt"`
+	Users   []OnCallUserStats `json:"users"`
+}
+
+// OnCallAnalytics 值班负载统计结果
+type OnCallAnalytics struct {
+	StartAt     int64               `json:"startAt"`
+	EndAt       int64               `json:"endAt"`
+	Period      string              `json:"period"`
+	TimeZone    string              `json:"timeZone"`
+	Leaderboard []OnCallUserStats   `json:"leaderboard"`
+	Trend       []OnCallPeriodStats `json:"trend"`
+}
+
+// BuildOnCallAnalytics 汇总 start 到 end 期间的值班负载, 按 period 在 loc 时区下划分趋势周期
+// acks 为告警事件 ID 对应的认领信息, shifts 为同期的当班记录
+func BuildOnCallAnalytics(pages []DutyPage, acks map[string]OnCallAck, shifts []DutyOnCallSegment, start, end time.Time, period string, loc *time.Location, sortBy string) OnCallAnalytics {
+	result := OnCallAnalytics{
+		StartAt:  start.Unix(),
+		EndAt:    end.Unix(),
+		Period:   period,
+		TimeZone: loc.String(),
+	}
+
+	total := newOnCallAccumulator()
+	for _, bucket := range onCallPeriods(start, end, period, loc) {
+		acc := newOnCallAccumulator()
+		for _, page := range pages {
+			if page.At >= bucket.StartAt && page.At < bucket.EndAt {
+				ack, ok := acks[page.EventId]
+				acc.addPage(page, ack, ok)
+				total.addPage(page, ack, ok)
+			}
+		}
+		for _, shift := range shifts {
+			seconds := overlapSeconds(shift.StartAt, shift.EndAt, bucket.StartAt, bucket.EndAt)
+			if seconds <= 0 {
+				continue
+			}
+			for _, user := range shift.Users {
+				acc.addOnCall(user, seconds)
+				total.addOnCall(user, seconds)
+			}
+		}
+
+		bucket.Users = acc.result(sortBy)
+		result.Trend = append(result.Trend, bucket)
+	}
+	result.Leaderboard = total.result(sortBy)
+
+	return result
+}
+
+// onCallPeriods 按周期划分时间范围, 首尾周期截取到 start、end
+func onCallPeriods(start, end time.Time, period string, loc *time.Location) []OnCallPeriodStats {
+	var (
+		result []OnCallPeriodStats
+		local  = start.In(loc)
+		cursor = time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+		label  = "2006-01-02"
+	)
+	switch period {
+	case OnCallPeriodWeek:
+		// 以周一为每周首日
+		cursor = cursor.AddDate(0, 0, -(int(cursor.Weekday())+6)%7)
+	case OnCallPeriodMonth:
+		cursor = time.Date(local.Year(), local.Month(), 1, 0, 0, 0, 0, loc)
+		label = "2006-01"
+	}
+
+	for cursor.Before(end) {
+		next := cursor.AddDate(0, 0, 1)
+		switch period {
+		case OnCallPeriodWeek:
+			next = cursor.AddDate(0, 0, 7)
+		case OnCallPeriodMonth:
+			next = cursor.AddDate(0, 1, 0)
+		}
+
+		bucket := OnCallPeriodStats{Period: cursor.Format(label), StartAt: cursor.Unix(), EndAt: next.Unix()}
+		if bucket.StartAt < start.Unix() {
+			bucket.StartAt = start.Unix()
+		}
+		if bucket.EndAt > end.Unix() {
+			bucket.EndAt = end.Unix()
+		}
+		result = append(result, bucket)
+		cursor = next
+	}
+	return result
+}
+
+type onCallAccumulator struct {
+	users    map[string]*OnCallUserStats
+	events   map[string]map[string]struct{}
+	ackTotal map[string]int64
+}
+
+func newOnCallAccumulator() *onCallAccumulator {
+	return &onCallAccumulator{
+		users:    make(map[string]*OnCallUserStats),
+		events:   make(map[string]map[string]struct{}),
+		ackTotal: make(map[string]int64),
+	}
+}
+
+func (a *onCallAccumulator) user(userId, username string) *OnCallUserStats {
+	stats, ok := a.users[userId]
+	if !ok {
+		stats = &OnCallUserStats{UserId: userId, Username: username}
+		a.users[userId] = stats
+		a.events[userId] = make(map[string]struct{})
+	}
+	return stats
+}
+
+// addPage 记录一次通知, 同一告警事件的多次通知只计一次认领耗时
+func (a *onCallAccumulator) addPage(page DutyPage, ack OnCallAck, acked bool) {
+	stats := a.user(page.UserId, page.Username)
+	stats.Pages++
+	if page.Night {
+		stats.NightPages++
+	}
+	if page.OffDay {
+		stats.OffDayPages++
+	}
+
+	if _, ok := a.events[page.UserId][page.EventId]; ok {
+		return
+	}
+	a.events[page.UserId][page.EventId] = struct{}{}
+	stats.Events++
+	if acked && ack.FirstTriggerTime > 0 && ack.AckAt >= ack.FirstTriggerTime {
+		stats.Acked++
+		a.ackTotal[page.UserId] += ack.AckAt - ack.FirstTriggerTime
+	}
+}
+
+func (a *onCallAccumulator) addOnCall(user DutyUser, seconds int64) {
+	a.user(user.UserId, user.Username).OnCallSeconds += seconds
+}
+
+func (a *onCallAccumulator) result(sortBy string) []OnCallUserStats {
+	result := make([]OnCallUserStats, 0, len(a.users))
+	for userId, stats := range a.users {
+		if stats.Acked > 0 {
+			stats.MTTA = float64(a.ackTotal[userId]) / float64(stats.Acked)
+		}
+		result = append(result, *stats)
+	}
+
+	key := func(s OnCallUserStats) float64 {
+		switch sortBy {
+		case OnCallSortNightPages:
+			return float64(s.NightPages)
+		case OnCallSortOffDayPages:
+			return float64(s.OffDayPages)
+		case OnCallSortMTTA:
+			return s.MTTA
+		case OnCallSortOnCall:
+			return float64(s.OnCallSeconds)
+		}
+		return float64(s.Pages)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if ki, kj := key(result[i]), key(result[j]); ki != kj {
+			return ki > kj
+		}
+		return result[i].UserId < result[j].UserId
+	})
+	return result
+}
+
+func overlapSeconds(start, end, windowStart, windowEnd int64) int64 {
+	if start < windowStart {
+		start = windowStart
+	}
+	if end > windowEnd {
+		end = windowEnd
+	}
+	return end - start
+}
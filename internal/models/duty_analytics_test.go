@@ -0,0 +1,65 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBuildOnCallAnalytics(t *testing.T) {
+	// 2025-01-03 为周五
+	start := time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 2)
+	pages := []DutyPage{
+		{EventId: "e1", UserId: "alice", Username: "alice", At: start.Add(10 * time.Hour).Unix()},
+		{EventId: "e1", UserId: "alice", Username: "alice", At: start.Add(11 * time.Hour).Unix()},
+		{EventId: "e2", UserId: "alice", Username: "alice", At: start.Add(23 * time.Hour).Unix(), Night: true},
+		{EventId: "e3", UserId: "bob", Username: "bob", At: start.Add(30 * time.Hour).Unix(), OffDay: true},
+	}
+	acks := map[string]OnCallAck{
+		"e1": {FirstTriggerTime: start.Add(10 * time.Hour).Unix(), AckAt: start.Add(10*time.Hour + 2*time.Minute).Unix()},
+		"e2": {FirstTriggerTime: start.Add(23 * time.Hour).Unix(), AckAt: start.Add(23*time.Hour + 4*time.Minute).Unix()},
+	}
+	shifts := []DutyOnCallSegment{
+		{Users: dutyUsers("alice"), StartAt: start.Unix(), EndAt: start.Add(24 * time.Hour).Unix()},
+		{Users: dutyUsers("bob"), StartAt: start.Add(24 * time.Hour).Unix(), EndAt: end.Add(24 * time.Hour).Unix()},
+	}
+
+	result := BuildOnCallAnalytics(pages, acks, shifts, start, end, OnCallPeriodDay, time.UTC, "")
+	if len(result.Leaderboard) != 2 || len(result.Trend) != 2 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+
+	alice := result.Leaderboard[0]
+	if alice.UserId != "alice" || alice.Pages != 3 || alice.Events != 2 || alice.NightPages != 1 || alice.Acked != 2 || alice.MTTA != 180 || alice.OnCallSeconds != 24*3600 {
+		t.Fatalf("unexpected stats for alice: %+v", alice)
+	}
+	// 当班时长截取到查询范围
+	bob := result.Leaderboard[1]
+	if bob.Pages != 1 || bob.OffDayPages != 1 || bob.Acked != 0 || bob.OnCallSeconds != 24*3600 {
+		t.Fatalf("unexpected stats for bob: %+v", bob)
+	}
+	if result.Trend[1].Period != "2025-01-04" || len(result.Trend[1].Users) != 1 || result.Trend[1].Users[0].UserId != "bob" {
+		t.Fatalf("unexpected trend: %+v", result.Trend)
+	}
+
+	weekly := BuildOnCallAnalytics(pages, acks, shifts, start, end, OnCallPeriodWeek, time.UTC, OnCallSortOffDayPages)
+	if len(weekly.Trend) != 1 || weekly.Trend[0].Period != "2024-12-30" || weekly.Trend[0].StartAt != start.Unix() {
+		t.Fatalf("unexpected weekly trend: %+v", weekly.Trend)
+	}
+	if weekly.Leaderboard[0].UserId != "bob" {
+		t.Fatalf("expected leaderboard sorted by off-day pages, got %+v", weekly.Leaderboard)
+	}
+}
+
+func TestNoticeRecordAttribution(t *testing.T) {
+	record := NoticeRecord{EventId: "abc", NObj: "运维 (值班) (n-123)"}
+	if NoticeIdFromRecord(record) != "n-123" || !IsAlertNoticeRecord(record) {
+		t.Fatalf("unexpected attribution for %+v", record)
+	}
+	if IsAlertNoticeRecord(NoticeRecord{EventId: "dh-1"}) || IsAlertNoticeRecord(NoticeRecord{EventId: "inc-1"}) {
+		t.Fatal("expected handoff and incident notices to be excluded")
+	}
+	if IsOnCallNight(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)) || !IsOnCallNight(time.Date(2025, 1, 1, 7, 59, 0, 0, time.UTC)) {
+		t.Fatal("unexpected night hours")
+	}
+}
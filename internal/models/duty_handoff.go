@@ -0,0 +1,218 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// 交接报告的来源
+	HandoffSourceAuto   = "auto"   // 班次边界自动生成
+	HandoffSourceManual = "manual" // 手动生成
+
+	// 交接报告文本中每类事项最多展示的条数, 完整内容可在交接记录中查看
+	handoffTextMaxItems = 10
+)
+
+// DutyHandoff 值班交接报告, 在主值班人员变化的班次边界生成, 汇总交出班次的告警情况并通知交接双方
+type DutyHandoff struct {
+	TenantId      string            `json:"tenantId"`
+	ID            string            `json:"id"`
+	DutyId        string            `json:"dutyId"`
+	DutyName      string            `json:"dutyName"`
+	Source        string            `json:"source"`
+	OutgoingUsers []DutyUser        `json:"outgoingUsers" gorm:"column:outgoingUsers;serializer:json"`
+	IncomingUsers []DutyUser        `json:"incomingUsers" gorm:"column:incomingUsers;serializer:json"`
+	ShiftStart    int64             `json:"shiftStart"`   // 交出班次开始时间
+	HandoffAt     int64             `json:"handoffAt"`    // 交接时间, 即接班班次开始时间
+	NextShiftEnd  int64             `json:"nextShiftEnd"` // 接班班次结束时间
+	Report        DutyHandoffReport `json:"report" gorm:"column:report;serializer:json"`
+	NoticeIds     []string          `json:"noticeIds" gorm:"column:noticeIds;serializer:json"`
+	SendError     string            `json:"sendError"`
+	CreateBy      string            `json:"createBy"`
+	CreateAt      int64             `json:"createAt"`
+}
+
+func (d *DutyHandoff) TableName() string {
+	return "w8t_duty_handoff"
+}
+
+// DutyHandoffReport 交接报告内容, 按故障中心分组
+type DutyHandoffReport struct {
+	FaultCenters []DutyHandoffSection `json:"faultCenters"`
+}
+
+// DutyHandoffSection 单个故障中心的交接事项
+type DutyHandoffSection struct {
+	FaultCenterId    string               `json:"faultCenterId"`
+	FaultCenterName  string               `json:"faultCenterName"`
+	Open             []DutyHandoffEvent   `json:"open"`             // 未认领的告警
+	Acknowledged     []DutyHandoffEvent   `json:"acknowledged"`     // 已认领未恢复的告警
+	Fired            []DutyHandoffEvent   `json:"fired"`            // 交出班次内触发的告警, 包含已恢复的告警
+	ExpiringSilences []DutyHandoffSilence `json:"expiringSilences"` // 接班班次内到期的静默规则
+	Comments         []DutyHandoffComment `json:"comments"`         // 未恢复告警的评论
+}
+
+type DutyHandoffEvent struct {
+	Fingerprint      string `json:"fingerprint"`
+	RuleName         string `json:"ruleName"`
+	Severity         string `json:"severity"`
+	Status           string `json:"status"`
+	FirstTriggerTime int64  `json:"firstTriggerTime"`
+	RecoverTime      int64  `json:"recoverTime"`
+	ConfirmUsername  string `json:"confirmUsername"`
+}
+
+type DutyHandoffSilence struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Comment string `json:"comment"`
+	EndsAt  int64  `json:"endsAt"`
+}
+
+type DutyHandoffComment struct {
+	Fingerprint string `json:"fingerprint"`
+	RuleName    string `json:"ruleName"`
+	Username    string `json:"username"`
+	Content     string `json:"content"`
+	Time        int64  `json:"time"`
+}
+
+// IsEmpty 故障中心没有需要交接的事项
+func (s DutyHandoffSection) IsEmpty() bool {
+	return len(s.Open) == 0 && len(s.Acknowledged) == 0 && len(s.Fired) == 0 &&
+		len(s.ExpiringSilences) == 0 && len(s.Comments) == 0
+}
+
+// HandoffOnCall 获取主值班层级的当班人员（按用户去重）及其所在班次的时间范围, 多个轮值层时取最早的开始及结束时间
+func HandoffOnCall(onCall []DutyOnCall) ([]DutyUser, int64, int64) {
+	var (
+		users      []DutyUser
+		seen       = make(map[string]struct{})
+		start, end int64
+	)
+	for _, o := range onCall {
+		if o.Level != DutyLevelPrimary {
+			continue
+		}
+		for _, user := range o.Users {
+			if _, ok := seen[user.UserId]; ok {
+				continue
+			}
+			seen[user.UserId] = struct{}{}
+			users = append(users, user)
+		}
+		if start == 0 || o.ShiftStart < start {
+			start = o.ShiftStart
+		}
+		if end == 0 || o.ShiftEnd < end {
+			end = o.ShiftEnd
+		}
+	}
+
+	sort.Slice(users, func(i, j int) bool { return users[i].UserId < users[j].UserId })
+	return users, start, end
+}
+
+// SameDutyUsers 两组值班人员是否相同, 忽略顺序
+func SameDutyUsers(a, b []DutyUser) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	ids := make(map[string]struct{}, len(a))
+	for _, user := range a {
+		ids[user.UserId] = struct{}{}
+	}
+	for _, user := range b {
+		if _, ok := ids[user.UserId]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// Text 生成交接报告的通知文本, 时间按 loc 展示
+func (d DutyHandoff) Text(loc *time.Location) string {
+	var (
+		layout = "2006-01-02 15:04"
+		format = func(ts int64) string {
+			if ts <= 0 {
+				return "-"
+			}
+			return time.Unix(ts, 0).In(loc).Format(layout)
+		}
+		lines = []string{
+			fmt.Sprintf("值班组: %s", d.DutyName),
+			fmt.Sprintf("交接时间: %s (%s)", format(d.HandoffAt), loc.String()),
+			fmt.Sprintf("交班人员: %s, 班次 %s ~ %s", dutyUserNames(d.OutgoingUsers), format(d.ShiftStart), format(d.HandoffAt)),
+			fmt.Sprintf("接班人员: %s, 班次 %s ~ %s", dutyUserNames(d.IncomingUsers), format(d.HandoffAt), format(d.NextShiftEnd)),
+		}
+	)
+
+	var empty = true
+	for _, section := range d.Report.FaultCenters {
+		if section.IsEmpty() {
+			continue
+		}
+		empty = false
+		lines = append(lines, "", fmt.Sprintf("【%s】", section.FaultCenterName))
+
+		lines = appendHandoffItems(lines, "未认领告警", len(section.Open), func(i int) string {
+			e := section.Open[i]
+			return fmt.Sprintf("[%s] %s, 触发于 %s", e.Severity, e.RuleName, format(e.FirstTriggerTime))
+		})
+		lines = appendHandoffItems(lines, "已认领告警", len(section.Acknowledged), func(i int) string {
+			e := section.Acknowledged[i]
+			return fmt.Sprintf("[%s] %s, 认领人 %s", e.Severity, e.RuleName, e.ConfirmUsername)
+		})
+		lines = appendHandoffItems(lines, "本班次触发", len(section.Fired), func(i int) string {
+			e := section.Fired[i]
+			text := fmt.Sprintf("[%s] %s, 触发于 %s", e.Severity, e.RuleName, format(e.FirstTriggerTime))
+			if e.RecoverTime > 0 {
+				text += ", 恢复于 " + format(e.RecoverTime)
+			}
+			return text
+		})
+		lines = appendHandoffItems(lines, "接班期间到期的静默", len(section.ExpiringSilences), func(i int) string {
+			s := section.ExpiringSilences[i]
+			return fmt.Sprintf("%s, 到期于 %s", s.Name, format(s.EndsAt))
+		})
+		lines = appendHandoffItems(lines, "未恢复告警的评论", len(section.Comments), func(i int) string {
+			c := section.Comments[i]
+			return fmt.Sprintf("%s / %s: %s", c.RuleName, c.Username, c.Content)
+		})
+	}
+	if empty {
+		lines = append(lines, "", "无待交接事项")
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func appendHandoffItems(lines []string, title string, total int, item func(i int) string) []string {
+	if total == 0 {
+		return lines
+	}
+
+	lines = append(lines, fmt.Sprintf("%s (%d):", title, total))
+	for i := 0; i < total && i < handoffTextMaxItems; i++ {
+		lines = append(lines, "  - "+item(i))
+	}
+	if total > handoffTextMaxItems {
+		lines = append(lines, fmt.Sprintf("  ... 其余 %d 条请在交接记录中查看", total-handoffTextMaxItems))
+	}
+	return lines
+}
+
+func dutyUserNames(users []DutyUser) string {
+	if len(users) == 0 {
+		return "无"
+	}
+	names := make([]string, 0, len(users))
+	for _, user := range users {
+		names = append(names, user.Username)
+	}
+	return strings.Join(names, ", ")
+}
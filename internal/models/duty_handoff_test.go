@@ -0,0 +1,77 @@
+package models
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHandoffOnCall(t *testing.T) {
+	base := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	timeline := DutyTimeline{
+		Duty: DutyManagement{
+			TimeZone: "UTC",
+			Layers: []DutyRotationLayer{
+				{Name: "primary", Level: DutyLevelPrimary, StartAt: "2025-01-01 09:00", ShiftLength: 12 * 60, UserGroups: [][]DutyUser{dutyUsers("alice"), dutyUsers("bob")}},
+				{Name: "secondary", Level: DutyLevelSecondary, StartAt: "2025-01-01 09:00", ShiftLength: 60, UserGroups: [][]DutyUser{dutyUsers("lead"), dutyUsers("manager")}},
+			},
+		},
+	}
+
+	// 备份值班的轮换不视为交接
+	prev, _, _ := HandoffOnCall(timeline.At(base.Add(time.Hour - time.Minute)))
+	cur, _, _ := HandoffOnCall(timeline.At(base.Add(time.Hour)))
+	if !SameDutyUsers(prev, cur) {
+		t.Fatalf("expected no handoff on secondary rotation, got %v -> %v", prev, cur)
+	}
+
+	prev, start, _ := HandoffOnCall(timeline.At(base.Add(12*time.Hour - time.Minute)))
+	cur, _, end := HandoffOnCall(timeline.At(base.Add(12 * time.Hour)))
+	if SameDutyUsers(prev, cur) || prev[0].UserId != "alice" || cur[0].UserId != "bob" {
+		t.Fatalf("expected handoff from alice to bob, got %v -> %v", prev, cur)
+	}
+	if start != base.Unix() || end != base.Add(24*time.Hour).Unix() {
+		t.Fatalf("unexpected shift range: %d - %d", start, end)
+	}
+}
+
+func TestDutyHandoffText(t *testing.T) {
+	base := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	handoff := DutyHandoff{
+		DutyName:      "基础设施",
+		OutgoingUsers: []DutyUser{{UserId: "alice", Username: "alice"}},
+		IncomingUsers: []DutyUser{{UserId: "bob", Username: "bob"}},
+		ShiftStart:    base.Unix(),
+		HandoffAt:     base.Add(12 * time.Hour).Unix(),
+		NextShiftEnd:  base.Add(24 * time.Hour).Unix(),
+		Report: DutyHandoffReport{FaultCenters: []DutyHandoffSection{
+			{FaultCenterName: "空故障中心"},
+			{
+				FaultCenterName:  "生产环境",
+				Open:             []DutyHandoffEvent{{RuleName: "CPU 使用率过高", Severity: "P1", FirstTriggerTime: base.Add(time.Hour).Unix()}},
+				ExpiringSilences: []DutyHandoffSilence{{Name: "变更窗口", EndsAt: base.Add(14 * time.Hour).Unix()}},
+			},
+		}},
+	}
+	for i := 0; i < handoffTextMaxItems+2; i++ {
+		handoff.Report.FaultCenters[1].Fired = append(handoff.Report.FaultCenters[1].Fired, DutyHandoffEvent{RuleName: "磁盘", Severity: "P2"})
+	}
+
+	text := handoff.Text(time.UTC)
+	for _, want := range []string{
+		"交接时间: 2025-01-01 21:00 (UTC)",
+		"交班人员: alice, 班次 2025-01-01 09:00 ~ 2025-01-01 21:00",
+		"【生产环境】",
+		"未认领告警 (1):\n  - [P1] CPU 使用率过高, 触发于 2025-01-01 10:00",
+		"本班次触发 (12):",
+		"其余 2 条",
+		"变更窗口, 到期于 2025-01-01 23:00",
+	} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("expected %q in text:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "空故障中心") || strings.Contains(text, "无待交接事项") {
+		t.Fatalf("unexpected empty section in text:\n%s", text)
+	}
+}
@@ -0,0 +1,89 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+const (
+	// HolidayTypeHoliday 法定节假日
+	HolidayTypeHoliday = "holiday"
+	// HolidayTypeWorkday 调休上班的周末
+	HolidayTypeWorkday = "workday"
+
+	// HolidayModeSkip 非工作日（节假日及未调休的周末）不计入轮换, 由前一个工作日的值班人员继续值班
+	HolidayModeSkip = "skip"
+	// HolidayModeAssign 节假日由节假日值班人员按天轮流值班, 常规轮换不计入这些日期
+	HolidayModeAssign = "assign"
+
+	// HolidayDateLayout 节假日日期格式
+	HolidayDateLayout = "2006-01-02"
+
+	// 日历订阅类型
+	CalendarFeedDuty = "duty" // 订阅值班组的全部班次
+	CalendarFeedUser = "user" // 订阅个人在租户下所有值班组的班次
+)
+
+// DefaultWorkdayKeywords 导入节假日日历时, 事件标题包含这些关键字的日期视为调休上班
+var DefaultWorkdayKeywords = []string{"补班", "上班", "(班)", "（班）"}
+
+// DutyHoliday 租户的节假日日历, 由 ICS 文件导入
+type DutyHoliday struct {
+	TenantId string `json:"tenantId"`
+	Date     string `json:"date"` // 2006-01-02
+	Name     string `json:"name"`
+	Type     string `json:"type"`   // holiday 节假日, workday 调休上班
+	Source   string `json:"source"` // 导入来源, 用于按来源整体替换
+	CreateAt int64  `json:"createAt"`
+}
+
+func (d *DutyHoliday) TableName() string {
+	return "w8t_duty_holiday"
+}
+
+// IsWorkdayEvent 根据事件标题判断是否为调休上班
+func IsWorkdayEvent(summary string, keywords []string) bool {
+	if len(keywords) == 0 {
+		keywords = DefaultWorkdayKeywords
+	}
+	for _, keyword := range keywords {
+		if keyword != "" && strings.Contains(summary, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// HolidayCalendar 节假日日历, key 为 2006-01-02 格式的日期, value 为 holiday 或 workday
+type HolidayCalendar map[string]string
+
+// IsHoliday 是否为法定节假日
+func (h HolidayCalendar) IsHoliday(date time.Time) bool {
+	return h[date.Format(HolidayDateLayout)] == HolidayTypeHoliday
+}
+
+// IsWorkingDay 是否为工作日, 调休上班的周末为工作日, 法定节假日为非工作日
+func (h HolidayCalendar) IsWorkingDay(date time.Time) bool {
+	switch h[date.Format(HolidayDateLayout)] {
+	case HolidayTypeHoliday:
+		return false
+	case HolidayTypeWorkday:
+		return true
+	}
+	return date.Weekday() != time.Saturday && date.Weekday() != time.Sunday
+}
+
+// DutyCalendarFeed 值班日历订阅, 通过 Token 免登录访问 iCalendar 订阅地址
+type DutyCalendarFeed struct {
+	TenantId string `json:"tenantId"`
+	Token    string `json:"token"`
+	Type     string `json:"type"` // duty 值班组, user 个人
+	DutyId   string `json:"dutyId"`
+	UserId   string `json:"userId"` // 订阅人, 个人订阅时只包含该用户的班次
+	CreateBy string `json:"createBy"`
+	CreateAt int64  `json:"createAt"`
+}
+
+func (d *DutyCalendarFeed) TableName() string {
+	return "w8t_duty_calendar_feed"
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHolidayCalendarWorkdaySwap(t *testing.T) {
+	calendar := HolidayCalendar{
+		"2025-10-01": HolidayTypeHoliday,
+		"2025-09-28": HolidayTypeWorkday,
+	}
+
+	cases := map[string]bool{
+		"2025-10-01": false, // 国庆节, 周三
+		"2025-09-28": true,  // 调休上班, 周日
+		"2025-09-27": false, // 普通周六
+		"2025-09-29": true,  // 普通周一
+	}
+	for date, want := range cases {
+		d, _ := time.Parse(HolidayDateLayout, date)
+		if got := calendar.IsWorkingDay(d); got != want {
+			t.Fatalf("%s: expected working day %v, got %v", date, want, got)
+		}
+	}
+	if d, _ := time.Parse(HolidayDateLayout, "2025-09-28"); calendar.IsHoliday(d) {
+		t.Fatal("workday swap should not be a holiday")
+	}
+
+	if !IsWorkdayEvent("国庆节 补班", nil) || IsWorkdayEvent("国庆节、中秋节 假期", nil) || !IsWorkdayEvent("Makeup", []string{"Makeup"}) {
+		t.Fatal("unexpected workday keyword matching")
+	}
+}
@@ -0,0 +1,321 @@
+package models
+
+import (
+	"sort"
+	"time"
+)
+
+const (
+	// DutyCalendarLayer 按天值班表对应的轮值层名称
+	DutyCalendarLayer = "calendar"
+	// DutyOverrideLayer 覆盖值班没有可替换的轮值层时使用的名称
+	DutyOverrideLayer = "override"
+	// DutyHistoryMaxRange 值班历史最大查询范围, 单位（天）
+	DutyHistoryMaxRange = 31
+)
+
+// DutySwapStatus 换班申请状态
+type DutySwapStatus string
+
+const (
+	DutySwapPending  DutySwapStatus = "pending"  // 待对方确认
+	DutySwapAccepted DutySwapStatus = "accepted" // 已接受
+	DutySwapRejected DutySwapStatus = "rejected" // 已拒绝
+	DutySwapCanceled DutySwapStatus = "canceled" // 申请人已撤回
+)
+
+// DutyOverride 覆盖值班, 在 StartAt 到 EndAt 期间由 User 顶替轮值层的当班人员
+type DutyOverride struct {
+	TenantId string   `json:"tenantId"`
+	ID       string   `json:"id"`
+	DutyId   string   `json:"dutyId"`
+	Layer    string   `json:"layer"` // 被覆盖的轮值层, 为空时覆盖主值班
+	User     DutyUser `json:"user" gorm:"column:user;serializer:json"`
+	// 被顶替的当班人员, 为空时顶替轮值层的全部当班人员; 换班生成的覆盖只顶替申请人, 同组的其他人员继续值班
+	ReplaceUserId string `json:"replaceUserId"`
+	StartAt       int64  `json:"startAt"`
+	EndAt         int64  `json:"endAt"`
+	Reason        string `json:"reason"`
+	SwapId        string `json:"swapId"` // 由换班申请生成时关联的申请 ID
+	CreateBy      string `json:"createBy"`
+	CreateAt      int64  `json:"createAt"`
+	CanceledBy    string `json:"canceledBy"`
+	CanceledAt    int64  `json:"canceledAt"` // 取消后保留记录用于审计, 取消前的时段仍视为已生效
+}
+
+func (d *DutyOverride) TableName() string {
+	return "w8t_duty_override"
+}
+
+// ActiveAt at 时刻覆盖值班是否生效
+func (d DutyOverride) ActiveAt(at int64) bool {
+	if at < d.StartAt || at >= d.EndAt {
+		return false
+	}
+	return d.CanceledAt == 0 || at < d.CanceledAt
+}
+
+// DutySwap 换班申请, 申请人的班次由对方顶替, 配置 CounterStartAt/CounterEndAt 时申请人同时顶替对方的班次
+type DutySwap struct {
+	TenantId       string         `json:"tenantId"`
+	ID             string         `json:"id"`
+	DutyId         string         `json:"dutyId"`
+	Layer          string         `json:"layer"`
+	Requester      DutyUser       `json:"requester" gorm:"column:requester;serializer:json"`
+	Counterpart    DutyUser       `json:"counterpart" gorm:"column:counterpart;serializer:json"`
+	StartAt        int64          `json:"startAt"`
+	EndAt          int64          `json:"endAt"`
+	CounterStartAt int64          `json:"counterStartAt"`
+	CounterEndAt   int64          `json:"counterEndAt"`
+	Reason         string         `json:"reason"`
+	Status         DutySwapStatus `json:"status"`
+	OverrideIds    []string       `json:"overrideIds" gorm:"column:overrideIds;serializer:json"`
+	CreateAt       int64          `json:"createAt"`
+	UpdateAt       int64          `json:"updateAt"`
+}
+
+func (d *DutySwap) TableName() string {
+	return "w8t_duty_swap"
+}
+
+// DutyRevision 值班组轮值配置的历史版本, 用于回溯任意时刻的值班人员
+type DutyRevision struct {
+	TenantId    string              `json:"tenantId"`
+	DutyId      string              `json:"dutyId"`
+	TimeZone    string              `json:"timeZone"`
+	Layers      []DutyRotationLayer `json:"layers" gorm:"column:layers;serializer:json"`
+	EffectiveAt int64               `json:"effectiveAt"`
+	UpdateBy    string              `json:"updateBy"`
+}
+
+func (d *DutyRevision) TableName() string {
+	return "w8t_duty_revision"
+}
+
+// DutyOnCallSegment 值班历史中一段连续的当班记录
+type DutyOnCallSegment struct {
+	Layer    string     `json:"layer"`
+	Level    int        `json:"level"`
+	Users    []DutyUser `json:"users"`
+	Override string     `json:"override,omitempty"`
+	StartAt  int64      `json:"startAt"`
+	EndAt    int64      `json:"endAt"`
+}
+
+// DutyTimeline 解析值班人员所需的全部数据
+type DutyTimeline struct {
+	Duty      DutyManagement
+	Revisions []DutyRevision        // 按 EffectiveAt 升序
+	Calendar  map[string][]DutyUser // 按天值班表, key 为 2006-1-2 格式的日期
+	Overrides []DutyOverride        // 按 CreateAt 升序, 后创建的覆盖优先
+}
+
+// At 获取 at 时刻各轮值层的当班人员, 按值班层级排序
+func (t DutyTimeline) At(at time.Time) []DutyOnCall {
+	duty := t.dutyAt(at.Unix())
+
+	var onCall []DutyOnCall
+	if len(duty.Layers) > 0 {
+		onCall = duty.ResolveOnCall(at)
+	} else {
+		loc := duty.GetLocation()
+		local := at.In(loc)
+		if users := t.Calendar[local.Format("2006-1-2")]; len(users) > 0 {
+			dayStart := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+			onCall = []DutyOnCall{{
+				Layer:      DutyCalendarLayer,
+				Level:      DutyLevelPrimary,
+				Users:      users,
+				ShiftStart: dayStart.Unix(),
+				ShiftEnd:   dayStart.AddDate(0, 0, 1).Unix(),
+			}}
+		}
+	}
+
+	return applyOverrides(duty, onCall, t.Overrides, at.Unix())
+}
+
+// History 获取 start 到 end 期间的值班记录, 在换班、生效时段、自然日、覆盖值班及配置变更的时间点解析后合并为连续时段
+func (t DutyTimeline) History(start, end time.Time) []DutyOnCallSegment {
+	points := []int64{start.Unix()}
+	for _, duty := range t.configs() {
+		points = append(points, duty.boundaries(start, end)...)
+	}
+	for _, o := range t.Overrides {
+		points = append(points, o.StartAt, o.EndAt, o.CanceledAt)
+	}
+	for _, r := range t.Revisions {
+		points = append(points, r.EffectiveAt)
+	}
+	sort.Slice(points, func(i, j int) bool { return points[i] < points[j] })
+
+	var (
+		result []DutyOnCallSegment
+		open   = make(map[string]int)
+		last   int64
+	)
+	for i, p := range points {
+		if p < start.Unix() || p >= end.Unix() || (i > 0 && p == last) {
+			continue
+		}
+		last = p
+
+		seen := make(map[string]struct{})
+		for _, o := range t.At(time.Unix(p, 0)) {
+			seen[o.Layer] = struct{}{}
+			if idx, ok := open[o.Layer]; ok && sameSegment(result[idx], o) {
+				continue
+			}
+			if idx, ok := open[o.Layer]; ok {
+				result[idx].EndAt = p
+			}
+			open[o.Layer] = len(result)
+			result = append(result, DutyOnCallSegment{
+				Layer:    o.Layer,
+				Level:    o.Level,
+				Users:    o.Users,
+				Override: o.Override,
+				StartAt:  p,
+			})
+		}
+		for layer, idx := range open {
+			if _, ok := seen[layer]; !ok {
+				result[idx].EndAt = p
+				delete(open, layer)
+			}
+		}
+	}
+	for _, idx := range open {
+		result[idx].EndAt = end.Unix()
+	}
+
+	return result
+}
+
+// configs 时间线中用到的全部轮值配置版本
+func (t DutyTimeline) configs() []DutyManagement {
+	if len(t.Revisions) == 0 {
+		return []DutyManagement{t.Duty}
+	}
+
+	var result []DutyManagement
+	for _, r := range t.Revisions {
+		duty := t.Duty
+		duty.TimeZone = r.TimeZone
+		duty.Layers = r.Layers
+		result = append(result, duty)
+	}
+	return result
+}
+
+// dutyAt 获取 at 时刻生效的轮值配置, 早于首个版本时使用首个版本
+func (t DutyTimeline) dutyAt(at int64) DutyManagement {
+	duty := t.Duty
+	if len(t.Revisions) == 0 {
+		return duty
+	}
+
+	revision := t.Revisions[0]
+	for _, r := range t.Revisions {
+		if r.EffectiveAt > at {
+			break
+		}
+		revision = r
+	}
+	duty.TimeZone = revision.TimeZone
+	duty.Layers = revision.Layers
+	return duty
+}
+
+func applyOverrides(duty DutyManagement, onCall []DutyOnCall, overrides []DutyOverride, at int64) []DutyOnCall {
+	for _, o := range overrides {
+		if !o.ActiveAt(at) {
+			continue
+		}
+
+		var replaced bool
+		for i := range onCall {
+			if (o.Layer == "" && onCall[i].Level == DutyLevelPrimary) || (o.Layer != "" && onCall[i].Layer == o.Layer) {
+				if o.ReplaceUserId != "" {
+					users, ok := replaceDutyUser(onCall[i].Users, o.ReplaceUserId, o.User)
+					if !ok {
+						continue
+					}
+					onCall[i].Users = users
+				} else {
+					onCall[i].Users = []DutyUser{o.User}
+					onCall[i].ShiftStart = o.StartAt
+					onCall[i].ShiftEnd = o.EndAt
+				}
+				onCall[i].Override = o.ID
+				replaced = true
+			}
+		}
+		// 只顶替指定人员的覆盖, 被顶替的人员不在班时无需生效
+		if replaced || o.ReplaceUserId != "" {
+			continue
+		}
+
+		layer, level := o.Layer, DutyLevelPrimary
+		if layer == "" {
+			layer = DutyOverrideLayer
+		}
+		for _, l := range duty.Layers {
+			if l.Name == o.Layer && l.Level > 0 {
+				level = l.Level
+			}
+		}
+		onCall = append(onCall, DutyOnCall{
+			Layer:      layer,
+			Level:      level,
+			Users:      []DutyUser{o.User},
+			ShiftStart: o.StartAt,
+			ShiftEnd:   o.EndAt,
+			Override:   o.ID,
+		})
+	}
+
+	sort.SliceStable(onCall, func(i, j int) bool {
+		return onCall[i].Level < onCall[j].Level
+	})
+	return onCall
+}
+
+// replaceDutyUser 将当班人员中的 userId 替换为 user, 不在当班人员中时返回 false
+func replaceDutyUser(users []DutyUser, userId string, user DutyUser) ([]DutyUser, bool) {
+	idx := -1
+	for i, u := range users {
+		if u.UserId == userId {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		return users, false
+	}
+
+	var result []DutyUser
+	for i, u := range users {
+		switch {
+		case i == idx:
+			result = append(result, user)
+		case u.UserId == user.UserId:
+			// 顶替人员本就在同一班次中时不重复
+		default:
+			result = append(result, u)
+		}
+	}
+	return result, true
+}
+
+func sameSegment(s DutyOnCallSegment, o DutyOnCall) bool {
+	if s.Override != o.Override || len(s.Users) != len(o.Users) {
+		return false
+	}
+	for i := range s.Users {
+		if s.Users[i].UserId != o.Users[i].UserId {
+			return false
+		}
+	}
+	return true
+}
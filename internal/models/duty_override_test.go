@@ -0,0 +1,170 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDutyTimelineOverrides(t *testing.T) {
+	base := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	timeline := DutyTimeline{
+		Duty: DutyManagement{
+			TimeZone: "UTC",
+			Layers: []DutyRotationLayer{
+				{Name: "primary", Level: DutyLevelPrimary, StartAt: "2025-01-01 09:00", ShiftLength: 12 * 60, UserGroups: [][]DutyUser{dutyUsers("alice"), dutyUsers("bob")}},
+				{Name: "secondary", Level: DutyLevelSecondary, StartAt: "2025-01-01 09:00", ShiftLength: 24 * 60, UserGroups: [][]DutyUser{dutyUsers("lead")}},
+			},
+		},
+		Overrides: []DutyOverride{
+			{ID: "o1", User: DutyUser{UserId: "carol"}, StartAt: base.Add(2 * time.Hour).Unix(), EndAt: base.Add(4 * time.Hour).Unix()},
+			// 取消前已生效的时段仍计入
+			{ID: "o2", Layer: "secondary", User: DutyUser{UserId: "dave"}, StartAt: base.Add(time.Hour).Unix(), EndAt: base.Add(10 * time.Hour).Unix(), CanceledAt: base.Add(3 * time.Hour).Unix()},
+		},
+	}
+
+	cases := []struct {
+		at   time.Time
+		want []string
+	}{
+		{base.Add(time.Hour / 2), []string{"primary:alice", "secondary:lead"}},
+		{base.Add(2*time.Hour + time.Minute), []string{"primary:carol", "secondary:dave"}},
+		{base.Add(3*time.Hour + time.Minute), []string{"primary:carol", "secondary:lead"}},
+		{base.Add(4 * time.Hour), []string{"primary:alice", "secondary:lead"}},
+		{base.Add(13 * time.Hour), []string{"primary:bob", "secondary:lead"}},
+	}
+	for _, c := range cases {
+		got := onCallUsers(timeline.At(c.at))
+		if len(got) != len(c.want) {
+			t.Fatalf("at %s: expected %v, got %v", c.at, c.want, got)
+		}
+		for i := range got {
+			if got[i] != c.want[i] {
+				t.Fatalf("at %s: expected %v, got %v", c.at, c.want, got)
+			}
+		}
+	}
+
+	var primary []string
+	for _, s := range timeline.History(base, base.Add(24*time.Hour)) {
+		if s.Layer == "primary" {
+			primary = append(primary, s.Users[0].UserId+"@"+time.Unix(s.StartAt, 0).UTC().Format("15:04")+"-"+time.Unix(s.EndAt, 0).UTC().Format("15:04"))
+		}
+	}
+	want := []string{"alice@09:00-11:00", "carol@11:00-13:00", "alice@13:00-21:00", "bob@21:00-09:00"}
+	if len(primary) != len(want) {
+		t.Fatalf("unexpected primary history: %v", primary)
+	}
+	for i := range want {
+		if primary[i] != want[i] {
+			t.Fatalf("unexpected primary history: %v", primary)
+		}
+	}
+}
+
+func TestDutyTimelineRevisionsAndCalendar(t *testing.T) {
+	switchAt := time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)
+	timeline := DutyTimeline{
+		Duty: DutyManagement{TimeZone: "UTC"},
+		Revisions: []DutyRevision{
+			{TimeZone: "UTC", EffectiveAt: 0},
+			{TimeZone: "UTC", EffectiveAt: switchAt.Unix(), Layers: []DutyRotationLayer{
+				{Name: "primary", StartAt: "2025-01-10 00:00", ShiftLength: 24 * 60, UserGroups: [][]DutyUser{dutyUsers("bob")}},
+			}},
+		},
+		Calendar: map[string][]DutyUser{"2025-1-9": dutyUsers("alice")},
+		Overrides: []DutyOverride{
+			{ID: "o1", User: DutyUser{UserId: "carol"}, StartAt: switchAt.Add(-time.Hour).Unix(), EndAt: switchAt.Unix()},
+		},
+	}
+
+	if got := onCallUsers(timeline.At(switchAt.Add(-2 * time.Hour))); len(got) != 1 || got[0] != DutyCalendarLayer+":alice" {
+		t.Fatalf("expected calendar user before revision, got %v", got)
+	}
+	if got := onCallUsers(timeline.At(switchAt.Add(-time.Minute))); len(got) != 1 || got[0] != DutyCalendarLayer+":carol" {
+		t.Fatalf("expected override on calendar, got %v", got)
+	}
+	if got := onCallUsers(timeline.At(switchAt.Add(time.Hour))); len(got) != 1 || got[0] != "primary:bob" {
+		t.Fatalf("expected rotation after revision, got %v", got)
+	}
+}
+
+func TestDutyTimelineHistoryBoundaries(t *testing.T) {
+	start := time.Date(2025, 3, 28, 0, 0, 0, 0, time.UTC)
+	timeline := DutyTimeline{
+		Duty: DutyManagement{
+			TimeZone: "Europe/Berlin",
+			Layers: []DutyRotationLayer{
+				{Name: "primary", Level: DutyLevelPrimary, StartAt: "2025-03-28 09:30", ShiftLength: 7 * 60, UserGroups: [][]DutyUser{dutyUsers("alice"), dutyUsers("bob", "carol")}},
+				{Name: "apac", Level: DutyLevelSecondary, TimeZone: "Asia/Shanghai", StartAt: "2025-03-27 00:00", ShiftLength: 24 * 60, UserGroups: [][]DutyUser{dutyUsers("li"), dutyUsers("wang")},
+					Windows: []DutyTimeWindow{{Start: "21:00", End: "09:00"}}},
+			},
+		},
+		Overrides: []DutyOverride{
+			{ID: "o1", User: DutyUser{UserId: "dave"}, ReplaceUserId: "carol", StartAt: start.Add(30 * time.Hour).Unix(), EndAt: start.Add(40 * time.Hour).Unix()},
+		},
+	}
+
+	// 跨越夏令时切换, 与逐分钟采样的结果一致
+	end := start.Add(4 * 24 * time.Hour)
+	got := timeline.History(start, end)
+
+	var want []DutyOnCallSegment
+	open := make(map[string]int)
+	for p := start; p.Before(end); p = p.Add(time.Minute) {
+		seen := make(map[string]struct{})
+		for _, o := range timeline.At(p) {
+			seen[o.Layer] = struct{}{}
+			if idx, ok := open[o.Layer]; ok && sameSegment(want[idx], o) {
+				continue
+			}
+			if idx, ok := open[o.Layer]; ok {
+				want[idx].EndAt = p.Unix()
+			}
+			open[o.Layer] = len(want)
+			want = append(want, DutyOnCallSegment{Layer: o.Layer, Users: o.Users, Override: o.Override, StartAt: p.Unix()})
+		}
+		for layer, idx := range open {
+			if _, ok := seen[layer]; !ok {
+				want[idx].EndAt = p.Unix()
+				delete(open, layer)
+			}
+		}
+	}
+	for _, idx := range open {
+		want[idx].EndAt = end.Unix()
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("expected %d segments, got %d", len(want), len(got))
+	}
+	for i := range want {
+		if got[i].Layer != want[i].Layer || got[i].StartAt != want[i].StartAt || got[i].EndAt != want[i].EndAt || !sameSegment(got[i], DutyOnCall{Users: want[i].Users, Override: want[i].Override}) {
+			t.Fatalf("segment %d: expected %+v, got %+v", i, want[i], got[i])
+		}
+	}
+}
+
+func TestDutyOverrideReplaceUser(t *testing.T) {
+	base := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	timeline := DutyTimeline{
+		Duty: DutyManagement{
+			TimeZone: "UTC",
+			Layers: []DutyRotationLayer{
+				{Name: "primary", Level: DutyLevelPrimary, StartAt: "2025-01-01 09:00", ShiftLength: 12 * 60, UserGroups: [][]DutyUser{dutyUsers("alice", "bob"), dutyUsers("carol")}},
+			},
+		},
+		Overrides: []DutyOverride{
+			// 换班只顶替申请人, 同组的 bob 继续值班
+			{ID: "o1", User: DutyUser{UserId: "carol"}, ReplaceUserId: "alice", StartAt: base.Unix(), EndAt: base.Add(12 * time.Hour).Unix()},
+			// 被顶替的人员不在班时不生效
+			{ID: "o2", User: DutyUser{UserId: "alice"}, ReplaceUserId: "bob", StartAt: base.Add(12 * time.Hour).Unix(), EndAt: base.Add(24 * time.Hour).Unix()},
+		},
+	}
+
+	if got := onCallUsers(timeline.At(base.Add(time.Hour))); len(got) != 2 || got[0] != "primary:carol" || got[1] != "primary:bob" {
+		t.Fatalf("expected only requester replaced, got %v", got)
+	}
+	if got := onCallUsers(timeline.At(base.Add(13 * time.Hour))); len(got) != 1 || got[0] != "primary:carol" {
+		t.Fatalf("override for an off-shift user should not apply, got %v", got)
+	}
+}
@@ -0,0 +1,314 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DutyRotationStartLayout 轮换起点格式, 按轮值层时区解析
+	DutyRotationStartLayout = "2006-01-02 15:04"
+	// DutyLevelPrimary 主值班
+	DutyLevelPrimary = 1
+	// DutyLevelSecondary 备份值班
+	DutyLevelSecondary = 2
+)
+
+// DutyRotationLayer 轮值层
+// 从 StartAt 开始每 ShiftLength 分钟换一次班, 按顺序轮换 UserGroups; 配置 Windows 时只在生效时段内值班。
+// 同一层级配置多个不同时区生效时段的轮值层即可实现跨地区接力值班（follow-the-sun）。
+type DutyRotationLayer struct {
+	Name        string           `json:"name"`
+	Level       int              `json:"level"`       // 值班层级, 1 为主值班, 2 为备份值班, 以此类推
+	TimeZone    string           `json:"timeZone"`    // 为空时使用值班组时区
+	StartAt     string           `json:"startAt"`     // 轮换起点, 如 2025-01-01 09:00
+	ShiftLength int64            `json:"shiftLength"` // 每班时长, 单位（分钟）
+	UserGroups  [][]DutyUser     `json:"userGroups"`
+	Windows     []DutyTimeWindow `json:"windows"` // 生效时段, 为空时全天生效
+}
+
+// DutyTimeWindow 每日生效时段, End 小于 Start 时表示跨天, 如 21:00-09:00
+type DutyTimeWindow struct {
+	Start    string `json:"start"` // 如 09:00
+	End      string `json:"end"`   // 如 21:00
+	TimeZone string `json:"timeZone"`
+}
+
+// DutyOnCall 某一时刻轮值层的当班人员
+type DutyOnCall struct {
+	Layer      string     `json:"layer"`
+	Level      int        `json:"level"`
+	Users      []DutyUser `json:"users"`
+	ShiftStart int64      `json:"shiftStart"`
+	ShiftEnd   int64      `json:"shiftEnd"`
+	Override   string     `json:"override,omitempty"` // 生效的覆盖值班 ID
+}
+
+// GetLocation 值班组时区, 未配置或无效时使用服务器时区
+func (d DutyManagement) GetLocation() *time.Location {
+	if d.TimeZone == "" {
+		return time.Local
+	}
+	loc, err := loadLocation(d.TimeZone)
+	if err != nil {
+		return time.Local
+	}
+	return loc
+}
+
+// ValidateLayers 校验值班组时区及轮值层配置
+func (d DutyManagement) ValidateLayers() error {
+	if d.TimeZone != "" {
+		if _, err := time.LoadLocation(d.TimeZone); err != nil {
+			return fmt.Errorf("无效的时区: %s", d.TimeZone)
+		}
+	}
+
+	for _, layer := range d.Layers {
+		if layer.TimeZone != "" {
+			if _, err := time.LoadLocation(layer.TimeZone); err != nil {
+				return fmt.Errorf("轮值层 %s 时区无效: %s", layer.Name, layer.TimeZone)
+			}
+		}
+		if _, err := time.ParseInLocation(DutyRotationStartLayout, layer.StartAt, time.UTC); err != nil {
+			return fmt.Errorf("轮值层 %s 轮换起点无效, 格式应为 %s", layer.Name, DutyRotationStartLayout)
+		}
+		if layer.ShiftLength <= 0 {
+			return fmt.Errorf("轮值层 %s 每班时长必须大于 0", layer.Name)
+		}
+		if len(layer.UserGroups) == 0 {
+			return fmt.Errorf("轮值层 %s 未配置值班人员", layer.Name)
+		}
+		for _, window := range layer.Windows {
+			if window.TimeZone != "" {
+				if _, err := time.LoadLocation(window.TimeZone); err != nil {
+					return fmt.Errorf("轮值层 %s 生效时段时区无效: %s", layer.Name, window.TimeZone)
+				}
+			}
+			if _, ok := parseClock(window.Start); !ok {
+				return fmt.Errorf("轮值层 %s 生效时段开始时间无效: %s", layer.Name, window.Start)
+			}
+			if _, ok := parseClock(window.End); !ok {
+				return fmt.Errorf("轮值层 %s 生效时段结束时间无效: %s", layer.Name, window.End)
+			}
+		}
+	}
+
+	return nil
+}
+
+// HasLayer 覆盖值班及换班指定的轮值层是否存在, 为空时表示主值班; 未配置轮值层时仅有按天值班表对应的轮值层
+func (d DutyManagement) HasLayer(name string) bool {
+	if name == "" {
+		return true
+	}
+	if len(d.Layers) == 0 {
+		return name == DutyCalendarLayer
+	}
+	for _, layer := range d.Layers {
+		if layer.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ResolveOnCall 获取 at 时刻各轮值层的当班人员, 按值班层级排序
+func (d DutyManagement) ResolveOnCall(at time.Time) []DutyOnCall {
+	var result []DutyOnCall
+	for _, layer := range d.Layers {
+		loc := d.GetLocation()
+		if layer.TimeZone != "" {
+			if l, err := loadLocation(layer.TimeZone); err == nil {
+				loc = l
+			}
+		}
+
+		onCall, ok := layer.resolve(at, loc)
+		if ok {
+			result = append(result, onCall)
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Level < result[j].Level
+	})
+	return result
+}
+
+func (layer DutyRotationLayer) resolve(at time.Time, loc *time.Location) (DutyOnCall, bool) {
+	if layer.ShiftLength <= 0 || len(layer.UserGroups) == 0 {
+		return DutyOnCall{}, false
+	}
+	start, err := time.ParseInLocation(DutyRotationStartLayout, layer.StartAt, loc)
+	if err != nil || at.Before(start) {
+		return DutyOnCall{}, false
+	}
+	if len(layer.Windows) > 0 && !layer.inWindows(at, loc) {
+		return DutyOnCall{}, false
+	}
+
+	// 按当地挂钟时间换班, 避免夏令时切换后换班时间偏移
+	var (
+		length  = time.Duration(layer.ShiftLength) * time.Minute
+		elapsed = wallClock(at, loc).Sub(wallClock(start, loc))
+		index   = int64(elapsed / length)
+	)
+	shiftStart := wallClock(start, loc).Add(time.Duration(index) * length)
+	shiftEnd := shiftStart.Add(length)
+
+	level := layer.Level
+	if level <= 0 {
+		level = DutyLevelPrimary
+	}
+	return DutyOnCall{
+		Layer:      layer.Name,
+		Level:      level,
+		Users:      layer.UserGroups[index%int64(len(layer.UserGroups))],
+		ShiftStart: fromWallClock(shiftStart, loc).Unix(),
+		ShiftEnd:   fromWallClock(shiftEnd, loc).Unix(),
+	}, true
+}
+
+func (layer DutyRotationLayer) inWindows(at time.Time, loc *time.Location) bool {
+	for _, window := range layer.Windows {
+		wloc := loc
+		if window.TimeZone != "" {
+			if l, err := loadLocation(window.TimeZone); err == nil {
+				wloc = l
+			}
+		}
+		start, ok1 := parseClock(window.Start)
+		end, ok2 := parseClock(window.End)
+		if !ok1 || !ok2 {
+			continue
+		}
+
+		local := at.In(wloc)
+		minute := local.Hour()*60 + local.Minute()
+		switch {
+		case start == end:
+			return true
+		case start < end:
+			if minute >= start && minute < end {
+				return true
+			}
+		default:
+			if minute >= start || minute < end {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// boundaries start 到 end 期间当班人员可能变化的时间点, 包括各轮值层的换班时间、生效时段的边界及自然日边界
+func (d DutyManagement) boundaries(start, end time.Time) []int64 {
+	loc := d.GetLocation()
+	points := dayEdges(start, end, loc, 0)
+	for _, layer := range d.Layers {
+		lloc := loc
+		if layer.TimeZone != "" {
+			if l, err := loadLocation(layer.TimeZone); err == nil {
+				lloc = l
+			}
+		}
+		points = append(points, layer.shiftEdges(start, end, lloc)...)
+
+		for _, window := range layer.Windows {
+			wloc := lloc
+			if window.TimeZone != "" {
+				if l, err := loadLocation(window.TimeZone); err == nil {
+					wloc = l
+				}
+			}
+			for _, clock := range []string{window.Start, window.End} {
+				if minute, ok := parseClock(clock); ok {
+					points = append(points, dayEdges(start, end, wloc, minute)...)
+				}
+			}
+		}
+	}
+	return points
+}
+
+// shiftEdges start 到 end 期间轮值层的换班时间
+func (layer DutyRotationLayer) shiftEdges(start, end time.Time, loc *time.Location) []int64 {
+	if layer.ShiftLength <= 0 {
+		return nil
+	}
+	layerStart, err := time.ParseInLocation(DutyRotationStartLayout, layer.StartAt, loc)
+	if err != nil || layerStart.After(end) {
+		return nil
+	}
+
+	var (
+		length = time.Duration(layer.ShiftLength) * time.Minute
+		origin = wallClock(layerStart, loc)
+		first  int64
+		last   = int64(wallClock(end, loc).Sub(origin)/length) + 1
+	)
+	if start.After(layerStart) {
+		first = int64(wallClock(start, loc).Sub(origin) / length)
+	}
+
+	var points []int64
+	for k := first; k <= last; k++ {
+		points = append(points, fromWallClock(origin.Add(time.Duration(k)*length), loc).Unix())
+	}
+	return points
+}
+
+// dayEdges start 到 end 期间每天当地 minute 分钟对应的时间点, 前后各多取一天
+func dayEdges(start, end time.Time, loc *time.Location, minute int) []int64 {
+	var (
+		points []int64
+		local  = start.In(loc).AddDate(0, 0, -1)
+	)
+	for day := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc); !day.After(end.AddDate(0, 0, 1)); day = day.AddDate(0, 0, 1) {
+		points = append(points, time.Date(day.Year(), day.Month(), day.Day(), 0, minute, 0, 0, loc).Unix())
+	}
+	return points
+}
+
+var locations sync.Map
+
+// loadLocation 缓存已加载的时区, 避免解析值班历史时反复读取时区数据
+func loadLocation(name string) (*time.Location, error) {
+	if loc, ok := locations.Load(name); ok {
+		return loc.(*time.Location), nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, err
+	}
+	locations.Store(name, loc)
+	return loc, nil
+}
+
+// parseClock 解析 15:04 格式的时间, 返回当日分钟数, 24:00 表示当日结束
+func parseClock(s string) (int, bool) {
+	parts := strings.Split(strings.TrimSpace(s), ":")
+	if len(parts) != 2 {
+		return 0, false
+	}
+	hour, err1 := strconv.Atoi(parts[0])
+	minute, err2 := strconv.Atoi(parts[1])
+	if err1 != nil || err2 != nil || hour < 0 || minute < 0 || minute > 59 || hour*60+minute > 24*60 {
+		return 0, false
+	}
+	return hour*60 + minute, true
+}
+
+func wallClock(t time.Time, loc *time.Location) time.Time {
+	l := t.In(loc)
+	return time.Date(l.Year(), l.Month(), l.Day(), l.Hour(), l.Minute(), l.Second(), l.Nanosecond(), time.UTC)
+}
+
+func fromWallClock(t time.Time, loc *time.Location) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), loc)
+}
@@ -0,0 +1,138 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func dutyUsers(ids ...string) []DutyUser {
+	var users []DutyUser
+	for _, id := range ids {
+		users = append(users, DutyUser{UserId: id, Username: id})
+	}
+	return users
+}
+
+func onCallUsers(onCall []DutyOnCall) []string {
+	var ids []string
+	for _, o := range onCall {
+		for _, u := range o.Users {
+			ids = append(ids, o.Layer+":"+u.UserId)
+		}
+	}
+	return ids
+}
+
+func TestResolveOnCallHourShifts(t *testing.T) {
+	duty := DutyManagement{
+		TimeZone: "Asia/Shanghai",
+		Layers: []DutyRotationLayer{
+			{Name: "secondary", Level: DutyLevelSecondary, StartAt: "2025-01-01 09:00", ShiftLength: 7 * 24 * 60, UserGroups: [][]DutyUser{dutyUsers("lead")}},
+			{Name: "primary", Level: DutyLevelPrimary, StartAt: "2025-01-01 09:00", ShiftLength: 12 * 60, UserGroups: [][]DutyUser{dutyUsers("alice"), dutyUsers("bob"), dutyUsers("carol")}},
+		},
+	}
+	if err := duty.ValidateLayers(); err != nil {
+		t.Fatal(err)
+	}
+
+	loc, _ := time.LoadLocation("Asia/Shanghai")
+	cases := []struct {
+		at   time.Time
+		want []string
+	}{
+		{time.Date(2025, 1, 1, 8, 59, 0, 0, loc), nil},
+		{time.Date(2025, 1, 1, 9, 0, 0, 0, loc), []string{"primary:alice", "secondary:lead"}},
+		{time.Date(2025, 1, 1, 23, 30, 0, 0, loc), []string{"primary:bob", "secondary:lead"}},
+		{time.Date(2025, 1, 2, 3, 0, 0, 0, loc), []string{"primary:bob", "secondary:lead"}},
+		{time.Date(2025, 1, 2, 9, 0, 0, 0, loc), []string{"primary:carol", "secondary:lead"}},
+		{time.Date(2025, 1, 2, 21, 0, 0, 0, loc), []string{"primary:alice", "secondary:lead"}},
+	}
+	for _, c := range cases {
+		got := onCallUsers(duty.ResolveOnCall(c.at.UTC()))
+		if len(got) != len(c.want) {
+			t.Fatalf("at %s: expected %v, got %v", c.at, c.want, got)
+		}
+		for i := range got {
+			if got[i] != c.want[i] {
+				t.Fatalf("at %s: expected %v, got %v", c.at, c.want, got)
+			}
+		}
+	}
+
+	onCall := duty.ResolveOnCall(time.Date(2025, 1, 1, 23, 30, 0, 0, loc))
+	if onCall[0].ShiftStart != time.Date(2025, 1, 1, 21, 0, 0, 0, loc).Unix() || onCall[0].ShiftEnd != time.Date(2025, 1, 2, 9, 0, 0, 0, loc).Unix() {
+		t.Fatalf("unexpected shift boundary: %+v", onCall[0])
+	}
+}
+
+func TestResolveOnCallFollowTheSun(t *testing.T) {
+	duty := DutyManagement{
+		Layers: []DutyRotationLayer{
+			{Name: "apac", StartAt: "2025-01-01 00:00", ShiftLength: 24 * 60, TimeZone: "Asia/Shanghai", UserGroups: [][]DutyUser{dutyUsers("li")}, Windows: []DutyTimeWindow{{Start: "08:00", End: "20:00"}}},
+			{Name: "emea", StartAt: "2025-01-01 00:00", ShiftLength: 24 * 60, TimeZone: "Europe/London", UserGroups: [][]DutyUser{dutyUsers("tom")}, Windows: []DutyTimeWindow{{Start: "20:00", End: "08:00", TimeZone: "Asia/Shanghai"}}},
+		},
+	}
+
+	for hour, want := range map[int]string{0: "apac:li", 11: "apac:li", 12: "emea:tom", 23: "emea:tom"} {
+		at := time.Date(2025, 3, 3, hour, 0, 0, 0, time.UTC)
+		got := onCallUsers(duty.ResolveOnCall(at))
+		if len(got) != 1 || got[0] != want {
+			t.Fatalf("at %s: expected %s, got %v", at, want, got)
+		}
+	}
+}
+
+func TestResolveOnCallDaylightSaving(t *testing.T) {
+	duty := DutyManagement{
+		TimeZone: "America/New_York",
+		Layers: []DutyRotationLayer{
+			{Name: "primary", StartAt: "2025-03-01 09:00", ShiftLength: 24 * 60, UserGroups: [][]DutyUser{dutyUsers("a"), dutyUsers("b")}},
+		},
+	}
+
+	// 夏令时切换后仍在当地 09:00 换班
+	loc, _ := time.LoadLocation("America/New_York")
+	onCall := duty.ResolveOnCall(time.Date(2025, 3, 10, 9, 0, 0, 0, loc))
+	if len(onCall) != 1 || onCall[0].Users[0].UserId != "b" || onCall[0].ShiftStart != time.Date(2025, 3, 10, 9, 0, 0, 0, loc).Unix() {
+		t.Fatalf("unexpected on-call after DST change: %+v", onCall)
+	}
+}
+
+func TestValidateLayers(t *testing.T) {
+	invalid := []DutyManagement{
+		{TimeZone: "Mars/Olympus"},
+		{Layers: []DutyRotationLayer{{Name: "p", StartAt: "2025-01-01", ShiftLength: 60, UserGroups: [][]DutyUser{dutyUsers("a")}}}},
+		{Layers: []DutyRotationLayer{{Name: "p", StartAt: "2025-01-01 09:00", UserGroups: [][]DutyUser{dutyUsers("a")}}}},
+		{Layers: []DutyRotationLayer{{Name: "p", StartAt: "2025-01-01 09:00", ShiftLength: 60}}},
+		{Layers: []DutyRotationLayer{{Name: "p", StartAt: "2025-01-01 09:00", ShiftLength: 60, UserGroups: [][]DutyUser{dutyUsers("a")}, Windows: []DutyTimeWindow{{Start: "25:00", End: "09:00"}}}}},
+	}
+	for i, duty := range invalid {
+		if err := duty.ValidateLayers(); err == nil {
+			t.Fatalf("case %d: expected validation error", i)
+		}
+	}
+}
+
+func TestDutyHasLayer(t *testing.T) {
+	layered := DutyManagement{Layers: []DutyRotationLayer{{Name: "primary"}, {Name: "secondary"}}}
+	calendar := DutyManagement{}
+
+	cases := []struct {
+		name  string
+		duty  DutyManagement
+		layer string
+		want  bool
+	}{
+		{"empty layer is primary", layered, "", true},
+		{"configured layer", layered, "secondary", true},
+		{"unknown layer", layered, "tertiary", false},
+		{"calendar layer is not a rotation layer", layered, DutyCalendarLayer, false},
+		{"calendar duty", calendar, DutyCalendarLayer, true},
+		{"unknown layer on calendar duty", calendar, "primary", false},
+	}
+	for _, c := range cases {
+		if got := c.duty.HasLayer(c.layer); got != c.want {
+			t.Errorf("%s: got %v, want %v", c.name, got, c.want)
+		}
+	}
+}
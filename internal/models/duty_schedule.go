@@ -1,14 +1,18 @@
 package models
 
 type DutyManagement struct {
-	TenantId    string     `json:"tenantId"`
-	ID          string     `json:"id"`
-	Name        string     `json:"name"`
-	Manager     DutyUser   `json:"manager" gorm:"manager;serializer:json"`
-	Description string     `json:"description"`
-	CurDutyUser []DutyUser `json:"curDutyUser" gorm:"curDutyUser;serializer:json"`
-	UpdateBy    string     `json:"updateBy"`
-	UpdateAt    int64      `json:"updateAt"`
+	TenantId          string              `json:"tenantId"`
+	ID                string              `json:"id"`
+	Name              string              `json:"name"`
+	Manager           DutyUser            `json:"manager" gorm:"manager;serializer:json"`
+	Description       string              `json:"description"`
+	CurDutyUser       []DutyUser          `json:"curDutyUser" gorm:"curDutyUser;serializer:json"`
+	TimeZone          string              `json:"timeZone"`                                                   // 值班组时区, 如 Asia/Shanghai, 为空时使用服务器时区
+	Layers            []DutyRotationLayer `json:"layers" gorm:"layers;serializer:json"`                       // 按小时轮值层, 配置后优先于按天的值班表
+	HolidayMode       string              `json:"holidayMode"`                                                // 生成值班表时节假日的处理方式, skip 跳过非工作日, assign 节假日单独排班
+	HolidayUserGroups [][]DutyUser        `json:"holidayUserGroups" gorm:"holidayUserGroups;serializer:json"` // 节假日值班人员, 按天轮换
+	UpdateBy          string              `json:"updateBy"`
+	UpdateAt          int64               `json:"updateAt"`
 }
 
 type CalendarStatus string
@@ -0,0 +1,89 @@
+package models
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	emailHtmlTagRe   = regexp.MustCompile(`(?i)<(html|body|div|p|br|table|tr|td|span|b|strong|a|h[1-6]|ul|ol|li|pre|font)\b`)
+	emailBlockRe     = regexp.MustCompile(`(?is)<(style|script|head)\b.*?</(style|script|head)>`)
+	emailLineBreakRe = regexp.MustCompile(`(?i)<br\s*/?>|</(p|div|tr|h[1-6]|li|table|pre)>`)
+	emailCellRe      = regexp.MustCompile(`(?i)</t[dh]>`)
+	emailTagRe       = regexp.MustCompile(`<[^>]*>`)
+	emailBlankRe     = regexp.MustCompile(`\n{3,}`)
+	emailIdRe        = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+)
+
+// EmailBodies 由通知内容生成纯文本及 HTML 正文
+// 内容为 HTML 时去除标签生成纯文本, 否则转义后保留换行生成 HTML
+func EmailBodies(content string) (text, body string) {
+	if !emailHtmlTagRe.MatchString(content) {
+		return content, fmt.Sprintf(`<div style="white-space: pre-wrap;">%s</div>`, html.EscapeString(content))
+	}
+
+	text = emailBlockRe.ReplaceAllString(content, "")
+	text = emailLineBreakRe.ReplaceAllString(text, "\n")
+	text = emailCellRe.ReplaceAllString(text, " ")
+	text = html.UnescapeString(emailTagRe.ReplaceAllString(text, ""))
+
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+	text = emailBlankRe.ReplaceAllString(strings.Join(lines, "\n"), "\n\n")
+
+	return strings.TrimSpace(text), content
+}
+
+// EmailThreadHeaders 按事件 ID 生成会话相关的邮件头
+// 首封告警邮件的 Message-ID 为以事件 ID 生成的根 ID, 之后的重复通知及恢复邮件使用唯一的 Message-ID, 并通过 In-Reply-To、References 回复根邮件
+func EmailThreadHeaders(eventId, from string, first bool, now time.Time) map[string]string {
+	domain := "watchalert"
+	if at := strings.LastIndex(from, "@"); at >= 0 && at < len(from)-1 {
+		domain = from[at+1:]
+	}
+	id := emailIdRe.ReplaceAllString(eventId, "")
+	root := fmt.Sprintf("<%s@%s>", id, domain)
+	if first {
+		return map[string]string{"Message-Id": root}
+	}
+
+	return map[string]string{
+		"Message-Id":  fmt.Sprintf("<%s.%d@%s>", id, now.UnixNano(), domain),
+		"In-Reply-To": root,
+		"References":  root,
+	}
+}
+
+// EmailThreadSubject 同一事件的邮件使用相同的主题, 后续邮件以 Re: 开头, 邮件客户端按主题及引用归为同一会话
+func EmailThreadSubject(subject string, first bool) string {
+	if first {
+		return subject
+	}
+	return "Re: " + subject
+}
+
+// EmailPrependState 在正文开头标注告警状态, 状态不放在主题中以免打断会话
+func EmailPrependState(text, body, state string) (string, string) {
+	text = fmt.Sprintf("【%s】\n%s", state, text)
+	banner := fmt.Sprintf(`<p><strong>【%s】</strong></p>`, html.EscapeString(state))
+	if i := strings.Index(strings.ToLower(body), "<body"); i >= 0 {
+		if j := strings.Index(body[i:], ">"); j >= 0 {
+			return text, body[:i+j+1] + banner + body[i+j+1:]
+		}
+	}
+	return text, banner + body
+}
+
+// EmailAppendImage 在 HTML 正文末尾附加内联图片
+func EmailAppendImage(body, cid, alt string) string {
+	img := fmt.Sprintf(`<p><img src="cid:%s" alt="%s" style="max-width: 100%%;"></p>`, cid, html.EscapeString(alt))
+	if i := strings.LastIndex(strings.ToLower(body), "</body>"); i >= 0 {
+		return body[:i] + img + body[i:]
+	}
+	return body + img
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEmailBodies(t *testing.T) {
+	text, body := EmailBodies(`<html><head><style>p{color:red}</style></head><body><h3>CPU &amp; 内存</h3><p>实例: node-1<br>值: 95%</p><table><tr><td>P0</td><td>firing</td></tr></table></body></html>`)
+	if text != "CPU & 内存\n实例: node-1\n值: 95%\nP0 firing" {
+		t.Fatalf("unexpected text: %q", text)
+	}
+	if !strings.HasPrefix(body, "<html>") {
+		t.Fatalf("html body should be kept: %q", body)
+	}
+
+	text, body = EmailBodies("值班交接\n1 < 2")
+	if text != "值班交接\n1 < 2" || body != `<div style="white-space: pre-wrap;">值班交接`+"\n"+`1 &lt; 2</div>` {
+		t.Fatalf("unexpected plain bodies: %q %q", text, body)
+	}
+}
+
+func TestEmailThreadHeaders(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	firing := EmailThreadHeaders("evt-1", "alert@example.com", true, now)
+	repeat := EmailThreadHeaders("evt-1", "alert@example.com", false, now)
+	recovered := EmailThreadHeaders("evt-1", "alert@example.com", false, now.Add(time.Minute))
+
+	// 首封邮件即为会话的根邮件, 后续邮件回复根邮件
+	if firing["Message-Id"] != "<evt-1@example.com>" || firing["References"] != "" {
+		t.Fatalf("first email should be the thread root: %v", firing)
+	}
+	if repeat["In-Reply-To"] != firing["Message-Id"] || recovered["References"] != firing["Message-Id"] {
+		t.Fatalf("follow-up emails should reference the root: %v %v", repeat, recovered)
+	}
+	if repeat["Message-Id"] == recovered["Message-Id"] || !strings.HasSuffix(repeat["Message-Id"], "@example.com>") {
+		t.Fatalf("message id should be unique per email: %v %v", repeat, recovered)
+	}
+	if got := EmailThreadHeaders("a b<c>", "", false, now)["References"]; got != "<abc@watchalert>" {
+		t.Fatalf("unexpected sanitized id: %s", got)
+	}
+
+	if EmailThreadSubject("CPU 告警", true) != "CPU 告警" || EmailThreadSubject("CPU 告警", false) != "Re: CPU 告警" {
+		t.Fatalf("subject should stay stable across the thread")
+	}
+}
+
+func TestEmailPrependState(t *testing.T) {
+	text, body := EmailPrependState("CPU 90%", "<html><body><p>CPU 90%</p></body></html>", "已恢复")
+	if text != "【已恢复】\nCPU 90%" || body != "<html><body><p><strong>【已恢复】</strong></p><p>CPU 90%</p></body></html>" {
+		t.Fatalf("unexpected bodies: %q %q", text, body)
+	}
+	if _, body := EmailPrependState("", "<div>CPU</div>", "报警中"); body != "<p><strong>【报警中】</strong></p><div>CPU</div>" {
+		t.Fatalf("unexpected body: %q", body)
+	}
+}
+
+func TestEmailAppendImage(t *testing.T) {
+	got := EmailAppendImage("<html><body><p>CPU</p></BODY></html>", "graph.png", "趋势图")
+	if got != `<html><body><p>CPU</p><p><img src="cid:graph.png" alt="趋势图" style="max-width: 100%;"></p></BODY></html>` {
+		t.Fatalf("unexpected body: %s", got)
+	}
+	if got := EmailAppendImage("<div>CPU</div>", "graph.png", ""); !strings.HasSuffix(got, `<img src="cid:graph.png" alt="" style="max-width: 100%;"></p>`) {
+		t.Fatalf("unexpected body: %s", got)
+	}
+}
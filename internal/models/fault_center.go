@@ -12,24 +12,27 @@ const (
 )
 
 type FaultCenter struct {
-	TenantId              string          `json:"tenantId"`
-	ID                    string          `json:"id"`
-	Name                  string          `json:"name"`
-	Description           string          `json:"description"`
-	NoticeIds             []string        `json:"noticeIds" gorm:"column:noticeIds;serializer:json"`
-	NoticeRoutes          []NoticeRoute   `json:"noticeRoutes" gorm:"noticeRoutes;serializer:json"`
-	RepeatNoticeInterval  int64           `json:"repeatNoticeInterval"`
-	RecoverNotify         *bool           `json:"recoverNotify"`
-	AggregationType       string          `json:"aggregationType"`
-	CreateAt              int64           `json:"createAt"`
-	RecoverWaitTime       int64           `json:"recoverWaitTime"` // 告警恢复等待时间，单位（秒）
-	CurrentPreAlertNumber int64           `json:"currentPreAlertNumber" gorm:"-"`
-	CurrentAlertNumber    int64           `json:"currentAlertNumber" gorm:"-"`
-	CurrentMuteNumber     int64           `json:"currentMuteNumber" gorm:"-"`
-	CurrentRecoverNumber  int64           `json:"currentRecoverNumber" gorm:"-"`
-	IsUpgradeEnabled      *bool           `json:"isUpgradeEnabled" gorm:"column:isUpgradeEnabled"`
-	UpgradableSeverity    []string        `json:"upgradableSeverity" gorm:"column:upgradableSeverity;serializer:json"`
-	UpgradeStrategy       UpgradeStrategy `json:"upgradeStrategy" gorm:"column:upgradeStrategy;serializer:json"`
+	TenantId              string            `json:"tenantId"`
+	ID                    string            `json:"id"`
+	Name                  string            `json:"name"`
+	Description           string            `json:"description"`
+	NoticeIds             []string          `json:"noticeIds" gorm:"column:noticeIds;serializer:json"`
+	NoticeRoutes          []NoticeRoute     `json:"noticeRoutes" gorm:"noticeRoutes;serializer:json"`
+	RepeatNoticeInterval  int64             `json:"repeatNoticeInterval"`
+	RecoverNotify         *bool             `json:"recoverNotify"`
+	AggregationType       string            `json:"aggregationType"`
+	CreateAt              int64             `json:"createAt"`
+	RecoverWaitTime       int64             `json:"recoverWaitTime"` // 告警恢复等待时间，单位（秒）
+	CurrentPreAlertNumber int64             `json:"currentPreAlertNumber" gorm:"-"`
+	CurrentAlertNumber    int64             `json:"currentAlertNumber" gorm:"-"`
+	CurrentMuteNumber     int64             `json:"currentMuteNumber" gorm:"-"`
+	CurrentRecoverNumber  int64             `json:"currentRecoverNumber" gorm:"-"`
+	IsUpgradeEnabled      *bool             `json:"isUpgradeEnabled" gorm:"column:isUpgradeEnabled"`
+	UpgradableSeverity    []string          `json:"upgradableSeverity" gorm:"column:upgradableSeverity;serializer:json"`
+	UpgradeStrategy       UpgradeStrategy   `json:"upgradeStrategy" gorm:"column:upgradeStrategy;serializer:json"`
+	FlapDetection         FlapDetection     `json:"flapDetection" gorm:"column:flapDetection;serializer:json"`
+	IncidentConfig        IncidentConfig    `json:"incidentConfig" gorm:"column:incidentConfig;serializer:json"`
+	Correlation           CorrelationConfig `json:"correlation" gorm:"column:correlation;serializer:json"`
 }
 
 type UpgradeStrategy struct {
@@ -92,6 +95,11 @@ func (f *FaultCenter) GetAlarmAggregationType() string {
 	return f.AggregationType
 }
 
+// GetCorrelationEnabled 启用告警关联或按聚类聚合通知时对活跃告警进行聚类
+func (f *FaultCenter) GetCorrelationEnabled() bool {
+	return f.Correlation.GetEnabled() || f.AggregationType == AggregationTypeCluster
+}
+
 type AlertEventCacheKey string
 
 func BuildAlertEventCacheKey(tenantId, faultCenterId string) AlertEventCacheKey {
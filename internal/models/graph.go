@@ -0,0 +1,75 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// 趋势图查询的最大时间跨度
+	graphMaxSpan = 24 * time.Hour
+	// 趋势图最多绘制的数据点数量
+	graphMaxPoints = 240
+	graphMinStep   = 15 * time.Second
+)
+
+// GraphWindow 趋势图的查询时间范围及步长
+// 以首次触发时间为中心前后各 window, 已恢复的告警延长至恢复后 window/2, 结束时间不晚于当前时间
+func GraphWindow(firstTriggerTime, recoverTime int64, isRecovered bool, window time.Duration, now time.Time) (start, end time.Time, step time.Duration) {
+	trigger := time.Unix(firstTriggerTime, 0)
+	start = trigger.Add(-window)
+	end = trigger.Add(window)
+	if isRecovered && recoverTime > 0 {
+		if recovered := time.Unix(recoverTime, 0).Add(window / 2); recovered.After(end) {
+			end = recovered
+		}
+	}
+	if end.After(now) {
+		end = now
+	}
+	if end.Sub(start) > graphMaxSpan {
+		start = end.Add(-graphMaxSpan)
+	}
+	if !end.After(start) {
+		start = end.Add(-window)
+	}
+
+	// 向上取整到秒, 保证数据点数量不超过上限
+	step = (end.Sub(start)/graphMaxPoints/time.Second + 1) * time.Second
+	if step < graphMinStep {
+		step = graphMinStep
+	}
+	return start, end, step
+}
+
+// GraphSeriesMatch 查询结果的曲线是否属于该告警, 曲线的所有标签需与告警标签一致
+func GraphSeriesMatch(eventLabels, seriesLabels map[string]interface{}) bool {
+	for key, value := range seriesLabels {
+		label, ok := eventLabels[key]
+		if !ok || fmt.Sprint(label) != fmt.Sprint(value) {
+			return false
+		}
+	}
+	return true
+}
+
+// GraphSeriesName 曲线的图例, 格式与 PromQL 的标签选择器一致
+func GraphSeriesName(labels map[string]interface{}) string {
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		if key == "__name__" {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, fmt.Sprintf("%s=%q", key, fmt.Sprint(labels[key])))
+	}
+	name, _ := labels["__name__"].(string)
+	return fmt.Sprintf("%s{%s}", name, strings.Join(pairs, ", "))
+}
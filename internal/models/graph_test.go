@@ -0,0 +1,60 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGraphWindow(t *testing.T) {
+	trigger := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	window := 30 * time.Minute
+
+	// 触发不久, 结束时间为当前时间
+	now := trigger.Add(5 * time.Minute)
+	start, end, step := GraphWindow(trigger.Unix(), 0, false, window, now)
+	if !start.Equal(trigger.Add(-window)) || !end.Equal(now) || step != graphMinStep {
+		t.Fatalf("firing: start=%v end=%v step=%v", start, end, step)
+	}
+
+	// 已恢复, 延长至恢复后 window/2
+	now = trigger.Add(5 * time.Hour)
+	recoverAt := trigger.Add(2 * time.Hour)
+	start, end, step = GraphWindow(trigger.Unix(), recoverAt.Unix(), true, window, now)
+	if !start.Equal(trigger.Add(-window)) || !end.Equal(recoverAt.Add(window/2)) {
+		t.Fatalf("recovered: start=%v end=%v", start, end)
+	}
+	if points := end.Sub(start) / step; points > graphMaxPoints {
+		t.Fatalf("too many points: %d", points)
+	}
+
+	// 跨度不超过 24 小时
+	recoverAt = trigger.Add(72 * time.Hour)
+	start, end, _ = GraphWindow(trigger.Unix(), recoverAt.Unix(), true, window, recoverAt.Add(time.Hour))
+	if end.Sub(start) != graphMaxSpan {
+		t.Fatalf("span = %v", end.Sub(start))
+	}
+}
+
+func TestGraphSeriesMatch(t *testing.T) {
+	event := map[string]interface{}{"instance": "10.0.0.1:9100", "job": "node", "severity": "P0", "value": 91.5}
+
+	if !GraphSeriesMatch(event, map[string]interface{}{"instance": "10.0.0.1:9100", "job": "node"}) {
+		t.Fatal("expected series to match")
+	}
+	if GraphSeriesMatch(event, map[string]interface{}{"instance": "10.0.0.2:9100", "job": "node"}) {
+		t.Fatal("expected other instance not to match")
+	}
+	if GraphSeriesMatch(event, map[string]interface{}{"instance": "10.0.0.1:9100", "device": "sda"}) {
+		t.Fatal("expected series with extra label not to match")
+	}
+}
+
+func TestGraphSeriesName(t *testing.T) {
+	got := GraphSeriesName(map[string]interface{}{"__name__": "up", "job": "node", "instance": "a:9100"})
+	if got != `up{instance="a:9100", job="node"}` {
+		t.Fatalf("GraphSeriesName() = %s", got)
+	}
+	if got := GraphSeriesName(map[string]interface{}{"job": "node"}); got != `{job="node"}` {
+		t.Fatalf("GraphSeriesName() = %s", got)
+	}
+}
@@ -0,0 +1,233 @@
+package models
+
+import "slices"
+
+// IncidentStatus 故障状态
+type IncidentStatus string
+
+const (
+	IncidentTriggered    IncidentStatus = "triggered"    // 已触发
+	IncidentAcknowledged IncidentStatus = "acknowledged" // 已响应
+	IncidentMitigated    IncidentStatus = "mitigated"    // 已止损
+	IncidentResolved     IncidentStatus = "resolved"     // 已解决
+)
+
+// 故障来源
+const (
+	IncidentSourceManual = "manual" // 手动创建
+	IncidentSourceAuto   = "auto"   // 故障中心自动创建
+)
+
+// 时间线类型
+const (
+	TimelineStatusChange    = "status_change"    // 状态变更
+	TimelineEventLinked     = "event_linked"     // 关联告警事件
+	TimelineEventTransition = "event_transition" // 关联的告警事件状态变化
+	TimelineComment         = "comment"          // 评论
+	TimelineAssign          = "assign"           // 指挥官/响应人变更
+	TimelineBroadcast       = "broadcast"        // 状态通报
+)
+
+// Incident 故障, 将多个告警事件聚合为一次需要统一处理的故障
+type Incident struct {
+	TenantId       string         `json:"tenantId"`
+	ID             string         `json:"id"`
+	Title          string         `json:"title"`
+	Description    string         `json:"description"`
+	Status         IncidentStatus `json:"status"`
+	Severity       string         `json:"severity"`
+	Commander      string         `json:"commander"`                                           // 故障指挥官
+	Responders     []string       `json:"responders" gorm:"column:responders;serializer:json"` // 响应人
+	FaultCenterId  string         `json:"faultCenterId"`
+	Source         string         `json:"source"`                                            // manual 手动创建, auto 自动创建
+	GroupKey       string         `json:"groupKey"`                                          // 自动创建时的分组标识, 故障中心 + 规则
+	NoticeIds      []string       `json:"noticeIds" gorm:"column:noticeIds;serializer:json"` // 状态通报的通知对象
+	CreateBy       string         `json:"createBy"`
+	CreateAt       int64          `json:"createAt"`
+	UpdateAt       int64          `json:"updateAt"`
+	AcknowledgedAt int64          `json:"acknowledgedAt"`
+	MitigatedAt    int64          `json:"mitigatedAt"`
+	ResolvedAt     int64          `json:"resolvedAt"`
+	EventCount     int64          `json:"eventCount" gorm:"-"`
+}
+
+func (i *Incident) TableName() string {
+	return "w8t_incident"
+}
+
+// IsOpen 未解决的故障
+func (i *Incident) IsOpen() bool {
+	return i.Status != IncidentResolved
+}
+
+// TransitionStatus 变更故障状态并记录对应的时间点, 已解决的故障允许重新打开为已触发
+func (i *Incident) TransitionStatus(status IncidentStatus, now int64) bool {
+	if !slices.Contains([]IncidentStatus{IncidentTriggered, IncidentAcknowledged, IncidentMitigated, IncidentResolved}, status) {
+		return false
+	}
+	if i.Status == status {
+		return false
+	}
+
+	i.Status = status
+	switch status {
+	case IncidentTriggered:
+		i.AcknowledgedAt, i.MitigatedAt, i.ResolvedAt = 0, 0, 0
+	case IncidentAcknowledged:
+		if i.AcknowledgedAt == 0 {
+			i.AcknowledgedAt = now
+		}
+	case IncidentMitigated:
+		if i.AcknowledgedAt == 0 {
+			i.AcknowledgedAt = now
+		}
+		i.MitigatedAt = now
+	case IncidentResolved:
+		if i.AcknowledgedAt == 0 {
+			i.AcknowledgedAt = now
+		}
+		if i.MitigatedAt == 0 {
+			i.MitigatedAt = now
+		}
+		i.ResolvedAt = now
+	}
+	i.UpdateAt = now
+
+	return true
+}
+
+// IncidentEvent 故障关联的告警事件
+type IncidentEvent struct {
+	TenantId    string `json:"tenantId"`
+	IncidentId  string `json:"incidentId"`
+	Fingerprint string `json:"fingerprint"`
+	EventId     string `json:"eventId"`
+	RuleId      string `json:"ruleId"`
+	RuleName    string `json:"ruleName"`
+	Severity    string `json:"severity"`
+	Status      string `json:"status"` // 最近一次同步的事件状态
+	LinkedAt    int64  `json:"linkedAt"`
+	LinkedBy    string `json:"linkedBy"`
+}
+
+func (i *IncidentEvent) TableName() string {
+	return "w8t_incident_event"
+}
+
+// IncidentGroupKey 自动创建故障的分组标识, 同一故障中心同一规则的告警归入同一故障
+func IncidentGroupKey(faultCenterId, ruleId string) string {
+	return faultCenterId + ":" + ruleId
+}
+
+// OpenIncidents 一次同步内未解决的故障及其关联的告警, 按分组标识及告警指纹查找, 避免逐个告警查询数据库
+type OpenIncidents struct {
+	incidents []Incident
+	events    map[string]map[string]IncidentEvent // 故障 ID -> 告警指纹 -> 关联的告警
+}
+
+// NewOpenIncidents 已解决的故障不参与同步
+func NewOpenIncidents(incidents []Incident, events []IncidentEvent) *OpenIncidents {
+	o := &OpenIncidents{events: make(map[string]map[string]IncidentEvent)}
+	for _, incident := range incidents {
+		if incident.IsOpen() {
+			o.Add(incident)
+		}
+	}
+	for _, e := range events {
+		o.SetEvent(e)
+	}
+	return o
+}
+
+// Add 记录新创建的故障
+func (o *OpenIncidents) Add(incident Incident) {
+	o.incidents = append(o.incidents, incident)
+	if _, ok := o.events[incident.ID]; !ok {
+		o.events[incident.ID] = make(map[string]IncidentEvent)
+	}
+}
+
+// ByGroupKey 获取分组标识对应的未解决故障, 存在多个时取最近创建的
+func (o *OpenIncidents) ByGroupKey(groupKey string) (Incident, bool) {
+	var (
+		found Incident
+		ok    bool
+	)
+	for _, incident := range o.incidents {
+		if incident.GroupKey == groupKey && (!ok || incident.CreateAt > found.CreateAt) {
+			found, ok = incident, true
+		}
+	}
+	return found, ok
+}
+
+// ByFingerprint 获取关联了该告警的未解决故障, faultCenterId 不为空时仅返回该故障中心的故障
+func (o *OpenIncidents) ByFingerprint(fingerprint, faultCenterId string) []Incident {
+	var list []Incident
+	for _, incident := range o.incidents {
+		if faultCenterId != "" && incident.FaultCenterId != faultCenterId {
+			continue
+		}
+		if _, ok := o.events[incident.ID][fingerprint]; ok {
+			list = append(list, incident)
+		}
+	}
+	return list
+}
+
+// LinkedEvent 获取故障已关联的告警
+func (o *OpenIncidents) LinkedEvent(incidentId, fingerprint string) (IncidentEvent, bool) {
+	e, ok := o.events[incidentId][fingerprint]
+	return e, ok
+}
+
+// SetEvent 记录故障关联告警的最新状态, 忽略不在同步范围内的故障
+func (o *OpenIncidents) SetEvent(e IncidentEvent) {
+	if linked, ok := o.events[e.IncidentId]; ok {
+		linked[e.Fingerprint] = e
+	}
+}
+
+// AllRecovered 故障关联的告警是否均已恢复
+func (o *OpenIncidents) AllRecovered(incidentId string) bool {
+	for _, e := range o.events[incidentId] {
+		if e.Status != string(StateRecovered) {
+			return false
+		}
+	}
+	return len(o.events[incidentId]) > 0
+}
+
+// IncidentTimeline 故障时间线
+type IncidentTimeline struct {
+	TenantId   string `json:"tenantId"`
+	ID         string `json:"id"`
+	IncidentId string `json:"incidentId"`
+	Type       string `json:"type"`
+	Content    string `json:"content"`
+	Operator   string `json:"operator"`
+	CreateAt   int64  `json:"createAt"`
+}
+
+func (i *IncidentTimeline) TableName() string {
+	return "w8t_incident_timeline"
+}
+
+// IncidentConfig 故障中心自动创建故障的配置
+type IncidentConfig struct {
+	AutoCreate *bool    `json:"autoCreate"` // 是否根据告警分组自动创建故障
+	Severities []string `json:"severities"` // 触发自动创建的告警等级, 为空时不限制
+	NoticeIds  []string `json:"noticeIds"`  // 自动创建的故障默认的状态通报对象
+}
+
+func (c IncidentConfig) GetAutoCreate() bool {
+	if c.AutoCreate == nil {
+		return false
+	}
+	return *c.AutoCreate
+}
+
+// Match 告警等级是否满足自动创建故障的条件
+func (c IncidentConfig) Match(severity string) bool {
+	return len(c.Severities) == 0 || slices.Contains(c.Severities, severity)
+}
@@ -0,0 +1,93 @@
+package models
+
+import "testing"
+
+func TestIncidentGroupKey(t *testing.T) {
+	cases := []struct {
+		name                   string
+		fcA, ruleA, fcB, ruleB string
+		same                   bool
+	}{
+		{"same fault center and rule", "fc1", "r1", "fc1", "r1", true},
+		{"different rule", "fc1", "r1", "fc1", "r2", false},
+		{"same rule in another fault center", "fc1", "r1", "fc2", "r1", false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := IncidentGroupKey(c.fcA, c.ruleA) == IncidentGroupKey(c.fcB, c.ruleB); got != c.same {
+				t.Fatalf("expected same group: %v, got %v", c.same, got)
+			}
+		})
+	}
+}
+
+func TestOpenIncidents(t *testing.T) {
+	groupKey := IncidentGroupKey("fc1", "r1")
+	incidents := []Incident{
+		{ID: "old", FaultCenterId: "fc1", GroupKey: groupKey, Status: IncidentAcknowledged, CreateAt: 100},
+		{ID: "new", FaultCenterId: "fc1", GroupKey: groupKey, Status: IncidentTriggered, CreateAt: 200},
+		{ID: "resolved", FaultCenterId: "fc1", GroupKey: IncidentGroupKey("fc1", "r2"), Status: IncidentResolved, CreateAt: 300},
+		{ID: "other", FaultCenterId: "fc2", GroupKey: IncidentGroupKey("fc2", "r1"), Status: IncidentTriggered, CreateAt: 300},
+	}
+	events := []IncidentEvent{
+		{IncidentId: "new", Fingerprint: "a", Status: string(StateAlerting)},
+		{IncidentId: "new", Fingerprint: "b", Status: string(StateRecovered)},
+		{IncidentId: "resolved", Fingerprint: "c", Status: string(StateAlerting)},
+		{IncidentId: "other", Fingerprint: "a", Status: string(StateAlerting)},
+	}
+
+	cases := []struct {
+		name string
+		run  func(o *OpenIncidents) bool
+	}{
+		{"group key picks the latest open incident", func(o *OpenIncidents) bool {
+			incident, ok := o.ByGroupKey(groupKey)
+			return ok && incident.ID == "new"
+		}},
+		{"resolved incident is not reused", func(o *OpenIncidents) bool {
+			_, ok := o.ByGroupKey(IncidentGroupKey("fc1", "r2"))
+			return !ok && len(o.ByFingerprint("c", "")) == 0
+		}},
+		{"fingerprint lookup across fault centers", func(o *OpenIncidents) bool {
+			return len(o.ByFingerprint("a", "")) == 2 && len(o.ByFingerprint("a", "fc1")) == 1
+		}},
+		{"new incident is found in the same sync", func(o *OpenIncidents) bool {
+			o.Add(Incident{ID: "created", FaultCenterId: "fc1", GroupKey: IncidentGroupKey("fc1", "r3"), Status: IncidentTriggered})
+			o.SetEvent(IncidentEvent{IncidentId: "created", Fingerprint: "d", Status: string(StateAlerting)})
+			incident, ok := o.ByGroupKey(IncidentGroupKey("fc1", "r3"))
+			return ok && incident.ID == "created" && len(o.ByFingerprint("d", "fc1")) == 1
+		}},
+		{"recovered event reopens when it fires again", func(o *OpenIncidents) bool {
+			e, ok := o.LinkedEvent("new", "b")
+			if !ok || e.Status != string(StateRecovered) {
+				return false
+			}
+			e.Status = string(StateAlerting)
+			o.SetEvent(e)
+			e, _ = o.LinkedEvent("new", "b")
+			return e.Status == string(StateAlerting) && !o.AllRecovered("new")
+		}},
+		{"incident resolves when all linked events recover", func(o *OpenIncidents) bool {
+			if o.AllRecovered("new") {
+				return false
+			}
+			o.SetEvent(IncidentEvent{IncidentId: "new", Fingerprint: "a", Status: string(StateRecovered)})
+			return o.AllRecovered("new")
+		}},
+		{"incident without linked events is not recovered", func(o *OpenIncidents) bool {
+			return !o.AllRecovered("old")
+		}},
+		{"events of incidents outside the sync are ignored", func(o *OpenIncidents) bool {
+			o.SetEvent(IncidentEvent{IncidentId: "missing", Fingerprint: "x"})
+			_, ok := o.LinkedEvent("missing", "x")
+			return !ok
+		}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if !c.run(NewOpenIncidents(incidents, events)) {
+				t.Fatalf("unexpected result")
+			}
+		})
+	}
+}
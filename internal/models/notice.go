@@ -12,8 +12,10 @@ type AlertNotice struct {
 	Routes       []Route  `json:"routes" gorm:"column:routes;serializer:json"`
 	Email        Email    `json:"email" gorm:"email;serializer:json"`
 	PhoneNumber  []string `json:"phoneNumber" gorm:"phoneNumber;serializer:json"`
-	UpdateAt     int64    `json:"updateAt"`
-	UpdateBy     string   `json:"updateBy"`
+	// 自定义 Hook 的请求配置
+	Webhook  WebhookConfig `json:"webhook" gorm:"webhook;serializer:json"`
+	UpdateAt int64         `json:"updateAt"`
+	UpdateBy string        `json:"updateBy"`
 }
 
 func (alertNotice *AlertNotice) GetDutyId() *string {
@@ -40,6 +42,8 @@ type Email struct {
 	Subject string   `json:"subject"`
 	To      []string `json:"to" gorm:"column:to;serializer:json"`
 	CC      []string `json:"cc" gorm:"column:cc;serializer:json"`
+	// 使用的 SMTP 配置名称, 为空时使用默认配置
+	SmtpProfile string `json:"smtpProfile"`
 }
 
 type NoticeRecord struct {
@@ -0,0 +1,91 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+const (
+	PhoneProviderAliyun  = "aliyun"
+	PhoneProviderTencent = "tencent"
+	PhoneProviderTwilio  = "twilio"
+
+	// PhoneCallAckDigit 语音通知中按此键认领告警
+	PhoneCallAckDigit = "1"
+)
+
+// 各服务商的默认长度限制（字符）, 阿里云、腾讯云为单个模版变量, Twilio 为整条播报或短信内容
+var (
+	voiceContentLimits = map[string]int{
+		PhoneProviderAliyun:  20,
+		PhoneProviderTencent: 20,
+		PhoneProviderTwilio:  1000,
+	}
+	smsContentLimits = map[string]int{
+		PhoneProviderAliyun:  35,
+		PhoneProviderTencent: 35,
+		PhoneProviderTwilio:  1600,
+	}
+)
+
+// VoiceContentLimit 语音通知内容的长度限制, configured 大于 0 时优先使用
+func VoiceContentLimit(provider string, configured int) int {
+	if configured > 0 {
+		return configured
+	}
+	return voiceContentLimits[provider]
+}
+
+// SmsContentLimit 短信通知内容的长度限制, configured 大于 0 时优先使用
+func SmsContentLimit(provider string, configured int) int {
+	if configured > 0 {
+		return configured
+	}
+	return smsContentLimits[provider]
+}
+
+// LimitTemplateParams 将通知内容转换为阿里云模版变量 JSON 并限制每个变量的长度
+// 内容为 JSON 对象时视为模版变量, 否则作为 content 变量
+func LimitTemplateParams(content string, max int) string {
+	params := make(map[string]any)
+	if err := json.Unmarshal([]byte(content), &params); err != nil {
+		params = map[string]any{"content": strings.TrimSpace(content)}
+	}
+
+	for key, value := range params {
+		if text, ok := value.(string); ok && max > 0 {
+			params[key] = TruncateRunes(text, max)
+		}
+	}
+
+	data, _ := json.Marshal(params)
+	return string(data)
+}
+
+// LimitContent 合并空白字符后按长度截断, 用于腾讯云模版变量及 Twilio 内容
+func LimitContent(content string, max int) string {
+	content = strings.Join(strings.Fields(content), " ")
+	if max > 0 {
+		content = TruncateRunes(content, max)
+	}
+	return content
+}
+
+// SamePhoneNumber 比较手机号, 忽略国家码及分隔符
+func SamePhoneNumber(a, b string) bool {
+	a, b = phoneDigits(a), phoneDigits(b)
+	if len(a) < 7 || len(b) < 7 {
+		return false
+	}
+	return strings.HasSuffix(a, b) || strings.HasSuffix(b, a)
+}
+
+func phoneDigits(phone string) string {
+	var b strings.Builder
+	for _, r := range phone {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,35 @@
+package models
+
+import "testing"
+
+func TestLimitTemplateParams(t *testing.T) {
+	if got := LimitTemplateParams(`{"rule":"CPU 使用率过高告警","level":"P0"}`, 4); got != `{"level":"P0","rule":"CPU "}` {
+		t.Fatalf("unexpected params: %s", got)
+	}
+	if got := LimitTemplateParams(" 磁盘空间不足 \n", 4); got != `{"content":"磁盘空间"}` {
+		t.Fatalf("unexpected params: %s", got)
+	}
+	if got := LimitContent("告警:\n  CPU 过高", 0); got != "告警: CPU 过高" {
+		t.Fatalf("unexpected content: %q", got)
+	}
+	if VoiceContentLimit(PhoneProviderAliyun, 0) != 20 || VoiceContentLimit(PhoneProviderAliyun, 50) != 50 || SmsContentLimit(PhoneProviderTwilio, 0) != 1600 {
+		t.Fatal("unexpected content limits")
+	}
+}
+
+func TestSamePhoneNumber(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want bool
+	}{
+		{"+8613800138000", "13800138000", true},
+		{"138-0013-8000", "13800138000", true},
+		{"13800138000", "13800138001", false},
+		{"123", "123", false},
+	}
+	for _, c := range cases {
+		if got := SamePhoneNumber(c.a, c.b); got != c.want {
+			t.Fatalf("SamePhoneNumber(%q, %q) = %v, want %v", c.a, c.b, got, c.want)
+		}
+	}
+}
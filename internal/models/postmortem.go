@@ -0,0 +1,116 @@
+package models
+
+// 复盘报告状态
+const (
+	PostmortemDraft = "draft" // 草稿, 允许继续编辑
+	PostmortemFinal = "final" // 已定稿
+)
+
+// 复盘报告版本来源
+const (
+	PostmortemSourceAi     = "ai"     // AI 生成
+	PostmortemSourceManual = "manual" // 人工编辑
+)
+
+// Postmortem 故障复盘报告
+type Postmortem struct {
+	TenantId       string            `json:"tenantId"`
+	ID             string            `json:"id"`
+	Title          string            `json:"title"`
+	IncidentId     string            `json:"incidentId"`    // 基于故障生成时的故障 ID
+	FaultCenterId  string            `json:"faultCenterId"` // 基于时间窗口生成时的故障中心
+	StartAt        int64             `json:"startAt"`
+	EndAt          int64             `json:"endAt"`
+	Status         string            `json:"status"`
+	CurrentVersion int               `json:"currentVersion"`
+	Metrics        PostmortemMetrics `json:"metrics" gorm:"column:metrics;serializer:json"`
+	CreateBy       string            `json:"createBy"`
+	CreateAt       int64             `json:"createAt"`
+	UpdateAt       int64             `json:"updateAt"`
+	FinalizedBy    string            `json:"finalizedBy"`
+	FinalizedAt    int64             `json:"finalizedAt"`
+}
+
+func (p *Postmortem) TableName() string {
+	return "w8t_postmortem"
+}
+
+// PostmortemVersion 复盘报告版本
+type PostmortemVersion struct {
+	TenantId     string `json:"tenantId"`
+	PostmortemId string `json:"postmortemId"`
+	Version      int    `json:"version"`
+	Content      string `json:"content" gorm:"type:longtext"` // Markdown 内容
+	Source       string `json:"source"`                       // ai 或 manual
+	CreateBy     string `json:"createBy"`
+	CreateAt     int64  `json:"createAt"`
+}
+
+func (p *PostmortemVersion) TableName() string {
+	return "w8t_postmortem_version"
+}
+
+// PostmortemMetrics 复盘指标, 时间单位（秒）
+type PostmortemMetrics struct {
+	EventCount        int     `json:"eventCount"`        // 告警事件数
+	AcknowledgedCount int     `json:"acknowledgedCount"` // 已认领的告警数
+	MTTA              float64 `json:"mtta"`              // 平均认领时长
+	MTTR              float64 `json:"mttr"`              // 平均恢复时长
+	MaxDuration       int64   `json:"maxDuration"`       // 最长告警持续时长
+	NoticeCount       int     `json:"noticeCount"`       // 通知发送次数
+	NoticeFailedCount int     `json:"noticeFailedCount"` // 通知发送失败次数
+	FirstTriggerTime  int64   `json:"firstTriggerTime"`  // 最早触发时间
+	LastRecoverTime   int64   `json:"lastRecoverTime"`   // 最晚恢复时间
+}
+
+// CalcPostmortemMetrics 根据历史告警及通知记录计算 MTTA/MTTR 等复盘指标
+// ackAt 大于 0 时(如故障的响应时间)作为所有未单独认领告警的认领时间
+func CalcPostmortemMetrics(events []AlertHisEvent, records []NoticeRecord, ackAt int64) PostmortemMetrics {
+	var (
+		m            = PostmortemMetrics{EventCount: len(events), NoticeCount: len(records)}
+		ackTotal     int64
+		recoverTotal int64
+		recovered    int
+	)
+
+	for _, event := range events {
+		if m.FirstTriggerTime == 0 || event.FirstTriggerTime < m.FirstTriggerTime {
+			m.FirstTriggerTime = event.FirstTriggerTime
+		}
+		if event.RecoverTime > m.LastRecoverTime {
+			m.LastRecoverTime = event.RecoverTime
+		}
+
+		confirmAt := event.ConfirmState.ConfirmActionTime
+		if !event.ConfirmState.IsOk || confirmAt == 0 {
+			confirmAt = ackAt
+		}
+		if confirmAt >= event.FirstTriggerTime && confirmAt > 0 {
+			ackTotal += confirmAt - event.FirstTriggerTime
+			m.AcknowledgedCount++
+		}
+
+		if event.RecoverTime > 0 && event.RecoverTime >= event.FirstTriggerTime {
+			duration := event.RecoverTime - event.FirstTriggerTime
+			recoverTotal += duration
+			recovered++
+			if duration > m.MaxDuration {
+				m.MaxDuration = duration
+			}
+		}
+	}
+
+	if m.AcknowledgedCount > 0 {
+		m.MTTA = float64(ackTotal) / float64(m.AcknowledgedCount)
+	}
+	if recovered > 0 {
+		m.MTTR = float64(recoverTotal) / float64(recovered)
+	}
+	for _, record := range records {
+		if record.Status != 0 {
+			m.NoticeFailedCount++
+		}
+	}
+
+	return m
+}
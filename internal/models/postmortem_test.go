@@ -0,0 +1,36 @@
+package models
+
+import "testing"
+
+func TestCalcPostmortemMetrics(t *testing.T) {
+	events := []AlertHisEvent{
+		{FirstTriggerTime: 1000, RecoverTime: 1600, ConfirmState: ConfirmState{IsOk: true, ConfirmActionTime: 1120}},
+		{FirstTriggerTime: 1200, RecoverTime: 1500},
+		// 未恢复且未单独认领, 使用故障响应时间
+		{FirstTriggerTime: 1300},
+	}
+	records := []NoticeRecord{{Status: 0}, {Status: 1}, {Status: 0}}
+
+	m := CalcPostmortemMetrics(events, records, 1400)
+	if m.EventCount != 3 || m.AcknowledgedCount != 3 {
+		t.Fatalf("unexpected counts: %+v", m)
+	}
+	// (120 + 200 + 100) / 3
+	if m.MTTA != 140 {
+		t.Fatalf("expected MTTA 140, got %.2f", m.MTTA)
+	}
+	// (600 + 300) / 2
+	if m.MTTR != 450 || m.MaxDuration != 600 {
+		t.Fatalf("expected MTTR 450 and max 600, got %.2f / %d", m.MTTR, m.MaxDuration)
+	}
+	if m.FirstTriggerTime != 1000 || m.LastRecoverTime != 1600 {
+		t.Fatalf("unexpected window: %d ~ %d", m.FirstTriggerTime, m.LastRecoverTime)
+	}
+	if m.NoticeCount != 3 || m.NoticeFailedCount != 1 {
+		t.Fatalf("unexpected notice stats: %d / %d", m.NoticeCount, m.NoticeFailedCount)
+	}
+
+	if m := CalcPostmortemMetrics(nil, nil, 0); m.MTTA != 0 || m.MTTR != 0 {
+		t.Fatalf("expected zero metrics for empty input, got %+v", m)
+	}
+}
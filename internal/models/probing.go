@@ -32,6 +32,11 @@ func (n *ProbingRule) GetRecoverNotify() *bool {
 	return n.RecoverNotify
 }
 
+// GetEventId 拨测事件没有独立的事件 ID, 以规则及首次触发时间标识同一次告警, 触发、重复及恢复通知保持一致
+func (n *ProbingEvent) GetEventId() string {
+	return fmt.Sprintf("%s-%d", n.RuleId, n.FirstTriggerTime)
+}
+
 func (n *ProbingRule) GetEnabled() *bool {
 	if n.Enabled == nil {
 		isOk := false
@@ -1,5 +1,10 @@
 package models
 
+import (
+	"slices"
+	"strings"
+)
+
 const (
 	SettingSystemAuth = 0
 	SettingLdapAuth   = 1
@@ -12,10 +17,14 @@ type Settings struct {
 	EmailConfig         emailConfig         `json:"emailConfig" gorm:"emailConfig;serializer:json"`
 	AppVersion          string              `json:"appVersion" gorm:"-"`
 	PhoneCallConfig     phoneCallConfig     `json:"phoneCallConfig" gorm:"phoneCallConfig;serializer:json"`
+	SmsConfig           smsConfig           `json:"smsConfig" gorm:"smsConfig;serializer:json"`
 	AiConfig            AiConfig            `json:"aiConfig" gorm:"aiConfig;serializer:json"`
 	LdapConfig          LdapConfig          `json:"ldapConfig" gorm:"ldapConfig;serializer:json"`
 	OidcConfig          OidcConfig          `json:"oidcConfig" gorm:"oidcConfig;serializer:json"`
 	QuickActionConfig   QuickActionConfig   `json:"quickActionConfig" gorm:"quickActionConfig;serializer:json"`
+	TelegramConfig      TelegramConfig      `json:"telegramConfig" gorm:"telegramConfig;serializer:json"`
+	PagingConfig        PagingConfig        `json:"pagingConfig" gorm:"pagingConfig;serializer:json"`
+	GraphConfig         GraphConfig         `json:"graphConfig" gorm:"graphConfig;serializer:json"`
 }
 
 type emailConfig struct {
@@ -23,14 +32,83 @@ type emailConfig struct {
 	Port          int    `json:"port"`
 	Email         string `json:"email"`
 	Token         string `json:"token"`
+	// 加密方式, 为空时在服务器支持时自动使用 STARTTLS
+	Security string `json:"security"`
+	// 发件人名称, 默认 WatchAlert
+	FromName string `json:"fromName"`
+	// 其它 SMTP 配置, 通知对象可按名称选择, 未选择时使用默认配置
+	Profiles []EmailProfile `json:"profiles"`
+}
+
+// 邮件加密方式
+const (
+	EmailSecurityStartTLS = "starttls" // 强制 STARTTLS
+	EmailSecuritySSL      = "ssl"      // SSL/TLS 连接, 通常为 465 端口
+)
+
+// EmailProfile SMTP 配置
+type EmailProfile struct {
+	Name          string `json:"name"`
+	ServerAddress string `json:"serverAddress"`
+	Port          int    `json:"port"`
+	Email         string `json:"email"`
+	Token         string `json:"token"`
+	Security      string `json:"security"`
+	FromName      string `json:"fromName"`
 }
 
+// GetProfile 获取指定名称的 SMTP 配置, 名称为空或不存在时返回默认配置
+func (e emailConfig) GetProfile(name string) EmailProfile {
+	if name != "" {
+		for _, profile := range e.Profiles {
+			if profile.Name == name {
+				return profile
+			}
+		}
+	}
+
+	return EmailProfile{
+		Name:          "default",
+		ServerAddress: e.ServerAddress,
+		Port:          e.Port,
+		Email:         e.Email,
+		Token:         e.Token,
+		Security:      e.Security,
+		FromName:      e.FromName,
+	}
+}
+
+// phoneCallConfig 语音通知配置, 服务商为 aliyun、tencent、twilio
+// Twilio 的 AccessKeyId 为 Account SID, AccessKeySecret 为 Auth Token
 type phoneCallConfig struct {
 	Provider        string `json:"provider"`
 	Endpoint        string `json:"endpoint"`
 	AccessKeyId     string `json:"accessKeyId"`
 	AccessKeySecret string `json:"accessKeySecret"`
-	TtsCode         string `json:"ttsCode"`
+	TtsCode         string `json:"ttsCode"`  // 语音模版 ID
+	AppId           string `json:"appId"`    // 腾讯云语音应用 SdkAppId
+	Region          string `json:"region"`   // 腾讯云地域, 默认 ap-guangzhou
+	From            string `json:"from"`     // Twilio 主叫号码
+	Language        string `json:"language"` // Twilio 播报语言, 默认 zh-CN
+	// 单个模版变量（Twilio 为播报内容）的最大长度, 为 0 时使用服务商默认值
+	MaxLength int `json:"maxLength"`
+	// 后端 API 地址, 配置后腾讯云、Twilio 语音通知支持按 1 认领告警
+	CallbackUrl string `json:"callbackUrl"`
+}
+
+// smsConfig 短信通知配置, 服务商为 aliyun、tencent、twilio
+type smsConfig struct {
+	Provider        string `json:"provider"`
+	Endpoint        string `json:"endpoint"`
+	AccessKeyId     string `json:"accessKeyId"`
+	AccessKeySecret string `json:"accessKeySecret"`
+	SignName        string `json:"signName"`     // 短信签名
+	TemplateCode    string `json:"templateCode"` // 短信模版 ID
+	AppId           string `json:"appId"`        // 腾讯云短信应用 SdkAppId
+	Region          string `json:"region"`       // 腾讯云地域, 默认 ap-guangzhou
+	From            string `json:"from"`         // Twilio 发送号码
+	// 单个模版变量（Twilio 为短信内容）的最大长度, 为 0 时使用服务商默认值
+	MaxLength int `json:"maxLength"`
 }
 
 // AiConfig ai config
@@ -43,6 +121,98 @@ type AiConfig struct {
 	Timeout   int    `json:"timeout"`
 	MaxTokens int    `json:"maxTokens"`
 	Prompt    string `json:"prompt"`
+	// 分析结果缓存时长(分钟), 默认 1440, 小于 0 时不缓存
+	CacheTTL     int            `json:"cacheTTL"`
+	AutoAnalysis AiAutoAnalysis `json:"autoAnalysis"`
+	// 未配置 Profiles 时 Url/AppKey/Model 使用的服务类型, 默认 OpenAI 兼容接口
+	Provider string `json:"provider"`
+	// 系统提示词, 为空时使用默认的 SRE 专家角色
+	SystemPrompt string `json:"systemPrompt"`
+	// 多个模型服务配置, 按顺序作为主用及备用
+	Profiles []AiProfile `json:"profiles"`
+	// 租户使用的模型服务及顺序, key 为租户 ID, 未配置的租户使用全部 Profiles
+	TenantProfiles map[string][]string `json:"tenantProfiles"`
+}
+
+// AI 服务类型
+const (
+	AiProviderOpenAI    = "openai"    // OpenAI 及兼容接口
+	AiProviderAzure     = "azure"     // Azure OpenAI
+	AiProviderAnthropic = "anthropic" // Anthropic Messages API
+	AiProviderOllama    = "ollama"    // Ollama 本地模型
+)
+
+// AiProfile 模型服务配置
+type AiProfile struct {
+	Name       string `json:"name"`
+	Enable     *bool  `json:"enable"`
+	Provider   string `json:"provider"`
+	Url        string `json:"url"`
+	AppKey     string `json:"appKey"`
+	Model      string `json:"model"` // Azure 中为部署名称
+	ApiVersion string `json:"apiVersion"`
+	Timeout    int    `json:"timeout"`
+	MaxTokens  int    `json:"maxTokens"`
+}
+
+func (a AiProfile) GetEnable() bool {
+	if a.Enable == nil {
+		return true
+	}
+	return *a.Enable
+}
+
+// GetProfiles 获取启用的模型服务配置, 未配置 Profiles 时使用基础配置
+func (a AiConfig) GetProfiles() []AiProfile {
+	if len(a.Profiles) == 0 {
+		return []AiProfile{{
+			Name:      "default",
+			Provider:  a.Provider,
+			Url:       a.Url,
+			AppKey:    a.AppKey,
+			Model:     a.Model,
+			Timeout:   a.Timeout,
+			MaxTokens: a.MaxTokens,
+		}}
+	}
+
+	var profiles []AiProfile
+	for _, profile := range a.Profiles {
+		if profile.GetEnable() {
+			profiles = append(profiles, profile)
+		}
+	}
+	return profiles
+}
+
+// AiAutoAnalysis 发送告警通知时自动附带 Ai 分析结果
+type AiAutoAnalysis struct {
+	Enable     *bool    `json:"enable"`
+	Severities []string `json:"severities"` // 需要附带分析的告警等级, 为空时不限制
+}
+
+func (a AiAutoAnalysis) GetEnable() bool {
+	if a.Enable == nil {
+		return false
+	}
+	return *a.Enable
+}
+
+// Match 告警等级是否需要附带分析结果
+func (a AiAutoAnalysis) Match(severity string) bool {
+	return a.GetEnable() && (len(a.Severities) == 0 || slices.Contains(a.Severities, severity))
+}
+
+// GetCacheTTL 分析结果缓存时长(秒), 0 表示不缓存
+func (a AiConfig) GetCacheTTL() int64 {
+	switch {
+	case a.CacheTTL < 0:
+		return 0
+	case a.CacheTTL == 0:
+		return 1440 * 60
+	default:
+		return int64(a.CacheTTL) * 60
+	}
 }
 
 type LdapConfig struct {
@@ -71,6 +241,97 @@ type QuickActionConfig struct {
 	SecretKey string `json:"secretKey"` // Token签名密钥
 }
 
+// TelegramConfig Telegram Bot 配置, 通知对象的 Hook 为接收消息的 Chat ID
+type TelegramConfig struct {
+	BotToken string `json:"botToken"`
+	// Bot API 地址, 默认 https://api.telegram.org, 可配置为反向代理地址
+	ApiUrl string `json:"apiUrl"`
+	// 回调 Webhook 的校验密钥, 需与 setWebhook 的 secret_token 一致, 为空时不附带操作按钮
+	WebhookSecret string `json:"webhookSecret"`
+}
+
+func (t TelegramConfig) GetApiUrl() string {
+	if t.ApiUrl == "" {
+		return "https://api.telegram.org"
+	}
+	return strings.TrimSuffix(t.ApiUrl, "/")
+}
+
+// PagingConfig PagerDuty、Opsgenie 回传认领状态的 Webhook 配置, 为空时不接收对应平台的回调
+type PagingConfig struct {
+	// PagerDuty Webhook v3 订阅的签名密钥
+	PagerDutyWebhookSecret string `json:"pagerDutyWebhookSecret"`
+	// Opsgenie Webhook 集成需添加请求头 X-Webhook-Token, 值与此一致
+	OpsgenieWebhookToken string `json:"opsgenieWebhookToken"`
+}
+
+const (
+	GraphStorageLocal = "local"
+	GraphStorageS3    = "s3"
+)
+
+// GraphConfig 指标告警通知附带的趋势图配置
+type GraphConfig struct {
+	Enable *bool `json:"enable"`
+	// 趋势图访问地址前缀, 即 WatchAlert 后端地址, 为空时使用快捷操作的 ApiUrl
+	BaseUrl string `json:"baseUrl"`
+	// 存储方式 local 或 s3(兼容 S3 协议的对象存储), 默认 local
+	Storage string `json:"storage"`
+	// 本地存储目录, 默认 data/graphs
+	LocalPath string `json:"localPath"`
+	// 图片保留天数, 默认 7 天, 对象存储请配置存储桶的生命周期规则
+	RetentionDays int      `json:"retentionDays"`
+	S3            S3Config `json:"s3"`
+	// 触发时间前后的查询时长(分钟), 默认 30
+	WindowMinutes int `json:"windowMinutes"`
+}
+
+type S3Config struct {
+	Endpoint        string `json:"endpoint"`
+	Region          string `json:"region"`
+	Bucket          string `json:"bucket"`
+	AccessKeyId     string `json:"accessKeyId"`
+	AccessKeySecret string `json:"accessKeySecret"`
+	// 使用 Path-Style 访问存储桶, MinIO 等需开启
+	PathStyle bool `json:"pathStyle"`
+}
+
+func (g GraphConfig) GetEnable() bool {
+	if g.Enable == nil {
+		return false
+	}
+
+	return *g.Enable
+}
+
+func (g GraphConfig) GetStorage() string {
+	if g.Storage == "" {
+		return GraphStorageLocal
+	}
+	return g.Storage
+}
+
+func (g GraphConfig) GetLocalPath() string {
+	if g.LocalPath == "" {
+		return "data/graphs"
+	}
+	return g.LocalPath
+}
+
+func (g GraphConfig) GetRetentionDays() int {
+	if g.RetentionDays <= 0 {
+		return 7
+	}
+	return g.RetentionDays
+}
+
+func (g GraphConfig) GetWindowMinutes() int {
+	if g.WindowMinutes <= 0 {
+		return 30
+	}
+	return g.WindowMinutes
+}
+
 func (a AiConfig) GetEnable() bool {
 	if a.Enable == nil {
 		return false
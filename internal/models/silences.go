@@ -1,17 +1,50 @@
 package models
 
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+const (
+	// 静默类型
+	SilenceTypeOnce      = "once"      // 单次静默, 使用 StartsAt ~ EndsAt
+	SilenceTypeRecurring = "recurring" // 周期静默, StartsAt ~ EndsAt 为整体有效期
+
+	// 周期静默的计划方式
+	RecurrenceModeCron   = "cron"   // Cron 表达式指定每次开始时间, 配合 Duration 使用
+	RecurrenceModeWeekly = "weekly" // 按星期几 + 每日时间段
+
+	// 静默状态
+	SilenceStatusPending = 0 // 未生效
+	SilenceStatusActive  = 1 // 进行中
+	SilenceStatusExpired = 2 // 已失效
+
+	// 静默匹配条件的版本, 0 为引入 =~ / !~ 之前保存的规则, 其 = / != 按正则匹配, 启动时迁移
+	SilenceMatcherVersion = 1
+)
+
 type AlertSilences struct {
-	TenantId      string         `json:"tenantId"`
-	Name          string         `json:"name"`
-	ID            string         `json:"id"`
-	Labels        []SilenceLabel `json:"labels" gorm:"labels;serializer:json"`
-	StartsAt      int64          `json:"startsAt"`
-	UpdateBy      string         `json:"updateBy"`
-	EndsAt        int64          `json:"endsAt"`
-	UpdateAt      int64          `json:"updateAt"`
-	FaultCenterId string         `json:"faultCenterId"`
-	Comment       string         `json:"comment"`
-	Status        int            `json:"status"` // 0 未生效, 1 进行中, 2 已失效
+	TenantId      string            `json:"tenantId"`
+	Name          string            `json:"name"`
+	ID            string            `json:"id"`
+	Labels        []SilenceLabel    `json:"labels" gorm:"labels;serializer:json"`
+	StartsAt      int64             `json:"startsAt"`
+	UpdateBy      string            `json:"updateBy"`
+	EndsAt        int64             `json:"endsAt"`
+	UpdateAt      int64             `json:"updateAt"`
+	FaultCenterId string            `json:"faultCenterId"`
+	Comment       string            `json:"comment"`
+	Status        int               `json:"status"` // 0 未生效, 1 进行中, 2 已失效
+	Type          string            `json:"type"`   // once 单次静默, recurring 周期静默
+	Recurrence    SilenceRecurrence `json:"recurrence" gorm:"recurrence;serializer:json"`
+	// 周期静默当前生效的时间窗口, 由 consumer 每个周期计算
+	ActiveStartsAt int64 `json:"activeStartsAt" gorm:"-"`
+	ActiveEndsAt   int64 `json:"activeEndsAt" gorm:"-"`
+	// 匹配条件的版本, 见 SilenceMatcherVersion
+	MatcherVersion int `json:"-" gorm:"default:0"`
 }
 
 type SilenceLabel struct {
@@ -19,3 +52,195 @@ type SilenceLabel struct {
 	Value    string `json:"value"`
 	Operator string `json:"operator"`
 }
+
+// SilenceRecurrence 周期静默计划
+type SilenceRecurrence struct {
+	Mode      string   `json:"mode"`      // cron 或 weekly
+	Cron      string   `json:"cron"`      // 每次静默的开始时间, 标准 5 位 Cron 表达式
+	Duration  int64    `json:"duration"`  // 每次静默持续时间，单位（分钟）, cron 模式使用
+	Week      []string `json:"week"`      // 星期, 如 Monday, weekly 模式使用
+	StartTime int      `json:"startTime"` // 每日开始时间, 当日秒数
+	EndTime   int      `json:"endTime"`   // 每日结束时间, 当日秒数, 小于开始时间时表示跨天
+	TimeZone  string   `json:"timeZone"`  // 时区, 如 Asia/Shanghai, 为空时使用服务器时区
+}
+
+// SilenceOccurrence 静默的一次生效时间窗口
+type SilenceOccurrence struct {
+	StartsAt int64 `json:"startsAt"`
+	EndsAt   int64 `json:"endsAt"`
+}
+
+func (a AlertSilences) IsRecurring() bool {
+	return a.Type == SilenceTypeRecurring
+}
+
+// Validate 校验静默时间配置
+func (a AlertSilences) Validate() error {
+	if !a.IsRecurring() {
+		if a.EndsAt <= a.StartsAt {
+			return fmt.Errorf("静默结束时间必须晚于开始时间")
+		}
+		return nil
+	}
+
+	if a.EndsAt > 0 && a.EndsAt <= a.StartsAt {
+		return fmt.Errorf("静默有效期结束时间必须晚于开始时间")
+	}
+
+	return a.Recurrence.Validate()
+}
+
+// EvalStatus 计算静默在指定时间的状态, 周期静默同时刷新当前生效的时间窗口
+func (a *AlertSilences) EvalStatus(now time.Time) int {
+	ts := now.Unix()
+	if !a.IsRecurring() {
+		switch {
+		case a.EndsAt <= ts:
+			return SilenceStatusExpired
+		case a.StartsAt > ts:
+			return SilenceStatusPending
+		default:
+			return SilenceStatusActive
+		}
+	}
+
+	a.ActiveStartsAt, a.ActiveEndsAt = 0, 0
+	if a.EndsAt > 0 && a.EndsAt <= ts {
+		return SilenceStatusExpired
+	}
+
+	occurrences, err := a.Occurrences(now, 1)
+	if err == nil && len(occurrences) > 0 && occurrences[0].StartsAt <= ts {
+		a.ActiveStartsAt, a.ActiveEndsAt = occurrences[0].StartsAt, occurrences[0].EndsAt
+		return SilenceStatusActive
+	}
+
+	return SilenceStatusPending
+}
+
+// GetWindow 获取当前的静默时间窗口, 周期静默返回当前生效的窗口
+func (a AlertSilences) GetWindow() (int64, int64) {
+	if a.IsRecurring() && a.ActiveEndsAt > 0 {
+		return a.ActiveStartsAt, a.ActiveEndsAt
+	}
+	return a.StartsAt, a.EndsAt
+}
+
+// Occurrences 获取从 from 开始（包含正在生效的窗口）最多 n 次静默时间窗口
+func (a AlertSilences) Occurrences(from time.Time, n int) ([]SilenceOccurrence, error) {
+	if !a.IsRecurring() {
+		if a.EndsAt <= from.Unix() {
+			return nil, nil
+		}
+		return []SilenceOccurrence{{StartsAt: a.StartsAt, EndsAt: a.EndsAt}}, nil
+	}
+
+	var result []SilenceOccurrence
+	err := a.Recurrence.iterate(from, func(start, end time.Time) bool {
+		s, e := start.Unix(), end.Unix()
+		if a.EndsAt > 0 && s >= a.EndsAt {
+			return false
+		}
+		if e <= a.StartsAt {
+			return true
+		}
+
+		// 按有效期裁剪窗口
+		s = max(s, a.StartsAt)
+		if a.EndsAt > 0 {
+			e = min(e, a.EndsAt)
+		}
+		result = append(result, SilenceOccurrence{StartsAt: s, EndsAt: e})
+		return len(result) < n
+	})
+
+	return result, err
+}
+
+// Validate 校验周期静默计划
+func (r SilenceRecurrence) Validate() error {
+	if _, err := r.location(); err != nil {
+		return fmt.Errorf("无效的时区 %s", r.TimeZone)
+	}
+
+	switch r.Mode {
+	case RecurrenceModeCron:
+		if _, err := cron.ParseStandard(r.Cron); err != nil {
+			return fmt.Errorf("无效的 Cron 表达式 %s, err: %s", r.Cron, err.Error())
+		}
+		if r.Duration <= 0 {
+			return fmt.Errorf("静默持续时间必须大于 0")
+		}
+	case RecurrenceModeWeekly:
+		if len(r.Week) == 0 {
+			return fmt.Errorf("至少需要选择一天")
+		}
+		for _, day := range r.Week {
+			if !slices.Contains(weekdays, day) {
+				return fmt.Errorf("无效的星期 %s", day)
+			}
+		}
+		if r.StartTime < 0 || r.StartTime >= 86400 || r.EndTime < 0 || r.EndTime > 86400 || r.StartTime == r.EndTime {
+			return fmt.Errorf("无效的每日静默时间段")
+		}
+	default:
+		return fmt.Errorf("不支持的周期静默类型 %s", r.Mode)
+	}
+
+	return nil
+}
+
+var weekdays = []string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"}
+
+func (r SilenceRecurrence) location() (*time.Location, error) {
+	if r.TimeZone == "" {
+		return time.Local, nil
+	}
+	return time.LoadLocation(r.TimeZone)
+}
+
+// iterate 按时间顺序遍历结束时间晚于 from 的静默窗口, fn 返回 false 时停止
+func (r SilenceRecurrence) iterate(from time.Time, fn func(start, end time.Time) bool) error {
+	if err := r.Validate(); err != nil {
+		return err
+	}
+	loc, _ := r.location()
+	from = from.In(loc)
+
+	switch r.Mode {
+	case RecurrenceModeCron:
+		schedule, _ := cron.ParseStandard(r.Cron)
+		duration := time.Duration(r.Duration) * time.Minute
+		// 从 from - duration 开始查找, 以包含正在生效的窗口
+		start := schedule.Next(from.Add(-duration))
+		// 最多遍历一年内的窗口, 避免无效的计划导致死循环
+		for limit := from.AddDate(1, 0, 0); !start.IsZero() && start.Before(limit); start = schedule.Next(start) {
+			if !fn(start, start.Add(duration)) {
+				return nil
+			}
+		}
+	case RecurrenceModeWeekly:
+		// 从前一天开始, 以包含跨天且正在生效的窗口
+		day := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, loc).AddDate(0, 0, -1)
+		for i := 0; i < 370; i++ {
+			d := day.AddDate(0, 0, i)
+			if !slices.Contains(r.Week, d.Weekday().String()) {
+				continue
+			}
+
+			start := time.Date(d.Year(), d.Month(), d.Day(), 0, 0, r.StartTime, 0, loc)
+			end := time.Date(d.Year(), d.Month(), d.Day(), 0, 0, r.EndTime, 0, loc)
+			if r.EndTime < r.StartTime {
+				end = end.AddDate(0, 0, 1)
+			}
+			if !end.After(from) {
+				continue
+			}
+			if !fn(start, end) {
+				return nil
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,72 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRecurringSilenceWeeklyWindow(t *testing.T) {
+	loc, _ := time.LoadLocation("Asia/Shanghai")
+	silence := AlertSilences{
+		Type:     SilenceTypeRecurring,
+		StartsAt: time.Date(2024, 1, 1, 0, 0, 0, 0, loc).Unix(),
+		Recurrence: SilenceRecurrence{
+			Mode:      RecurrenceModeWeekly,
+			Week:      []string{"Saturday"},
+			StartTime: 23 * 3600,
+			EndTime:   2 * 3600,
+			TimeZone:  "Asia/Shanghai",
+		},
+	}
+	if err := silence.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 2024-01-07 是周日, 01:00 处于周六 23:00 开始的跨天窗口内
+	now := time.Date(2024, 1, 7, 1, 0, 0, 0, loc)
+	if status := silence.EvalStatus(now); status != SilenceStatusActive {
+		t.Fatalf("expected active, got %d", status)
+	}
+	if silence.ActiveEndsAt != time.Date(2024, 1, 7, 2, 0, 0, 0, loc).Unix() {
+		t.Fatalf("unexpected active window end %d", silence.ActiveEndsAt)
+	}
+
+	if status := silence.EvalStatus(now.Add(2 * time.Hour)); status != SilenceStatusPending {
+		t.Fatalf("expected pending between windows, got %d", status)
+	}
+
+	occurrences, err := silence.Occurrences(now.Add(2*time.Hour), 2)
+	if err != nil || len(occurrences) != 2 {
+		t.Fatalf("expected 2 occurrences, got %v %v", occurrences, err)
+	}
+	if occurrences[0].StartsAt != time.Date(2024, 1, 13, 23, 0, 0, 0, loc).Unix() {
+		t.Fatalf("unexpected next occurrence %v", time.Unix(occurrences[0].StartsAt, 0).In(loc))
+	}
+}
+
+func TestRecurringSilenceCronValidityRange(t *testing.T) {
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	silence := AlertSilences{
+		Type:     SilenceTypeRecurring,
+		StartsAt: start.Unix(),
+		EndsAt:   start.AddDate(0, 0, 2).Unix(),
+		Recurrence: SilenceRecurrence{
+			Mode:     RecurrenceModeCron,
+			Cron:     "30 3 * * *",
+			Duration: 60,
+			TimeZone: "UTC",
+		},
+	}
+
+	occurrences, err := silence.Occurrences(start, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(occurrences) != 2 {
+		t.Fatalf("expected occurrences limited by validity range, got %d", len(occurrences))
+	}
+
+	if status := silence.EvalStatus(start.AddDate(0, 0, 3)); status != SilenceStatusExpired {
+		t.Fatalf("expected expired, got %d", status)
+	}
+}
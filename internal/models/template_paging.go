@@ -0,0 +1,125 @@
+package models
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+const (
+	PagerDutyEventsUrl = "https://events.pagerduty.com/v2/enqueue"
+	OpsgenieApiUrl     = "https://api.opsgenie.com"
+
+	// PagerDuty 事件类型
+	PagerDutyActionTrigger     = "trigger"
+	PagerDutyActionAcknowledge = "acknowledge"
+	PagerDutyActionResolve     = "resolve"
+
+	// Opsgenie 告警操作
+	OpsgenieActionCreate      = "create"
+	OpsgenieActionAcknowledge = "acknowledge"
+	OpsgenieActionClose       = "close"
+)
+
+// PagerDutyEvent Events API v2 事件, RoutingKey 由发送时按通知对象的签名填充
+type PagerDutyEvent struct {
+	RoutingKey  string            `json:"routing_key"`
+	EventAction string            `json:"event_action"`
+	DedupKey    string            `json:"dedup_key"`
+	Payload     *PagerDutyPayload `json:"payload,omitempty"`
+	Client      string            `json:"client,omitempty"`
+	ClientUrl   string            `json:"client_url,omitempty"`
+	Links       []PagerDutyLink   `json:"links,omitempty"`
+}
+
+type PagerDutyPayload struct {
+	Summary       string         `json:"summary"`
+	Source        string         `json:"source"`
+	Severity      string         `json:"severity"`
+	Timestamp     string         `json:"timestamp,omitempty"`
+	Component     string         `json:"component,omitempty"`
+	Group         string         `json:"group,omitempty"`
+	Class         string         `json:"class,omitempty"`
+	CustomDetails map[string]any `json:"custom_details,omitempty"`
+}
+
+type PagerDutyLink struct {
+	Href string `json:"href"`
+	Text string `json:"text"`
+}
+
+// OpsgenieMessage Opsgenie 告警操作, 创建时携带 Alert, 认领及关闭时按 Alias 操作
+type OpsgenieMessage struct {
+	Action string         `json:"action"`
+	Alias  string         `json:"alias"`
+	Alert  *OpsgenieAlert `json:"alert,omitempty"`
+	Note   string         `json:"note,omitempty"`
+	User   string         `json:"user,omitempty"`
+}
+
+// OpsgenieAlert Alert API 创建告警请求
+type OpsgenieAlert struct {
+	Message     string            `json:"message"`
+	Alias       string            `json:"alias"`
+	Description string            `json:"description,omitempty"`
+	Priority    string            `json:"priority"`
+	Source      string            `json:"source,omitempty"`
+	Entity      string            `json:"entity,omitempty"`
+	Tags        []string          `json:"tags,omitempty"`
+	Details     map[string]string `json:"details,omitempty"`
+}
+
+// PagerDutySeverity 告警等级对应的 PagerDuty 严重程度
+func PagerDutySeverity(severity string) string {
+	switch severity {
+	case "P0":
+		return "critical"
+	case "P1":
+		return "error"
+	case "P2":
+		return "warning"
+	default:
+		return "info"
+	}
+}
+
+// OpsgeniePriority 告警等级对应的 Opsgenie 优先级, P0 对应最高优先级 P1
+func OpsgeniePriority(severity string) string {
+	switch severity {
+	case "P0":
+		return "P1"
+	case "P1":
+		return "P2"
+	case "P2":
+		return "P3"
+	default:
+		return "P4"
+	}
+}
+
+// VerifyPagerDutySignature 校验 Webhook v3 签名, 请求头可包含多个以逗号分隔的 v1 签名（密钥轮换期间）
+func VerifyPagerDutySignature(body []byte, secret, header string) bool {
+	if secret == "" || header == "" {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	expected := "v1=" + hex.EncodeToString(mac.Sum(nil))
+	for _, signature := range strings.Split(header, ",") {
+		if hmac.Equal([]byte(strings.TrimSpace(signature)), []byte(expected)) {
+			return true
+		}
+	}
+	return false
+}
+
+// TruncateRunes 按字符截断文本, 用于第三方接口的长度限制
+func TruncateRunes(text string, max int) string {
+	runes := []rune(text)
+	if len(runes) <= max {
+		return text
+	}
+	return string(runes[:max])
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+)
+
+func TestVerifyPagerDutySignature(t *testing.T) {
+	body := []byte(`{"event":{"event_type":"incident.acknowledged"}}`)
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write(body)
+	signature := "v1=" + hex.EncodeToString(mac.Sum(nil))
+
+	if !VerifyPagerDutySignature(body, "secret", signature) {
+		t.Fatal("expected valid signature")
+	}
+	// 密钥轮换期间携带多个签名
+	if !VerifyPagerDutySignature(body, "secret", "v1=deadbeef, "+signature) {
+		t.Fatal("expected valid signature among multiple")
+	}
+	if VerifyPagerDutySignature(body, "other", signature) || VerifyPagerDutySignature(body, "", signature) {
+		t.Fatal("expected invalid signature")
+	}
+}
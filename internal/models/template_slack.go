@@ -1,5 +1,12 @@
 package models
 
 type SlackMsgTemplate struct {
-	Text string `json:"text"`
+	Text        string            `json:"text"`
+	Attachments []SlackAttachment `json:"attachments,omitempty"`
+}
+
+// SlackAttachment 消息附件, 用于附带趋势图
+type SlackAttachment struct {
+	Fallback string `json:"fallback"`
+	ImageUrl string `json:"image_url"`
 }
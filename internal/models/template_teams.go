@@ -0,0 +1,111 @@
+package models
+
+import "strings"
+
+const (
+	AdaptiveCardSchema      = "http://adaptivecards.io/schemas/adaptive-card.json"
+	AdaptiveCardVersion     = "1.4"
+	AdaptiveCardContentType = "application/vnd.microsoft.card.adaptive"
+)
+
+// TeamsMessage Teams Workflows / Incoming Webhook 消息, 以附件形式携带 Adaptive Card
+type TeamsMessage struct {
+	Type        string            `json:"type"`
+	Attachments []TeamsAttachment `json:"attachments"`
+}
+
+type TeamsAttachment struct {
+	ContentType string       `json:"contentType"`
+	ContentUrl  *string      `json:"contentUrl"`
+	Content     AdaptiveCard `json:"content"`
+}
+
+type AdaptiveCard struct {
+	Schema  string            `json:"$schema"`
+	Type    string            `json:"type"`
+	Version string            `json:"version"`
+	Body    []AdaptiveElement `json:"body"`
+	Actions []AdaptiveAction  `json:"actions,omitempty"`
+	MsTeams AdaptiveCardTeams `json:"msteams"`
+}
+
+// AdaptiveElement 卡片元素, 目前使用 TextBlock、Container 及 Image
+type AdaptiveElement struct {
+	Type     string            `json:"type"`
+	Text     string            `json:"text,omitempty"`
+	Wrap     bool              `json:"wrap,omitempty"`
+	Size     string            `json:"size,omitempty"`
+	Weight   string            `json:"weight,omitempty"`
+	Color    string            `json:"color,omitempty"`
+	Spacing  string            `json:"spacing,omitempty"`
+	IsSubtle bool              `json:"isSubtle,omitempty"`
+	Style    string            `json:"style,omitempty"`
+	Bleed    bool              `json:"bleed,omitempty"`
+	Items    []AdaptiveElement `json:"items,omitempty"`
+	Url      string            `json:"url,omitempty"`
+	AltText  string            `json:"altText,omitempty"`
+}
+
+type AdaptiveAction struct {
+	Type  string `json:"type"`
+	Title string `json:"title"`
+	Url   string `json:"url,omitempty"`
+}
+
+type AdaptiveCardTeams struct {
+	Width    string         `json:"width"`
+	Entities []TeamsMention `json:"entities,omitempty"`
+}
+
+// TeamsMention 提及实体, Text 需与卡片文本中的 <at>...</at> 完全一致
+type TeamsMention struct {
+	Type      string         `json:"type"`
+	Text      string         `json:"text"`
+	Mentioned TeamsMentioned `json:"mentioned"`
+}
+
+type TeamsMentioned struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// NewTeamsMessage 将 Adaptive Card 包装为 Teams 消息
+func NewTeamsMessage(card AdaptiveCard) TeamsMessage {
+	card.Schema = AdaptiveCardSchema
+	card.Type = "AdaptiveCard"
+	card.Version = AdaptiveCardVersion
+	if card.MsTeams.Width == "" {
+		card.MsTeams.Width = "Full"
+	}
+
+	return TeamsMessage{
+		Type: "message",
+		Attachments: []TeamsAttachment{{
+			ContentType: AdaptiveCardContentType,
+			Content:     card,
+		}},
+	}
+}
+
+// NewTeamsTextMessage 构建纯文本的 Teams 消息, 每行文本为一个 TextBlock
+func NewTeamsTextMessage(title, text string) TeamsMessage {
+	var body []AdaptiveElement
+	if title != "" {
+		body = append(body, AdaptiveElement{Type: "TextBlock", Text: title, Size: "Medium", Weight: "Bolder", Wrap: true})
+	}
+	body = append(body, TeamsTextBlocks(text)...)
+
+	return NewTeamsMessage(AdaptiveCard{Body: body})
+}
+
+// TeamsTextBlocks 按行拆分为 TextBlock, TextBlock 中的单个换行符在部分 Teams 客户端不生效
+func TeamsTextBlocks(text string) []AdaptiveElement {
+	var blocks []AdaptiveElement
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		blocks = append(blocks, AdaptiveElement{Type: "TextBlock", Text: line, Wrap: true, Spacing: "None"})
+	}
+	return blocks
+}
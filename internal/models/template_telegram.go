@@ -0,0 +1,89 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const (
+	// TelegramCallbackDataMaxLen Telegram 回调数据的最大长度（字节）
+	TelegramCallbackDataMaxLen = 64
+
+	// Telegram 回调操作, 静默操作以 s 开头并携带时长, 如 s1h
+	TelegramActionClaim   = "c"
+	TelegramActionResolve = "r"
+	TelegramActionSilence = "s"
+)
+
+// TelegramMessage Bot API sendMessage / editMessageText 请求
+type TelegramMessage struct {
+	ChatId                string               `json:"chat_id,omitempty"`
+	MessageId             int64                `json:"message_id,omitempty"`
+	Text                  string               `json:"text"`
+	ParseMode             string               `json:"parse_mode,omitempty"`
+	Entities              json.RawMessage      `json:"entities,omitempty"`
+	DisableWebPagePreview bool                 `json:"disable_web_page_preview,omitempty"`
+	ReplyMarkup           *TelegramReplyMarkup `json:"reply_markup,omitempty"`
+}
+
+type TelegramReplyMarkup struct {
+	InlineKeyboard [][]TelegramInlineButton `json:"inline_keyboard"`
+}
+
+// TelegramInlineButton 内联按钮, CallbackData 与 Url 二选一
+type TelegramInlineButton struct {
+	Text         string `json:"text"`
+	CallbackData string `json:"callback_data,omitempty"`
+	Url          string `json:"url,omitempty"`
+}
+
+// FilterTelegramKeyboard 按条件保留内联按钮, 并移除空行
+func FilterTelegramKeyboard(markup *TelegramReplyMarkup, keep func(button TelegramInlineButton) bool) *TelegramReplyMarkup {
+	if markup == nil {
+		return nil
+	}
+
+	result := &TelegramReplyMarkup{InlineKeyboard: [][]TelegramInlineButton{}}
+	for _, row := range markup.InlineKeyboard {
+		var buttons []TelegramInlineButton
+		for _, button := range row {
+			if keep(button) {
+				buttons = append(buttons, button)
+			}
+		}
+		if len(buttons) > 0 {
+			result.InlineKeyboard = append(result.InlineKeyboard, buttons)
+		}
+	}
+	return result
+}
+
+// TelegramCallbackData 构建回调数据 "操作:租户ID:告警指纹", 超出长度限制时返回空
+func TelegramCallbackData(action, tenantId, fingerprint string) string {
+	data := fmt.Sprintf("%s:%s:%s", action, tenantId, fingerprint)
+	if len(data) > TelegramCallbackDataMaxLen {
+		return ""
+	}
+	return data
+}
+
+// ParseTelegramCallbackData 解析回调数据, 静默操作返回静默时长
+func ParseTelegramCallbackData(data string) (action, duration, tenantId, fingerprint string, err error) {
+	parts := strings.Split(data, ":")
+	if len(parts) != 3 || parts[1] == "" || parts[2] == "" {
+		return "", "", "", "", fmt.Errorf("无效的回调数据: %s", data)
+	}
+
+	action, tenantId, fingerprint = parts[0], parts[1], parts[2]
+	switch {
+	case action == TelegramActionClaim, action == TelegramActionResolve:
+	case strings.HasPrefix(action, TelegramActionSilence) && len(action) > len(TelegramActionSilence):
+		duration = strings.TrimPrefix(action, TelegramActionSilence)
+		action = TelegramActionSilence
+	default:
+		return "", "", "", "", fmt.Errorf("无效的回调操作: %s", action)
+	}
+
+	return action, duration, tenantId, fingerprint, nil
+}
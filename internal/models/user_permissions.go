@@ -23,6 +23,82 @@ func PermissionsInfo() map[string]UserPermissions {
 			Key: "更新值班表",
 			API: "/api/w8t/calendar/calendarUpdate",
 		},
+		"onCall": {
+			Key: "查看当班人员",
+			API: "/api/w8t/calendar/onCall",
+		},
+		"onCallHistory": {
+			Key: "查看值班历史",
+			API: "/api/w8t/calendar/onCallHistory",
+		},
+		"onCallAnalytics": {
+			Key: "查看值班负载统计",
+			API: "/api/w8t/calendar/onCallAnalytics",
+		},
+		"holidayImport": {
+			Key: "导入节假日日历",
+			API: "/api/w8t/calendar/holidayImport",
+		},
+		"holidayDelete": {
+			Key: "删除节假日日历",
+			API: "/api/w8t/calendar/holidayDelete",
+		},
+		"holidayList": {
+			Key: "查看节假日日历",
+			API: "/api/w8t/calendar/holidayList",
+		},
+		"feedCreate": {
+			Key: "创建日历订阅",
+			API: "/api/w8t/calendar/feedCreate",
+		},
+		"feedDelete": {
+			Key: "删除日历订阅",
+			API: "/api/w8t/calendar/feedDelete",
+		},
+		"feedList": {
+			Key: "查看日历订阅",
+			API: "/api/w8t/calendar/feedList",
+		},
+		"handoffCreate": {
+			Key: "生成值班交接报告",
+			API: "/api/w8t/calendar/handoffCreate",
+		},
+		"handoffList": {
+			Key: "查看值班交接记录",
+			API: "/api/w8t/calendar/handoffList",
+		},
+		"handoffGet": {
+			Key: "查看值班交接报告详情",
+			API: "/api/w8t/calendar/handoffGet",
+		},
+		"overrideCreate": {
+			Key: "创建覆盖值班",
+			API: "/api/w8t/calendar/overrideCreate",
+		},
+		"overrideCancel": {
+			Key: "取消覆盖值班",
+			API: "/api/w8t/calendar/overrideCancel",
+		},
+		"overrideList": {
+			Key: "查看覆盖值班",
+			API: "/api/w8t/calendar/overrideList",
+		},
+		"swapCreate": {
+			Key: "发起换班申请",
+			API: "/api/w8t/calendar/swapCreate",
+		},
+		"swapAccept": {
+			Key: "接受换班申请",
+			API: "/api/w8t/calendar/swapAccept",
+		},
+		"swapReject": {
+			Key: "拒绝换班申请",
+			API: "/api/w8t/calendar/swapReject",
+		},
+		"swapList": {
+			Key: "查看换班申请",
+			API: "/api/w8t/calendar/swapList",
+		},
 		"createTenant": {
 			Key: "创建租户",
 			API: "/api/w8t/tenant/createTenant",
@@ -207,6 +283,14 @@ func PermissionsInfo() map[string]UserPermissions {
 			Key: "更新静默规则",
 			API: "/api/w8t/silence/silenceUpdate",
 		},
+		"silenceOccurrences": {
+			Key: "查看静默规则生效时间",
+			API: "/api/w8t/silence/silenceOccurrences",
+		},
+		"silencePreview": {
+			Key: "预览静默规则影响范围",
+			API: "/api/w8t/silence/silencePreview",
+		},
 		"updateTenant": {
 			Key: "更新租户",
 			API: "/api/w8t/tenant/updateTenant",
@@ -371,6 +455,82 @@ func PermissionsInfo() map[string]UserPermissions {
 			Key: "执行快捷操作(认领/静默/处理)",
 			API: "/api/v1/alert/quick-action",
 		},
+		"incidentCreate": {
+			Key: "创建故障",
+			API: "/api/w8t/incident/incidentCreate",
+		},
+		"incidentUpdate": {
+			Key: "更新故障",
+			API: "/api/w8t/incident/incidentUpdate",
+		},
+		"incidentDelete": {
+			Key: "删除故障",
+			API: "/api/w8t/incident/incidentDelete",
+		},
+		"incidentStatus": {
+			Key: "变更故障状态",
+			API: "/api/w8t/incident/incidentStatus",
+		},
+		"incidentLinkEvents": {
+			Key: "关联故障告警",
+			API: "/api/w8t/incident/incidentLinkEvents",
+		},
+		"incidentUnlinkEvents": {
+			Key: "取消关联故障告警",
+			API: "/api/w8t/incident/incidentUnlinkEvents",
+		},
+		"incidentComment": {
+			Key: "评论故障",
+			API: "/api/w8t/incident/incidentComment",
+		},
+		"incidentBroadcast": {
+			Key: "发送故障通报",
+			API: "/api/w8t/incident/incidentBroadcast",
+		},
+		"incidentList": {
+			Key: "查看故障列表",
+			API: "/api/w8t/incident/incidentList",
+		},
+		"incidentGet": {
+			Key: "查看故障详情",
+			API: "/api/w8t/incident/incidentGet",
+		},
+		"postmortemGenerate": {
+			Key: "生成复盘报告",
+			API: "/api/w8t/postmortem/postmortemGenerate",
+		},
+		"postmortemUpdate": {
+			Key: "编辑复盘报告",
+			API: "/api/w8t/postmortem/postmortemUpdate",
+		},
+		"postmortemFinalize": {
+			Key: "定稿复盘报告",
+			API: "/api/w8t/postmortem/postmortemFinalize",
+		},
+		"postmortemDelete": {
+			Key: "删除复盘报告",
+			API: "/api/w8t/postmortem/postmortemDelete",
+		},
+		"postmortemList": {
+			Key: "查看复盘报告列表",
+			API: "/api/w8t/postmortem/postmortemList",
+		},
+		"postmortemGet": {
+			Key: "查看复盘报告详情",
+			API: "/api/w8t/postmortem/postmortemGet",
+		},
+		"agent": {
+			Key: "Ai 工具调用根因分析",
+			API: "/api/w8t/ai/agent",
+		},
+		"ruleDraft": {
+			Key: "Ai 生成告警规则草稿",
+			API: "/api/w8t/ai/ruleDraft",
+		},
+		"usage": {
+			Key: "查看 Ai 用量统计",
+			API: "/api/w8t/ai/usage",
+		},
 		"quickSilenceForm": {
 			Key: "查看自定义静默表单",
 			API: "/api/v1/alert/quick-silence",
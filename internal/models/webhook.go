@@ -0,0 +1,102 @@
+package models
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultWebhookSignHeader = "X-WatchAlert-Signature"
+	WebhookTimestampHeader   = "X-WatchAlert-Timestamp"
+	defaultWebhookTimeout    = 10
+)
+
+// WebhookConfig 自定义 Hook 的请求配置, 未配置时以 POST 发送默认的 JSON 内容
+// 地址、请求头及请求体均为 Go 模版, 模版数据为默认内容解析后的 JSON, 如 {{ .alarm.rule_name }}
+type WebhookConfig struct {
+	// 请求方法, 默认 POST
+	Method string `json:"method"`
+	// 地址模版, 为空时使用通知对象的 Hook 地址, 可通过 {{ .hook }} 引用当前告警等级路由的 Hook 地址
+	Url     string          `json:"url"`
+	Headers []WebhookHeader `json:"headers"`
+	// 请求体模版, 为空时发送默认内容
+	Body string `json:"body"`
+	// 视为发送成功的状态码, 为空时为 2xx
+	AcceptedStatus []int `json:"acceptedStatus"`
+	// HMAC-SHA256 签名密钥, 为空时不签名
+	SignSecret string `json:"signSecret"`
+	// 签名请求头, 默认 X-WatchAlert-Signature
+	SignHeader string `json:"signHeader"`
+	// 超时时间(秒), 默认 10
+	Timeout int `json:"timeout"`
+}
+
+type WebhookHeader struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+func (w WebhookConfig) GetMethod() string {
+	if w.Method == "" {
+		return http.MethodPost
+	}
+	return strings.ToUpper(w.Method)
+}
+
+func (w WebhookConfig) GetSignHeader() string {
+	if w.SignHeader == "" {
+		return DefaultWebhookSignHeader
+	}
+	return w.SignHeader
+}
+
+func (w WebhookConfig) GetTimeout() int {
+	if w.Timeout <= 0 {
+		return defaultWebhookTimeout
+	}
+	return w.Timeout
+}
+
+// IsAccepted 响应状态码是否视为发送成功
+func (w WebhookConfig) IsAccepted(status int) bool {
+	if len(w.AcceptedStatus) == 0 {
+		return status >= 200 && status < 300
+	}
+	for _, s := range w.AcceptedStatus {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookSignature 计算请求签名, 签名内容为 "时间戳.请求体", 格式为 sha256=<hex>
+func WebhookSignature(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyWebhookSignature 校验请求签名, 时间戳与当前时间相差超过 tolerance 时视为重放
+func VerifyWebhookSignature(secret, timestamp, signature string, body []byte, now time.Time, tolerance time.Duration) bool {
+	if secret == "" || signature == "" {
+		return false
+	}
+
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+	if diff := now.Sub(time.Unix(ts, 0)); diff > tolerance || diff < -tolerance {
+		return false
+	}
+
+	return hmac.Equal([]byte(signature), []byte(WebhookSignature(secret, ts, body)))
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestWebhookConfigDefaults(t *testing.T) {
+	var w WebhookConfig
+	if w.GetMethod() != http.MethodPost || w.GetSignHeader() != DefaultWebhookSignHeader || w.GetTimeout() != 10 {
+		t.Fatalf("unexpected defaults: %s %s %d", w.GetMethod(), w.GetSignHeader(), w.GetTimeout())
+	}
+	if !w.IsAccepted(200) || !w.IsAccepted(204) || w.IsAccepted(302) || w.IsAccepted(500) {
+		t.Fatal("default accepted status should be 2xx")
+	}
+
+	w = WebhookConfig{Method: "put", AcceptedStatus: []int{200, 409}}
+	if w.GetMethod() != http.MethodPut || !w.IsAccepted(409) || w.IsAccepted(201) {
+		t.Fatalf("unexpected config: %+v", w)
+	}
+}
+
+func TestWebhookSignature(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	body := []byte(`{"alarm":{"rule_name":"CPU"}}`)
+	signature := WebhookSignature("secret", now.Unix(), body)
+	ts := strconv.FormatInt(now.Unix(), 10)
+
+	if !VerifyWebhookSignature("secret", ts, signature, body, now.Add(time.Minute), 5*time.Minute) {
+		t.Fatal("expected signature to verify")
+	}
+	if VerifyWebhookSignature("other", ts, signature, body, now, 5*time.Minute) {
+		t.Fatal("expected wrong secret to fail")
+	}
+	if VerifyWebhookSignature("secret", ts, signature, []byte(`{}`), now, 5*time.Minute) {
+		t.Fatal("expected tampered body to fail")
+	}
+	if VerifyWebhookSignature("secret", ts, signature, body, now.Add(10*time.Minute), 5*time.Minute) {
+		t.Fatal("expected stale timestamp to fail")
+	}
+}
@@ -10,9 +10,12 @@ type (
 		entryRepo
 	}
 	InterAiRepo interface {
-		Get(ruleId string) (models.AiContentRecord, bool, error)
-		Create(data models.AiContentRecord) error
-		Update(data models.AiContentRecord) error
+		Get(ruleId, fingerprint, contentHash string) (models.AiContentRecord, bool, error)
+		GetLatestByEvent(tenantId, eventId string) (models.AiContentRecord, bool, error)
+		Save(data models.AiContentRecord) error
+		DeleteExpired(now int64) error
+		AddUsage(data models.AiUsage) error
+		UsageStat(tenantId string, startAt, endAt int64) ([]models.AiUsageStat, error)
 	}
 )
 
@@ -25,40 +28,93 @@ func newAiRepoInterface(db *gorm.DB, g InterGormDBCli) InterAiRepo {
 	}
 }
 
-func (a AiRepo) Get(ruleId string) (models.AiContentRecord, bool, error) {
+func (a AiRepo) Get(ruleId, fingerprint, contentHash string) (models.AiContentRecord, bool, error) {
 	var (
 		db   = a.DB().Model(&models.AiContentRecord{})
 		data models.AiContentRecord
 	)
 
-	db.Where("rule_id = ?", ruleId)
-	if err := db.First(&data).Error; err != nil {
+	db.Where("rule_id = ? AND fingerprint = ? AND content_hash = ?", ruleId, fingerprint, contentHash)
+	if err := db.Order("create_at desc").First(&data).Error; err != nil {
 		return data, false, err
 	}
 
 	return data, true, nil
 }
 
-func (a AiRepo) Create(data models.AiContentRecord) error {
-	err := a.g.Create(&models.AiContentRecord{}, &data)
-	if err != nil {
-		return err
+// GetLatestByEvent 获取同一告警事件最近一次的分析结果
+func (a AiRepo) GetLatestByEvent(tenantId, eventId string) (models.AiContentRecord, bool, error) {
+	var (
+		db   = a.DB().Model(&models.AiContentRecord{})
+		data models.AiContentRecord
+	)
+
+	db.Where("tenant_id = ? AND event_id = ?", tenantId, eventId)
+	if err := db.Order("create_at desc").First(&data).Error; err != nil {
+		return data, false, err
 	}
 
-	return nil
+	return data, true, nil
+}
+
+// Save 保存分析结果, 覆盖相同 规则 + 指纹 + 内容哈希 的旧结果
+func (a AiRepo) Save(data models.AiContentRecord) error {
+	return a.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("rule_id = ? AND fingerprint = ? AND content_hash = ?", data.RuleId, data.Fingerprint, data.ContentHash).
+			Delete(&models.AiContentRecord{}).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Create(&data).Error
+	})
 }
 
-func (a AiRepo) Update(data models.AiContentRecord) error {
-	err := a.g.Updates(Updates{
+// DeleteExpired 清理已过期的分析结果
+func (a AiRepo) DeleteExpired(now int64) error {
+	del := Delete{
 		Table: &models.AiContentRecord{},
 		Where: map[string]interface{}{
-			"rule_id = ?": data.RuleId,
+			"expire_at > ?":  0,
+			"expire_at <= ?": now,
 		},
-		Updates: &data,
-	})
+	}
+
+	return a.g.Delete(del)
+}
+
+// AddUsage 记录一次模型调用的 Token 用量
+func (a AiRepo) AddUsage(data models.AiUsage) error {
+	return a.g.Create(models.AiUsage{}, data)
+}
+
+// UsageStat 按模型服务统计租户在时间范围内的 Token 用量
+func (a AiRepo) UsageStat(tenantId string, startAt, endAt int64) ([]models.AiUsageStat, error) {
+	var (
+		db   = a.DB().Model(&models.AiUsage{})
+		data []models.AiUsageStat
+	)
+
+	db.Where("tenant_id = ?", tenantId)
+	if startAt > 0 {
+		db.Where("create_at >= ?", startAt)
+	}
+	if endAt > 0 {
+		db.Where("create_at <= ?", endAt)
+	}
+
+	err := db.Select("profile, provider, model, " +
+		"COUNT(*) AS requests, " +
+		"SUM(CASE WHEN success THEN 0 ELSE 1 END) AS failed_requests, " +
+		"SUM(prompt_tokens) AS prompt_tokens, " +
+		"SUM(completion_tokens) AS completion_tokens, " +
+		"SUM(total_tokens) AS total_tokens").
+		Group("profile, provider, model").
+		Order("total_tokens desc").
+		Scan(&data).Error
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	return data, nil
 }
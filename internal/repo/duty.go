@@ -14,6 +14,7 @@ type (
 	InterDutyRepo interface {
 		GetQuota(id string) bool
 		List(tenantId string) ([]models.DutyManagement, error)
+		ListAll() ([]models.DutyManagement, error)
 		Create(r models.DutyManagement) error
 		Update(r models.DutyManagement) error
 		Delete(tenantId, id string) error
@@ -59,15 +60,32 @@ func (d DutyRepo) List(tenantId string) ([]models.DutyManagement, error) {
 		return nil, err
 	}
 
+	now := time.Now()
 	for index, value := range data {
-		var dutySchedule models.DutySchedule
-		d.DB().Model(models.DutySchedule{}).Where("duty_id = ? and time = ?", value.ID, time.Now().Format("2006-1-2")).Find(&dutySchedule)
-		data[index].CurDutyUser = dutySchedule.Users
+		timeline, err := d.DutyCalendar().GetTimeline(value.ID, now, now)
+		if err != nil {
+			continue
+		}
+
+		var users []models.DutyUser
+		for _, onCall := range timeline.At(now) {
+			users = append(users, onCall.Users...)
+		}
+		data[index].CurDutyUser = users
 	}
 
 	return data, nil
 }
 
+// ListAll 获取所有租户的值班组, 不解析当班人员
+func (d DutyRepo) ListAll() ([]models.DutyManagement, error) {
+	var data []models.DutyManagement
+	if err := d.db.Model(&models.DutyManagement{}).Find(&data).Error; err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
 func (d DutyRepo) Create(r models.DutyManagement) error {
 	err := d.g.Create(&models.DutyManagement{}, r)
 	if err != nil {
@@ -124,6 +142,19 @@ func (d DutyRepo) Delete(tenantId, id string) error {
 		return err
 	}
 
+	for _, table := range []interface{}{&models.DutyOverride{}, &models.DutySwap{}, &models.DutyRevision{}, &models.DutyHandoff{}, &models.DutyPage{}} {
+		err = d.g.Delete(Delete{
+			Table: table,
+			Where: map[string]interface{}{
+				"tenant_id = ?": tenantId,
+				"duty_id = ?":   id,
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
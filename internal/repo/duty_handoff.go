@@ -0,0 +1,72 @@
+package repo
+
+import (
+	"gorm.io/gorm"
+	"watchAlert/internal/models"
+)
+
+type (
+	DutyHandoffRepo struct {
+		entryRepo
+	}
+
+	InterDutyHandoffRepo interface {
+		Create(r models.DutyHandoff) error
+		Get(tenantId, id string) (models.DutyHandoff, error)
+		List(tenantId, dutyId string, page models.Page) ([]models.DutyHandoff, int64, error)
+		Exists(dutyId string, handoffAt int64) bool
+	}
+)
+
+func newDutyHandoffInterface(db *gorm.DB, g InterGormDBCli) InterDutyHandoffRepo {
+	return &DutyHandoffRepo{
+		entryRepo{
+			g:  g,
+			db: db,
+		},
+	}
+}
+
+func (d DutyHandoffRepo) Create(r models.DutyHandoff) error {
+	return d.g.Create(&models.DutyHandoff{}, r)
+}
+
+func (d DutyHandoffRepo) Get(tenantId, id string) (models.DutyHandoff, error) {
+	var data models.DutyHandoff
+	err := d.db.Model(&models.DutyHandoff{}).
+		Where("tenant_id = ? AND id = ?", tenantId, id).
+		First(&data).Error
+	return data, err
+}
+
+// List 获取交接记录, 按交接时间倒序
+func (d DutyHandoffRepo) List(tenantId, dutyId string, page models.Page) ([]models.DutyHandoff, int64, error) {
+	var (
+		data  []models.DutyHandoff
+		count int64
+	)
+	db := d.db.Model(&models.DutyHandoff{})
+	db.Where("tenant_id = ?", tenantId)
+	if dutyId != "" {
+		db.Where("duty_id = ?", dutyId)
+	}
+
+	db.Count(&count)
+	if page.Size > 0 {
+		db.Limit(int(page.Size)).Offset(int((page.Index - 1) * page.Size))
+	}
+	if err := db.Order("handoff_at DESC").Find(&data).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return data, count, nil
+}
+
+// Exists 班次边界是否已生成过自动交接报告
+func (d DutyHandoffRepo) Exists(dutyId string, handoffAt int64) bool {
+	var count int64
+	d.db.Model(&models.DutyHandoff{}).
+		Where("duty_id = ? AND handoff_at = ? AND source = ?", dutyId, handoffAt, models.HandoffSourceAuto).
+		Count(&count)
+	return count > 0
+}
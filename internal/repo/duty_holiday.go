@@ -0,0 +1,101 @@
+package repo
+
+import (
+	"gorm.io/gorm"
+	"watchAlert/internal/models"
+)
+
+// ReplaceHolidays 按导入来源整体替换租户的节假日
+func (dc DutyCalendarRepo) ReplaceHolidays(tenantId, source string, holidays []models.DutyHoliday) error {
+	return dc.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("tenant_id = ? AND source = ?", tenantId, source).Delete(&models.DutyHoliday{}).Error
+		if err != nil {
+			return err
+		}
+		if len(holidays) == 0 {
+			return nil
+		}
+		return tx.Create(&holidays).Error
+	})
+}
+
+func (dc DutyCalendarRepo) DeleteHolidays(tenantId, source string) error {
+	return dc.g.Delete(Delete{
+		Table: &models.DutyHoliday{},
+		Where: map[string]interface{}{
+			"tenant_id = ?": tenantId,
+			"source = ?":    source,
+		},
+	})
+}
+
+// ListHolidays 获取租户在 startDate 到 endDate 之间的节假日, 日期格式为 2006-01-02
+func (dc DutyCalendarRepo) ListHolidays(tenantId, startDate, endDate string) ([]models.DutyHoliday, error) {
+	var data []models.DutyHoliday
+	db := dc.db.Model(&models.DutyHoliday{})
+	db.Where("tenant_id = ?", tenantId)
+	if startDate != "" {
+		db.Where("date >= ?", startDate)
+	}
+	if endDate != "" {
+		db.Where("date <= ?", endDate)
+	}
+	err := db.Order("date ASC").Find(&data).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// GetHolidayCalendar 获取租户的节假日日历, 同一日期存在多个来源时以节假日优先
+func (dc DutyCalendarRepo) GetHolidayCalendar(tenantId, startDate, endDate string) (models.HolidayCalendar, error) {
+	holidays, err := dc.ListHolidays(tenantId, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	calendar := make(models.HolidayCalendar, len(holidays))
+	for _, holiday := range holidays {
+		if calendar[holiday.Date] == models.HolidayTypeHoliday {
+			continue
+		}
+		calendar[holiday.Date] = holiday.Type
+	}
+	return calendar, nil
+}
+
+func (dc DutyCalendarRepo) CreateFeed(r models.DutyCalendarFeed) error {
+	return dc.g.Create(&models.DutyCalendarFeed{}, r)
+}
+
+func (dc DutyCalendarRepo) DeleteFeed(tenantId, token string) error {
+	return dc.g.Delete(Delete{
+		Table: &models.DutyCalendarFeed{},
+		Where: map[string]interface{}{
+			"tenant_id = ?": tenantId,
+			"token = ?":     token,
+		},
+	})
+}
+
+func (dc DutyCalendarRepo) GetFeed(token string) (models.DutyCalendarFeed, error) {
+	var data models.DutyCalendarFeed
+	err := dc.db.Model(&models.DutyCalendarFeed{}).Where("token = ?", token).First(&data).Error
+	return data, err
+}
+
+func (dc DutyCalendarRepo) ListFeeds(tenantId, userId string) ([]models.DutyCalendarFeed, error) {
+	var data []models.DutyCalendarFeed
+	db := dc.db.Model(&models.DutyCalendarFeed{})
+	db.Where("tenant_id = ?", tenantId)
+	if userId != "" {
+		db.Where("user_id = ?", userId)
+	}
+	err := db.Order("create_at DESC").Find(&data).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
@@ -0,0 +1,162 @@
+package repo
+
+import (
+	"gorm.io/gorm"
+	"watchAlert/internal/models"
+)
+
+type (
+	DutyOverrideRepo struct {
+		entryRepo
+	}
+
+	InterDutyOverrideRepo interface {
+		Create(r models.DutyOverride) error
+		Cancel(tenantId, id, canceledBy string, canceledAt int64) error
+		Get(tenantId, id string) (models.DutyOverride, error)
+		List(tenantId, dutyId string, startAt, endAt int64) ([]models.DutyOverride, error)
+		CreateSwap(r models.DutySwap) error
+		UpdateSwap(r models.DutySwap) error
+		UpdatePendingSwap(r models.DutySwap, overrides []models.DutyOverride) (bool, error)
+		GetSwap(tenantId, id string) (models.DutySwap, error)
+		ListSwaps(tenantId, dutyId, status string) ([]models.DutySwap, error)
+		CreateRevision(r models.DutyRevision) error
+		ListRevisions(dutyId string) ([]models.DutyRevision, error)
+	}
+)
+
+func newDutyOverrideInterface(db *gorm.DB, g InterGormDBCli) InterDutyOverrideRepo {
+	return &DutyOverrideRepo{
+		entryRepo{
+			g:  g,
+			db: db,
+		},
+	}
+}
+
+func (d DutyOverrideRepo) Create(r models.DutyOverride) error {
+	return d.g.Create(&models.DutyOverride{}, r)
+}
+
+// Cancel 取消覆盖值班, 保留记录用于审计
+func (d DutyOverrideRepo) Cancel(tenantId, id, canceledBy string, canceledAt int64) error {
+	return d.db.Model(&models.DutyOverride{}).
+		Where("tenant_id = ? AND id = ? AND canceled_at = 0", tenantId, id).
+		Updates(map[string]interface{}{
+			"canceled_by": canceledBy,
+			"canceled_at": canceledAt,
+		}).Error
+}
+
+func (d DutyOverrideRepo) Get(tenantId, id string) (models.DutyOverride, error) {
+	var data models.DutyOverride
+	err := d.db.Model(&models.DutyOverride{}).
+		Where("tenant_id = ? AND id = ?", tenantId, id).
+		First(&data).Error
+	return data, err
+}
+
+// List 获取与 startAt 到 endAt 有交集的覆盖值班, 包含已取消的记录, 按创建时间升序
+func (d DutyOverrideRepo) List(tenantId, dutyId string, startAt, endAt int64) ([]models.DutyOverride, error) {
+	var data []models.DutyOverride
+	db := d.db.Model(&models.DutyOverride{})
+	if tenantId != "" {
+		db.Where("tenant_id = ?", tenantId)
+	}
+	db.Where("duty_id = ? AND start_at < ? AND end_at > ?", dutyId, endAt, startAt)
+	err := db.Order("create_at ASC").Find(&data).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+func (d DutyOverrideRepo) CreateSwap(r models.DutySwap) error {
+	return d.g.Create(&models.DutySwap{}, r)
+}
+
+func (d DutyOverrideRepo) UpdateSwap(r models.DutySwap) error {
+	u := Updates{
+		Table: &models.DutySwap{},
+		Where: map[string]interface{}{
+			"tenant_id = ?": r.TenantId,
+			"id = ?":        r.ID,
+		},
+		Updates: r,
+	}
+
+	return d.g.Updates(u)
+}
+
+// UpdatePendingSwap 在同一事务中更新待确认的换班申请并创建覆盖值班, 申请已被处理时返回 false
+func (d DutyOverrideRepo) UpdatePendingSwap(r models.DutySwap, overrides []models.DutyOverride) (bool, error) {
+	var updated bool
+	err := d.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&models.DutySwap{}).
+			Where("tenant_id = ? AND id = ? AND status = ?", r.TenantId, r.ID, models.DutySwapPending).
+			Select("status", "overrideIds", "update_at").
+			Updates(&r)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return nil
+		}
+		updated = true
+
+		if len(overrides) == 0 {
+			return nil
+		}
+		return tx.Create(&overrides).Error
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return updated, nil
+}
+
+func (d DutyOverrideRepo) GetSwap(tenantId, id string) (models.DutySwap, error) {
+	var data models.DutySwap
+	err := d.db.Model(&models.DutySwap{}).
+		Where("tenant_id = ? AND id = ?", tenantId, id).
+		First(&data).Error
+	return data, err
+}
+
+func (d DutyOverrideRepo) ListSwaps(tenantId, dutyId, status string) ([]models.DutySwap, error) {
+	var data []models.DutySwap
+	db := d.db.Model(&models.DutySwap{})
+	db.Where("tenant_id = ?", tenantId)
+	if dutyId != "" {
+		db.Where("duty_id = ?", dutyId)
+	}
+	if status != "" {
+		db.Where("status = ?", status)
+	}
+	err := db.Order("create_at DESC").Find(&data).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+func (d DutyOverrideRepo) CreateRevision(r models.DutyRevision) error {
+	return d.g.Create(&models.DutyRevision{}, r)
+}
+
+// ListRevisions 获取值班组的轮值配置历史, 按生效时间升序
+func (d DutyOverrideRepo) ListRevisions(dutyId string) ([]models.DutyRevision, error) {
+	var data []models.DutyRevision
+	err := d.db.Model(&models.DutyRevision{}).
+		Where("duty_id = ?", dutyId).
+		Order("effective_at ASC").
+		Find(&data).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
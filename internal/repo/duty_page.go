@@ -0,0 +1,35 @@
+package repo
+
+import (
+	"gorm.io/gorm"
+	"watchAlert/internal/models"
+)
+
+// ReplacePages 整体替换 startAt 到 endAt 期间已归属的告警通知, 重复归属同一时段时结果不变
+func (dc DutyCalendarRepo) ReplacePages(startAt, endAt int64, pages []models.DutyPage) error {
+	return dc.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("at >= ? AND at < ?", startAt, endAt).Delete(&models.DutyPage{}).Error
+		if err != nil {
+			return err
+		}
+		if len(pages) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(&pages, 500).Error
+	})
+}
+
+// ListPages 获取 startAt 到 endAt 期间已归属的告警通知, dutyId 为空时获取租户下所有值班组
+func (dc DutyCalendarRepo) ListPages(tenantId, dutyId string, startAt, endAt int64) ([]models.DutyPage, error) {
+	var data []models.DutyPage
+	db := dc.db.Model(&models.DutyPage{})
+	db.Where("tenant_id = ? AND at >= ? AND at < ?", tenantId, startAt, endAt)
+	if dutyId != "" {
+		db.Where("duty_id = ?", dutyId)
+	}
+	if err := db.Order("at ASC").Find(&data).Error; err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
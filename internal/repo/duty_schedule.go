@@ -19,6 +19,18 @@ type (
 	InterDutyCalendar interface {
 		GetCalendarInfo(dutyId, time string) models.DutySchedule
 		GetDutyUserInfo(dutyId, time string) ([]models.Member, bool)
+		GetOnCallUsers(dutyId string, at time.Time) ([]models.Member, bool)
+		GetTimeline(dutyId string, start, end time.Time) (models.DutyTimeline, error)
+		ReplaceHolidays(tenantId, source string, holidays []models.DutyHoliday) error
+		DeleteHolidays(tenantId, source string) error
+		ListHolidays(tenantId, startDate, endDate string) ([]models.DutyHoliday, error)
+		GetHolidayCalendar(tenantId, startDate, endDate string) (models.HolidayCalendar, error)
+		CreateFeed(r models.DutyCalendarFeed) error
+		DeleteFeed(tenantId, token string) error
+		GetFeed(token string) (models.DutyCalendarFeed, error)
+		ListFeeds(tenantId, userId string) ([]models.DutyCalendarFeed, error)
+		ReplacePages(startAt, endAt int64, pages []models.DutyPage) error
+		ListPages(tenantId, dutyId string, startAt, endAt int64) ([]models.DutyPage, error)
 		Create(r models.DutySchedule) error
 		Update(r models.DutySchedule) error
 		Search(tenantId, dutyId, time string) ([]models.DutySchedule, error)
@@ -48,9 +60,82 @@ func (dc DutyCalendarRepo) GetCalendarInfo(dutyId, time string) models.DutySched
 
 // GetDutyUserInfo 获取值班用户信息
 func (dc DutyCalendarRepo) GetDutyUserInfo(dutyId, time string) ([]models.Member, bool) {
-	var users []models.Member
 	schedule := dc.GetCalendarInfo(dutyId, time)
-	for _, user := range schedule.Users {
+	return dc.getMembers(schedule.Users)
+}
+
+// GetOnCallUsers 获取 at 时刻的值班用户信息, 包含覆盖值班, 主值班在前
+func (dc DutyCalendarRepo) GetOnCallUsers(dutyId string, at time.Time) ([]models.Member, bool) {
+	timeline, err := dc.GetTimeline(dutyId, at, at)
+	if err != nil {
+		return dc.GetDutyUserInfo(dutyId, at.Format("2006-1-2"))
+	}
+
+	var (
+		users []models.DutyUser
+		seen  = make(map[string]struct{})
+	)
+	for _, onCall := range timeline.At(at) {
+		for _, user := range onCall.Users {
+			if _, ok := seen[user.UserId]; ok {
+				continue
+			}
+			seen[user.UserId] = struct{}{}
+			users = append(users, user)
+		}
+	}
+	return dc.getMembers(users)
+}
+
+// GetTimeline 获取解析 start 到 end 期间值班人员所需的轮值配置历史、按天值班表及覆盖值班
+func (dc DutyCalendarRepo) GetTimeline(dutyId string, start, end time.Time) (models.DutyTimeline, error) {
+	var timeline models.DutyTimeline
+	if err := dc.db.Model(models.DutyManagement{}).Where("id = ?", dutyId).First(&timeline.Duty).Error; err != nil {
+		return timeline, err
+	}
+
+	revisions, err := dc.DutyOverride().ListRevisions(dutyId)
+	if err != nil {
+		return timeline, err
+	}
+	timeline.Revisions = revisions
+
+	overrides, err := dc.DutyOverride().List("", dutyId, start.Unix(), end.Unix()+1)
+	if err != nil {
+		return timeline, err
+	}
+	timeline.Overrides = overrides
+
+	// 按天值班表的日期以生效配置的时区为准, 前后各多取一天以覆盖时区差
+	locations := []*time.Location{timeline.Duty.GetLocation()}
+	for _, r := range revisions {
+		locations = append(locations, models.DutyManagement{TimeZone: r.TimeZone}.GetLocation())
+	}
+	var dates []string
+	for _, loc := range locations {
+		for d := start.In(loc).AddDate(0, 0, -1); !d.After(end.In(loc).AddDate(0, 0, 1)); d = d.AddDate(0, 0, 1) {
+			dates = append(dates, d.Format("2006-1-2"))
+		}
+	}
+
+	var schedules []models.DutySchedule
+	err = dc.db.Model(models.DutySchedule{}).
+		Where("duty_id = ? AND time IN ?", dutyId, dates).
+		Find(&schedules).Error
+	if err != nil {
+		return timeline, err
+	}
+	timeline.Calendar = make(map[string][]models.DutyUser, len(schedules))
+	for _, schedule := range schedules {
+		timeline.Calendar[schedule.Time] = schedule.Users
+	}
+
+	return timeline, nil
+}
+
+func (dc DutyCalendarRepo) getMembers(dutyUsers []models.DutyUser) ([]models.Member, bool) {
+	var users []models.Member
+	for _, user := range dutyUsers {
 		var userData models.Member
 		db := dc.db.Model(models.Member{}).Where("user_id = ?", user.UserId)
 		if err := db.First(&userData).Error; err != nil {
@@ -125,6 +210,9 @@ func (dc DutyCalendarRepo) GetCalendarUsers(tenantId, dutyId string) ([][]models
 	// 使用 map 去重用户组，避免重复
 	user := make(map[string]struct{})
 	for _, entry := range entries {
+		if len(entry.Users) == 0 {
+			continue
+		}
 		key := tools.JsonMarshalToString(entry.Users)
 		if _, ok := user[key]; ok {
 			continue
@@ -19,6 +19,8 @@ type (
 		AuditLog() InterAuditLogRepo
 		Datasource() InterDatasourceRepo
 		Duty() InterDutyRepo
+		DutyOverride() InterDutyOverrideRepo
+		DutyHandoff() InterDutyHandoffRepo
 		DutyCalendar() InterDutyCalendar
 		Event() InterEventRepo
 		Notice() InterNoticeRepo
@@ -37,6 +39,8 @@ type (
 		FaultCenter() InterFaultCenterRepo
 		Ai() InterAiRepo
 		Comment() InterCommentRepo
+		Incident() InterIncidentRepo
+		Postmortem() InterPostmortemRepo
 	}
 )
 
@@ -63,6 +67,12 @@ func (e *entryRepo) Tenant() InterTenantRepo         { return newTenantInterface
 func (e *entryRepo) AuditLog() InterAuditLogRepo     { return newAuditLogInterface(e.db, e.g) }
 func (e *entryRepo) Datasource() InterDatasourceRepo { return newDatasourceInterface(e.db, e.g) }
 func (e *entryRepo) Duty() InterDutyRepo             { return newDutyInterface(e.db, e.g) }
+func (e *entryRepo) DutyOverride() InterDutyOverrideRepo {
+	return newDutyOverrideInterface(e.db, e.g)
+}
+func (e *entryRepo) DutyHandoff() InterDutyHandoffRepo {
+	return newDutyHandoffInterface(e.db, e.g)
+}
 func (e *entryRepo) DutyCalendar() InterDutyCalendar { return newDutyCalendarInterface(e.db, e.g) }
 func (e *entryRepo) Event() InterEventRepo           { return newEventInterface(e.db, e.g) }
 func (e *entryRepo) Notice() InterNoticeRepo         { return newNoticeInterface(e.db, e.g) }
@@ -85,3 +95,5 @@ func (e *entryRepo) Probing() InterProbingRepo         { return newProbingRepoIn
 func (e *entryRepo) FaultCenter() InterFaultCenterRepo { return newInterFaultCenterRepo(e.db, e.g) }
 func (e *entryRepo) Ai() InterAiRepo                   { return newAiRepoInterface(e.db, e.g) }
 func (e *entryRepo) Comment() InterCommentRepo         { return newCommentInterface(e.db, e.g) }
+func (e *entryRepo) Incident() InterIncidentRepo       { return newIncidentInterface(e.db, e.g) }
+func (e *entryRepo) Postmortem() InterPostmortemRepo   { return newPostmortemInterface(e.db, e.g) }
@@ -14,6 +14,10 @@ type (
 	InterEventRepo interface {
 		GetHistoryEvent(r types.RequestAlertHisEventQuery) (types.ResponseHistoryEventList, error)
 		CreateHistoryEvent(r models.AlertHisEvent) error
+		ListRecentHistoryEvents(tenantId, faultCenterId string, since int64, limit int) ([]models.AlertHisEvent, error)
+		ListHistoryByFingerprint(tenantId, fingerprint string, limit int) ([]models.AlertHisEvent, error)
+		ListHistoryEventsByTime(tenantId, faultCenterId string, fingerprints []string, startAt, endAt int64) ([]models.AlertHisEvent, error)
+		ListHistoryEventsByIds(tenantId string, eventIds []string) ([]models.AlertHisEvent, error)
 	}
 )
 
@@ -85,3 +89,68 @@ func (e EventRepo) CreateHistoryEvent(r models.AlertHisEvent) error {
 
 	return nil
 }
+
+// ListRecentHistoryEvents 获取指定时间之后恢复的历史告警, 按恢复时间倒序最多返回 limit 条
+func (e EventRepo) ListRecentHistoryEvents(tenantId, faultCenterId string, since int64, limit int) ([]models.AlertHisEvent, error) {
+	var data []models.AlertHisEvent
+	db := e.DB().Model(&models.AlertHisEvent{})
+	db.Where("tenant_id = ? AND fault_center_id = ?", tenantId, faultCenterId)
+	db.Where("recover_time >= ?", since)
+	if err := db.Order("recover_time desc").Limit(limit).Find(&data).Error; err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// ListHistoryEventsByTime 获取与时间窗口有交集的历史告警, 可按故障中心及指纹过滤
+func (e EventRepo) ListHistoryEventsByTime(tenantId, faultCenterId string, fingerprints []string, startAt, endAt int64) ([]models.AlertHisEvent, error) {
+	var data []models.AlertHisEvent
+	db := e.DB().Model(&models.AlertHisEvent{})
+	db.Where("tenant_id = ?", tenantId)
+	if faultCenterId != "" {
+		db.Where("fault_center_id = ?", faultCenterId)
+	}
+	if len(fingerprints) > 0 {
+		db.Where("fingerprint IN ?", fingerprints)
+	}
+	if startAt > 0 {
+		db.Where("recover_time >= ?", startAt)
+	}
+	if endAt > 0 {
+		db.Where("first_trigger_time <= ?", endAt)
+	}
+	if err := db.Order("first_trigger_time asc").Find(&data).Error; err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// ListHistoryEventsByIds 按事件 ID 获取历史告警
+func (e EventRepo) ListHistoryEventsByIds(tenantId string, eventIds []string) ([]models.AlertHisEvent, error) {
+	var data []models.AlertHisEvent
+	if len(eventIds) == 0 {
+		return data, nil
+	}
+
+	db := e.DB().Model(&models.AlertHisEvent{})
+	db.Where("tenant_id = ? AND event_id IN ?", tenantId, eventIds)
+	if err := db.Find(&data).Error; err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// ListHistoryByFingerprint 获取同一告警最近的历史记录
+func (e EventRepo) ListHistoryByFingerprint(tenantId, fingerprint string, limit int) ([]models.AlertHisEvent, error) {
+	var data []models.AlertHisEvent
+	db := e.DB().Model(&models.AlertHisEvent{})
+	db.Where("tenant_id = ? AND fingerprint = ?", tenantId, fingerprint)
+	if err := db.Order("recover_time desc").Limit(limit).Find(&data).Error; err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
@@ -0,0 +1,297 @@
+package repo
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+	"watchAlert/internal/models"
+)
+
+type (
+	IncidentRepo struct {
+		entryRepo
+	}
+
+	InterIncidentRepo interface {
+		Create(r models.Incident) error
+		Update(r models.Incident) error
+		UpdateStatus(r models.Incident) error
+		Delete(tenantId, id string) error
+		Get(tenantId, id string) (models.Incident, error)
+		List(tenantId, faultCenterId, status, query string, page models.Page) ([]models.Incident, int64, error)
+		ListOpenWithEvents(tenantId, faultCenterId string, fingerprints []string) ([]models.Incident, []models.IncidentEvent, error)
+		LinkEvent(r models.IncidentEvent) (bool, error)
+		UnlinkEvent(tenantId, incidentId, fingerprint string) error
+		UpdateEventStatus(tenantId, incidentId, fingerprint, status string) error
+		ListEvents(tenantId, incidentId string) ([]models.IncidentEvent, error)
+		GetOpenIncidentsByFingerprint(tenantId, fingerprint string) ([]models.Incident, error)
+		AddTimeline(r models.IncidentTimeline) error
+		ListTimeline(tenantId, incidentId string) ([]models.IncidentTimeline, error)
+	}
+)
+
+func newIncidentInterface(db *gorm.DB, g InterGormDBCli) InterIncidentRepo {
+	return &IncidentRepo{
+		entryRepo{
+			g:  g,
+			db: db,
+		},
+	}
+}
+
+func (i IncidentRepo) Create(r models.Incident) error {
+	err := i.g.Create(&models.Incident{}, r)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (i IncidentRepo) Update(r models.Incident) error {
+	u := Updates{
+		Table: &models.Incident{},
+		Where: map[string]interface{}{
+			"tenant_id = ?": r.TenantId,
+			"id = ?":        r.ID,
+		},
+		Updates: r,
+	}
+
+	err := i.g.Updates(u)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UpdateStatus 更新故障状态及各阶段时间, 重新打开故障时需要写入零值
+func (i IncidentRepo) UpdateStatus(r models.Incident) error {
+	u := Updates{
+		Table: &models.Incident{},
+		Where: map[string]interface{}{
+			"tenant_id = ?": r.TenantId,
+			"id = ?":        r.ID,
+		},
+		Updates: map[string]interface{}{
+			"status":          r.Status,
+			"acknowledged_at": r.AcknowledgedAt,
+			"mitigated_at":    r.MitigatedAt,
+			"resolved_at":     r.ResolvedAt,
+			"update_at":       r.UpdateAt,
+		},
+	}
+
+	err := i.g.Updates(u)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (i IncidentRepo) Delete(tenantId, id string) error {
+	return i.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("tenant_id = ? AND incident_id = ?", tenantId, id).Delete(&models.IncidentEvent{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("tenant_id = ? AND incident_id = ?", tenantId, id).Delete(&models.IncidentTimeline{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("tenant_id = ? AND id = ?", tenantId, id).Delete(&models.Incident{}).Error
+	})
+}
+
+func (i IncidentRepo) Get(tenantId, id string) (models.Incident, error) {
+	var data models.Incident
+	err := i.db.Model(&models.Incident{}).Where("tenant_id = ? AND id = ?", tenantId, id).First(&data).Error
+	if err != nil {
+		return data, err
+	}
+
+	return data, nil
+}
+
+func (i IncidentRepo) List(tenantId, faultCenterId, status, query string, page models.Page) ([]models.Incident, int64, error) {
+	var (
+		data  []models.Incident
+		count int64
+	)
+	db := i.db.Model(&models.Incident{})
+	db.Where("tenant_id = ?", tenantId)
+	if faultCenterId != "" {
+		db.Where("fault_center_id = ?", faultCenterId)
+	}
+	if status != "" {
+		db.Where("status = ?", status)
+	}
+	if query != "" {
+		db.Where("id LIKE ? OR title LIKE ? OR commander LIKE ?", "%"+query+"%", "%"+query+"%", "%"+query+"%")
+	}
+
+	if err := db.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	db.Order("create_at desc")
+	if page.Size > 0 {
+		db.Limit(int(page.Size)).Offset(int((page.Index - 1) * page.Size))
+	}
+	if err := db.Find(&data).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return data, count, nil
+}
+
+// ListOpenWithEvents 获取故障中心内及关联了指定告警的未解决故障, 以及这些故障关联的告警
+func (i IncidentRepo) ListOpenWithEvents(tenantId, faultCenterId string, fingerprints []string) ([]models.Incident, []models.IncidentEvent, error) {
+	var incidents []models.Incident
+	scope := i.db.Where("fault_center_id = ?", faultCenterId)
+	if len(fingerprints) > 0 {
+		sub := i.db.Model(&models.IncidentEvent{}).Select("incident_id").Where("tenant_id = ? AND fingerprint IN ?", tenantId, fingerprints)
+		scope = scope.Or("id IN (?)", sub)
+	}
+	err := i.db.Model(&models.Incident{}).
+		Where("tenant_id = ? AND status <> ?", tenantId, models.IncidentResolved).
+		Where(scope).
+		Find(&incidents).Error
+	if err != nil || len(incidents) == 0 {
+		return incidents, nil, err
+	}
+
+	ids := make([]string, 0, len(incidents))
+	for _, incident := range incidents {
+		ids = append(ids, incident.ID)
+	}
+	var events []models.IncidentEvent
+	err = i.db.Model(&models.IncidentEvent{}).
+		Where("tenant_id = ? AND incident_id IN ?", tenantId, ids).
+		Find(&events).Error
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return incidents, events, nil
+}
+
+// LinkEvent 关联告警事件, 已关联时仅同步事件状态并返回 true
+func (i IncidentRepo) LinkEvent(r models.IncidentEvent) (bool, error) {
+	var exist models.IncidentEvent
+	err := i.db.Model(&models.IncidentEvent{}).
+		Where("tenant_id = ? AND incident_id = ? AND fingerprint = ?", r.TenantId, r.IncidentId, r.Fingerprint).
+		First(&exist).Error
+	if err == nil {
+		if exist.Status == r.Status && exist.EventId == r.EventId {
+			return true, nil
+		}
+
+		err = i.db.Model(&models.IncidentEvent{}).
+			Where("tenant_id = ? AND incident_id = ? AND fingerprint = ?", r.TenantId, r.IncidentId, r.Fingerprint).
+			Updates(map[string]interface{}{
+				"status":   r.Status,
+				"event_id": r.EventId,
+				"severity": r.Severity,
+			}).Error
+		return true, err
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, err
+	}
+
+	err = i.g.Create(&models.IncidentEvent{}, r)
+	if err != nil {
+		return false, err
+	}
+
+	return false, nil
+}
+
+func (i IncidentRepo) UnlinkEvent(tenantId, incidentId, fingerprint string) error {
+	del := Delete{
+		Table: &models.IncidentEvent{},
+		Where: map[string]interface{}{
+			"tenant_id = ?":   tenantId,
+			"incident_id = ?": incidentId,
+			"fingerprint = ?": fingerprint,
+		},
+	}
+
+	err := i.g.Delete(del)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (i IncidentRepo) UpdateEventStatus(tenantId, incidentId, fingerprint, status string) error {
+	u := Updates{
+		Table: &models.IncidentEvent{},
+		Where: map[string]interface{}{
+			"tenant_id = ?":   tenantId,
+			"incident_id = ?": incidentId,
+			"fingerprint = ?": fingerprint,
+		},
+		Updates: map[string]interface{}{
+			"status": status,
+		},
+	}
+
+	err := i.g.Updates(u)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (i IncidentRepo) ListEvents(tenantId, incidentId string) ([]models.IncidentEvent, error) {
+	var data = []models.IncidentEvent{}
+	err := i.db.Model(&models.IncidentEvent{}).
+		Where("tenant_id = ? AND incident_id = ?", tenantId, incidentId).
+		Order("linked_at asc").
+		Find(&data).Error
+	if err != nil {
+		return data, err
+	}
+
+	return data, nil
+}
+
+// GetOpenIncidentsByFingerprint 获取关联了该告警事件且未解决的故障
+func (i IncidentRepo) GetOpenIncidentsByFingerprint(tenantId, fingerprint string) ([]models.Incident, error) {
+	var data []models.Incident
+	sub := i.db.Model(&models.IncidentEvent{}).Select("incident_id").Where("tenant_id = ? AND fingerprint = ?", tenantId, fingerprint)
+	err := i.db.Model(&models.Incident{}).
+		Where("tenant_id = ? AND status <> ? AND id IN (?)", tenantId, models.IncidentResolved, sub).
+		Find(&data).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+func (i IncidentRepo) AddTimeline(r models.IncidentTimeline) error {
+	err := i.g.Create(&models.IncidentTimeline{}, r)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (i IncidentRepo) ListTimeline(tenantId, incidentId string) ([]models.IncidentTimeline, error) {
+	var data = []models.IncidentTimeline{}
+	err := i.db.Model(&models.IncidentTimeline{}).
+		Where("tenant_id = ? AND incident_id = ?", tenantId, incidentId).
+		Order("create_at asc").
+		Find(&data).Error
+	if err != nil {
+		return data, err
+	}
+
+	return data, nil
+}
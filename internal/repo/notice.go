@@ -24,6 +24,8 @@ type (
 		Delete(tenantId, id string) error
 		AddRecord(r models.NoticeRecord) error
 		ListRecord(tenantId, eventId, severity, status, query string, page models.Page) (models.ResponseNoticeRecords, error)
+		ListRecordByEventIds(tenantId string, eventIds []string) ([]models.NoticeRecord, error)
+		ListRecordByTime(startAt, endAt int64) ([]models.NoticeRecord, error)
 		CountRecord(r models.CountRecord) (int64, error)
 		DeleteRecord() error
 	}
@@ -190,6 +192,34 @@ func (nr NoticeRepo) ListRecord(tenantId, eventId, severity, status, query strin
 	}, nil
 }
 
+// ListRecordByEventIds 获取告警事件的全部通知记录
+func (nr NoticeRepo) ListRecordByEventIds(tenantId string, eventIds []string) ([]models.NoticeRecord, error) {
+	var records []models.NoticeRecord
+	if len(eventIds) == 0 {
+		return records, nil
+	}
+
+	db := nr.db.Model(&models.NoticeRecord{})
+	db.Where("tenant_id = ? AND event_id IN ?", tenantId, eventIds)
+	if err := db.Order("create_at asc").Find(&records).Error; err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+// ListRecordByTime 获取所有租户在 startAt 到 endAt 期间发送成功的通知记录
+func (nr NoticeRepo) ListRecordByTime(startAt, endAt int64) ([]models.NoticeRecord, error) {
+	var records []models.NoticeRecord
+	db := nr.db.Model(&models.NoticeRecord{})
+	db.Where("create_at >= ? AND create_at < ? AND status = 0", startAt, endAt)
+	if err := db.Order("create_at asc").Find(&records).Error; err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
 func (nr NoticeRepo) CountRecord(r models.CountRecord) (int64, error) {
 	var count int64
 	db := nr.db.Model(&models.NoticeRecord{})
@@ -0,0 +1,131 @@
+package repo
+
+import (
+	"gorm.io/gorm"
+	"watchAlert/internal/models"
+)
+
+type (
+	PostmortemRepo struct {
+		entryRepo
+	}
+
+	InterPostmortemRepo interface {
+		Create(r models.Postmortem) error
+		Update(r models.Postmortem) error
+		Delete(tenantId, id string) error
+		Get(tenantId, id string) (models.Postmortem, error)
+		List(tenantId, incidentId, status, query string, page models.Page) ([]models.Postmortem, int64, error)
+		AddVersion(r models.PostmortemVersion) error
+		ListVersions(tenantId, postmortemId string) ([]models.PostmortemVersion, error)
+	}
+)
+
+func newPostmortemInterface(db *gorm.DB, g InterGormDBCli) InterPostmortemRepo {
+	return &PostmortemRepo{
+		entryRepo{
+			g:  g,
+			db: db,
+		},
+	}
+}
+
+func (p PostmortemRepo) Create(r models.Postmortem) error {
+	err := p.g.Create(&models.Postmortem{}, r)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (p PostmortemRepo) Update(r models.Postmortem) error {
+	u := Updates{
+		Table: &models.Postmortem{},
+		Where: map[string]interface{}{
+			"tenant_id = ?": r.TenantId,
+			"id = ?":        r.ID,
+		},
+		Updates: r,
+	}
+
+	err := p.g.Updates(u)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (p PostmortemRepo) Delete(tenantId, id string) error {
+	return p.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("tenant_id = ? AND postmortem_id = ?", tenantId, id).Delete(&models.PostmortemVersion{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("tenant_id = ? AND id = ?", tenantId, id).Delete(&models.Postmortem{}).Error
+	})
+}
+
+func (p PostmortemRepo) Get(tenantId, id string) (models.Postmortem, error) {
+	var data models.Postmortem
+	err := p.db.Model(&models.Postmortem{}).Where("tenant_id = ? AND id = ?", tenantId, id).First(&data).Error
+	if err != nil {
+		return data, err
+	}
+
+	return data, nil
+}
+
+func (p PostmortemRepo) List(tenantId, incidentId, status, query string, page models.Page) ([]models.Postmortem, int64, error) {
+	var (
+		data  []models.Postmortem
+		count int64
+	)
+	db := p.db.Model(&models.Postmortem{})
+	db.Where("tenant_id = ?", tenantId)
+	if incidentId != "" {
+		db.Where("incident_id = ?", incidentId)
+	}
+	if status != "" {
+		db.Where("status = ?", status)
+	}
+	if query != "" {
+		db.Where("id LIKE ? OR title LIKE ?", "%"+query+"%", "%"+query+"%")
+	}
+
+	if err := db.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	db.Order("create_at desc")
+	if page.Size > 0 {
+		db.Limit(int(page.Size)).Offset(int((page.Index - 1) * page.Size))
+	}
+	if err := db.Find(&data).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return data, count, nil
+}
+
+func (p PostmortemRepo) AddVersion(r models.PostmortemVersion) error {
+	err := p.g.Create(&models.PostmortemVersion{}, r)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (p PostmortemRepo) ListVersions(tenantId, postmortemId string) ([]models.PostmortemVersion, error) {
+	var data = []models.PostmortemVersion{}
+	err := p.db.Model(&models.PostmortemVersion{}).
+		Where("tenant_id = ? AND postmortem_id = ?", tenantId, postmortemId).
+		Order("version desc").
+		Find(&data).Error
+	if err != nil {
+		return data, err
+	}
+
+	return data, nil
+}
@@ -12,8 +12,12 @@ type (
 
 	InterSilenceRepo interface {
 		List(tenantId, faultCenterId, query string, page models.Page) ([]models.AlertSilences, int64, error)
+		Get(tenantId, id string) (models.AlertSilences, error)
 		Create(r models.AlertSilences) error
 		Update(r models.AlertSilences) error
+		UpdateStatus(tenantId, id string, status int) error
+		ListLegacyMatchers() ([]models.AlertSilences, error)
+		UpdateMatchers(tenantId, id string, labels []models.SilenceLabel) error
 		Delete(tenantId, id string) error
 	}
 )
@@ -55,7 +59,18 @@ func (sr SilenceRepo) List(tenantId, faultCenterId, query string, page models.Pa
 	return silenceList, count, nil
 }
 
+func (sr SilenceRepo) Get(tenantId, id string) (models.AlertSilences, error) {
+	var silence models.AlertSilences
+	err := sr.db.Model(models.AlertSilences{}).Where("tenant_id = ? AND id = ?", tenantId, id).First(&silence).Error
+	if err != nil {
+		return silence, err
+	}
+
+	return silence, nil
+}
+
 func (sr SilenceRepo) Create(r models.AlertSilences) error {
+	r.MatcherVersion = models.SilenceMatcherVersion
 	err := sr.g.Create(models.AlertSilences{}, r)
 	if err != nil {
 		return err
@@ -65,6 +80,7 @@ func (sr SilenceRepo) Create(r models.AlertSilences) error {
 }
 
 func (sr SilenceRepo) Update(r models.AlertSilences) error {
+	r.MatcherVersion = models.SilenceMatcherVersion
 	u := Updates{
 		Table: models.AlertSilences{},
 		Where: map[string]interface{}{
@@ -82,6 +98,60 @@ func (sr SilenceRepo) Update(r models.AlertSilences) error {
 	return nil
 }
 
+// UpdateStatus 更新静默状态, 使用 map 以便写入「未生效」的零值状态
+func (sr SilenceRepo) UpdateStatus(tenantId, id string, status int) error {
+	u := Updates{
+		Table: models.AlertSilences{},
+		Where: map[string]interface{}{
+			"tenant_id = ?": tenantId,
+			"id = ?":        id,
+		},
+		Updates: map[string]interface{}{
+			"status": status,
+		},
+	}
+
+	err := sr.g.Updates(u)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ListLegacyMatchers 获取匹配条件尚未迁移的静默规则
+func (sr SilenceRepo) ListLegacyMatchers() ([]models.AlertSilences, error) {
+	var data []models.AlertSilences
+	err := sr.db.Model(models.AlertSilences{}).Where("matcher_version IS NULL OR matcher_version < ?", models.SilenceMatcherVersion).Find(&data).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// UpdateMatchers 保存迁移后的匹配条件
+func (sr SilenceRepo) UpdateMatchers(tenantId, id string, labels []models.SilenceLabel) error {
+	u := Updates{
+		Table: models.AlertSilences{},
+		Where: map[string]interface{}{
+			"tenant_id = ?": tenantId,
+			"id = ?":        id,
+		},
+		Updates: models.AlertSilences{
+			Labels:         labels,
+			MatcherVersion: models.SilenceMatcherVersion,
+		},
+	}
+
+	err := sr.g.Updates(u)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (sr SilenceRepo) Delete(tenantId, id string) error {
 	var silence models.AlertSilences
 	db := sr.db.Where("tenant_id = ? AND id = ?", tenantId, id)
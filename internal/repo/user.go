@@ -19,6 +19,7 @@ type (
 	InterUserRepo interface {
 		List(query, joinDuty string) ([]models.Member, error)
 		Get(userId, username, query string) (models.Member, bool, error)
+		GetByDutyUserId(dutyUserId string) (models.Member, error)
 		Create(r models.Member) error
 		Update(r models.Member) error
 		Delete(userId string) error
@@ -83,6 +84,23 @@ func (ur UserRepo) Get(userId, username, query string) (models.Member, bool, err
 	return data, true, nil
 }
 
+// GetByDutyUserId 根据值班用户 ID（IM 平台用户 ID）获取用户
+func (ur UserRepo) GetByDutyUserId(dutyUserId string) (models.Member, error) {
+	var data models.Member
+	if dutyUserId == "" {
+		return data, fmt.Errorf("用户不存在")
+	}
+	err := ur.db.Model(&models.Member{}).Where("duty_user_id = ?", dutyUserId).First(&data).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return data, fmt.Errorf("用户不存在")
+		}
+		return data, err
+	}
+
+	return data, nil
+}
+
 func (ur UserRepo) Create(r models.Member) error {
 	err := ur.g.Create(models.Member{}, r)
 	if err != nil {
@@ -1,14 +1,26 @@
 package routers
 
 import (
+	"context"
+	"crypto/subtle"
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/zeromicro/go-zero/core/logc"
 	"net/http"
+	"net/netip"
+	"strings"
+	"watchAlert/config"
+	"watchAlert/internal/global"
 )
 
 func HealthCheck(gin *gin.Engine) {
 
 	gin.GET("hello", health)
 
+	if global.Config.Metrics.Enable {
+		gin.GET("metrics", metricsAuth(global.Config.Metrics), metrics())
+	}
+
 }
 
 func health(ctx *gin.Context) {
@@ -18,3 +30,65 @@ func health(ctx *gin.Context) {
 	})
 
 }
+
+// metrics 暴露规则评估调度等内部指标
+func metrics() gin.HandlerFunc {
+	return gin.WrapH(promhttp.Handler())
+}
+
+// metricsAuth 按配置校验来源 IP 及访问令牌
+func metricsAuth(conf config.Metrics) gin.HandlerFunc {
+	var prefixes []netip.Prefix
+	for _, s := range conf.AllowIPs {
+		prefix, err := parsePrefix(s)
+		if err != nil {
+			logc.Errorf(context.Background(), "无效的 /metrics 来源 IP 配置 %s, err: %s", s, err.Error())
+			continue
+		}
+		prefixes = append(prefixes, prefix)
+	}
+	allowAll := len(conf.AllowIPs) == 0
+
+	return func(ctx *gin.Context) {
+		if !allowAll && !ipAllowed(ctx.ClientIP(), prefixes) {
+			ctx.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+
+		if conf.Token != "" {
+			token, ok := strings.CutPrefix(ctx.GetHeader("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(conf.Token)) != 1 {
+				ctx.AbortWithStatus(http.StatusUnauthorized)
+				return
+			}
+		}
+
+		ctx.Next()
+	}
+}
+
+// parsePrefix 解析 IP 或网段, 单个 IP 视为只包含该地址的网段
+func parsePrefix(s string) (netip.Prefix, error) {
+	if strings.Contains(s, "/") {
+		return netip.ParsePrefix(s)
+	}
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+func ipAllowed(ip string, prefixes []netip.Prefix) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,44 @@
+package routers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"watchAlert/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestMetricsAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	conf := config.Metrics{Enable: true, Token: "secret", AllowIPs: []string{"10.0.0.0/8", "192.168.1.10", "invalid"}}
+	engine := gin.New()
+	engine.GET("metrics", metricsAuth(conf), func(ctx *gin.Context) { ctx.Status(http.StatusOK) })
+
+	cases := []struct {
+		name   string
+		remote string
+		auth   string
+		want   int
+	}{
+		{"allowed network with token", "10.1.2.3:1234", "Bearer secret", http.StatusOK},
+		{"allowed ip with token", "192.168.1.10:1234", "Bearer secret", http.StatusOK},
+		{"ip outside allow list", "192.168.1.11:1234", "Bearer secret", http.StatusForbidden},
+		{"missing token", "10.1.2.3:1234", "", http.StatusUnauthorized},
+		{"wrong token", "10.1.2.3:1234", "Bearer other", http.StatusUnauthorized},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+			req.RemoteAddr = c.remote
+			if c.auth != "" {
+				req.Header.Set("Authorization", c.auth)
+			}
+			w := httptest.NewRecorder()
+			engine.ServeHTTP(w, req)
+			if w.Code != c.want {
+				t.Fatalf("expected %d, got %d", c.want, w.Code)
+			}
+		})
+	}
+}
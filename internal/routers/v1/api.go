@@ -36,6 +36,8 @@ func Router(engine *gin.Engine) {
 			api.RuleTmplController.API(w8t)
 			api.DutyController.API(w8t)
 			api.DutyCalendarController.API(w8t)
+			api.DutyOverrideController.API(w8t)
+			api.DutyHandoffController.API(w8t)
 			api.AuditLogController.API(w8t)
 			api.ClientController.API(w8t)
 			api.AWSCloudWatchController.API(w8t)
@@ -46,6 +48,8 @@ func Router(engine *gin.Engine) {
 			api.ProbingController.API(w8t)
 			api.FaultCenterController.API(w8t)
 			api.AiController.API(w8t)
+			api.IncidentController.API(w8t)
+			api.PostmortemController.API(w8t)
 		}
 
 		oidc := v1.Group("oidc")
@@ -1,9 +1,10 @@
 package services
 
 import (
+	"context"
 	"fmt"
-	"gorm.io/gorm"
 	"strings"
+	"watchAlert/alert/process"
 	"watchAlert/internal/ctx"
 	"watchAlert/internal/models"
 	"watchAlert/internal/types"
@@ -17,6 +18,10 @@ type (
 
 	InterAiService interface {
 		Chat(req interface{}) (interface{}, interface{})
+		Agent(ctx context.Context, req interface{}) (interface{}, interface{})
+		StreamChat(ctx context.Context, req interface{}, onChunk func(chunk string)) (interface{}, interface{})
+		Usage(req interface{}) (interface{}, interface{})
+		RuleDraft(req interface{}) (interface{}, interface{})
 	}
 )
 
@@ -42,57 +47,151 @@ func (a aiService) Chat(req interface{}) (interface{}, interface{}) {
 		return nil, err
 	}
 
+	prompt, record := a.prepareChat(setting.AiConfig, r)
+	if r.Deep != "true" {
+		if cache, ok := process.GetAiAnalysisCache(a.ctx, record.RuleId, record.Fingerprint, record.ContentHash); ok {
+			return cache.Content, nil
+		}
+	} else {
+		prompt = fmt.Sprintf("注意, 请深度思考下面的问题!\n%s", prompt)
+	}
+
 	client, err := a.ctx.Redis.ProviderPools().GetClient("AiClient")
 	if err != nil {
 		return "", err
 	}
 
-	aiClient := client.(ai.AiClient)
-	prompt := setting.AiConfig.Prompt
-	prompt = strings.ReplaceAll(prompt, "{{ RuleName }}", r.RuleName)
-	prompt = strings.ReplaceAll(prompt, "{{ Content }}", r.Content)
-	prompt = strings.ReplaceAll(prompt, "{{ SearchQL }}", r.SearchQL)
-	r.Content = prompt
+	completion, err := client.(ai.AiClient).ChatCompletion(ai.WithTenant(a.ctx.Ctx, r.TenantId), prompt)
+	if err != nil {
+		return "", err
+	}
 
-	switch r.Deep {
-	case "true":
-		r.Content = fmt.Sprintf("注意, 请深度思考下面的问题!\n%s", r.Content)
-		completion, err := aiClient.ChatCompletion(a.ctx.Ctx, r.Content)
-		if err != nil {
-			return "", err
-		}
-		err = a.ctx.DB.Ai().Update(models.AiContentRecord{
-			RuleId:  r.RuleId,
-			Content: completion,
-		})
-		if err != nil {
-			return nil, err
+	record.Content = completion
+	err = process.SaveAiAnalysis(a.ctx, setting.AiConfig, record)
+	if err != nil {
+		return nil, err
+	}
+
+	return completion, nil
+}
+
+// StreamChat 流式分析, 通过 onChunk 逐段返回内容, 客户端断开时 ctx 被取消并停止生成
+// 完整的分析结果写入 AiContentRecord, 非深度分析时优先返回已缓存的结果
+func (a aiService) StreamChat(ctx context.Context, req interface{}, onChunk func(chunk string)) (interface{}, interface{}) {
+	setting, err := a.ctx.DB.Setting().Get()
+	if err != nil {
+		return nil, err
+	}
+
+	if !setting.AiConfig.GetEnable() {
+		return nil, fmt.Errorf("未开启 Ai 分析能力")
+	}
+
+	r := req.(*types.RequestAiChatContent)
+	err = r.ValidateParams()
+	if err != nil {
+		return nil, err
+	}
+
+	prompt, record := a.prepareChat(setting.AiConfig, r)
+	if r.Deep != "true" {
+		if cache, ok := process.GetAiAnalysisCache(a.ctx, record.RuleId, record.Fingerprint, record.ContentHash); ok {
+			onChunk(cache.Content)
+			return cache.Content, nil
 		}
+	} else {
+		prompt = fmt.Sprintf("注意, 请深度思考下面的问题!\n%s", prompt)
+	}
 
-		return completion, nil
+	client, err := a.ctx.Redis.ProviderPools().GetClient("AiClient")
+	if err != nil {
+		return nil, err
+	}
+
+	stream, err := client.(ai.AiClient).StreamCompletion(ai.WithTenant(ctx, r.TenantId), prompt)
+	if err != nil {
+		return nil, err
+	}
 
-	default:
-		data, exist, err := a.ctx.DB.Ai().Get(r.RuleId)
-		if err != nil && err != gorm.ErrRecordNotFound {
-			return "", err
+	var (
+		completion strings.Builder
+		done       bool
+		streamErr  error
+	)
+	for event := range stream {
+		if event.Err != nil {
+			streamErr = event.Err
+			continue
 		}
-		if exist {
-			return data.Content, nil
+		if event.Done {
+			done = true
+			continue
 		}
+		completion.WriteString(event.Content)
+		onChunk(event.Content)
+	}
 
-		completion, err := aiClient.ChatCompletion(a.ctx.Ctx, r.Content)
-		if err != nil {
-			return "", err
-		}
+	// 客户端中途断开或流未正常结束时内容不完整, 不写入缓存
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("分析已取消: %s", err.Error())
+	}
+	if streamErr != nil {
+		return nil, streamErr
+	}
+	if !done {
+		return nil, fmt.Errorf("分析未正常结束")
+	}
+	if completion.Len() == 0 {
+		return nil, fmt.Errorf("无有效返回内容")
+	}
 
-		err = a.ctx.DB.Ai().Create(models.AiContentRecord{
-			RuleId:  r.RuleId,
-			Content: completion,
-		})
-		if err != nil {
-			return nil, err
+	record.Content = completion.String()
+	err = process.SaveAiAnalysis(a.ctx, setting.AiConfig, record)
+	if err != nil {
+		return nil, err
+	}
+
+	return record.Content, nil
+}
+
+// prepareChat 构建提示词及缓存标识, 指定故障中心及指纹时基于告警事件的上下文分析
+func (a aiService) prepareChat(cfg models.AiConfig, r *types.RequestAiChatContent) (string, models.AiContentRecord) {
+	if r.TenantId != "" && r.FaultCenterId != "" && r.Fingerprint != "" {
+		event, err := a.ctx.Redis.Alert().GetEventFromCache(r.TenantId, r.FaultCenterId, r.Fingerprint)
+		if err == nil && event.Fingerprint != "" {
+			return process.BuildEventAnalysisPrompt(a.ctx, cfg, event), models.AiContentRecord{
+				TenantId:    event.TenantId,
+				RuleId:      event.RuleId,
+				Fingerprint: event.Fingerprint,
+				EventId:     event.EventId,
+				ContentHash: process.AiEventContentHash(event),
+			}
 		}
+	}
+
+	return process.RenderAiPrompt(cfg, r.RuleName, r.Content, r.SearchQL), models.AiContentRecord{
+		TenantId:    r.TenantId,
+		RuleId:      r.RuleId,
+		Fingerprint: r.Fingerprint,
+		ContentHash: models.BuildAiContentHash(r.RuleId, r.Fingerprint, r.Content, r.SearchQL),
+	}
+}
 
-		return completion, nil
+// Usage 按模型服务统计租户的 Token 用量
+func (a aiService) Usage(req interface{}) (interface{}, interface{}) {
+	r := req.(*types.RequestAiUsageQuery)
+	list, err := a.ctx.DB.Ai().UsageStat(r.TenantId, r.StartAt, r.EndAt)
+	if err != nil {
+		return nil, err
 	}
+
+	res := types.ResponseAiUsage{List: list}
+	for _, stat := range list {
+		res.Requests += stat.Requests
+		res.PromptTokens += stat.PromptTokens
+		res.CompletionTokens += stat.CompletionTokens
+		res.TotalTokens += stat.TotalTokens
+	}
+
+	return res, nil
 }
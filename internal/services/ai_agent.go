@@ -0,0 +1,493 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"watchAlert/internal/models"
+	"watchAlert/internal/types"
+	"watchAlert/pkg/ai"
+	"watchAlert/pkg/provider"
+	"watchAlert/pkg/tools"
+
+	"github.com/bytedance/sonic"
+)
+
+// 工具查询结果的数量上限, 避免回传给模型的内容过大
+const (
+	agentMaxSeries = 20
+	agentMaxLogs   = 50
+	agentMaxEvents = 30
+)
+
+const rcaAgentSystemPrompt = `您是站点可靠性工程 (SRE) 专家, 正在对一条告警做根因分析。
+您可以调用工具查询指标、日志、当前活跃告警及 Kubernetes 事件来收集证据, 每次调用都应有明确目的, 不要重复相同的查询。
+证据充分后, 使用 Markdown 输出: 1. 结论(最可能的根因) 2. 关键证据(引用执行过的查询及结果) 3. 处置建议。数据不足时请明确说明。`
+
+// Agent 工具调用模式的根因分析, 模型可按需查询数据源后给出结论
+// ctx 为请求的上下文, 客户端断开时停止推理及正在执行的工具调用
+func (a aiService) Agent(ctx context.Context, req interface{}) (interface{}, interface{}) {
+	r := req.(*types.RequestAiAgent)
+	if err := r.ValidateParams(); err != nil {
+		return nil, err
+	}
+
+	setting, err := a.ctx.DB.Setting().Get()
+	if err != nil {
+		return nil, err
+	}
+	if !setting.AiConfig.GetEnable() {
+		return nil, fmt.Errorf("未开启 Ai 分析能力")
+	}
+
+	event, err := a.ctx.Redis.Alert().GetEventFromCache(r.TenantId, r.FaultCenterId, r.Fingerprint)
+	if err != nil || event.Fingerprint == "" {
+		return nil, fmt.Errorf("告警事件不存在, 指纹: %s", r.Fingerprint)
+	}
+
+	client, err := a.ctx.Redis.ProviderPools().GetClient("AiClient")
+	if err != nil {
+		return nil, err
+	}
+	agentClient, ok := client.(ai.ToolCallingClient)
+	if !ok {
+		return nil, fmt.Errorf("当前 AI 客户端不支持工具调用")
+	}
+
+	result, err := ai.RunAgent(ai.WithTenant(ctx, r.TenantId), agentClient, rcaAgentSystemPrompt, buildRcaAgentTask(event, r.Question), a.rcaAgentTools(r.TenantId), r.MaxSteps)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func buildRcaAgentTask(event models.AlertCurEvent, question string) string {
+	var b strings.Builder
+	b.WriteString("请分析以下告警的根因:\n")
+	fmt.Fprintf(&b, "- 规则名称: %s\n- 告警等级: %s\n- 状态: %s\n", event.RuleName, event.Severity, event.Status)
+	fmt.Fprintf(&b, "- 数据源: %s (ID: %s)\n- 故障中心 ID: %s\n", event.DatasourceType, event.DatasourceId, event.FaultCenterId)
+	fmt.Fprintf(&b, "- 首次触发: %s\n", time.Unix(event.FirstTriggerTime, 0).Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(&b, "- 标签: %s\n", tools.JsonMarshalToString(event.Labels))
+	if event.SearchQL != "" {
+		fmt.Fprintf(&b, "- 告警查询语句: %s\n", event.SearchQL)
+	}
+	if event.Annotations != "" {
+		fmt.Fprintf(&b, "- 告警详情: %s\n", event.Annotations)
+	}
+	if question != "" {
+		fmt.Fprintf(&b, "\n补充问题: %s\n", question)
+	}
+
+	return b.String()
+}
+
+// rcaAgentTools 基于已有数据源 Provider 构建 Agent 可调用的工具
+func (a aiService) rcaAgentTools(tenantId string) []ai.AgentTool {
+	return []ai.AgentTool{
+		{
+			Name:        "list_datasources",
+			Description: "列出当前租户可用的数据源, 返回数据源 ID、名称及类型, 用于确定其他工具的 datasourceId",
+			Parameters:  agentToolSchema(map[string]interface{}{}),
+			Handler: func(ctx context.Context, _ string) (string, error) {
+				return ai.CallWithContext(ctx, func() (string, error) {
+					return a.agentListDatasources(tenantId)
+				})
+			},
+		},
+		{
+			Name:        "query_metrics_range",
+			Description: "在 Prometheus/VictoriaMetrics 数据源上执行 PromQL 区间查询, 返回每条时间序列的标签及最小值、最大值、平均值、最新值",
+			Parameters: agentToolSchema(map[string]interface{}{
+				"datasourceId":    agentToolParam("string", "指标数据源 ID"),
+				"promQL":          agentToolParam("string", "PromQL 查询语句"),
+				"lookbackMinutes": agentToolParam("integer", "查询最近多少分钟, 默认 60, 最大 1440"),
+				"stepSeconds":     agentToolParam("integer", "查询步长(秒), 默认 60, 数据点过多时自动放大"),
+			}, "datasourceId", "promQL"),
+			Handler: func(ctx context.Context, arguments string) (string, error) {
+				return ai.CallWithContext(ctx, func() (string, error) {
+					return a.agentQueryMetricsRange(tenantId, arguments)
+				})
+			},
+		},
+		{
+			Name:        "query_logs",
+			Description: "在 Loki/VictoriaLogs/ClickHouse/ElasticSearch 数据源上查询日志样本",
+			Parameters: agentToolSchema(map[string]interface{}{
+				"datasourceId":    agentToolParam("string", "日志数据源 ID"),
+				"query":           agentToolParam("string", "查询语句, ElasticSearch 为 DSL JSON, ClickHouse 仅允许 SELECT"),
+				"index":           agentToolParam("string", "ElasticSearch 索引名称"),
+				"lookbackMinutes": agentToolParam("integer", "查询最近多少分钟, 默认 30, 最大 1440"),
+				"limit":           agentToolParam("integer", "返回的日志条数, 默认 20, 最大 50"),
+			}, "datasourceId", "query"),
+			Handler: func(ctx context.Context, arguments string) (string, error) {
+				return ai.CallWithContext(ctx, func() (string, error) {
+					return a.agentQueryLogs(tenantId, arguments)
+				})
+			},
+		},
+		{
+			Name:        "list_active_events",
+			Description: "列出当前仍在告警中的事件, 可按故障中心、规则名称、等级或标签关键字过滤, 用于判断是否存在关联告警",
+			Parameters: agentToolSchema(map[string]interface{}{
+				"faultCenterId": agentToolParam("string", "故障中心 ID, 为空时查询全部故障中心"),
+				"ruleName":      agentToolParam("string", "规则名称关键字"),
+				"severity":      agentToolParam("string", "告警等级, 如 P0"),
+				"keyword":       agentToolParam("string", "标签值关键字, 如实例名或服务名"),
+			}),
+			Handler: func(ctx context.Context, arguments string) (string, error) {
+				return ai.CallWithContext(ctx, func() (string, error) {
+					return a.agentListActiveEvents(tenantId, arguments)
+				})
+			},
+		},
+		{
+			Name:        "get_kubernetes_events",
+			Description: "读取 Kubernetes 集群中指定原因(Reason)的事件, 如 BackOff、OOMKilling、FailedScheduling、Unhealthy",
+			Parameters: agentToolSchema(map[string]interface{}{
+				"datasourceId":    agentToolParam("string", "Kubernetes 数据源 ID"),
+				"reason":          agentToolParam("string", "事件原因"),
+				"lookbackMinutes": agentToolParam("integer", "查询最近多少分钟, 默认 30, 最大 1440"),
+				"keyword":         agentToolParam("string", "资源名称关键字"),
+			}, "datasourceId", "reason"),
+			Handler: func(ctx context.Context, arguments string) (string, error) {
+				return ai.CallWithContext(ctx, func() (string, error) {
+					return a.agentKubernetesEvents(tenantId, arguments)
+				})
+			},
+		},
+	}
+}
+
+func (a aiService) agentListDatasources(tenantId string) (string, error) {
+	list, err := a.ctx.DB.Datasource().List(tenantId, "", "", "")
+	if err != nil {
+		return "", err
+	}
+
+	var data []map[string]string
+	for _, ds := range list {
+		data = append(data, map[string]string{"id": ds.ID, "name": ds.Name, "type": ds.Type})
+	}
+	return tools.JsonMarshalToString(data), nil
+}
+
+func (a aiService) agentQueryMetricsRange(tenantId, arguments string) (string, error) {
+	var args struct {
+		DatasourceId    string `json:"datasourceId"`
+		PromQL          string `json:"promQL"`
+		LookbackMinutes int    `json:"lookbackMinutes"`
+		StepSeconds     int    `json:"stepSeconds"`
+	}
+	if err := sonic.UnmarshalString(arguments, &args); err != nil {
+		return "", fmt.Errorf("参数解析失败: %s", err.Error())
+	}
+	if args.PromQL == "" {
+		return "", fmt.Errorf("promQL 不能为空")
+	}
+
+	cli, err := a.agentDatasourceClient(tenantId, args.DatasourceId)
+	if err != nil {
+		return "", err
+	}
+	metricsCli, ok := cli.(provider.MetricsFactoryProvider)
+	if !ok {
+		return "", fmt.Errorf("数据源 %s 不是指标类型", args.DatasourceId)
+	}
+
+	lookback, step := models.AiQueryRange(args.LookbackMinutes, args.StepSeconds, 60, 60)
+	end := time.Now()
+	series, err := metricsCli.QueryRange(args.PromQL, end.Add(-lookback), end, step)
+	if err != nil {
+		return "", err
+	}
+
+	return summarizeMetricsSeries(series), nil
+}
+
+// summarizeMetricsSeries 按标签聚合区间查询结果, 仅返回统计值
+func summarizeMetricsSeries(points []provider.Metrics) string {
+	type summary struct {
+		Labels map[string]interface{} `json:"labels"`
+		Points int                    `json:"points"`
+		Min    float64                `json:"min"`
+		Max    float64                `json:"max"`
+		Avg    float64                `json:"avg"`
+		Last   float64                `json:"last"`
+		lastTs float64
+		sum    float64
+	}
+
+	var (
+		order  []string
+		series = map[string]*summary{}
+	)
+	for _, point := range points {
+		fingerprint := point.GetFingerprint()
+		s, ok := series[fingerprint]
+		if !ok {
+			s = &summary{Labels: point.Metric, Min: math.Inf(1), Max: math.Inf(-1)}
+			series[fingerprint] = s
+			order = append(order, fingerprint)
+		}
+		s.Points++
+		s.sum += point.Value
+		s.Min = math.Min(s.Min, point.Value)
+		s.Max = math.Max(s.Max, point.Value)
+		if point.Timestamp >= s.lastTs {
+			s.lastTs, s.Last = point.Timestamp, point.Value
+		}
+	}
+	if len(order) == 0 {
+		return "查询结果为空"
+	}
+
+	var result []*summary
+	for idx, fingerprint := range order {
+		if idx >= agentMaxSeries {
+			break
+		}
+		s := series[fingerprint]
+		s.Avg = math.Round(s.sum/float64(s.Points)*1000) / 1000
+		result = append(result, s)
+	}
+
+	content := tools.JsonMarshalToString(result)
+	if len(order) > agentMaxSeries {
+		content += fmt.Sprintf("\n共 %d 条时间序列, 仅返回前 %d 条", len(order), agentMaxSeries)
+	}
+	return content
+}
+
+func (a aiService) agentQueryLogs(tenantId, arguments string) (string, error) {
+	var args struct {
+		DatasourceId    string `json:"datasourceId"`
+		Query           string `json:"query"`
+		Index           string `json:"index"`
+		LookbackMinutes int    `json:"lookbackMinutes"`
+		Limit           int    `json:"limit"`
+	}
+	if err := sonic.UnmarshalString(arguments, &args); err != nil {
+		return "", fmt.Errorf("参数解析失败: %s", err.Error())
+	}
+	if args.Query == "" {
+		return "", fmt.Errorf("query 不能为空")
+	}
+
+	datasource, err := a.ctx.DB.Datasource().GetInstance(args.DatasourceId)
+	if err != nil || datasource.TenantId != tenantId {
+		return "", fmt.Errorf("数据源不存在: %s", args.DatasourceId)
+	}
+	cli, err := a.ctx.Redis.ProviderPools().GetClient(args.DatasourceId)
+	if err != nil {
+		return "", err
+	}
+	logsCli, ok := cli.(provider.LogsFactoryProvider)
+	if !ok {
+		return "", fmt.Errorf("数据源 %s 不是日志类型", args.DatasourceId)
+	}
+
+	var (
+		limit   = min(agentDefault(args.Limit, 20), agentMaxLogs)
+		end     = time.Now()
+		start   = end.Add(-models.AiQueryLookback(args.LookbackMinutes, 30))
+		options provider.LogQueryOptions
+	)
+	switch datasource.Type {
+	case provider.LokiDsProviderName:
+		options = provider.LogQueryOptions{
+			Loki:    provider.Loki{Query: args.Query, Direction: "backward", Limit: int64(limit)},
+			StartAt: start.Unix(),
+			EndAt:   end.Unix(),
+		}
+	case provider.VictoriaLogsDsProviderName:
+		options = provider.LogQueryOptions{
+			VictoriaLogs: provider.VictoriaLogs{Query: args.Query, Limit: limit},
+			StartAt:      int32(start.Unix()),
+			EndAt:        int32(end.Unix()),
+		}
+	case provider.ClickHouseDsProviderName:
+		if err := models.ValidateAiSQL(args.Query); err != nil {
+			return "", err
+		}
+		options = provider.LogQueryOptions{
+			ClickHouse: provider.ClickHouse{Query: args.Query},
+		}
+	case provider.ElasticSearchDsProviderName:
+		if args.Index == "" {
+			return "", fmt.Errorf("ElasticSearch 数据源需要指定 index")
+		}
+		options = provider.LogQueryOptions{
+			ElasticSearch: provider.Elasticsearch{Index: args.Index, QueryType: "RawJson", RawJson: args.Query},
+		}
+	default:
+		return "", fmt.Errorf("暂不支持查询 %s 类型的日志数据源", datasource.Type)
+	}
+
+	logs, count, err := logsCli.Query(options)
+	if err != nil {
+		return "", err
+	}
+	if len(logs.Message) == 0 {
+		return "查询结果为空", nil
+	}
+
+	messages := logs.Message
+	if len(messages) > limit {
+		messages = messages[:limit]
+	}
+	return fmt.Sprintf("命中 %d 条, 返回 %d 条样本:\n%s", count, len(messages), tools.JsonMarshalToString(messages)), nil
+}
+
+func (a aiService) agentListActiveEvents(tenantId, arguments string) (string, error) {
+	var args struct {
+		FaultCenterId string `json:"faultCenterId"`
+		RuleName      string `json:"ruleName"`
+		Severity      string `json:"severity"`
+		Keyword       string `json:"keyword"`
+	}
+	if err := sonic.UnmarshalString(arguments, &args); err != nil {
+		return "", fmt.Errorf("参数解析失败: %s", err.Error())
+	}
+
+	faultCenters, err := a.ctx.DB.FaultCenter().List(tenantId, "")
+	if err != nil {
+		return "", err
+	}
+
+	var list []map[string]interface{}
+	for _, faultCenter := range faultCenters {
+		if args.FaultCenterId != "" && faultCenter.ID != args.FaultCenterId {
+			continue
+		}
+
+		events, err := a.ctx.Redis.Alert().GetAllEvents(models.BuildAlertEventCacheKey(tenantId, faultCenter.ID))
+		if err != nil {
+			continue
+		}
+		for _, event := range events {
+			if event.Status != models.StateAlerting && event.Status != models.StatePendingRecovery {
+				continue
+			}
+			if args.RuleName != "" && !strings.Contains(event.RuleName, args.RuleName) {
+				continue
+			}
+			if args.Severity != "" && event.Severity != args.Severity {
+				continue
+			}
+			if args.Keyword != "" && !strings.Contains(tools.JsonMarshalToString(event.Labels), args.Keyword) {
+				continue
+			}
+
+			list = append(list, map[string]interface{}{
+				"faultCenter":      faultCenter.Name,
+				"ruleName":         event.RuleName,
+				"severity":         event.Severity,
+				"status":           event.Status,
+				"labels":           event.Labels,
+				"firstTriggerTime": time.Unix(event.FirstTriggerTime, 0).Format("2006-01-02 15:04:05"),
+				"firstTriggerUnix": event.FirstTriggerTime,
+			})
+		}
+	}
+	if len(list) == 0 {
+		return "没有符合条件的活跃告警", nil
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i]["firstTriggerUnix"].(int64) < list[j]["firstTriggerUnix"].(int64)
+	})
+	total := len(list)
+	if total > agentMaxEvents {
+		list = list[:agentMaxEvents]
+	}
+	return fmt.Sprintf("共 %d 条活跃告警:\n%s", total, tools.JsonMarshalToString(list)), nil
+}
+
+func (a aiService) agentKubernetesEvents(tenantId, arguments string) (string, error) {
+	var args struct {
+		DatasourceId    string `json:"datasourceId"`
+		Reason          string `json:"reason"`
+		LookbackMinutes int    `json:"lookbackMinutes"`
+		Keyword         string `json:"keyword"`
+	}
+	if err := sonic.UnmarshalString(arguments, &args); err != nil {
+		return "", fmt.Errorf("参数解析失败: %s", err.Error())
+	}
+	if args.Reason == "" {
+		return "", fmt.Errorf("reason 不能为空")
+	}
+
+	cli, err := a.agentDatasourceClient(tenantId, args.DatasourceId)
+	if err != nil {
+		return "", err
+	}
+	kubeCli, ok := cli.(provider.KubernetesClient)
+	if !ok {
+		return "", fmt.Errorf("数据源 %s 不是 Kubernetes 类型", args.DatasourceId)
+	}
+
+	events, err := kubeCli.GetWarningEvent(args.Reason, int(models.AiQueryLookback(args.LookbackMinutes, 30).Minutes()))
+	if err != nil {
+		return "", err
+	}
+
+	var list []map[string]interface{}
+	for _, event := range events.Items {
+		if args.Keyword != "" && !strings.Contains(event.InvolvedObject.Name, args.Keyword) {
+			continue
+		}
+		list = append(list, map[string]interface{}{
+			"namespace": event.InvolvedObject.Namespace,
+			"kind":      event.InvolvedObject.Kind,
+			"name":      event.InvolvedObject.Name,
+			"reason":    event.Reason,
+			"message":   event.Message,
+			"count":     event.Count,
+			"time":      event.EventTime.Format("2006-01-02 15:04:05"),
+		})
+		if len(list) >= agentMaxEvents {
+			break
+		}
+	}
+	if len(list) == 0 {
+		return "没有符合条件的 Kubernetes 事件", nil
+	}
+
+	return tools.JsonMarshalToString(list), nil
+}
+
+// agentDatasourceClient 获取租户下数据源的客户端
+func (a aiService) agentDatasourceClient(tenantId, datasourceId string) (interface{}, error) {
+	datasource, err := a.ctx.DB.Datasource().GetInstance(datasourceId)
+	if err != nil || datasource.TenantId != tenantId {
+		return nil, fmt.Errorf("数据源不存在: %s", datasourceId)
+	}
+
+	return a.ctx.Redis.ProviderPools().GetClient(datasourceId)
+}
+
+func agentToolSchema(properties map[string]interface{}, required ...string) map[string]interface{} {
+	schema := map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+func agentToolParam(typ, description string) map[string]interface{} {
+	return map[string]interface{}{"type": typ, "description": description}
+}
+
+func agentDefault(value, def int) int {
+	if value <= 0 {
+		return def
+	}
+	return value
+}
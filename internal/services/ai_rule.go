@@ -0,0 +1,337 @@
+package services
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"watchAlert/alert/process"
+	"watchAlert/internal/models"
+	"watchAlert/internal/types"
+	"watchAlert/pkg/ai"
+	"watchAlert/pkg/provider"
+	"watchAlert/pkg/tools"
+
+	"github.com/bytedance/sonic"
+)
+
+// 规则草稿校验时返回的样本数量
+const ruleDraftSampleLimit = 10
+
+var ruleDraftSeverities = []string{"P0", "P1", "P2"}
+
+// ruleDraftSpec 要求模型输出的规则草稿结构
+type ruleDraftSpec struct {
+	RuleName         string         `json:"ruleName"`
+	Description      string         `json:"description"`
+	Query            string         `json:"query"`
+	Index            string         `json:"index"`
+	LogScope         int            `json:"logScope"`
+	EvalInterval     int64          `json:"evalInterval"`
+	Rules            []models.Rules `json:"rules"`
+	Severity         string         `json:"severity"`
+	LogEvalCondition string         `json:"logEvalCondition"`
+	Annotations      string         `json:"annotations"`
+	Explanation      string         `json:"explanation"`
+}
+
+// RuleDraft 根据自然语言描述生成规则草稿, 并使用数据源实际执行查询校验
+func (a aiService) RuleDraft(req interface{}) (interface{}, interface{}) {
+	r := req.(*types.RequestAiRuleDraft)
+	if err := r.ValidateParams(); err != nil {
+		return nil, err
+	}
+
+	setting, err := a.ctx.DB.Setting().Get()
+	if err != nil {
+		return nil, err
+	}
+	if !setting.AiConfig.GetEnable() {
+		return nil, fmt.Errorf("未开启 Ai 分析能力")
+	}
+
+	datasource, err := a.ctx.DB.Datasource().GetInstance(r.DatasourceId)
+	if err != nil || datasource.TenantId != r.TenantId {
+		return nil, fmt.Errorf("数据源不存在: %s", r.DatasourceId)
+	}
+	language, ok := ruleDraftQueryLanguage(datasource.Type)
+	if !ok {
+		return nil, fmt.Errorf("暂不支持为 %s 类型的数据源生成规则", datasource.Type)
+	}
+
+	client, err := a.ctx.Redis.ProviderPools().GetClient("AiClient")
+	if err != nil {
+		return nil, err
+	}
+	completion, err := client.(ai.AiClient).ChatCompletion(ai.WithTenant(a.ctx.Ctx, r.TenantId), buildRuleDraftPrompt(r, datasource, language))
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := ai.ExtractJSON(completion)
+	if err != nil {
+		return nil, err
+	}
+	var spec ruleDraftSpec
+	if err := sonic.UnmarshalString(content, &spec); err != nil {
+		return nil, fmt.Errorf("解析规则草稿失败: %s", err.Error())
+	}
+
+	res := &types.ResponseAiRuleDraft{
+		Rule:        buildRuleDraft(r, datasource.Type, spec),
+		Explanation: spec.Explanation,
+		Triggered:   map[string]int{},
+	}
+	cli, err := a.ctx.Redis.ProviderPools().GetClient(r.DatasourceId)
+	if err != nil {
+		return nil, err
+	}
+	checkRuleDraft(cli, datasource.Type, res)
+
+	return res, nil
+}
+
+// checkRuleDraft 校验规则草稿并使用数据源执行查询, 结果写入 res
+func checkRuleDraft(cli interface{}, datasourceType string, res *types.ResponseAiRuleDraft) {
+	res.ValidationErrors = validateRuleDraft(datasourceType, res.Rule)
+	if err := runRuleDraftQuery(cli, datasourceType, res); err != nil {
+		res.ValidationErrors = append(res.ValidationErrors, fmt.Sprintf("查询执行失败: %s", err.Error()))
+	}
+	res.Valid = len(res.ValidationErrors) == 0
+}
+
+func ruleDraftQueryLanguage(datasourceType string) (string, bool) {
+	switch datasourceType {
+	case provider.PrometheusDsProvider, provider.VictoriaMetricsDsProvider:
+		return "PromQL", true
+	case provider.LokiDsProviderName:
+		return "LogQL", true
+	case provider.VictoriaLogsDsProviderName:
+		return "LogsQL", true
+	case provider.ClickHouseDsProviderName:
+		return "ClickHouse SQL", true
+	case provider.ElasticSearchDsProviderName:
+		return "ElasticSearch Query DSL (JSON)", true
+	default:
+		return "", false
+	}
+}
+
+func isMetricsDatasource(datasourceType string) bool {
+	return datasourceType == provider.PrometheusDsProvider || datasourceType == provider.VictoriaMetricsDsProvider
+}
+
+func buildRuleDraftPrompt(r *types.RequestAiRuleDraft, datasource models.AlertDataSource, language string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "请根据以下需求, 为 %s 类型的数据源 (%s) 编写一条告警规则, 查询语言为 %s。\n", datasource.Type, datasource.Name, language)
+	if r.Description != "" {
+		fmt.Fprintf(&b, "需求描述: %s\n", r.Description)
+	}
+	if r.Draft != nil {
+		fmt.Fprintf(&b, "\n上一版草稿:\n%s\n", tools.JsonMarshalToString(r.Draft))
+	}
+	if r.Feedback != "" {
+		fmt.Fprintf(&b, "修改意见: %s\n", r.Feedback)
+	}
+
+	b.WriteString("\n只输出一个 JSON 对象, 不要输出其他内容, 字段如下:\n")
+	b.WriteString("- ruleName: 规则名称\n- description: 规则描述\n- query: 查询语句\n")
+	b.WriteString("- evalInterval: 评估周期, 单位秒\n- explanation: 对查询语句及阈值的说明\n")
+	if isMetricsDatasource(datasource.Type) {
+		b.WriteString("- rules: 各告警等级的阈值, 数组元素为 {\"severity\": \"P0|P1|P2\", \"expr\": \"> 80\", \"forDuration\": 持续时间(秒)}, 查询结果的值与 expr 比较\n")
+		b.WriteString("- annotations: 告警详情模版, 可使用 ${labels.instance} 引用标签, ${labels.value} 引用当前值\n")
+	} else {
+		b.WriteString("- severity: 告警等级, P0|P1|P2\n")
+		b.WriteString("- logEvalCondition: 命中日志条数的判断条件, 如 \"> 0\"\n")
+		b.WriteString("- logScope: 查询最近多少分钟的日志\n")
+		if datasource.Type == provider.ElasticSearchDsProviderName {
+			b.WriteString("- index: 索引名称, 按天滚动的索引可使用 YYYY.MM.dd 占位\n")
+		}
+	}
+
+	return b.String()
+}
+
+func buildRuleDraft(r *types.RequestAiRuleDraft, datasourceType string, spec ruleDraftSpec) types.RequestRuleCreate {
+	enabled := false
+	rule := types.RequestRuleCreate{
+		TenantId:             r.TenantId,
+		RuleGroupId:          r.RuleGroupId,
+		DatasourceType:       datasourceType,
+		DatasourceIdList:     []string{r.DatasourceId},
+		RuleName:             spec.RuleName,
+		EvalInterval:         spec.EvalInterval,
+		EvalTimeType:         "second",
+		RepeatNoticeInterval: 60,
+		Description:          spec.Description,
+		Severity:             spec.Severity,
+		LogEvalCondition:     spec.LogEvalCondition,
+		FaultCenterId:        r.FaultCenterId,
+		Enabled:              &enabled,
+	}
+	if rule.EvalInterval <= 0 {
+		rule.EvalInterval = 60
+	}
+	logScope := int(models.AiQueryLookback(spec.LogScope, 5).Minutes())
+
+	switch datasourceType {
+	case provider.PrometheusDsProvider, provider.VictoriaMetricsDsProvider:
+		rule.Severity = ""
+		rule.LogEvalCondition = ""
+		rule.PrometheusConfig = models.PrometheusConfig{
+			PromQL:      spec.Query,
+			Annotations: spec.Annotations,
+			Rules:       spec.Rules,
+		}
+	case provider.LokiDsProviderName:
+		rule.LokiConfig = models.LokiConfig{LogQL: spec.Query, LogScope: logScope}
+	case provider.VictoriaLogsDsProviderName:
+		rule.VictoriaLogsConfig = models.VictoriaLogsConfig{LogQL: spec.Query, LogScope: logScope, Limit: 100}
+	case provider.ClickHouseDsProviderName:
+		rule.ClickHouseConfig = models.ClickHouseConfig{LogQL: spec.Query}
+	case provider.ElasticSearchDsProviderName:
+		rule.ElasticSearchConfig = models.ElasticSearchConfig{
+			Index:       spec.Index,
+			Scope:       int64(logScope),
+			EsQueryType: models.EsQueryTypeRawJson,
+			RawJson:     spec.Query,
+		}
+	}
+
+	return rule
+}
+
+// validateRuleDraft 校验规则草稿的必填项及阈值表达式
+func validateRuleDraft(datasourceType string, rule types.RequestRuleCreate) []string {
+	var errs []string
+	if rule.RuleName == "" {
+		errs = append(errs, "规则名称为空")
+	}
+
+	if isMetricsDatasource(datasourceType) {
+		if rule.PrometheusConfig.PromQL == "" {
+			errs = append(errs, "查询语句为空")
+		}
+		if len(rule.PrometheusConfig.Rules) == 0 {
+			errs = append(errs, "未配置告警阈值")
+		}
+		for _, r := range rule.PrometheusConfig.Rules {
+			if !slices.Contains(ruleDraftSeverities, r.Severity) {
+				errs = append(errs, fmt.Sprintf("无效的告警等级: %s", r.Severity))
+			}
+			if _, _, err := tools.ProcessRuleExpr(r.Expr); err != nil {
+				errs = append(errs, fmt.Sprintf("%s 阈值表达式错误: %s", r.Severity, err.Error()))
+			}
+		}
+		return errs
+	}
+
+	if !slices.Contains(ruleDraftSeverities, rule.Severity) {
+		errs = append(errs, fmt.Sprintf("无效的告警等级: %s", rule.Severity))
+	}
+	if _, _, err := tools.ProcessRuleExpr(rule.LogEvalCondition); err != nil {
+		errs = append(errs, fmt.Sprintf("日志判断条件错误: %s", err.Error()))
+	}
+	if datasourceType == provider.ElasticSearchDsProviderName && rule.ElasticSearchConfig.Index == "" {
+		errs = append(errs, "ElasticSearch 索引为空")
+	}
+	return errs
+}
+
+// runRuleDraftQuery 使用数据源执行草稿的查询语句, 返回样本并按当前数据评估各等级是否会触发
+// 指标查询结果为空时说明指标或标签不存在, 视为查询失败; 日志未命中属于正常情况
+func runRuleDraftQuery(cli interface{}, datasourceType string, res *types.ResponseAiRuleDraft) error {
+	if _, ok := ruleDraftQueryLanguage(datasourceType); !ok {
+		return fmt.Errorf("暂不支持 %s 类型的数据源", datasourceType)
+	}
+
+	if isMetricsDatasource(datasourceType) {
+		metricsCli, ok := cli.(provider.MetricsFactoryProvider)
+		if !ok {
+			return fmt.Errorf("数据源不是指标类型")
+		}
+		if res.Rule.PrometheusConfig.PromQL == "" {
+			return fmt.Errorf("查询语句为空")
+		}
+
+		series, err := metricsCli.Query(res.Rule.PrometheusConfig.PromQL)
+		if err != nil {
+			return err
+		}
+		if len(series) == 0 {
+			return fmt.Errorf("查询结果为空, 请确认指标名称及标签是否正确")
+		}
+		res.Total = len(series)
+		for i, s := range series {
+			if i < ruleDraftSampleLimit {
+				res.MetricSamples = append(res.MetricSamples, types.AiRuleDraftMetricSample{Labels: s.GetMetric(), Value: s.Value})
+			}
+			for _, r := range res.Rule.PrometheusConfig.Rules {
+				operator, value, err := tools.ProcessRuleExpr(r.Expr)
+				if err != nil {
+					continue
+				}
+				if process.EvalCondition(models.EvalCondition{Operator: operator, QueryValue: s.Value, ExpectedValue: value}) {
+					res.Triggered[r.Severity]++
+				}
+			}
+		}
+		return nil
+	}
+
+	logsCli, ok := cli.(provider.LogsFactoryProvider)
+	if !ok {
+		return fmt.Errorf("数据源不是日志类型")
+	}
+	if datasourceType == provider.ClickHouseDsProviderName {
+		if err := models.ValidateAiSQL(res.Rule.ClickHouseConfig.LogQL); err != nil {
+			return err
+		}
+	}
+	logs, count, err := logsCli.Query(ruleDraftLogQueryOptions(datasourceType, res.Rule))
+	if err != nil {
+		return err
+	}
+	res.Total = count
+	res.LogSamples = logs.Message
+	if len(res.LogSamples) > ruleDraftSampleLimit {
+		res.LogSamples = res.LogSamples[:ruleDraftSampleLimit]
+	}
+
+	operator, value, err := tools.ProcessRuleExpr(res.Rule.LogEvalCondition)
+	if err == nil && count > 0 && process.EvalCondition(models.EvalCondition{Operator: operator, QueryValue: float64(count), ExpectedValue: value}) {
+		res.Triggered[res.Rule.Severity] = 1
+	}
+	return nil
+}
+
+// ruleDraftLogQueryOptions 与告警评估时的日志查询参数保持一致
+func ruleDraftLogQueryOptions(datasourceType string, rule types.RequestRuleCreate) provider.LogQueryOptions {
+	curAt := time.Now()
+	switch datasourceType {
+	case provider.LokiDsProviderName:
+		return provider.LogQueryOptions{
+			Loki:    provider.Loki{Query: rule.LokiConfig.LogQL},
+			StartAt: tools.ParserDuration(curAt, rule.LokiConfig.LogScope, "m").Unix(),
+			EndAt:   curAt.Unix(),
+		}
+	case provider.VictoriaLogsDsProviderName:
+		return provider.LogQueryOptions{
+			VictoriaLogs: provider.VictoriaLogs{Query: rule.VictoriaLogsConfig.LogQL, Limit: rule.VictoriaLogsConfig.Limit},
+			StartAt:      int32(tools.ParserDuration(curAt, rule.VictoriaLogsConfig.LogScope, "m").Unix()),
+			EndAt:        int32(curAt.Unix()),
+		}
+	case provider.ClickHouseDsProviderName:
+		return provider.LogQueryOptions{
+			ClickHouse: provider.ClickHouse{Query: rule.ClickHouseConfig.LogQL},
+		}
+	default:
+		return provider.LogQueryOptions{
+			ElasticSearch: provider.Elasticsearch{
+				Index:     rule.ElasticSearchConfig.Index,
+				QueryType: rule.ElasticSearchConfig.EsQueryType,
+				RawJson:   rule.ElasticSearchConfig.RawJson,
+			},
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+	"watchAlert/internal/models"
+	"watchAlert/internal/types"
+	"watchAlert/pkg/provider"
+)
+
+type fakeMetricsClient struct {
+	series  []provider.Metrics
+	err     error
+	queries []string
+}
+
+func (f *fakeMetricsClient) Query(promQL string) ([]provider.Metrics, error) {
+	f.queries = append(f.queries, promQL)
+	return f.series, f.err
+}
+
+func (f *fakeMetricsClient) QueryRange(promQL string, _, _ time.Time, _ time.Duration) ([]provider.Metrics, error) {
+	return f.Query(promQL)
+}
+
+func (f *fakeMetricsClient) Check() (bool, error) { return true, nil }
+
+func (f *fakeMetricsClient) GetExternalLabels() map[string]interface{} { return nil }
+
+type fakeLogsClient struct {
+	logs    provider.Logs
+	count   int
+	err     error
+	queried bool
+}
+
+func (f *fakeLogsClient) Query(provider.LogQueryOptions) (provider.Logs, int, error) {
+	f.queried = true
+	return f.logs, f.count, f.err
+}
+
+func (f *fakeLogsClient) Check() (bool, error) { return true, nil }
+
+func (f *fakeLogsClient) GetExternalLabels() map[string]interface{} { return nil }
+
+func metricsDraft(promQL string, rules ...models.Rules) *types.ResponseAiRuleDraft {
+	return &types.ResponseAiRuleDraft{
+		Rule: types.RequestRuleCreate{
+			RuleName:         "cpu usage",
+			DatasourceType:   provider.PrometheusDsProvider,
+			PrometheusConfig: models.PrometheusConfig{PromQL: promQL, Rules: rules},
+		},
+		Triggered: map[string]int{},
+	}
+}
+
+func logsDraft(datasourceType, query string) *types.ResponseAiRuleDraft {
+	return &types.ResponseAiRuleDraft{
+		Rule: types.RequestRuleCreate{
+			RuleName:         "error logs",
+			DatasourceType:   datasourceType,
+			Severity:         "P1",
+			LogEvalCondition: "> 0",
+			LokiConfig:       models.LokiConfig{LogQL: query, LogScope: 5},
+			ClickHouseConfig: models.ClickHouseConfig{LogQL: query},
+		},
+		Triggered: map[string]int{},
+	}
+}
+
+func hasValidationError(res *types.ResponseAiRuleDraft, substr string) bool {
+	for _, e := range res.ValidationErrors {
+		if strings.Contains(e, substr) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestCheckRuleDraftMetrics(t *testing.T) {
+	cli := &fakeMetricsClient{series: []provider.Metrics{
+		{Metric: map[string]interface{}{"instance": "a"}, Value: 95},
+		{Metric: map[string]interface{}{"instance": "b"}, Value: 50},
+	}}
+	res := metricsDraft("cpu_usage", models.Rules{Severity: "P0", Expr: "> 90"}, models.Rules{Severity: "P1", Expr: "> 40"})
+	checkRuleDraft(cli, provider.PrometheusDsProvider, res)
+
+	if !res.Valid || len(res.ValidationErrors) != 0 {
+		t.Fatalf("expected valid draft, got %v", res.ValidationErrors)
+	}
+	if res.Total != 2 || len(res.MetricSamples) != 2 {
+		t.Fatalf("unexpected samples: total=%d samples=%d", res.Total, len(res.MetricSamples))
+	}
+	if res.Triggered["P0"] != 1 || res.Triggered["P1"] != 2 {
+		t.Fatalf("unexpected triggered: %v", res.Triggered)
+	}
+}
+
+func TestCheckRuleDraftRejected(t *testing.T) {
+	t.Run("bad query", func(t *testing.T) {
+		cli := &fakeMetricsClient{err: fmt.Errorf("parse error: unexpected identifier")}
+		res := metricsDraft("cpu_usage{", models.Rules{Severity: "P0", Expr: "> 90"})
+		checkRuleDraft(cli, provider.PrometheusDsProvider, res)
+		if res.Valid || !hasValidationError(res, "parse error") {
+			t.Fatalf("query error should invalidate the draft, got %v", res.ValidationErrors)
+		}
+	})
+
+	t.Run("empty metrics result", func(t *testing.T) {
+		res := metricsDraft("not_exist_metric", models.Rules{Severity: "P0", Expr: "> 90"})
+		checkRuleDraft(&fakeMetricsClient{}, provider.PrometheusDsProvider, res)
+		if res.Valid || !hasValidationError(res, "查询结果为空") {
+			t.Fatalf("empty result should invalidate the draft, got %v", res.ValidationErrors)
+		}
+	})
+
+	t.Run("invalid threshold", func(t *testing.T) {
+		cli := &fakeMetricsClient{series: []provider.Metrics{{Value: 1}}}
+		res := metricsDraft("cpu_usage", models.Rules{Severity: "P9", Expr: "about 90"})
+		checkRuleDraft(cli, provider.PrometheusDsProvider, res)
+		if res.Valid || !hasValidationError(res, "P9") {
+			t.Fatalf("invalid severity and expr should be reported, got %v", res.ValidationErrors)
+		}
+	})
+
+	t.Run("disallowed datasource type", func(t *testing.T) {
+		cli := &fakeMetricsClient{series: []provider.Metrics{{Value: 1}}}
+		res := metricsDraft("cpu_usage", models.Rules{Severity: "P0", Expr: "> 90"})
+		checkRuleDraft(cli, "Jaeger", res)
+		if res.Valid || len(cli.queries) != 0 {
+			t.Fatalf("unsupported datasource should not be queried, got %v", res.ValidationErrors)
+		}
+	})
+
+	t.Run("datasource client mismatch", func(t *testing.T) {
+		res := metricsDraft("cpu_usage", models.Rules{Severity: "P0", Expr: "> 90"})
+		checkRuleDraft(&fakeLogsClient{}, provider.PrometheusDsProvider, res)
+		if res.Valid || !hasValidationError(res, "指标类型") {
+			t.Fatalf("mismatched client should be rejected, got %v", res.ValidationErrors)
+		}
+	})
+
+	t.Run("clickhouse non select", func(t *testing.T) {
+		cli := &fakeLogsClient{}
+		res := logsDraft(provider.ClickHouseDsProviderName, "DROP TABLE logs")
+		checkRuleDraft(cli, provider.ClickHouseDsProviderName, res)
+		if res.Valid || cli.queried {
+			t.Fatalf("non select statement must not be executed, got %v", res.ValidationErrors)
+		}
+	})
+}
+
+func TestCheckRuleDraftLogs(t *testing.T) {
+	cli := &fakeLogsClient{}
+	res := logsDraft(provider.LokiDsProviderName, `{app="api"} |= "error"`)
+	checkRuleDraft(cli, provider.LokiDsProviderName, res)
+	if !res.Valid || res.Total != 0 || res.Triggered["P1"] != 0 {
+		t.Fatalf("log query without hits is still a valid draft, got %+v", res)
+	}
+
+	cli = &fakeLogsClient{count: 3, logs: provider.Logs{Message: []map[string]interface{}{{"msg": "error"}}}}
+	res = logsDraft(provider.LokiDsProviderName, `{app="api"} |= "error"`)
+	checkRuleDraft(cli, provider.LokiDsProviderName, res)
+	if !res.Valid || res.Total != 3 || res.Triggered["P1"] != 1 || len(res.LogSamples) != 1 {
+		t.Fatalf("unexpected log draft result: %+v", res)
+	}
+}
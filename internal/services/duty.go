@@ -2,6 +2,7 @@ package services
 
 import (
 	"fmt"
+	"github.com/zeromicro/go-zero/core/logc"
 	"time"
 	"watchAlert/internal/ctx"
 	"watchAlert/internal/models"
@@ -43,40 +44,108 @@ func (dms *dutyManageService) Create(req interface{}) (interface{}, interface{})
 		return nil, fmt.Errorf("创建失败, 配额不足")
 	}
 
-	err := dms.ctx.DB.Duty().Create(models.DutyManagement{
-		TenantId:    r.TenantId,
-		ID:          "dt-" + tools.RandId(),
-		Name:        r.Name,
-		Manager:     r.Manager,
-		Description: r.Description,
-		CurDutyUser: r.CurDutyUser,
-		UpdateBy:    r.UpdateBy,
-		UpdateAt:    time.Now().Unix(),
-	})
+	duty := models.DutyManagement{
+		TenantId:          r.TenantId,
+		ID:                "dt-" + tools.RandId(),
+		Name:              r.Name,
+		Manager:           r.Manager,
+		Description:       r.Description,
+		CurDutyUser:       r.CurDutyUser,
+		TimeZone:          r.TimeZone,
+		Layers:            r.Layers,
+		HolidayMode:       r.HolidayMode,
+		HolidayUserGroups: r.HolidayUserGroups,
+		UpdateBy:          r.UpdateBy,
+		UpdateAt:          time.Now().Unix(),
+	}
+	if err := duty.ValidateLayers(); err != nil {
+		return nil, err
+	}
+	if err := validateHolidayMode(duty.HolidayMode, duty.HolidayUserGroups); err != nil {
+		return nil, err
+	}
+
+	err := dms.ctx.DB.Duty().Create(duty)
 	if err != nil {
 		return nil, err
 	}
+
+	dms.recordRevision(duty)
 	return nil, nil
 }
 
 func (dms *dutyManageService) Update(req interface{}) (interface{}, interface{}) {
 	r := req.(*types.RequestDutyManagementUpdate)
-	err := dms.ctx.DB.Duty().Update(models.DutyManagement{
-		TenantId:    r.TenantId,
-		ID:          r.ID,
-		Name:        r.Name,
-		Manager:     r.Manager,
-		Description: r.Description,
-		CurDutyUser: r.CurDutyUser,
-		UpdateBy:    r.UpdateBy,
-		UpdateAt:    time.Now().Unix(),
-	})
+	duty := models.DutyManagement{
+		TenantId:          r.TenantId,
+		ID:                r.ID,
+		Name:              r.Name,
+		Manager:           r.Manager,
+		Description:       r.Description,
+		CurDutyUser:       r.CurDutyUser,
+		TimeZone:          r.TimeZone,
+		Layers:            r.Layers,
+		HolidayMode:       r.HolidayMode,
+		HolidayUserGroups: r.HolidayUserGroups,
+		UpdateBy:          r.UpdateBy,
+		UpdateAt:          time.Now().Unix(),
+	}
+	if err := duty.ValidateLayers(); err != nil {
+		return nil, err
+	}
+	if err := validateHolidayMode(duty.HolidayMode, duty.HolidayUserGroups); err != nil {
+		return nil, err
+	}
+
+	// 首次变更前补录原有配置, 使变更前的值班历史仍按原配置解析
+	if revisions, err := dms.ctx.DB.DutyOverride().ListRevisions(r.ID); err == nil && len(revisions) == 0 {
+		if old, err := dms.ctx.DB.Duty().Get(r.TenantId, r.ID); err == nil {
+			old.UpdateAt = 0
+			dms.recordRevision(old)
+		}
+	}
+
+	err := dms.ctx.DB.Duty().Update(duty)
 	if err != nil {
 		return nil, err
 	}
+
+	if updated, err := dms.ctx.DB.Duty().Get(r.TenantId, r.ID); err == nil {
+		dms.recordRevision(updated)
+	}
 	return nil, nil
 }
 
+// validateHolidayMode 校验节假日处理方式
+func validateHolidayMode(mode string, userGroups [][]models.DutyUser) error {
+	switch mode {
+	case "", models.HolidayModeSkip:
+		return nil
+	case models.HolidayModeAssign:
+		if len(userGroups) == 0 {
+			return fmt.Errorf("节假日单独排班时需配置节假日值班人员")
+		}
+		return nil
+	default:
+		return fmt.Errorf("无效的节假日处理方式: %s", mode)
+	}
+}
+
+// recordRevision 记录轮值配置版本, 用于回溯任意时刻的值班人员
+func (dms *dutyManageService) recordRevision(duty models.DutyManagement) {
+	err := dms.ctx.DB.DutyOverride().CreateRevision(models.DutyRevision{
+		TenantId:    duty.TenantId,
+		DutyId:      duty.ID,
+		TimeZone:    duty.TimeZone,
+		Layers:      duty.Layers,
+		EffectiveAt: duty.UpdateAt,
+		UpdateBy:    duty.UpdateBy,
+	})
+	if err != nil {
+		logc.Errorf(dms.ctx.Ctx, "记录值班组 %s 轮值配置版本失败: %s", duty.ID, err.Error())
+	}
+}
+
 func (dms *dutyManageService) Delete(req interface{}) (interface{}, interface{}) {
 	r := req.(*types.RequestDutyManagementQuery)
 	err := dms.ctx.DB.Duty().Delete(r.TenantId, r.ID)
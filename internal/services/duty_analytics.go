@@ -0,0 +1,238 @@
+package services
+
+import (
+	"fmt"
+	"time"
+	"watchAlert/internal/models"
+	"watchAlert/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logc"
+)
+
+// 每次归属最近 24 小时的通知记录, 服务中断不超过 24 小时时可自动补齐
+const dutyPageAttributeRange = 24 * time.Hour
+
+// OnCallAnalytics 统计值班人员的通知次数、夜间及节假日通知、MTTA 与当班时长, 返回排行榜及趋势
+// 通知按小时归属到当班人员, 最近一小时内的通知在下次归属后计入
+func (dms dutyCalendarService) OnCallAnalytics(req interface{}) (interface{}, interface{}) {
+	r := req.(*types.RequestOnCallAnalytics)
+	if r.StartAt <= 0 || r.EndAt <= r.StartAt {
+		return nil, fmt.Errorf("无效的查询时间范围")
+	}
+	if r.EndAt-r.StartAt > models.OnCallAnalyticsMaxRange*24*60*60 {
+		return nil, fmt.Errorf("查询时间范围不能超过 %d 天", models.OnCallAnalyticsMaxRange)
+	}
+
+	switch r.Period {
+	case "":
+		r.Period = models.OnCallPeriodDay
+	case models.OnCallPeriodDay, models.OnCallPeriodWeek, models.OnCallPeriodMonth:
+	default:
+		return nil, fmt.Errorf("无效的统计周期: %s", r.Period)
+	}
+
+	loc := time.Local
+	if r.TimeZone != "" {
+		l, err := time.LoadLocation(r.TimeZone)
+		if err != nil {
+			return nil, fmt.Errorf("无效的时区: %s", r.TimeZone)
+		}
+		loc = l
+	}
+
+	var duties []models.DutyManagement
+	if r.DutyId != "" {
+		duty, err := dms.ctx.DB.Duty().Get(r.TenantId, r.DutyId)
+		if err != nil {
+			return nil, err
+		}
+		duties = append(duties, duty)
+	} else {
+		list, err := dms.ctx.DB.Duty().List(r.TenantId)
+		if err != nil {
+			return nil, err
+		}
+		duties = list
+	}
+
+	start, end := time.Unix(r.StartAt, 0), time.Unix(r.EndAt, 0)
+	var shifts []models.DutyOnCallSegment
+	for _, duty := range duties {
+		timeline, err := dms.ctx.DB.DutyCalendar().GetTimeline(duty.ID, start, end)
+		if err != nil {
+			return nil, err
+		}
+		for _, segment := range timeline.History(start, end) {
+			if r.Level == 0 || segment.Level == r.Level {
+				shifts = append(shifts, segment)
+			}
+		}
+	}
+
+	list, err := dms.ctx.DB.DutyCalendar().ListPages(r.TenantId, r.DutyId, r.StartAt, r.EndAt)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		pages    []models.DutyPage
+		eventIds []string
+		seen     = make(map[string]struct{})
+	)
+	for _, page := range list {
+		if r.Level != 0 && page.Level != r.Level {
+			continue
+		}
+		pages = append(pages, page)
+		if _, ok := seen[page.EventId]; !ok {
+			seen[page.EventId] = struct{}{}
+			eventIds = append(eventIds, page.EventId)
+		}
+	}
+
+	// 认领耗时取自已恢复的历史告警及已认领但尚未恢复的活跃告警
+	events, err := dms.ctx.DB.Event().ListHistoryEventsByIds(r.TenantId, eventIds)
+	if err != nil {
+		return nil, err
+	}
+	acks := make(map[string]models.OnCallAck, len(events))
+	for _, event := range events {
+		if event.ConfirmState.ConfirmActionTime > 0 {
+			acks[event.EventId] = models.OnCallAck{
+				FirstTriggerTime: event.FirstTriggerTime,
+				AckAt:            event.ConfirmState.ConfirmActionTime,
+			}
+		}
+	}
+	if len(acks) < len(seen) {
+		dms.activeAcks(r.TenantId, seen, acks)
+	}
+
+	return models.BuildOnCallAnalytics(pages, acks, shifts, start, end, r.Period, loc, r.SortBy), nil
+}
+
+// activeAcks 从告警缓存中补充已认领的活跃告警, 仅处理 eventIds 中尚无认领记录的事件
+func (dms dutyCalendarService) activeAcks(tenantId string, eventIds map[string]struct{}, acks map[string]models.OnCallAck) {
+	faultCenters, err := dms.ctx.DB.FaultCenter().List(tenantId, "")
+	if err != nil {
+		logc.Errorf(dms.ctx.Ctx, "获取故障中心失败, tenantId: %s, err: %s", tenantId, err.Error())
+		return
+	}
+
+	for _, faultCenter := range faultCenters {
+		events, err := dms.ctx.Redis.Alert().GetAllEvents(models.BuildAlertEventCacheKey(tenantId, faultCenter.ID))
+		if err != nil {
+			continue
+		}
+		for _, event := range events {
+			if _, ok := eventIds[event.EventId]; !ok || event.ConfirmState.ConfirmActionTime <= 0 {
+				continue
+			}
+			if _, ok := acks[event.EventId]; ok {
+				continue
+			}
+			acks[event.EventId] = models.OnCallAck{
+				FirstTriggerTime: event.FirstTriggerTime,
+				AckAt:            event.ConfirmState.ConfirmActionTime,
+			}
+		}
+	}
+}
+
+// AttributePages 将最近 24 小时内发送成功的告警通知归属到发送时刻的当班人员
+// 通知记录仅保留 7 天, 归属结果单独保存用于长期统计
+func (dms dutyCalendarService) AttributePages(now time.Time) {
+	end := now.Truncate(time.Hour)
+	start := end.Add(-dutyPageAttributeRange)
+
+	records, err := dms.ctx.DB.Notice().ListRecordByTime(start.Unix(), end.Unix())
+	if err != nil {
+		logc.Errorf(dms.ctx.Ctx, "获取通知记录失败: %s", err.Error())
+		return
+	}
+
+	var (
+		pages     []models.DutyPage
+		noticeMap = make(map[string]map[string]string)
+		timelines = make(map[string]*models.DutyTimeline)
+		holidays  = make(map[string]models.HolidayCalendar)
+	)
+	for _, record := range records {
+		if !models.IsAlertNoticeRecord(record) {
+			continue
+		}
+
+		duties, ok := noticeMap[record.TenantId]
+		if !ok {
+			duties = dms.noticeDuties(record.TenantId)
+			noticeMap[record.TenantId] = duties
+		}
+		dutyId := duties[models.NoticeIdFromRecord(record)]
+		if dutyId == "" {
+			continue
+		}
+
+		timeline, ok := timelines[dutyId]
+		if !ok {
+			if t, err := dms.ctx.DB.DutyCalendar().GetTimeline(dutyId, start, end); err == nil {
+				timeline = &t
+			}
+			timelines[dutyId] = timeline
+		}
+		if timeline == nil {
+			continue
+		}
+
+		calendar, ok := holidays[record.TenantId]
+		if !ok {
+			// 前后各多取一天, 覆盖不同时区的日期
+			calendar, _ = dms.ctx.DB.DutyCalendar().GetHolidayCalendar(record.TenantId,
+				start.AddDate(0, 0, -1).Format(models.HolidayDateLayout), end.AddDate(0, 0, 1).Format(models.HolidayDateLayout))
+			holidays[record.TenantId] = calendar
+		}
+
+		var (
+			at    = time.Unix(record.CreateAt, 0)
+			local = at.In(timeline.Duty.GetLocation())
+		)
+		for _, onCall := range timeline.At(at) {
+			for _, user := range onCall.Users {
+				pages = append(pages, models.DutyPage{
+					TenantId: record.TenantId,
+					DutyId:   dutyId,
+					NoticeId: models.NoticeIdFromRecord(record),
+					EventId:  record.EventId,
+					RuleName: record.RuleName,
+					Severity: record.Severity,
+					UserId:   user.UserId,
+					Username: user.Username,
+					Layer:    onCall.Layer,
+					Level:    onCall.Level,
+					At:       record.CreateAt,
+					Night:    models.IsOnCallNight(local),
+					OffDay:   !calendar.IsWorkingDay(local),
+				})
+			}
+		}
+	}
+
+	if err := dms.ctx.DB.DutyCalendar().ReplacePages(start.Unix(), end.Unix(), pages); err != nil {
+		logc.Errorf(dms.ctx.Ctx, "保存值班通知归属失败: %s", err.Error())
+	}
+}
+
+// noticeDuties 获取租户下绑定了值班组的通知对象, key 为通知对象 ID, value 为值班组 ID
+func (dms dutyCalendarService) noticeDuties(tenantId string) map[string]string {
+	result := make(map[string]string)
+	notices, err := dms.ctx.DB.Notice().List(tenantId, "", "")
+	if err != nil {
+		logc.Errorf(dms.ctx.Ctx, "获取通知对象失败, tenantId: %s, err: %s", tenantId, err.Error())
+		return result
+	}
+
+	for _, notice := range notices {
+		if notice.DutyId != nil && *notice.DutyId != "" {
+			result[notice.Uuid] = *notice.DutyId
+		}
+	}
+	return result
+}
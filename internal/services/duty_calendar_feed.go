@@ -0,0 +1,273 @@
+package services
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+	"watchAlert/internal/models"
+	"watchAlert/internal/types"
+	"watchAlert/pkg/ical"
+)
+
+const (
+	// 日历订阅包含的班次范围
+	calendarFeedPastDays   = 7
+	calendarFeedFutureDays = 56
+	// 默认节假日导入来源
+	defaultHolidaySource = "default"
+)
+
+// ImportHolidays 导入 ICS 格式的节假日日历, 标题包含调休关键字的日期视为调休上班
+func (dms dutyCalendarService) ImportHolidays(req interface{}) (interface{}, interface{}) {
+	r := req.(*types.RequestHolidayImport)
+	if strings.TrimSpace(r.Content) == "" {
+		return nil, fmt.Errorf("日历内容不能为空")
+	}
+	if r.Source == "" {
+		r.Source = defaultHolidaySource
+	}
+
+	events, err := ical.Parse(strings.NewReader(r.Content))
+	if err != nil {
+		return nil, fmt.Errorf("解析日历失败: %w", err)
+	}
+
+	var (
+		resp   types.ResponseHolidayImport
+		dates  = make(map[string]int)
+		list   []models.DutyHoliday
+		now    = time.Now().Unix()
+		keys   = r.WorkdayKeywords
+		maxDay = 366
+	)
+	for _, event := range events {
+		if !event.AllDay {
+			resp.Skipped++
+			continue
+		}
+
+		holidayType := models.HolidayTypeHoliday
+		if models.IsWorkdayEvent(event.Summary, keys) {
+			holidayType = models.HolidayTypeWorkday
+		}
+		for d, n := event.Start, 0; d.Before(event.End) && n < maxDay; d, n = d.AddDate(0, 0, 1), n+1 {
+			date := d.Format(models.HolidayDateLayout)
+			if idx, ok := dates[date]; ok {
+				// 同一日期既有节假日又有调休时以节假日为准
+				if holidayType == models.HolidayTypeHoliday {
+					list[idx].Type, list[idx].Name = holidayType, event.Summary
+				}
+				continue
+			}
+			dates[date] = len(list)
+			list = append(list, models.DutyHoliday{
+				TenantId: r.TenantId,
+				Date:     date,
+				Name:     event.Summary,
+				Type:     holidayType,
+				Source:   r.Source,
+				CreateAt: now,
+			})
+		}
+	}
+
+	for _, holiday := range list {
+		if holiday.Type == models.HolidayTypeWorkday {
+			resp.Workdays++
+		} else {
+			resp.Holidays++
+		}
+	}
+	if err := dms.ctx.DB.DutyCalendar().ReplaceHolidays(r.TenantId, r.Source, list); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+func (dms dutyCalendarService) ListHolidays(req interface{}) (interface{}, interface{}) {
+	r := req.(*types.RequestHolidayQuery)
+	var start, end string
+	if r.Year > 0 {
+		start, end = fmt.Sprintf("%d-01-01", r.Year), fmt.Sprintf("%d-12-31", r.Year)
+	}
+
+	data, err := dms.ctx.DB.DutyCalendar().ListHolidays(r.TenantId, start, end)
+	if err != nil {
+		return nil, err
+	}
+	if r.Source == "" {
+		return data, nil
+	}
+
+	var filtered []models.DutyHoliday
+	for _, holiday := range data {
+		if holiday.Source == r.Source {
+			filtered = append(filtered, holiday)
+		}
+	}
+	return filtered, nil
+}
+
+func (dms dutyCalendarService) DeleteHolidays(req interface{}) (interface{}, interface{}) {
+	r := req.(*types.RequestHolidayQuery)
+	if r.Source == "" {
+		r.Source = defaultHolidaySource
+	}
+
+	if err := dms.ctx.DB.DutyCalendar().DeleteHolidays(r.TenantId, r.Source); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+// CreateFeed 创建日历订阅, 返回带 Token 的订阅地址
+func (dms dutyCalendarService) CreateFeed(req interface{}) (interface{}, interface{}) {
+	r := req.(*types.RequestCalendarFeedCreate)
+	switch r.Type {
+	case models.CalendarFeedDuty:
+		if _, err := dms.ctx.DB.Duty().Get(r.TenantId, r.DutyId); err != nil {
+			return nil, err
+		}
+	case models.CalendarFeedUser:
+		r.DutyId = ""
+	default:
+		return nil, fmt.Errorf("无效的订阅类型: %s", r.Type)
+	}
+
+	token, err := newFeedToken()
+	if err != nil {
+		return nil, err
+	}
+	feed := models.DutyCalendarFeed{
+		TenantId: r.TenantId,
+		Token:    token,
+		Type:     r.Type,
+		DutyId:   r.DutyId,
+		UserId:   r.UserId,
+		CreateBy: r.CreateBy,
+		CreateAt: time.Now().Unix(),
+	}
+	if err := dms.ctx.DB.DutyCalendar().CreateFeed(feed); err != nil {
+		return nil, err
+	}
+
+	return types.ResponseCalendarFeed{DutyCalendarFeed: feed, Path: feedPath(token)}, nil
+}
+
+func (dms dutyCalendarService) DeleteFeed(req interface{}) (interface{}, interface{}) {
+	r := req.(*types.RequestCalendarFeedQuery)
+	feed, err := dms.ctx.DB.DutyCalendar().GetFeed(r.Token)
+	if err != nil || feed.TenantId != r.TenantId || feed.UserId != r.UserId {
+		return nil, fmt.Errorf("订阅不存在")
+	}
+
+	if err := dms.ctx.DB.DutyCalendar().DeleteFeed(r.TenantId, r.Token); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+// ListFeeds 获取当前用户创建的日历订阅
+func (dms dutyCalendarService) ListFeeds(req interface{}) (interface{}, interface{}) {
+	r := req.(*types.RequestCalendarFeedQuery)
+	feeds, err := dms.ctx.DB.DutyCalendar().ListFeeds(r.TenantId, r.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]types.ResponseCalendarFeed, 0, len(feeds))
+	for _, feed := range feeds {
+		data = append(data, types.ResponseCalendarFeed{DutyCalendarFeed: feed, Path: feedPath(feed.Token)})
+	}
+	return data, nil
+}
+
+// Feed 按 Token 输出 iCalendar 格式的值班日历, 包含过去 7 天及未来 8 周的班次
+func (dms dutyCalendarService) Feed(req interface{}) (interface{}, interface{}) {
+	r := req.(*types.RequestCalendarFeedQuery)
+	feed, err := dms.ctx.DB.DutyCalendar().GetFeed(r.Token)
+	if err != nil {
+		return nil, fmt.Errorf("订阅不存在")
+	}
+
+	var duties []models.DutyManagement
+	if feed.Type == models.CalendarFeedDuty {
+		duty, err := dms.ctx.DB.Duty().Get(feed.TenantId, feed.DutyId)
+		if err != nil {
+			return nil, err
+		}
+		duties = append(duties, duty)
+	} else {
+		duties, err = dms.ctx.DB.Duty().List(feed.TenantId)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var (
+		now   = time.Now()
+		start = now.AddDate(0, 0, -calendarFeedPastDays).Truncate(time.Hour)
+		end   = now.AddDate(0, 0, calendarFeedFutureDays).Truncate(time.Hour)
+		cal   = ical.Calendar{Name: "WatchAlert 我的值班"}
+	)
+	if feed.Type == models.CalendarFeedDuty {
+		cal.Name = "WatchAlert 值班 - " + duties[0].Name
+	}
+
+	for _, duty := range duties {
+		timeline, err := dms.ctx.DB.DutyCalendar().GetTimeline(duty.ID, start, end)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, segment := range timeline.History(start, end) {
+			names := make([]string, 0, len(segment.Users))
+			var mine bool
+			for _, user := range segment.Users {
+				names = append(names, user.Username)
+				mine = mine || user.UserId == feed.UserId
+			}
+			if len(names) == 0 || (feed.Type == models.CalendarFeedUser && !mine) {
+				continue
+			}
+
+			summary := fmt.Sprintf("%s %s: %s", duty.Name, segment.Layer, strings.Join(names, ", "))
+			if feed.Type == models.CalendarFeedUser {
+				summary = fmt.Sprintf("值班: %s (%s)", duty.Name, segment.Layer)
+			}
+			description := fmt.Sprintf("值班组: %s\n轮值层: %s\n值班人员: %s", duty.Name, segment.Layer, strings.Join(names, ", "))
+			if segment.Override != "" {
+				description += "\n覆盖值班: " + segment.Override
+			}
+			cal.Events = append(cal.Events, ical.Event{
+				UID:         fmt.Sprintf("%s-%s-%d@watchalert", duty.ID, segment.Layer, segment.StartAt),
+				Summary:     summary,
+				Description: description,
+				Start:       time.Unix(segment.StartAt, 0),
+				End:         time.Unix(segment.EndAt, 0),
+			})
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := cal.Write(&buf, now); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func newFeedToken() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func feedPath(token string) string {
+	return "/api/w8t/calendar/feed/" + token + ".ics"
+}
@@ -0,0 +1,350 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"watchAlert/alert/process"
+	"watchAlert/internal/ctx"
+	"watchAlert/internal/models"
+	"watchAlert/internal/types"
+	"watchAlert/pkg/tools"
+
+	"github.com/zeromicro/go-zero/core/logc"
+)
+
+const (
+	// HandoffCheckInterval 班次边界检查周期, 与定时任务的执行周期一致
+	HandoffCheckInterval = time.Minute
+	// 交出班次开始时间未知或过早时, 报告统计的最大时间范围
+	handoffMaxShiftRange = 7 * 24 * time.Hour
+	// 每个故障中心统计的静默规则上限
+	handoffMaxSilences = 1000
+)
+
+type dutyHandoffService struct {
+	ctx *ctx.Context
+}
+
+type InterDutyHandoffService interface {
+	Create(req interface{}) (interface{}, interface{})
+	List(req interface{}) (interface{}, interface{})
+	Get(req interface{}) (interface{}, interface{})
+	CheckShiftBoundary(now time.Time)
+}
+
+func newInterDutyHandoffService(ctx *ctx.Context) InterDutyHandoffService {
+	return &dutyHandoffService{
+		ctx: ctx,
+	}
+}
+
+// Create 手动生成当前班次的交接报告, 接班人员为下一班次的主值班人员
+func (hs dutyHandoffService) Create(req interface{}) (interface{}, interface{}) {
+	r := req.(*types.RequestDutyHandoffCreate)
+	duty, err := hs.ctx.DB.Duty().Get(r.TenantId, r.DutyId)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	timeline, err := hs.ctx.DB.DutyCalendar().GetTimeline(duty.ID, now.Add(-handoffMaxShiftRange), now.Add(handoffMaxShiftRange))
+	if err != nil {
+		return nil, err
+	}
+
+	outgoing, shiftStart, shiftEnd := models.HandoffOnCall(timeline.At(now))
+	if len(outgoing) == 0 {
+		return nil, fmt.Errorf("值班组当前无主值班人员")
+	}
+	var (
+		incoming     []models.DutyUser
+		nextShiftEnd int64
+	)
+	if shiftEnd > now.Unix() {
+		incoming, _, nextShiftEnd = models.HandoffOnCall(timeline.At(time.Unix(shiftEnd, 0)))
+	}
+
+	handoff := hs.build(duty, outgoing, incoming, shiftStart, now, nextShiftEnd)
+	handoff.Source = models.HandoffSourceManual
+	handoff.CreateBy = r.CreateBy
+	if r.Notify {
+		hs.deliver(duty, &handoff)
+	}
+	if err := hs.ctx.DB.DutyHandoff().Create(handoff); err != nil {
+		return nil, err
+	}
+
+	return handoff, nil
+}
+
+func (hs dutyHandoffService) List(req interface{}) (interface{}, interface{}) {
+	r := req.(*types.RequestDutyHandoffQuery)
+	data, count, err := hs.ctx.DB.DutyHandoff().List(r.TenantId, r.DutyId, r.Page)
+	if err != nil {
+		return nil, err
+	}
+
+	return types.ResponseDutyHandoffList{
+		List: data,
+		Page: models.Page{
+			Total: count,
+			Index: r.Page.Index,
+			Size:  r.Page.Size,
+		},
+	}, nil
+}
+
+func (hs dutyHandoffService) Get(req interface{}) (interface{}, interface{}) {
+	r := req.(*types.RequestDutyHandoffQuery)
+	return hs.ctx.DB.DutyHandoff().Get(r.TenantId, r.ID)
+}
+
+// CheckShiftBoundary 检查各值班组在上一检查周期内是否发生主值班人员交接, 发生时生成交接报告并发送到值班组绑定的通知对象
+func (hs dutyHandoffService) CheckShiftBoundary(now time.Time) {
+	now = now.Truncate(time.Minute)
+	prev := now.Add(-HandoffCheckInterval)
+
+	duties, err := hs.ctx.DB.Duty().ListAll()
+	if err != nil {
+		logc.Errorf(hs.ctx.Ctx, "获取值班组列表失败: %s", err.Error())
+		return
+	}
+
+	for _, duty := range duties {
+		timeline, err := hs.ctx.DB.DutyCalendar().GetTimeline(duty.ID, prev, now)
+		if err != nil {
+			logc.Errorf(hs.ctx.Ctx, "获取值班时间线失败, dutyId: %s, err: %s", duty.ID, err.Error())
+			continue
+		}
+
+		outgoing, shiftStart, _ := models.HandoffOnCall(timeline.At(prev))
+		incoming, _, nextShiftEnd := models.HandoffOnCall(timeline.At(now))
+		if len(incoming) == 0 || models.SameDutyUsers(outgoing, incoming) {
+			continue
+		}
+		if hs.ctx.DB.DutyHandoff().Exists(duty.ID, now.Unix()) {
+			continue
+		}
+
+		handoff := hs.build(duty, outgoing, incoming, shiftStart, now, nextShiftEnd)
+		handoff.Source = models.HandoffSourceAuto
+		handoff.CreateBy = process.IncidentSystemOperator
+		hs.deliver(duty, &handoff)
+		if err := hs.ctx.DB.DutyHandoff().Create(handoff); err != nil {
+			logc.Errorf(hs.ctx.Ctx, "保存交接报告失败, dutyId: %s, err: %s", duty.ID, err.Error())
+		}
+	}
+}
+
+// build 汇总值班组关联故障中心在交出班次内的告警情况
+func (hs dutyHandoffService) build(duty models.DutyManagement, outgoing, incoming []models.DutyUser, shiftStart int64, at time.Time, nextShiftEnd int64) models.DutyHandoff {
+	if earliest := at.Add(-handoffMaxShiftRange).Unix(); shiftStart < earliest {
+		shiftStart = earliest
+	}
+
+	handoff := models.DutyHandoff{
+		TenantId:      duty.TenantId,
+		ID:            "dh-" + tools.RandId(),
+		DutyId:        duty.ID,
+		DutyName:      duty.Name,
+		OutgoingUsers: outgoing,
+		IncomingUsers: incoming,
+		ShiftStart:    shiftStart,
+		HandoffAt:     at.Unix(),
+		NextShiftEnd:  nextShiftEnd,
+		CreateAt:      time.Now().Unix(),
+	}
+
+	faultCenters, err := hs.dutyFaultCenters(duty)
+	if err != nil {
+		logc.Errorf(hs.ctx.Ctx, "获取值班组关联的故障中心失败, dutyId: %s, err: %s", duty.ID, err.Error())
+	}
+	for _, faultCenter := range faultCenters {
+		handoff.Report.FaultCenters = append(handoff.Report.FaultCenters, hs.buildSection(faultCenter, shiftStart, at.Unix(), nextShiftEnd))
+	}
+
+	return handoff
+}
+
+func (hs dutyHandoffService) buildSection(faultCenter models.FaultCenter, shiftStart, at, nextShiftEnd int64) models.DutyHandoffSection {
+	section := models.DutyHandoffSection{
+		FaultCenterId:   faultCenter.ID,
+		FaultCenterName: faultCenter.Name,
+	}
+
+	var (
+		fired   = make(map[string]struct{})
+		pending []*models.AlertCurEvent
+	)
+	events, _ := hs.ctx.Redis.Alert().GetAllEvents(models.BuildAlertEventCacheKey(faultCenter.TenantId, faultCenter.ID))
+	for _, event := range events {
+		item := models.DutyHandoffEvent{
+			Fingerprint:      event.Fingerprint,
+			RuleName:         event.RuleName,
+			Severity:         event.Severity,
+			Status:           string(event.Status),
+			FirstTriggerTime: event.FirstTriggerTime,
+			RecoverTime:      event.RecoverTime,
+			ConfirmUsername:  event.ConfirmState.ConfirmUsername,
+		}
+		if event.Status == models.StateAlerting || event.Status == models.StatePendingRecovery {
+			pending = append(pending, event)
+			if event.ConfirmState.IsOk {
+				section.Acknowledged = append(section.Acknowledged, item)
+			} else {
+				section.Open = append(section.Open, item)
+			}
+		}
+		if event.Status != models.StatePreAlert && event.FirstTriggerTime >= shiftStart && event.FirstTriggerTime < at {
+			fired[fmt.Sprintf("%s:%d", event.Fingerprint, event.FirstTriggerTime)] = struct{}{}
+			section.Fired = append(section.Fired, item)
+		}
+	}
+
+	history, err := hs.ctx.DB.Event().ListHistoryEventsByTime(faultCenter.TenantId, faultCenter.ID, nil, shiftStart, at)
+	if err != nil {
+		logc.Errorf(hs.ctx.Ctx, "获取历史告警失败, faultCenterId: %s, err: %s", faultCenter.ID, err.Error())
+	}
+	for _, event := range history {
+		key := fmt.Sprintf("%s:%d", event.Fingerprint, event.FirstTriggerTime)
+		if _, ok := fired[key]; ok || event.FirstTriggerTime < shiftStart {
+			continue
+		}
+		fired[key] = struct{}{}
+		section.Fired = append(section.Fired, models.DutyHandoffEvent{
+			Fingerprint:      event.Fingerprint,
+			RuleName:         event.RuleName,
+			Severity:         event.Severity,
+			Status:           string(models.StateRecovered),
+			FirstTriggerTime: event.FirstTriggerTime,
+			RecoverTime:      event.RecoverTime,
+			ConfirmUsername:  event.ConfirmState.ConfirmUsername,
+		})
+	}
+
+	for _, list := range [][]models.DutyHandoffEvent{section.Open, section.Acknowledged, section.Fired} {
+		sort.Slice(list, func(i, j int) bool { return list[i].FirstTriggerTime < list[j].FirstTriggerTime })
+	}
+
+	if nextShiftEnd > at {
+		silences, _, err := hs.ctx.DB.Silence().List(faultCenter.TenantId, faultCenter.ID, "", models.Page{Index: 1, Size: handoffMaxSilences})
+		if err != nil {
+			logc.Errorf(hs.ctx.Ctx, "获取静默规则失败, faultCenterId: %s, err: %s", faultCenter.ID, err.Error())
+		}
+		for _, silence := range silences {
+			if silence.Status == models.SilenceStatusExpired || silence.EndsAt < at || silence.EndsAt >= nextShiftEnd {
+				continue
+			}
+			section.ExpiringSilences = append(section.ExpiringSilences, models.DutyHandoffSilence{
+				ID:      silence.ID,
+				Name:    silence.Name,
+				Comment: silence.Comment,
+				EndsAt:  silence.EndsAt,
+			})
+		}
+		sort.Slice(section.ExpiringSilences, func(i, j int) bool {
+			return section.ExpiringSilences[i].EndsAt < section.ExpiringSilences[j].EndsAt
+		})
+	}
+
+	for _, event := range pending {
+		comments, err := hs.ctx.DB.Comment().List(types.RequestListEventComments{TenantId: event.TenantId, Fingerprint: event.Fingerprint})
+		if err != nil {
+			continue
+		}
+		for _, comment := range comments {
+			// 只保留本次告警触发后的评论
+			if comment.Time < event.FirstTriggerTime {
+				continue
+			}
+			section.Comments = append(section.Comments, models.DutyHandoffComment{
+				Fingerprint: event.Fingerprint,
+				RuleName:    event.RuleName,
+				Username:    comment.Username,
+				Content:     comment.Content,
+				Time:        comment.Time,
+			})
+		}
+	}
+	sort.Slice(section.Comments, func(i, j int) bool { return section.Comments[i].Time < section.Comments[j].Time })
+
+	return section
+}
+
+// dutyFaultCenters 获取通知对象（含通知路由）绑定了该值班组的故障中心
+func (hs dutyHandoffService) dutyFaultCenters(duty models.DutyManagement) ([]models.FaultCenter, error) {
+	noticeIds, err := hs.dutyNoticeIds(duty)
+	if err != nil || len(noticeIds) == 0 {
+		return nil, err
+	}
+	bound := make(map[string]struct{}, len(noticeIds))
+	for _, id := range noticeIds {
+		bound[id] = struct{}{}
+	}
+
+	faultCenters, err := hs.ctx.DB.FaultCenter().List(duty.TenantId, "")
+	if err != nil {
+		return nil, err
+	}
+
+	var result []models.FaultCenter
+	for _, faultCenter := range faultCenters {
+		ids := append([]string{}, faultCenter.NoticeIds...)
+		for _, route := range faultCenter.NoticeRoutes {
+			ids = append(ids, route.NoticeIds...)
+		}
+		for _, id := range ids {
+			if _, ok := bound[id]; ok {
+				result = append(result, faultCenter)
+				break
+			}
+		}
+	}
+	return result, nil
+}
+
+func (hs dutyHandoffService) dutyNoticeIds(duty models.DutyManagement) ([]string, error) {
+	notices, err := hs.ctx.DB.Notice().List(duty.TenantId, "", "")
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	for _, notice := range notices {
+		if notice.DutyId != nil && *notice.DutyId == duty.ID {
+			ids = append(ids, notice.Uuid)
+		}
+	}
+	return ids, nil
+}
+
+// deliver 发送交接报告到值班组绑定的通知对象, 发送结果记录在交接报告中
+func (hs dutyHandoffService) deliver(duty models.DutyManagement, handoff *models.DutyHandoff) {
+	noticeIds, err := hs.dutyNoticeIds(duty)
+	if err != nil {
+		handoff.SendError = err.Error()
+		return
+	}
+	if len(noticeIds) == 0 {
+		handoff.SendError = "值班组未绑定通知对象"
+		return
+	}
+
+	var (
+		title  = fmt.Sprintf("【值班交接】%s", duty.Name)
+		text   = handoff.Text(duty.GetLocation())
+		failed []string
+	)
+	handoff.NoticeIds = noticeIds
+	for _, noticeId := range noticeIds {
+		if err := process.SendTextNotice(hs.ctx, duty.TenantId, noticeId, handoff.ID, duty.Name, "", title, text); err != nil {
+			logc.Errorf(hs.ctx.Ctx, "交接报告发送失败, dutyId: %s, noticeId: %s, err: %s", duty.ID, noticeId, err.Error())
+			failed = append(failed, noticeId)
+		}
+	}
+	if len(failed) > 0 {
+		handoff.SendError = fmt.Sprintf("部分通知对象发送失败: %s", strings.Join(failed, ", "))
+	}
+}
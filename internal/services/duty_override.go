@@ -0,0 +1,350 @@
+package services
+
+import (
+	"fmt"
+	"github.com/zeromicro/go-zero/core/logc"
+	"strings"
+	"time"
+	"watchAlert/internal/ctx"
+	"watchAlert/internal/models"
+	"watchAlert/internal/types"
+	"watchAlert/pkg/client"
+	"watchAlert/pkg/tools"
+)
+
+type dutyOverrideService struct {
+	ctx *ctx.Context
+}
+
+type InterDutyOverrideService interface {
+	Create(req interface{}) (interface{}, interface{})
+	Cancel(req interface{}) (interface{}, interface{})
+	List(req interface{}) (interface{}, interface{})
+	CreateSwap(req interface{}) (interface{}, interface{})
+	AcceptSwap(req interface{}) (interface{}, interface{})
+	RejectSwap(req interface{}) (interface{}, interface{})
+	ListSwap(req interface{}) (interface{}, interface{})
+}
+
+func newInterDutyOverrideService(ctx *ctx.Context) InterDutyOverrideService {
+	return &dutyOverrideService{
+		ctx: ctx,
+	}
+}
+
+// Create 创建覆盖值班, 在指定时段内由指定用户顶替轮值层的当班人员
+func (ds dutyOverrideService) Create(req interface{}) (interface{}, interface{}) {
+	r := req.(*types.RequestDutyOverrideCreate)
+	duty, err := ds.ctx.DB.Duty().Get(r.TenantId, r.DutyId)
+	if err != nil {
+		return nil, err
+	}
+	if !duty.HasLayer(r.Layer) {
+		return nil, fmt.Errorf("轮值层 %s 不存在", r.Layer)
+	}
+	if r.User.UserId == "" {
+		return nil, fmt.Errorf("值班人员不能为空")
+	}
+	if r.StartAt <= 0 || r.EndAt <= r.StartAt {
+		return nil, fmt.Errorf("无效的覆盖时段")
+	}
+
+	override := models.DutyOverride{
+		TenantId: r.TenantId,
+		ID:       "do-" + tools.RandId(),
+		DutyId:   r.DutyId,
+		Layer:    r.Layer,
+		User:     r.User,
+		StartAt:  r.StartAt,
+		EndAt:    r.EndAt,
+		Reason:   r.Reason,
+		CreateBy: r.CreateBy,
+		CreateAt: time.Now().Unix(),
+	}
+	if err := ds.ctx.DB.DutyOverride().Create(override); err != nil {
+		return nil, err
+	}
+
+	return override, nil
+}
+
+// Cancel 取消覆盖值班, 已生效的时段仍保留在值班历史中
+func (ds dutyOverrideService) Cancel(req interface{}) (interface{}, interface{}) {
+	r := req.(*types.RequestDutyOverrideCancel)
+	override, err := ds.ctx.DB.DutyOverride().Get(r.TenantId, r.ID)
+	if err != nil {
+		return nil, err
+	}
+	if override.CanceledAt != 0 {
+		return nil, fmt.Errorf("覆盖值班已取消")
+	}
+
+	err = ds.ctx.DB.DutyOverride().Cancel(r.TenantId, r.ID, r.CanceledBy, time.Now().Unix())
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+func (ds dutyOverrideService) List(req interface{}) (interface{}, interface{}) {
+	r := req.(*types.RequestDutyOverrideQuery)
+	startAt, endAt := r.StartAt, r.EndAt
+	if endAt <= 0 {
+		endAt = time.Now().AddDate(1, 0, 0).Unix()
+	}
+
+	data, err := ds.ctx.DB.DutyOverride().List(r.TenantId, r.DutyId, startAt, endAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// CreateSwap 发起换班申请, 需由对方确认
+func (ds dutyOverrideService) CreateSwap(req interface{}) (interface{}, interface{}) {
+	r := req.(*types.RequestDutySwapCreate)
+	duty, err := ds.ctx.DB.Duty().Get(r.TenantId, r.DutyId)
+	if err != nil {
+		return nil, err
+	}
+
+	if !duty.HasLayer(r.Layer) {
+		return nil, fmt.Errorf("轮值层 %s 不存在", r.Layer)
+	}
+
+	requester, err := ds.getDutyUser(r.UserId)
+	if err != nil {
+		return nil, err
+	}
+	counterpart, err := ds.getDutyUser(r.Counterpart.UserId)
+	if err != nil {
+		return nil, fmt.Errorf("换班对象不存在")
+	}
+	if requester.UserId == counterpart.UserId {
+		return nil, fmt.Errorf("不能与自己换班")
+	}
+	if r.StartAt <= 0 || r.EndAt <= r.StartAt {
+		return nil, fmt.Errorf("无效的换班时段")
+	}
+	if (r.CounterStartAt != 0 || r.CounterEndAt != 0) && r.CounterEndAt <= r.CounterStartAt {
+		return nil, fmt.Errorf("无效的对方班次时段")
+	}
+
+	if !ds.isOnCall(duty.ID, r.Layer, requester.UserId, r.StartAt) {
+		return nil, fmt.Errorf("%s 在换班时段内不在值班", requester.Username)
+	}
+	if r.CounterStartAt != 0 && !ds.isOnCall(duty.ID, r.Layer, counterpart.UserId, r.CounterStartAt) {
+		return nil, fmt.Errorf("%s 在对方班次时段内不在值班", counterpart.Username)
+	}
+
+	now := time.Now().Unix()
+	swap := models.DutySwap{
+		TenantId:       r.TenantId,
+		ID:             "ds-" + tools.RandId(),
+		DutyId:         duty.ID,
+		Layer:          r.Layer,
+		Requester:      requester,
+		Counterpart:    counterpart,
+		StartAt:        r.StartAt,
+		EndAt:          r.EndAt,
+		CounterStartAt: r.CounterStartAt,
+		CounterEndAt:   r.CounterEndAt,
+		Reason:         r.Reason,
+		Status:         models.DutySwapPending,
+		CreateAt:       now,
+		UpdateAt:       now,
+	}
+	if err := ds.ctx.DB.DutyOverride().CreateSwap(swap); err != nil {
+		return nil, err
+	}
+
+	ds.notify(duty, swap, fmt.Sprintf("%s 申请与你换班", requester.Username), counterpart)
+	return swap, nil
+}
+
+// AcceptSwap 对方接受换班申请, 生成覆盖值班并通知值班组负责人
+func (ds dutyOverrideService) AcceptSwap(req interface{}) (interface{}, interface{}) {
+	r := req.(*types.RequestDutySwapAction)
+	swap, err := ds.ctx.DB.DutyOverride().GetSwap(r.TenantId, r.ID)
+	if err != nil {
+		return nil, err
+	}
+	if swap.Status != models.DutySwapPending {
+		return nil, fmt.Errorf("换班申请已处理")
+	}
+	if swap.Counterpart.UserId != r.UserId {
+		return nil, fmt.Errorf("只有换班对象可以接受申请")
+	}
+	duty, err := ds.ctx.DB.Duty().Get(r.TenantId, swap.DutyId)
+	if err != nil {
+		return nil, err
+	}
+
+	// 换班只替换班次中的换班双方, 同一班次的其他值班人员保持不变
+	now := time.Now().Unix()
+	overrides := []models.DutyOverride{{
+		User:          swap.Counterpart,
+		ReplaceUserId: swap.Requester.UserId,
+		StartAt:       swap.StartAt,
+		EndAt:         swap.EndAt,
+	}}
+	if swap.CounterStartAt != 0 {
+		overrides = append(overrides, models.DutyOverride{
+			User:          swap.Requester,
+			ReplaceUserId: swap.Counterpart.UserId,
+			StartAt:       swap.CounterStartAt,
+			EndAt:         swap.CounterEndAt,
+		})
+	}
+	for i := range overrides {
+		overrides[i].TenantId = swap.TenantId
+		overrides[i].ID = "do-" + tools.RandId()
+		overrides[i].DutyId = swap.DutyId
+		overrides[i].Layer = swap.Layer
+		overrides[i].Reason = fmt.Sprintf("%s 与 %s 换班: %s", swap.Requester.Username, swap.Counterpart.Username, swap.Reason)
+		overrides[i].SwapId = swap.ID
+		overrides[i].CreateBy = swap.Counterpart.Username
+		overrides[i].CreateAt = now
+		swap.OverrideIds = append(swap.OverrideIds, overrides[i].ID)
+	}
+
+	swap.Status = models.DutySwapAccepted
+	swap.UpdateAt = now
+	updated, err := ds.ctx.DB.DutyOverride().UpdatePendingSwap(swap, overrides)
+	if err != nil {
+		return nil, err
+	}
+	if !updated {
+		return nil, fmt.Errorf("换班申请已处理")
+	}
+
+	title := fmt.Sprintf("%s 已接受 %s 的换班申请", swap.Counterpart.Username, swap.Requester.Username)
+	ds.notify(duty, swap, title, duty.Manager, swap.Requester)
+	return swap, nil
+}
+
+// RejectSwap 对方拒绝或申请人撤回换班申请
+func (ds dutyOverrideService) RejectSwap(req interface{}) (interface{}, interface{}) {
+	r := req.(*types.RequestDutySwapAction)
+	swap, err := ds.ctx.DB.DutyOverride().GetSwap(r.TenantId, r.ID)
+	if err != nil {
+		return nil, err
+	}
+	if swap.Status != models.DutySwapPending {
+		return nil, fmt.Errorf("换班申请已处理")
+	}
+
+	switch r.UserId {
+	case swap.Counterpart.UserId:
+		swap.Status = models.DutySwapRejected
+	case swap.Requester.UserId:
+		swap.Status = models.DutySwapCanceled
+	default:
+		return nil, fmt.Errorf("只有换班双方可以处理申请")
+	}
+	swap.UpdateAt = time.Now().Unix()
+	updated, err := ds.ctx.DB.DutyOverride().UpdatePendingSwap(swap, nil)
+	if err != nil {
+		return nil, err
+	}
+	if !updated {
+		return nil, fmt.Errorf("换班申请已处理")
+	}
+
+	if swap.Status == models.DutySwapRejected {
+		if duty, err := ds.ctx.DB.Duty().Get(r.TenantId, swap.DutyId); err == nil {
+			ds.notify(duty, swap, fmt.Sprintf("%s 拒绝了你的换班申请", swap.Counterpart.Username), swap.Requester)
+		}
+	}
+	return swap, nil
+}
+
+func (ds dutyOverrideService) ListSwap(req interface{}) (interface{}, interface{}) {
+	r := req.(*types.RequestDutySwapQuery)
+	data, err := ds.ctx.DB.DutyOverride().ListSwaps(r.TenantId, r.DutyId, r.Status)
+	if err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+func (ds dutyOverrideService) getDutyUser(userId string) (models.DutyUser, error) {
+	if userId == "" {
+		return models.DutyUser{}, fmt.Errorf("用户不存在")
+	}
+	member, _, err := ds.ctx.DB.User().Get(userId, "", "")
+	if err != nil {
+		return models.DutyUser{}, err
+	}
+
+	return models.DutyUser{
+		UserId:   member.UserId,
+		Username: member.UserName,
+		Email:    member.Email,
+		Mobile:   member.Phone,
+	}, nil
+}
+
+// isOnCall 用户在 at 时刻是否在指定轮值层值班, 未指定轮值层时判断主值班
+func (ds dutyOverrideService) isOnCall(dutyId, layer, userId string, at int64) bool {
+	t := time.Unix(at, 0)
+	timeline, err := ds.ctx.DB.DutyCalendar().GetTimeline(dutyId, t, t)
+	if err != nil {
+		return false
+	}
+
+	for _, onCall := range timeline.At(t) {
+		if (layer == "" && onCall.Level != models.DutyLevelPrimary) || (layer != "" && onCall.Layer != layer) {
+			continue
+		}
+		for _, user := range onCall.Users {
+			if user.UserId == userId {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// notify 通过邮件通知换班相关人员, 未配置邮箱服务时跳过
+func (ds dutyOverrideService) notify(duty models.DutyManagement, swap models.DutySwap, title string, users ...models.DutyUser) {
+	var to []string
+	for _, user := range users {
+		if user.Email != "" {
+			to = append(to, user.Email)
+		}
+	}
+	if len(to) == 0 {
+		return
+	}
+
+	setting, err := ds.ctx.DB.Setting().Get()
+	if err != nil || setting.EmailConfig.ServerAddress == "" {
+		logc.Infof(ds.ctx.Ctx, "未配置邮箱服务, 跳过换班通知: %s", title)
+		return
+	}
+
+	var (
+		loc    = duty.GetLocation()
+		layout = "2006-01-02 15:04"
+		lines  = []string{
+			title,
+			fmt.Sprintf("值班组: %s", duty.Name),
+			fmt.Sprintf("申请人班次: %s ~ %s (%s)", time.Unix(swap.StartAt, 0).In(loc).Format(layout), time.Unix(swap.EndAt, 0).In(loc).Format(layout), loc.String()),
+		}
+	)
+	if swap.CounterStartAt != 0 {
+		lines = append(lines, fmt.Sprintf("对方班次: %s ~ %s (%s)", time.Unix(swap.CounterStartAt, 0).In(loc).Format(layout), time.Unix(swap.CounterEndAt, 0).In(loc).Format(layout), loc.String()))
+	}
+	if swap.Reason != "" {
+		lines = append(lines, fmt.Sprintf("原因: %s", swap.Reason))
+	}
+
+	eCli := client.NewEmailClientWithProfile(setting.EmailConfig.GetProfile(""))
+	if err := eCli.Send(to, nil, "WatchAlert 换班通知", []byte(strings.Join(lines, "<br>"))); err != nil {
+		logc.Errorf(ds.ctx.Ctx, "发送换班通知失败: %s", err.Error())
+	}
+}
@@ -20,6 +20,17 @@ type InterDutyCalendarService interface {
 	Update(req interface{}) (interface{}, interface{})
 	Search(req interface{}) (interface{}, interface{})
 	GetCalendarUsers(req interface{}) (interface{}, interface{})
+	OnCall(req interface{}) (interface{}, interface{})
+	OnCallHistory(req interface{}) (interface{}, interface{})
+	ImportHolidays(req interface{}) (interface{}, interface{})
+	ListHolidays(req interface{}) (interface{}, interface{})
+	DeleteHolidays(req interface{}) (interface{}, interface{})
+	CreateFeed(req interface{}) (interface{}, interface{})
+	DeleteFeed(req interface{}) (interface{}, interface{})
+	ListFeeds(req interface{}) (interface{}, interface{})
+	Feed(req interface{}) (interface{}, interface{})
+	OnCallAnalytics(req interface{}) (interface{}, interface{})
+	AttributePages(now time.Time)
 	AutoGenerateNextYearSchedule() error
 }
 
@@ -81,6 +92,54 @@ func (dms dutyCalendarService) GetCalendarUsers(req interface{}) (interface{}, i
 	return data, nil
 }
 
+// OnCall 查询指定时刻的当班人员, 包含覆盖值班
+func (dms dutyCalendarService) OnCall(req interface{}) (interface{}, interface{}) {
+	r := req.(*types.RequestDutyOnCallQuery)
+	duty, err := dms.ctx.DB.Duty().Get(r.TenantId, r.DutyId)
+	if err != nil {
+		return nil, err
+	}
+
+	at := time.Now()
+	if r.Time > 0 {
+		at = time.Unix(r.Time, 0)
+	}
+
+	timeline, err := dms.ctx.DB.DutyCalendar().GetTimeline(duty.ID, at, at)
+	if err != nil {
+		return nil, err
+	}
+
+	return types.ResponseDutyOnCall{
+		TimeZone: duty.GetLocation().String(),
+		OnCall:   timeline.At(at),
+	}, nil
+}
+
+// OnCallHistory 查询一段时间内的值班记录, 用于审计任意时刻的当班人员
+func (dms dutyCalendarService) OnCallHistory(req interface{}) (interface{}, interface{}) {
+	r := req.(*types.RequestDutyOnCallHistory)
+	duty, err := dms.ctx.DB.Duty().Get(r.TenantId, r.DutyId)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.StartAt <= 0 || r.EndAt <= r.StartAt {
+		return nil, fmt.Errorf("无效的查询时间范围")
+	}
+	if r.EndAt-r.StartAt > models.DutyHistoryMaxRange*24*60*60 {
+		return nil, fmt.Errorf("查询时间范围不能超过 %d 天", models.DutyHistoryMaxRange)
+	}
+
+	start, end := time.Unix(r.StartAt, 0), time.Unix(r.EndAt, 0)
+	timeline, err := dms.ctx.DB.DutyCalendar().GetTimeline(duty.ID, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	return timeline.History(start, end), nil
+}
+
 // AutoGenerateNextYearSchedule 自动生成次年值班表
 // 每年12月1日自动触发，为所有值班组生成次年全年的值班表
 func (dms dutyCalendarService) AutoGenerateNextYearSchedule() error {
@@ -140,6 +199,14 @@ func (dms dutyCalendarService) generateNextYearScheduleForDuty(tenantId, dutyId
 		return fmt.Errorf("未找到当前年度的值班记录，无法自动生成")
 	}
 
+	// 节假日单独排班及跳过的非工作日不属于常规轮换
+	if duty, err := dms.ctx.DB.Duty().Get(tenantId, dutyId); err == nil && duty.HolidayMode != "" {
+		holidays, err := dms.ctx.DB.DutyCalendar().GetHolidayCalendar(tenantId, fmt.Sprintf("%d-12-01", currentYear), fmt.Sprintf("%d-12-31", currentYear))
+		if err == nil {
+			schedules = regularDutySchedules(schedules, duty.HolidayMode, holidays)
+		}
+	}
+
 	// 分析值班规则：提取用户组和值班周期
 	userGroups, dateType, dutyPeriod := dms.analyzeSchedulePattern(schedules)
 	if len(userGroups) == 0 {
@@ -171,6 +238,23 @@ func (dms dutyCalendarService) generateNextYearScheduleForDuty(tenantId, dutyId
 	return nil
 }
 
+// regularDutySchedules 过滤掉不属于常规轮换的排班: 单独排班的节假日, 以及由前一个工作日值班人员顺延值班的非工作日
+func regularDutySchedules(schedules []models.DutySchedule, holidayMode string, holidays models.HolidayCalendar) []models.DutySchedule {
+	var regular []models.DutySchedule
+	for _, schedule := range schedules {
+		if t, err := time.Parse("2006-1-2", schedule.Time); err == nil {
+			if holidayMode == models.HolidayModeAssign && holidays.IsHoliday(t) {
+				continue
+			}
+			if holidayMode == models.HolidayModeSkip && !holidays.IsWorkingDay(t) {
+				continue
+			}
+		}
+		regular = append(regular, schedule)
+	}
+	return regular
+}
+
 // analyzeSchedulePattern 分析值班表规律，提取用户组和值班周期
 func (dms dutyCalendarService) analyzeSchedulePattern(schedules []models.DutySchedule) ([][]models.DutyUser, string, int) {
 	if len(schedules) == 0 {
@@ -228,12 +312,39 @@ func (dms dutyCalendarService) analyzeSchedulePattern(schedules []models.DutySch
 func (dms dutyCalendarService) generateDutySchedule(dutyInfo types.RequestDutyCalendarCreate) ([]models.DutySchedule, error) {
 	curYear, curMonth, _ := tools.ParseTime(dutyInfo.Month)
 	dutyDays := dms.calculateDutyDays(dutyInfo.DateType, dutyInfo.DutyPeriod)
+	holidays, err := dms.getHolidayCalendar(&dutyInfo, curYear, curMonth)
+	if err != nil {
+		return nil, err
+	}
 	timeC := dms.generateDutyDates(curYear, curMonth)
-	dutyScheduleList := dms.createDutyScheduleList(dutyInfo, timeC, dutyDays)
+	dutyScheduleList := dms.createDutyScheduleList(dutyInfo, timeC, dutyDays, holidays)
 
 	return dutyScheduleList, nil
 }
 
+// getHolidayCalendar 获取生成值班表所需的节假日日历, 请求未指定节假日处理方式时使用值班组的配置
+func (dms dutyCalendarService) getHolidayCalendar(dutyInfo *types.RequestDutyCalendarCreate, year int, startMonth time.Month) (models.HolidayCalendar, error) {
+	if dutyInfo.HolidayMode == "" {
+		duty, err := dms.ctx.DB.Duty().Get(dutyInfo.TenantId, dutyInfo.DutyId)
+		if err == nil {
+			dutyInfo.HolidayMode = duty.HolidayMode
+			if len(dutyInfo.HolidayUserGroups) == 0 {
+				dutyInfo.HolidayUserGroups = duty.HolidayUserGroups
+			}
+		}
+	}
+	if dutyInfo.HolidayMode == "" {
+		return nil, nil
+	}
+	if err := validateHolidayMode(dutyInfo.HolidayMode, dutyInfo.HolidayUserGroups); err != nil {
+		return nil, err
+	}
+
+	start := time.Date(year, startMonth, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(1, 0, -1)
+	return dms.ctx.DB.DutyCalendar().GetHolidayCalendar(dutyInfo.TenantId, start.Format(models.HolidayDateLayout), end.Format(models.HolidayDateLayout))
+}
+
 // 计算值班天数
 func (dms dutyCalendarService) calculateDutyDays(dateType string, dutyPeriod int) int {
 	switch dateType {
@@ -273,9 +384,11 @@ func (dms dutyCalendarService) generateDutyDates(year int, startMonth time.Month
 }
 
 // 创建值班表
-func (dms dutyCalendarService) createDutyScheduleList(dutyInfo types.RequestDutyCalendarCreate, timeC <-chan string, dutyDays int) []models.DutySchedule {
+// 配置节假日处理方式时, 跳过或单独排班的日期不计入常规轮换; 跳过的日期由前一个工作日的值班人员继续值班, 不会无人值班
+func (dms dutyCalendarService) createDutyScheduleList(dutyInfo types.RequestDutyCalendarCreate, timeC <-chan string, dutyDays int, holidays models.HolidayCalendar) []models.DutySchedule {
 	var dutyScheduleList []models.DutySchedule
-	var count int
+	var count, holidayIndex int
+	var lastUsers []models.DutyUser
 
 	for {
 		// 数据消费完成后退出
@@ -290,10 +403,28 @@ func (dms dutyCalendarService) createDutyScheduleList(dutyInfo types.RequestDuty
 					return dutyScheduleList
 				}
 
+				dayUsers := users
+				if holidays != nil {
+					t, _ := time.Parse("2006-1-2", date)
+					switch {
+					case dutyInfo.HolidayMode == models.HolidayModeSkip && !holidays.IsWorkingDay(t):
+						if len(lastUsers) > 0 {
+							dayUsers = lastUsers
+						}
+						day--
+					case dutyInfo.HolidayMode == models.HolidayModeAssign && holidays.IsHoliday(t) && len(dutyInfo.HolidayUserGroups) > 0:
+						dayUsers = dutyInfo.HolidayUserGroups[holidayIndex%len(dutyInfo.HolidayUserGroups)]
+						holidayIndex++
+						day--
+					default:
+						lastUsers = users
+					}
+				}
+
 				dutyScheduleList = append(dutyScheduleList, models.DutySchedule{
 					DutyId: dutyInfo.DutyId,
 					Time:   date,
-					Users:  users,
+					Users:  dayUsers,
 					Status: dutyInfo.Status,
 				})
 
@@ -0,0 +1,82 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"watchAlert/internal/models"
+	"watchAlert/internal/types"
+)
+
+func TestCreateDutyScheduleListHolidaySkip(t *testing.T) {
+	// 2025-10 月: 10-01 至 10-08 为节假日, 10-11(周六) 调休上班
+	holidays := models.HolidayCalendar{"2025-10-11": models.HolidayTypeWorkday}
+	for d := 1; d <= 8; d++ {
+		holidays[time.Date(2025, 10, d, 0, 0, 0, 0, time.UTC).Format(models.HolidayDateLayout)] = models.HolidayTypeHoliday
+	}
+
+	dms := dutyCalendarService{}
+	dutyInfo := types.RequestDutyCalendarCreate{
+		DutyId:      "d1",
+		DutyPeriod:  1,
+		DateType:    "day",
+		UserGroup:   [][]models.DutyUser{{{UserId: "alice"}}, {{UserId: "bob"}}},
+		HolidayMode: models.HolidayModeSkip,
+	}
+	list := dms.createDutyScheduleList(dutyInfo, dms.generateDutyDates(2025, time.September), 1, holidays)
+
+	schedule := make(map[string]string)
+	for _, s := range list {
+		if len(s.Users) == 0 {
+			t.Fatalf("%s has nobody on duty", s.Time)
+		}
+		schedule[s.Time] = s.Users[0].UserId
+	}
+
+	// 09-30(周二) bob 值班, 节假日及其后的周末由 bob 继续值班, 轮换不前进
+	cases := map[string]string{
+		"2025-9-29":  "alice",
+		"2025-9-30":  "bob",
+		"2025-10-1":  "bob",
+		"2025-10-5":  "bob",
+		"2025-10-8":  "bob",
+		"2025-10-9":  "alice",
+		"2025-10-10": "bob",
+		"2025-10-11": "alice",
+		"2025-10-12": "alice",
+		"2025-10-13": "bob",
+	}
+	for date, want := range cases {
+		if got := schedule[date]; got != want {
+			t.Fatalf("%s: expected %s, got %s", date, want, got)
+		}
+	}
+}
+
+func TestAnalyzeSchedulePatternHolidaySkip(t *testing.T) {
+	// 2025-12 月: 12-02、12-10 为节假日, 与周末一起由前一个工作日的值班人员顺延值班
+	holidays := models.HolidayCalendar{"2025-12-02": models.HolidayTypeHoliday, "2025-12-10": models.HolidayTypeHoliday}
+
+	dms := dutyCalendarService{}
+	dutyInfo := types.RequestDutyCalendarCreate{
+		DutyId:      "d1",
+		DutyPeriod:  3,
+		DateType:    "day",
+		UserGroup:   [][]models.DutyUser{{{UserId: "alice"}}, {{UserId: "bob"}}},
+		HolidayMode: models.HolidayModeSkip,
+	}
+	var december []models.DutySchedule
+	for _, s := range dms.createDutyScheduleList(dutyInfo, dms.generateDutyDates(2025, time.December), dms.calculateDutyDays("day", 3), holidays) {
+		if strings.HasPrefix(s.Time, "2025-12-") {
+			december = append(december, s)
+		}
+	}
+
+	userGroups, dateType, dutyPeriod := dms.analyzeSchedulePattern(regularDutySchedules(december, models.HolidayModeSkip, holidays))
+	if dateType != "day" || dutyPeriod != 3 {
+		t.Fatalf("expected a 3 day rotation, got %s %d", dateType, dutyPeriod)
+	}
+	if len(userGroups) != 2 || userGroups[0][0].UserId != "alice" || userGroups[1][0].UserId != "bob" {
+		t.Fatalf("unexpected user groups: %v", userGroups)
+	}
+}
@@ -12,6 +12,8 @@ var (
 	DashboardService        InterDashboardService
 	DutyManageService       InterDutyManageService
 	DutyCalendarService     InterDutyCalendarService
+	DutyOverrideService     InterDutyOverrideService
+	DutyHandoffService      InterDutyHandoffService
 	EventService            InterEventService
 	NoticeService           InterNoticeService
 	NoticeTmplService       InterNoticeTmplService
@@ -37,6 +39,8 @@ var (
 	AiService               InterAiService
 	OidcService             InterOidcService
 	QuickActionService      InterQuickActionService
+	IncidentService         InterIncidentService
+	PostmortemService       InterPostmortemService
 )
 
 func NewServices(ctx *ctx.Context) {
@@ -45,6 +49,8 @@ func NewServices(ctx *ctx.Context) {
 	DashboardService = newInterDashboardService(ctx)
 	DutyManageService = newInterDutyManageService(ctx)
 	DutyCalendarService = newInterDutyCalendarService(ctx)
+	DutyOverrideService = newInterDutyOverrideService(ctx)
+	DutyHandoffService = newInterDutyHandoffService(ctx)
 	EventService = newInterEventService(ctx)
 	NoticeService = newInterAlertNoticeService(ctx)
 	NoticeTmplService = newInterNoticeTmplService(ctx)
@@ -70,4 +76,6 @@ func NewServices(ctx *ctx.Context) {
 	AiService = newInterAiService(ctx)
 	OidcService = newInterOidcService(ctx)
 	QuickActionService = newInterQuickActionService(ctx)
+	IncidentService = newInterIncidentService(ctx)
+	PostmortemService = newInterPostmortemService(ctx)
 }
@@ -6,9 +6,11 @@ import (
 	"strings"
 	"sync"
 	"time"
+	"watchAlert/alert/process"
 	"watchAlert/internal/ctx"
 	"watchAlert/internal/models"
 	"watchAlert/internal/types"
+	"watchAlert/pkg/blob"
 	"watchAlert/pkg/tools"
 )
 
@@ -19,10 +21,13 @@ type eventService struct {
 type InterEventService interface {
 	ListCurrentEvent(req interface{}) (interface{}, interface{})
 	ListHistoryEvent(req interface{}) (interface{}, interface{})
+	ListFlapState(req interface{}) (interface{}, interface{})
+	ListClusters(req interface{}) (interface{}, interface{})
 	ProcessAlertEvent(req interface{}) (interface{}, interface{})
 	ListComments(req interface{}) (interface{}, interface{})
 	AddComment(req interface{}) (interface{}, interface{})
 	DeleteComment(req interface{}) (interface{}, interface{})
+	GetGraph(req interface{}) (interface{}, interface{})
 }
 
 func newInterEventService(ctx *ctx.Context) InterEventService {
@@ -31,6 +36,91 @@ func newInterEventService(ctx *ctx.Context) InterEventService {
 	}
 }
 
+// ListFlapState 获取故障中心的告警抖动状态
+func (e eventService) ListFlapState(req interface{}) (interface{}, interface{}) {
+	r := req.(*types.RequestAlertFlapStateQuery)
+	if r.FaultCenterId == "" {
+		return nil, fmt.Errorf("故障中心 ID 不能为空")
+	}
+
+	if r.Fingerprint != "" {
+		state, ok := e.ctx.Redis.Flapping().Get(r.TenantId, r.FaultCenterId, r.Fingerprint)
+		if !ok {
+			return []models.FlapState{}, nil
+		}
+		return []models.FlapState{state}, nil
+	}
+
+	states := e.ctx.Redis.Flapping().List(r.TenantId, r.FaultCenterId)
+	sort.Slice(states, func(i, j int) bool {
+		return states[i].Score > states[j].Score
+	})
+
+	return states, nil
+}
+
+// eventClusterIds 故障中心启用告警关联时, 获取活跃告警所属的聚类
+func (e eventService) eventClusterIds(tenantId, faultCenterId string) map[string]string {
+	if faultCenterId == "" {
+		return nil
+	}
+	faultCenter, err := e.ctx.DB.FaultCenter().Get(tenantId, faultCenterId, "")
+	if err != nil || !faultCenter.GetCorrelationEnabled() {
+		return nil
+	}
+	clusters, err := process.ClusterFaultCenterEvents(e.ctx, faultCenter)
+	if err != nil {
+		return nil
+	}
+
+	clusterIds := make(map[string]string)
+	for _, cluster := range clusters {
+		for _, event := range cluster.Events {
+			clusterIds[event.Fingerprint] = cluster.ClusterId
+		}
+	}
+	return clusterIds
+}
+
+// ListClusters 获取活跃告警的关联聚类, 按告警数量及等级排序
+func (e eventService) ListClusters(req interface{}) (interface{}, interface{}) {
+	r := req.(*types.RequestAlertClusterQuery)
+	if r.FaultCenterId == "" {
+		return nil, fmt.Errorf("故障中心 ID 不能为空")
+	}
+
+	faultCenter, err := e.ctx.DB.FaultCenter().Get(r.TenantId, r.FaultCenterId, "")
+	if err != nil {
+		return nil, err
+	}
+	clusters, err := process.ClusterFaultCenterEvents(e.ctx, faultCenter)
+	if err != nil {
+		return nil, err
+	}
+
+	minSize := r.MinSize
+	if minSize <= 0 {
+		minSize = 2
+	}
+	list := make([]models.EventCluster, 0, len(clusters))
+	for _, cluster := range clusters {
+		if len(cluster.Events) >= minSize {
+			list = append(list, cluster)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if len(list[i].Events) != len(list[j].Events) {
+			return len(list[i].Events) > len(list[j].Events)
+		}
+		if list[i].Severity != list[j].Severity {
+			return list[i].Severity < list[j].Severity
+		}
+		return list[i].FirstTriggerTime < list[j].FirstTriggerTime
+	})
+
+	return list, nil
+}
+
 func (e eventService) ProcessAlertEvent(req interface{}) (interface{}, interface{}) {
 	r := req.(*types.RequestProcessAlertEvent)
 
@@ -76,7 +166,11 @@ func (e eventService) ListCurrentEvent(req interface{}) (interface{}, interface{
 		filteredEvents []models.AlertCurEvent
 		curTime        = time.Now()
 	)
+	clusterIds := e.eventClusterIds(r.TenantId, r.FaultCenterId)
 	for _, alert := range center {
+		if clusterId, ok := clusterIds[alert.Fingerprint]; ok {
+			alert.ClusterId = clusterId
+		}
 		allEvents = append(allEvents, *alert)
 	}
 
@@ -118,11 +212,20 @@ func (e eventService) ListCurrentEvent(req interface{}) (interface{}, interface{
 			continue
 		}
 
+		if r.ClusterId != "" && event.ClusterId != r.ClusterId {
+			continue
+		}
+
 		// 如果没有指定状态过滤，默认过滤掉已恢复的告警（活跃告警列表不应该显示已恢复的告警）
 		if r.Status == "" {
 			if event.Status == models.StateRecovered {
 				continue
 			}
+		} else if r.Status == "flapping" {
+			// 抖动中的告警
+			if !event.Flapping {
+				continue
+			}
 		} else if string(event.Status) != r.Status {
 			// 如果指定了状态过滤，则按指定状态过滤
 			continue
@@ -223,6 +326,8 @@ func (e eventService) AddComment(req interface{}) (interface{}, interface{}) {
 	if err != nil {
 		return nil, fmt.Errorf("评论失败, %s", err.Error())
 	}
+	// 同步到关联故障的时间线
+	process.RecordIncidentComment(e.ctx, r.TenantId, r.Fingerprint, r.Username, r.Content)
 
 	return "评论成功", nil
 }
@@ -237,3 +342,23 @@ func (e eventService) DeleteComment(req interface{}) (interface{}, interface{})
 
 	return "删除评论成功", nil
 }
+
+// GetGraph 获取通知附带的趋势图
+func (e eventService) GetGraph(req interface{}) (interface{}, interface{}) {
+	r := req.(*types.RequestEventGraph)
+	setting, err := e.ctx.DB.Setting().Get()
+	if err != nil {
+		return nil, err
+	}
+
+	store, err := blob.NewStore(setting.GraphConfig)
+	if err != nil {
+		return nil, err
+	}
+	data, err := store.Get(r.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
@@ -49,6 +49,9 @@ func (f faultCenterService) Create(req interface{}) (data interface{}, err inter
 		IsUpgradeEnabled:     r.IsUpgradeEnabled,
 		UpgradableSeverity:   r.UpgradableSeverity,
 		UpgradeStrategy:      r.UpgradeStrategy,
+		FlapDetection:        r.FlapDetection,
+		IncidentConfig:       r.IncidentConfig,
+		Correlation:          r.Correlation,
 	}
 
 	err = f.ctx.DB.FaultCenter().Create(fc)
@@ -92,6 +95,9 @@ func (f faultCenterService) Update(req interface{}) (data interface{}, err inter
 		IsUpgradeEnabled:     r.IsUpgradeEnabled,
 		UpgradableSeverity:   r.UpgradableSeverity,
 		UpgradeStrategy:      r.UpgradeStrategy,
+		FlapDetection:        r.FlapDetection,
+		IncidentConfig:       r.IncidentConfig,
+		Correlation:          r.Correlation,
 	}
 
 	err = f.ctx.DB.FaultCenter().Update(fc)
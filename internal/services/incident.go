@@ -0,0 +1,283 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"watchAlert/alert/process"
+	"watchAlert/internal/ctx"
+	"watchAlert/internal/models"
+	"watchAlert/internal/types"
+	"watchAlert/pkg/tools"
+)
+
+type incidentService struct {
+	ctx *ctx.Context
+}
+
+type InterIncidentService interface {
+	Create(req interface{}) (interface{}, interface{})
+	Update(req interface{}) (interface{}, interface{})
+	Delete(req interface{}) (interface{}, interface{})
+	List(req interface{}) (interface{}, interface{})
+	Get(req interface{}) (interface{}, interface{})
+	ChangeStatus(req interface{}) (interface{}, interface{})
+	LinkEvents(req interface{}) (interface{}, interface{})
+	UnlinkEvents(req interface{}) (interface{}, interface{})
+	AddComment(req interface{}) (interface{}, interface{})
+	Broadcast(req interface{}) (interface{}, interface{})
+}
+
+func newInterIncidentService(ctx *ctx.Context) InterIncidentService {
+	return &incidentService{
+		ctx: ctx,
+	}
+}
+
+func (i incidentService) Create(req interface{}) (interface{}, interface{}) {
+	r := req.(*types.RequestIncidentCreate)
+	if r.Title == "" {
+		return nil, fmt.Errorf("故障标题不能为空")
+	}
+
+	now := time.Now().Unix()
+	incident := models.Incident{
+		TenantId:      r.TenantId,
+		ID:            "inc-" + tools.RandId(),
+		Title:         r.Title,
+		Description:   r.Description,
+		Status:        models.IncidentTriggered,
+		Severity:      r.Severity,
+		Commander:     r.Commander,
+		Responders:    r.Responders,
+		FaultCenterId: r.FaultCenterId,
+		Source:        models.IncidentSourceManual,
+		NoticeIds:     r.NoticeIds,
+		CreateBy:      r.CreateBy,
+		CreateAt:      now,
+		UpdateAt:      now,
+	}
+
+	err := i.ctx.DB.Incident().Create(incident)
+	if err != nil {
+		return nil, err
+	}
+	process.AddIncidentTimeline(i.ctx, incident.TenantId, incident.ID, models.TimelineStatusChange, "手动创建故障", r.CreateBy)
+
+	if len(r.Fingerprints) > 0 {
+		if err := i.linkEvents(incident, r.FaultCenterId, r.Fingerprints, r.CreateBy); err != nil {
+			return nil, err
+		}
+	}
+
+	return incident, nil
+}
+
+func (i incidentService) Update(req interface{}) (interface{}, interface{}) {
+	r := req.(*types.RequestIncidentUpdate)
+	incident, err := i.ctx.DB.Incident().Get(r.TenantId, r.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 指挥官及响应人变更记录到时间线
+	var changes []string
+	if r.Commander != incident.Commander {
+		changes = append(changes, fmt.Sprintf("指挥官: %s → %s", incident.Commander, r.Commander))
+	}
+	if strings.Join(r.Responders, ",") != strings.Join(incident.Responders, ",") {
+		changes = append(changes, fmt.Sprintf("响应人: %s", strings.Join(r.Responders, ", ")))
+	}
+
+	incident.Title = r.Title
+	incident.Description = r.Description
+	incident.Severity = r.Severity
+	incident.Commander = r.Commander
+	incident.Responders = r.Responders
+	incident.NoticeIds = r.NoticeIds
+	incident.UpdateAt = time.Now().Unix()
+
+	err = i.ctx.DB.Incident().Update(incident)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(changes) > 0 {
+		process.AddIncidentTimeline(i.ctx, incident.TenantId, incident.ID, models.TimelineAssign, strings.Join(changes, "; "), r.UpdateBy)
+	}
+
+	return nil, nil
+}
+
+func (i incidentService) Delete(req interface{}) (interface{}, interface{}) {
+	r := req.(*types.RequestIncidentQuery)
+	err := i.ctx.DB.Incident().Delete(r.TenantId, r.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+func (i incidentService) List(req interface{}) (interface{}, interface{}) {
+	r := req.(*types.RequestIncidentQuery)
+	data, count, err := i.ctx.DB.Incident().List(r.TenantId, r.FaultCenterId, r.Status, r.Query, r.Page)
+	if err != nil {
+		return nil, err
+	}
+
+	for idx := range data {
+		events, err := i.ctx.DB.Incident().ListEvents(data[idx].TenantId, data[idx].ID)
+		if err == nil {
+			data[idx].EventCount = int64(len(events))
+		}
+	}
+
+	return types.ResponseIncidentList{
+		List: data,
+		Page: models.Page{
+			Total: count,
+			Index: r.Page.Index,
+			Size:  r.Page.Size,
+		},
+	}, nil
+}
+
+func (i incidentService) Get(req interface{}) (interface{}, interface{}) {
+	r := req.(*types.RequestIncidentQuery)
+	incident, err := i.ctx.DB.Incident().Get(r.TenantId, r.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := i.ctx.DB.Incident().ListEvents(r.TenantId, r.ID)
+	if err != nil {
+		return nil, err
+	}
+	incident.EventCount = int64(len(events))
+
+	timeline, err := i.ctx.DB.Incident().ListTimeline(r.TenantId, r.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return types.ResponseIncidentDetail{
+		Incident: incident,
+		Events:   events,
+		Timeline: timeline,
+	}, nil
+}
+
+func (i incidentService) ChangeStatus(req interface{}) (interface{}, interface{}) {
+	r := req.(*types.RequestIncidentStatusChange)
+	incident, err := i.ctx.DB.Incident().Get(r.TenantId, r.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	oldStatus := incident.Status
+	if !incident.TransitionStatus(r.Status, time.Now().Unix()) {
+		return nil, fmt.Errorf("无效的故障状态变更: %s → %s", oldStatus, r.Status)
+	}
+
+	err = i.ctx.DB.Incident().UpdateStatus(incident)
+	if err != nil {
+		return nil, err
+	}
+
+	content := fmt.Sprintf("状态变更: %s → %s", oldStatus, incident.Status)
+	if r.Comment != "" {
+		content = fmt.Sprintf("%s, 备注: %s", content, r.Comment)
+	}
+	process.AddIncidentTimeline(i.ctx, incident.TenantId, incident.ID, models.TimelineStatusChange, content, r.Username)
+
+	return nil, nil
+}
+
+func (i incidentService) LinkEvents(req interface{}) (interface{}, interface{}) {
+	r := req.(*types.RequestIncidentLinkEvents)
+	incident, err := i.ctx.DB.Incident().Get(r.TenantId, r.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	faultCenterId := r.FaultCenterId
+	if faultCenterId == "" {
+		faultCenterId = incident.FaultCenterId
+	}
+
+	return nil, i.linkEvents(incident, faultCenterId, r.Fingerprints, r.Username)
+}
+
+func (i incidentService) UnlinkEvents(req interface{}) (interface{}, interface{}) {
+	r := req.(*types.RequestIncidentLinkEvents)
+	for _, fingerprint := range r.Fingerprints {
+		err := i.ctx.DB.Incident().UnlinkEvent(r.TenantId, r.ID, fingerprint)
+		if err != nil {
+			return nil, err
+		}
+		process.AddIncidentTimeline(i.ctx, r.TenantId, r.ID, models.TimelineEventLinked, fmt.Sprintf("取消关联告警, 指纹: %s", fingerprint), r.Username)
+	}
+
+	return nil, nil
+}
+
+func (i incidentService) AddComment(req interface{}) (interface{}, interface{}) {
+	r := req.(*types.RequestIncidentComment)
+	if strings.TrimSpace(r.Content) == "" {
+		return nil, fmt.Errorf("评论内容不能为空")
+	}
+
+	if _, err := i.ctx.DB.Incident().Get(r.TenantId, r.ID); err != nil {
+		return nil, err
+	}
+	process.AddIncidentTimeline(i.ctx, r.TenantId, r.ID, models.TimelineComment, r.Content, r.Username)
+
+	return nil, nil
+}
+
+func (i incidentService) Broadcast(req interface{}) (interface{}, interface{}) {
+	r := req.(*types.RequestIncidentBroadcast)
+	incident, err := i.ctx.DB.Incident().Get(r.TenantId, r.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	noticeIds := r.NoticeIds
+	if len(noticeIds) == 0 {
+		noticeIds = incident.NoticeIds
+	}
+	if len(noticeIds) == 0 {
+		return nil, fmt.Errorf("请选择通报的通知对象")
+	}
+	if strings.TrimSpace(r.Message) == "" {
+		return nil, fmt.Errorf("通报内容不能为空")
+	}
+
+	err = process.BroadcastIncident(i.ctx, incident, noticeIds, r.Message, r.Username)
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+// linkEvents 从故障中心获取告警事件并关联到故障
+func (i incidentService) linkEvents(incident models.Incident, faultCenterId string, fingerprints []string, operator string) error {
+	if faultCenterId == "" {
+		return fmt.Errorf("故障中心 ID 不能为空")
+	}
+
+	for _, fingerprint := range fingerprints {
+		event, err := i.ctx.Redis.Alert().GetEventFromCache(incident.TenantId, faultCenterId, fingerprint)
+		if err != nil || event.Fingerprint == "" {
+			return fmt.Errorf("告警事件不存在, 指纹: %s", fingerprint)
+		}
+
+		if err := process.LinkIncidentEvent(i.ctx, incident, event, operator); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
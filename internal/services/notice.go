@@ -63,6 +63,7 @@ func (n noticeService) Create(req interface{}) (interface{}, interface{}) {
 		Routes:       r.Routes,
 		Email:        r.Email,
 		PhoneNumber:  r.PhoneNumber,
+		Webhook:      r.Webhook,
 		UpdateAt:     time.Now().Unix(),
 		UpdateBy:     r.UpdateBy,
 	})
@@ -86,6 +87,7 @@ func (n noticeService) Update(req interface{}) (interface{}, interface{}) {
 		Routes:       r.Routes,
 		Email:        r.Email,
 		PhoneNumber:  r.PhoneNumber,
+		Webhook:      r.Webhook,
 		UpdateAt:     time.Now().Unix(),
 		UpdateBy:     r.UpdateBy,
 	})
@@ -204,6 +206,7 @@ func (n noticeService) Test(req interface{}) (interface{}, interface{}) {
 		Hook:       r.DefaultHook,
 		Email:      r.Email,
 		Sign:       r.DefaultSign,
+		Webhook:    r.Webhook,
 	})
 	if err != nil {
 		errList = append(errList, struct {
@@ -220,7 +223,8 @@ func (n noticeService) Test(req interface{}) (interface{}, interface{}) {
 				To: route.To,
 				CC: route.CC,
 			},
-			Sign: route.Sign,
+			Sign:    route.Sign,
+			Webhook: r.Webhook,
 		})
 		if err != nil {
 			errList = append(errList, struct {
@@ -0,0 +1,517 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"watchAlert/alert/process"
+	"watchAlert/internal/ctx"
+	"watchAlert/internal/models"
+	"watchAlert/internal/types"
+	"watchAlert/pkg/ai"
+	"watchAlert/pkg/tools"
+)
+
+// 提示词中最多列出的告警及评论数量, 避免超出模型上下文
+const (
+	postmortemMaxEvents   = 50
+	postmortemMaxComments = 50
+)
+
+type postmortemService struct {
+	ctx *ctx.Context
+}
+
+type InterPostmortemService interface {
+	Generate(req interface{}) (interface{}, interface{})
+	Update(req interface{}) (interface{}, interface{})
+	Finalize(req interface{}) (interface{}, interface{})
+	Delete(req interface{}) (interface{}, interface{})
+	List(req interface{}) (interface{}, interface{})
+	Get(req interface{}) (interface{}, interface{})
+}
+
+func newInterPostmortemService(ctx *ctx.Context) InterPostmortemService {
+	return &postmortemService{
+		ctx: ctx,
+	}
+}
+
+// postmortemData 生成复盘报告所需的故障数据
+type postmortemData struct {
+	Title    string
+	StartAt  int64
+	EndAt    int64
+	Incident *models.Incident
+	Timeline []models.IncidentTimeline
+	Events   []models.AlertHisEvent
+	Comments []models.Comment
+	Records  []models.NoticeRecord
+	Metrics  models.PostmortemMetrics
+	Extra    string
+}
+
+func (p postmortemService) Generate(req interface{}) (interface{}, interface{}) {
+	r := req.(*types.RequestPostmortemGenerate)
+	if err := r.ValidateParams(); err != nil {
+		return nil, err
+	}
+
+	setting, err := p.ctx.DB.Setting().Get()
+	if err != nil {
+		return nil, err
+	}
+	if !setting.AiConfig.GetEnable() {
+		return nil, fmt.Errorf("未开启 Ai 分析能力")
+	}
+
+	now := time.Now().Unix()
+	postmortem := models.Postmortem{
+		TenantId:      r.TenantId,
+		IncidentId:    r.IncidentId,
+		FaultCenterId: r.FaultCenterId,
+		StartAt:       r.StartAt,
+		EndAt:         r.EndAt,
+		Title:         r.Title,
+	}
+	// 重新生成时沿用已有报告的数据范围
+	if r.ID != "" {
+		postmortem, err = p.ctx.DB.Postmortem().Get(r.TenantId, r.ID)
+		if err != nil {
+			return nil, err
+		}
+		if postmortem.Status == models.PostmortemFinal {
+			return nil, fmt.Errorf("复盘报告已定稿, 不允许重新生成")
+		}
+	}
+
+	data, err := p.collect(postmortem, now)
+	if err != nil {
+		return nil, err
+	}
+	data.Extra = r.Extra
+
+	client, err := p.ctx.Redis.ProviderPools().GetClient("AiClient")
+	if err != nil {
+		return nil, err
+	}
+	content, err := client.(ai.AiClient).ChatCompletion(ai.WithTenant(p.ctx.Ctx, r.TenantId), buildPostmortemPrompt(data))
+	if err != nil {
+		return nil, err
+	}
+
+	postmortem.Title = data.Title
+	postmortem.StartAt = data.StartAt
+	postmortem.EndAt = data.EndAt
+	postmortem.Metrics = data.Metrics
+	postmortem.CurrentVersion++
+	postmortem.UpdateAt = now
+	if postmortem.ID == "" {
+		postmortem.ID = "pm-" + tools.RandId()
+		postmortem.Status = models.PostmortemDraft
+		postmortem.CreateBy = r.Username
+		postmortem.CreateAt = now
+		err = p.ctx.DB.Postmortem().Create(postmortem)
+	} else {
+		err = p.ctx.DB.Postmortem().Update(postmortem)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	version := models.PostmortemVersion{
+		TenantId:     postmortem.TenantId,
+		PostmortemId: postmortem.ID,
+		Version:      postmortem.CurrentVersion,
+		Content:      content,
+		Source:       models.PostmortemSourceAi,
+		CreateBy:     r.Username,
+		CreateAt:     now,
+	}
+	if err := p.ctx.DB.Postmortem().AddVersion(version); err != nil {
+		return nil, err
+	}
+
+	return types.ResponsePostmortemDetail{
+		Postmortem: postmortem,
+		Content:    content,
+	}, nil
+}
+
+func (p postmortemService) Update(req interface{}) (interface{}, interface{}) {
+	r := req.(*types.RequestPostmortemUpdate)
+	if strings.TrimSpace(r.Content) == "" {
+		return nil, fmt.Errorf("复盘内容不能为空")
+	}
+
+	postmortem, err := p.ctx.DB.Postmortem().Get(r.TenantId, r.ID)
+	if err != nil {
+		return nil, err
+	}
+	if postmortem.Status == models.PostmortemFinal {
+		return nil, fmt.Errorf("复盘报告已定稿, 不允许编辑")
+	}
+
+	now := time.Now().Unix()
+	if r.Title != "" {
+		postmortem.Title = r.Title
+	}
+	postmortem.CurrentVersion++
+	postmortem.UpdateAt = now
+	if err := p.ctx.DB.Postmortem().Update(postmortem); err != nil {
+		return nil, err
+	}
+
+	err = p.ctx.DB.Postmortem().AddVersion(models.PostmortemVersion{
+		TenantId:     postmortem.TenantId,
+		PostmortemId: postmortem.ID,
+		Version:      postmortem.CurrentVersion,
+		Content:      r.Content,
+		Source:       models.PostmortemSourceManual,
+		CreateBy:     r.Username,
+		CreateAt:     now,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+func (p postmortemService) Finalize(req interface{}) (interface{}, interface{}) {
+	r := req.(*types.RequestPostmortemFinalize)
+	postmortem, err := p.ctx.DB.Postmortem().Get(r.TenantId, r.ID)
+	if err != nil {
+		return nil, err
+	}
+	if postmortem.Status == models.PostmortemFinal {
+		return nil, fmt.Errorf("复盘报告已定稿")
+	}
+	if postmortem.CurrentVersion == 0 {
+		return nil, fmt.Errorf("复盘报告内容为空, 无法定稿")
+	}
+
+	now := time.Now().Unix()
+	postmortem.Status = models.PostmortemFinal
+	postmortem.FinalizedBy = r.Username
+	postmortem.FinalizedAt = now
+	postmortem.UpdateAt = now
+	if err := p.ctx.DB.Postmortem().Update(postmortem); err != nil {
+		return nil, err
+	}
+
+	if postmortem.IncidentId != "" {
+		content := fmt.Sprintf("复盘报告已定稿, 报告 ID: %s, 版本: v%d", postmortem.ID, postmortem.CurrentVersion)
+		process.AddIncidentTimeline(p.ctx, postmortem.TenantId, postmortem.IncidentId, models.TimelineComment, content, r.Username)
+	}
+
+	return nil, nil
+}
+
+func (p postmortemService) Delete(req interface{}) (interface{}, interface{}) {
+	r := req.(*types.RequestPostmortemQuery)
+	err := p.ctx.DB.Postmortem().Delete(r.TenantId, r.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+func (p postmortemService) List(req interface{}) (interface{}, interface{}) {
+	r := req.(*types.RequestPostmortemQuery)
+	data, count, err := p.ctx.DB.Postmortem().List(r.TenantId, r.IncidentId, r.Status, r.Query, r.Page)
+	if err != nil {
+		return nil, err
+	}
+
+	return types.ResponsePostmortemList{
+		List: data,
+		Page: models.Page{
+			Total: count,
+			Index: r.Page.Index,
+			Size:  r.Page.Size,
+		},
+	}, nil
+}
+
+func (p postmortemService) Get(req interface{}) (interface{}, interface{}) {
+	r := req.(*types.RequestPostmortemQuery)
+	postmortem, err := p.ctx.DB.Postmortem().Get(r.TenantId, r.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	versions, err := p.ctx.DB.Postmortem().ListVersions(r.TenantId, r.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	version := r.Version
+	if version == 0 {
+		version = postmortem.CurrentVersion
+	}
+	var content string
+	for _, v := range versions {
+		if v.Version == version {
+			content = v.Content
+			break
+		}
+	}
+
+	return types.ResponsePostmortemDetail{
+		Postmortem: postmortem,
+		Content:    content,
+		Versions:   versions,
+	}, nil
+}
+
+// collect 收集故障或时间窗口内的告警、评论、通知记录及认领数据
+func (p postmortemService) collect(postmortem models.Postmortem, now int64) (postmortemData, error) {
+	var (
+		data = postmortemData{
+			Title:   postmortem.Title,
+			StartAt: postmortem.StartAt,
+			EndAt:   postmortem.EndAt,
+		}
+		fingerprints  []string
+		faultCenterId = postmortem.FaultCenterId
+		ackAt         int64
+	)
+
+	if postmortem.IncidentId != "" {
+		incident, err := p.ctx.DB.Incident().Get(postmortem.TenantId, postmortem.IncidentId)
+		if err != nil {
+			return data, fmt.Errorf("故障不存在, %s", err.Error())
+		}
+		data.Incident = &incident
+		ackAt = incident.AcknowledgedAt
+		faultCenterId = incident.FaultCenterId
+		if data.Title == "" {
+			data.Title = incident.Title
+		}
+		data.StartAt = incident.CreateAt
+		data.EndAt = incident.ResolvedAt
+		if data.EndAt == 0 {
+			data.EndAt = now
+		}
+
+		linked, err := p.ctx.DB.Incident().ListEvents(incident.TenantId, incident.ID)
+		if err != nil {
+			return data, err
+		}
+		for _, event := range linked {
+			fingerprints = append(fingerprints, event.Fingerprint)
+		}
+		if len(fingerprints) == 0 {
+			return data, fmt.Errorf("故障未关联任何告警事件")
+		}
+
+		data.Timeline, err = p.ctx.DB.Incident().ListTimeline(incident.TenantId, incident.ID)
+		if err != nil {
+			return data, err
+		}
+	}
+	if data.Title == "" {
+		data.Title = fmt.Sprintf("%s ~ %s 故障复盘", formatPostmortemTime(data.StartAt), formatPostmortemTime(data.EndAt))
+	}
+
+	events, err := p.ctx.DB.Event().ListHistoryEventsByTime(postmortem.TenantId, faultCenterId, fingerprints, data.StartAt, data.EndAt)
+	if err != nil {
+		return data, err
+	}
+	data.Events = append(events, p.activeEvents(postmortem.TenantId, faultCenterId, fingerprints, data.StartAt, data.EndAt)...)
+	if len(data.Events) == 0 {
+		return data, fmt.Errorf("时间窗口内没有告警事件")
+	}
+	sort.Slice(data.Events, func(i, j int) bool {
+		return data.Events[i].FirstTriggerTime < data.Events[j].FirstTriggerTime
+	})
+
+	var (
+		eventIds []string
+		seen     = map[string]struct{}{}
+	)
+	for _, event := range data.Events {
+		eventIds = append(eventIds, event.EventId)
+		if _, ok := seen[event.Fingerprint]; ok {
+			continue
+		}
+		seen[event.Fingerprint] = struct{}{}
+
+		comments, err := p.ctx.DB.Comment().List(types.RequestListEventComments{
+			TenantId:    postmortem.TenantId,
+			Fingerprint: event.Fingerprint,
+		})
+		if err == nil {
+			data.Comments = append(data.Comments, comments...)
+		}
+	}
+	sort.Slice(data.Comments, func(i, j int) bool {
+		return data.Comments[i].Time < data.Comments[j].Time
+	})
+
+	data.Records, err = p.ctx.DB.Notice().ListRecordByEventIds(postmortem.TenantId, eventIds)
+	if err != nil {
+		return data, err
+	}
+
+	data.Metrics = models.CalcPostmortemMetrics(data.Events, data.Records, ackAt)
+
+	return data, nil
+}
+
+// activeEvents 获取仍处于告警中的事件, 作为未恢复的告警参与复盘
+func (p postmortemService) activeEvents(tenantId, faultCenterId string, fingerprints []string, startAt, endAt int64) []models.AlertHisEvent {
+	if faultCenterId == "" {
+		return nil
+	}
+
+	var events []models.AlertCurEvent
+	if len(fingerprints) > 0 {
+		for _, fingerprint := range fingerprints {
+			event, err := p.ctx.Redis.Alert().GetEventFromCache(tenantId, faultCenterId, fingerprint)
+			if err == nil && event.Fingerprint != "" {
+				events = append(events, event)
+			}
+		}
+	} else {
+		data, err := p.ctx.Redis.Alert().GetAllEvents(models.BuildAlertEventCacheKey(tenantId, faultCenterId))
+		if err != nil {
+			return nil
+		}
+		for _, event := range data {
+			if event.FirstTriggerTime >= startAt && event.FirstTriggerTime <= endAt {
+				events = append(events, *event)
+			}
+		}
+	}
+
+	var list []models.AlertHisEvent
+	for _, event := range events {
+		if event.Status == models.StateRecovered || event.Status == models.StatePreAlert {
+			continue
+		}
+		list = append(list, models.AlertHisEvent{
+			TenantId:         event.TenantId,
+			EventId:          event.EventId,
+			Fingerprint:      event.Fingerprint,
+			RuleId:           event.RuleId,
+			RuleName:         event.RuleName,
+			Severity:         event.Severity,
+			Labels:           event.Labels,
+			Annotations:      event.Annotations,
+			FirstTriggerTime: event.FirstTriggerTime,
+			FaultCenterId:    event.FaultCenterId,
+			ConfirmState:     event.ConfirmState,
+			SearchQL:         event.SearchQL,
+		})
+	}
+
+	return list
+}
+
+// buildPostmortemPrompt 构建复盘报告的提示词
+func buildPostmortemPrompt(data postmortemData) string {
+	var b strings.Builder
+
+	b.WriteString("你是一名资深 SRE, 请根据以下故障数据撰写一份中文故障复盘报告草稿, 使用 Markdown 格式输出, 必须包含以下章节:\n")
+	b.WriteString("1. 故障概述\n2. 故障时间线(按时间顺序, 包含发现、响应、止损、恢复等关键节点)\n3. 影响范围\n")
+	b.WriteString("4. 发现与响应指标(MTTA/MTTR, 直接使用下方给出的数值)\n5. 根因分析(数据不足时请明确标注为推测)\n6. 改进措施(以表格列出: 措施、负责人(待定)、优先级)\n\n")
+
+	fmt.Fprintf(&b, "## 基本信息\n- 标题: %s\n- 时间窗口: %s ~ %s\n", data.Title, formatPostmortemTime(data.StartAt), formatPostmortemTime(data.EndAt))
+	if inc := data.Incident; inc != nil {
+		fmt.Fprintf(&b, "- 故障等级: %s\n- 当前状态: %s\n- 指挥官: %s\n- 响应人: %s\n", inc.Severity, inc.Status, inc.Commander, strings.Join(inc.Responders, ", "))
+		if inc.Description != "" {
+			fmt.Fprintf(&b, "- 描述: %s\n", inc.Description)
+		}
+		fmt.Fprintf(&b, "- 响应时间: %s\n- 止损时间: %s\n- 解决时间: %s\n",
+			formatPostmortemTime(inc.AcknowledgedAt), formatPostmortemTime(inc.MitigatedAt), formatPostmortemTime(inc.ResolvedAt))
+	}
+
+	m := data.Metrics
+	fmt.Fprintf(&b, "\n## 指标\n- 告警事件数: %d, 已认领: %d\n- MTTA: %s\n- MTTR: %s\n- 最长告警持续: %s\n- 通知发送 %d 次, 失败 %d 次\n",
+		m.EventCount, m.AcknowledgedCount, formatPostmortemDuration(int64(m.MTTA)), formatPostmortemDuration(int64(m.MTTR)),
+		formatPostmortemDuration(m.MaxDuration), m.NoticeCount, m.NoticeFailedCount)
+
+	b.WriteString("\n## 告警事件\n")
+	for idx, event := range data.Events {
+		if idx >= postmortemMaxEvents {
+			fmt.Fprintf(&b, "- ... 其余 %d 条告警已省略\n", len(data.Events)-postmortemMaxEvents)
+			break
+		}
+		recover := "未恢复"
+		if event.RecoverTime > 0 {
+			recover = formatPostmortemTime(event.RecoverTime)
+		}
+		confirm := "未认领"
+		if event.ConfirmState.IsOk {
+			confirm = fmt.Sprintf("%s 于 %s 认领", event.ConfirmState.ConfirmUsername, formatPostmortemTime(event.ConfirmState.ConfirmActionTime))
+		}
+		fmt.Fprintf(&b, "- [%s] %s, 触发: %s, 恢复: %s, %s, 标签: %v\n",
+			event.Severity, event.RuleName, formatPostmortemTime(event.FirstTriggerTime), recover, confirm, event.Labels)
+		if event.Annotations != "" {
+			fmt.Fprintf(&b, "  详情: %s\n", event.Annotations)
+		}
+	}
+
+	if len(data.Timeline) > 0 {
+		b.WriteString("\n## 故障时间线\n")
+		for _, t := range data.Timeline {
+			fmt.Fprintf(&b, "- %s [%s] %s: %s\n", formatPostmortemTime(t.CreateAt), t.Type, t.Operator, t.Content)
+		}
+	}
+
+	if len(data.Comments) > 0 {
+		b.WriteString("\n## 处理评论\n")
+		for idx, c := range data.Comments {
+			if idx >= postmortemMaxComments {
+				break
+			}
+			fmt.Fprintf(&b, "- %s %s: %s\n", formatPostmortemTime(c.Time), c.Username, c.Content)
+		}
+	}
+
+	if len(data.Records) > 0 {
+		b.WriteString("\n## 通知记录\n")
+		stats := map[string][2]int{}
+		var keys []string
+		for _, record := range data.Records {
+			key := fmt.Sprintf("%s(%s)", record.NType, record.NObj)
+			s, ok := stats[key]
+			if !ok {
+				keys = append(keys, key)
+			}
+			if record.Status == 0 {
+				s[0]++
+			} else {
+				s[1]++
+			}
+			stats[key] = s
+		}
+		for _, key := range keys {
+			fmt.Fprintf(&b, "- %s: 成功 %d 次, 失败 %d 次\n", key, stats[key][0], stats[key][1])
+		}
+	}
+
+	if data.Extra != "" {
+		fmt.Fprintf(&b, "\n## 补充信息\n%s\n", data.Extra)
+	}
+
+	return b.String()
+}
+
+func formatPostmortemTime(ts int64) string {
+	if ts <= 0 {
+		return "-"
+	}
+	return time.Unix(ts, 0).Format("2006-01-02 15:04:05")
+}
+
+func formatPostmortemDuration(seconds int64) string {
+	if seconds <= 0 {
+		return "-"
+	}
+	return (time.Duration(seconds) * time.Second).String()
+}
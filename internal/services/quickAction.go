@@ -6,6 +6,7 @@ import (
 	"time"
 	"watchAlert/internal/ctx"
 	"watchAlert/internal/models"
+	"watchAlert/internal/types"
 	"watchAlert/pkg/quickaction"
 	"watchAlert/pkg/tools"
 )
@@ -25,6 +26,14 @@ type InterQuickActionService interface {
 	ResolveAlert(tenantId, fingerprint, username, clientIP string) error
 	// GetAlertByFingerprint 根据指纹获取告警
 	GetAlertByFingerprint(tenantId, fingerprint string) (*models.AlertCurEvent, error)
+	// TelegramCallback 处理 Telegram 内联按钮回调
+	TelegramCallback(secretToken string, update types.TelegramUpdate) error
+	// PagerDutyCallback 处理 PagerDuty Webhook 回调
+	PagerDutyCallback(tenantId, signature string, body []byte) error
+	// OpsgenieCallback 处理 Opsgenie Webhook 回调
+	OpsgenieCallback(tenantId, token string, body []byte) error
+	// PhoneCallAck 处理语音通知的按键认领
+	PhoneCallAck(token, phoneNumber, digits string) error
 }
 
 func newInterQuickActionService(ctx *ctx.Context) InterQuickActionService {
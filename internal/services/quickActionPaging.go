@@ -0,0 +1,106 @@
+package services
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"strings"
+	"watchAlert/internal/models"
+	"watchAlert/internal/types"
+
+	"github.com/bytedance/sonic"
+	"github.com/zeromicro/go-zero/core/logc"
+)
+
+// PagerDutyCallback 处理 PagerDuty Webhook v3 回调, 故障被认领时同步认领 dedup_key 对应的告警
+func (q *quickActionService) PagerDutyCallback(tenantId, signature string, body []byte) error {
+	setting, err := q.ctx.DB.Setting().Get()
+	if err != nil {
+		return err
+	}
+	if !models.VerifyPagerDutySignature(body, setting.PagingConfig.PagerDutyWebhookSecret, signature) {
+		return errors.New("PagerDuty Webhook 签名校验失败")
+	}
+
+	var payload types.PagerDutyWebhook
+	if err := sonic.Unmarshal(body, &payload); err != nil {
+		return fmt.Errorf("解析 PagerDuty Webhook 失败, err: %s", err.Error())
+	}
+	if payload.Event.EventType != "incident.acknowledged" || payload.Event.Data.IncidentKey == "" {
+		return nil
+	}
+
+	var name string
+	if payload.Event.Agent != nil {
+		name = payload.Event.Agent.Summary
+	}
+	q.syncAcknowledge(tenantId, payload.Event.Data.IncidentKey, q.pagingOperator(name, "", "PagerDuty"), "pagerduty")
+	return nil
+}
+
+// OpsgenieCallback 处理 Opsgenie Webhook 集成回调, 告警被认领时同步认领 alias 对应的告警
+func (q *quickActionService) OpsgenieCallback(tenantId, token string, body []byte) error {
+	setting, err := q.ctx.DB.Setting().Get()
+	if err != nil {
+		return err
+	}
+	expected := setting.PagingConfig.OpsgenieWebhookToken
+	if expected == "" || subtle.ConstantTimeCompare([]byte(token), []byte(expected)) != 1 {
+		return errors.New("Opsgenie Webhook 令牌校验失败")
+	}
+
+	var payload types.OpsgenieWebhook
+	if err := sonic.Unmarshal(body, &payload); err != nil {
+		return fmt.Errorf("解析 Opsgenie Webhook 失败, err: %s", err.Error())
+	}
+	if payload.Action != "Acknowledge" || payload.Alert.Alias == "" {
+		return nil
+	}
+
+	q.syncAcknowledge(tenantId, payload.Alert.Alias, q.pagingOperator("", payload.Alert.Username, "Opsgenie"), "opsgenie")
+	return nil
+}
+
+// syncAcknowledge 认领告警, 告警不存在或已被认领时忽略（WatchAlert 中认领后推送到寻呼平台的认领也会回调）
+func (q *quickActionService) syncAcknowledge(tenantId, fingerprint, username, source string) {
+	alert, err := q.GetAlertByFingerprint(tenantId, fingerprint)
+	if err != nil {
+		logc.Infof(q.ctx.Ctx, "%s 回调的告警不存在, tenantId: %s, fingerprint: %s", source, tenantId, fingerprint)
+		return
+	}
+	if alert.ConfirmState.IsOk {
+		return
+	}
+
+	if err := q.ClaimAlert(tenantId, fingerprint, username, source); err != nil {
+		logc.Errorf(q.ctx.Ctx, "同步 %s 认领状态失败, fingerprint: %s, err: %s", source, fingerprint, err.Error())
+	}
+}
+
+// pagingOperator 将寻呼平台的操作人匹配为 WatchAlert 用户, 按邮箱或用户名匹配, 未匹配时使用平台中的名称
+func (q *quickActionService) pagingOperator(name, email, platform string) string {
+	if email != "" {
+		users, err := q.ctx.DB.User().List(email, "")
+		if err == nil {
+			for _, user := range users {
+				if strings.EqualFold(user.Email, email) {
+					return user.UserName
+				}
+			}
+		}
+	}
+	if name != "" {
+		if user, ok, _ := q.ctx.DB.User().Get("", name, ""); ok {
+			return user.UserName
+		}
+	}
+
+	operator := name
+	if operator == "" {
+		operator = email
+	}
+	if operator == "" {
+		operator = "unknown"
+	}
+	return fmt.Sprintf("%s (%s)", operator, platform)
+}
@@ -0,0 +1,43 @@
+package services
+
+import (
+	"fmt"
+	"watchAlert/internal/models"
+	"watchAlert/pkg/utils"
+)
+
+// PhoneCallAck 处理语音通知的按键回调, 按 1 时认领告警, 认领后不再触发告警升级
+// 令牌由发送语音通知时使用服务商密钥签发, 操作人按被叫号码匹配 WatchAlert 用户
+func (q *quickActionService) PhoneCallAck(token, phoneNumber, digits string) error {
+	if digits != models.PhoneCallAckDigit {
+		return fmt.Errorf("未按 %s 键, 未认领告警", models.PhoneCallAckDigit)
+	}
+
+	setting, err := q.ctx.DB.Setting().Get()
+	if err != nil {
+		return err
+	}
+	payload, err := utils.VerifyQuickToken(token, setting.PhoneCallConfig.AccessKeySecret)
+	if err != nil {
+		return err
+	}
+
+	return q.ClaimAlert(payload.TenantId, payload.Fingerprint, q.phoneOperator(phoneNumber), "phonecall")
+}
+
+// phoneOperator 按手机号匹配 WatchAlert 用户, 未匹配时使用手机号
+func (q *quickActionService) phoneOperator(phoneNumber string) string {
+	query := phoneNumber
+	if len(query) > 8 {
+		query = query[len(query)-8:]
+	}
+	if users, err := q.ctx.DB.User().List(query, ""); err == nil {
+		for _, user := range users {
+			if models.SamePhoneNumber(user.Phone, phoneNumber) {
+				return user.UserName
+			}
+		}
+	}
+
+	return fmt.Sprintf("%s (电话)", phoneNumber)
+}
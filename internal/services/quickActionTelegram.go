@@ -0,0 +1,113 @@
+package services
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+	"watchAlert/internal/models"
+	"watchAlert/internal/types"
+	"watchAlert/pkg/sender"
+
+	"github.com/zeromicro/go-zero/core/logc"
+)
+
+// TelegramCallback 处理 Telegram 内联按钮回调
+// 仅按 Telegram 数字用户 ID 匹配 WatchAlert 用户的值班用户 ID, 执行认领、静默或标记已处理后编辑原消息展示操作人
+// 仅在 Webhook 密钥校验失败时返回错误, 操作失败通过回调应答提示操作人
+func (q *quickActionService) TelegramCallback(secretToken string, update types.TelegramUpdate) error {
+	setting, err := q.ctx.DB.Setting().Get()
+	if err != nil {
+		return err
+	}
+	config := setting.TelegramConfig
+	if config.WebhookSecret == "" || subtle.ConstantTimeCompare([]byte(secretToken), []byte(config.WebhookSecret)) != 1 {
+		return errors.New("Telegram Webhook 密钥校验失败")
+	}
+
+	query := update.CallbackQuery
+	if query == nil {
+		return nil
+	}
+
+	bot := sender.NewTelegramBot(config)
+	answer := func(text string) {
+		if err := bot.AnswerCallbackQuery(query.Id, text); err != nil {
+			logc.Errorf(q.ctx.Ctx, "应答 Telegram 回调失败: %s", err.Error())
+		}
+	}
+
+	action, duration, tenantId, fingerprint, err := models.ParseTelegramCallbackData(query.Data)
+	if err != nil {
+		answer("不支持的操作")
+		return nil
+	}
+
+	// 只按 Telegram 数字 ID 匹配, 用户名可由用户随意修改, 不能用于身份识别
+	member, err := q.ctx.DB.User().GetByDutyUserId(strconv.FormatInt(query.From.Id, 10))
+	if err != nil {
+		answer(fmt.Sprintf("未找到绑定的 WatchAlert 用户, 请将值班用户 ID 设置为 %d", query.From.Id))
+		return nil
+	}
+	if !slices.Contains(member.Tenants, tenantId) {
+		answer("无权操作该租户的告警")
+		return nil
+	}
+
+	const clientIP = "telegram"
+	var actionName string
+	switch action {
+	case models.TelegramActionClaim:
+		err = q.ClaimAlert(tenantId, fingerprint, member.UserName, clientIP)
+		actionName = "认领"
+	case models.TelegramActionSilence:
+		err = q.SilenceAlert(tenantId, fingerprint, duration, member.UserName, clientIP)
+		actionName = "静默 " + duration
+	case models.TelegramActionResolve:
+		err = q.ResolveAlert(tenantId, fingerprint, member.UserName, clientIP)
+		actionName = "标记已处理"
+	}
+	if err != nil {
+		answer(err.Error())
+		return nil
+	}
+	answer(actionName + "成功")
+
+	if query.Message != nil {
+		q.editTelegramMessage(bot, query.Message, action, fmt.Sprintf("✅ %s 已%s (%s)", member.UserName, actionName, time.Now().Format("2006-01-02 15:04:05")))
+	}
+
+	return nil
+}
+
+// editTelegramMessage 在原消息末尾追加操作记录, 认领后保留静默及标记已处理按钮, 其余操作后只保留链接按钮
+func (q *quickActionService) editTelegramMessage(bot sender.TelegramBot, message *types.TelegramCallbackMessage, action, note string) {
+	markup := models.FilterTelegramKeyboard(message.ReplyMarkup, func(button models.TelegramInlineButton) bool {
+		if button.Url != "" {
+			return true
+		}
+		if action != models.TelegramActionClaim {
+			return false
+		}
+		buttonAction, _, _, _, err := models.ParseTelegramCallbackData(button.CallbackData)
+		return err == nil && buttonAction != models.TelegramActionClaim
+	})
+	if markup == nil {
+		markup = &models.TelegramReplyMarkup{InlineKeyboard: [][]models.TelegramInlineButton{}}
+	}
+
+	// 只在末尾追加文本, 原消息的格式偏移量保持不变
+	err := bot.Call("editMessageText", models.TelegramMessage{
+		ChatId:                strconv.FormatInt(message.Chat.Id, 10),
+		MessageId:             message.MessageId,
+		Text:                  message.Text + "\n\n" + note,
+		Entities:              message.Entities,
+		DisableWebPagePreview: true,
+		ReplyMarkup:           markup,
+	})
+	if err != nil {
+		logc.Errorf(q.ctx.Ctx, "编辑 Telegram 消息失败: %s", err.Error())
+	}
+}
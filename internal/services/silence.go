@@ -1,7 +1,10 @@
 package services
 
 import (
+	"fmt"
+	"sort"
 	"time"
+	"watchAlert/alert/mute"
 	"watchAlert/internal/ctx"
 	models "watchAlert/internal/models"
 	"watchAlert/internal/types"
@@ -18,6 +21,8 @@ type InterSilenceService interface {
 	Update(req interface{}) (interface{}, interface{})
 	Delete(req interface{}) (interface{}, interface{})
 	List(req interface{}) (interface{}, interface{})
+	Occurrences(req interface{}) (interface{}, interface{})
+	Preview(req interface{}) (interface{}, interface{})
 }
 
 func newInterSilenceService(ctx *ctx.Context) InterSilenceService {
@@ -28,24 +33,32 @@ func newInterSilenceService(ctx *ctx.Context) InterSilenceService {
 
 func (ass alertSilenceService) Create(req interface{}) (interface{}, interface{}) {
 	r := req.(*types.RequestSilenceCreate)
-	updateAt := time.Now().Unix()
+	updateAt := time.Now()
 	silence := models.AlertSilences{
 		TenantId:      r.TenantId,
 		Name:          r.Name,
 		ID:            "s-" + tools.RandId(),
 		StartsAt:      r.StartsAt,
 		EndsAt:        r.EndsAt,
-		UpdateAt:      updateAt,
+		UpdateAt:      updateAt.Unix(),
 		UpdateBy:      r.UpdateBy,
 		FaultCenterId: r.FaultCenterId,
 		Labels:        r.Labels,
 		Comment:       r.Comment,
-		Status:        1,
+		Type:          r.Type,
+		Recurrence:    r.Recurrence,
 	}
 
-	if r.StartsAt > updateAt {
-		r.Status = 0
+	if silence.Type == "" {
+		silence.Type = models.SilenceTypeOnce
 	}
+	if err := mute.ValidateMatchers(silence.Labels); err != nil {
+		return nil, err
+	}
+	if err := silence.Validate(); err != nil {
+		return nil, err
+	}
+	silence.Status = silence.EvalStatus(updateAt)
 
 	ass.ctx.Redis.Silence().PushAlertMute(silence)
 	err := ass.ctx.DB.Silence().Create(silence)
@@ -58,31 +71,43 @@ func (ass alertSilenceService) Create(req interface{}) (interface{}, interface{}
 
 func (ass alertSilenceService) Update(req interface{}) (interface{}, interface{}) {
 	r := req.(*types.RequestSilenceUpdate)
+	updateAt := time.Now()
 	silence := models.AlertSilences{
 		TenantId:      r.TenantId,
 		Name:          r.Name,
 		ID:            r.ID,
 		StartsAt:      r.StartsAt,
 		EndsAt:        r.EndsAt,
-		UpdateAt:      time.Now().Unix(),
+		UpdateAt:      updateAt.Unix(),
 		UpdateBy:      r.UpdateBy,
 		FaultCenterId: r.FaultCenterId,
 		Labels:        r.Labels,
 		Comment:       r.Comment,
-		Status:        1,
+		Type:          r.Type,
+		Recurrence:    r.Recurrence,
 	}
 
-	if r.StartsAt > r.UpdateAt {
-		r.Status = 0
-	} else {
-		r.Status = 1
+	if silence.Type == "" {
+		silence.Type = models.SilenceTypeOnce
+	}
+	if err := mute.ValidateMatchers(silence.Labels); err != nil {
+		return nil, err
 	}
+	if err := silence.Validate(); err != nil {
+		return nil, err
+	}
+	silence.Status = silence.EvalStatus(updateAt)
 
 	ass.ctx.Redis.Silence().PushAlertMute(silence)
 	err := ass.ctx.DB.Silence().Update(silence)
 	if err != nil {
 		return nil, err
 	}
+	// Updates 不会写入零值, 单独更新状态
+	err = ass.ctx.DB.Silence().UpdateStatus(silence.TenantId, silence.ID, silence.Status)
+	if err != nil {
+		return nil, err
+	}
 
 	return nil, nil
 }
@@ -114,3 +139,88 @@ func (ass alertSilenceService) List(req interface{}) (interface{}, interface{})
 		},
 	}, nil
 }
+
+// Occurrences 获取静默规则接下来的生效时间窗口
+func (ass alertSilenceService) Occurrences(req interface{}) (interface{}, interface{}) {
+	r := req.(*types.RequestSilenceOccurrences)
+	silence, err := ass.ctx.DB.Silence().Get(r.TenantId, r.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	count := r.Count
+	if count <= 0 || count > 100 {
+		count = 10
+	}
+
+	occurrences, err := silence.Occurrences(time.Now(), count)
+	if err != nil {
+		return nil, err
+	}
+	if occurrences == nil {
+		occurrences = []models.SilenceOccurrence{}
+	}
+
+	return occurrences, nil
+}
+
+// 静默预览的历史告警查询范围(小时)及最大条数
+const (
+	silencePreviewMaxLookback  = 7 * 24
+	silencePreviewHistoryLimit = 1000
+)
+
+// Preview 预览静默规则保存后会影响的告警事件
+func (ass alertSilenceService) Preview(req interface{}) (interface{}, interface{}) {
+	r := req.(*types.RequestSilencePreview)
+	if r.FaultCenterId == "" {
+		return nil, fmt.Errorf("故障中心 ID 不能为空")
+	}
+	if err := mute.ValidateMatchers(r.Labels); err != nil {
+		return nil, err
+	}
+
+	lookback := r.Lookback
+	if lookback <= 0 {
+		lookback = 24
+	}
+	if lookback > silencePreviewMaxLookback {
+		lookback = silencePreviewMaxLookback
+	}
+
+	result := types.ResponseSilencePreview{
+		ActiveEvents: []models.AlertCurEvent{},
+		RecentEvents: []models.AlertHisEvent{},
+	}
+
+	events, err := ass.ctx.Redis.Alert().GetAllEvents(models.BuildAlertEventCacheKey(r.TenantId, r.FaultCenterId))
+	if err != nil {
+		return nil, err
+	}
+	for _, event := range events {
+		if mute.MatchLabels(event.Labels, event.Fingerprint, r.Labels) {
+			result.ActiveEvents = append(result.ActiveEvents, *event)
+		}
+	}
+	sort.Slice(result.ActiveEvents, func(i, j int) bool {
+		return result.ActiveEvents[i].FirstTriggerTime > result.ActiveEvents[j].FirstTriggerTime
+	})
+
+	since := time.Now().Add(-time.Duration(lookback) * time.Hour).Unix()
+	history, err := ass.ctx.DB.Event().ListRecentHistoryEvents(r.TenantId, r.FaultCenterId, since, silencePreviewHistoryLimit)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]struct{})
+	for _, event := range history {
+		if _, ok := seen[event.Fingerprint]; ok {
+			continue
+		}
+		if mute.MatchLabels(event.Labels, event.Fingerprint, r.Labels) {
+			seen[event.Fingerprint] = struct{}{}
+			result.RecentEvents = append(result.RecentEvents, event)
+		}
+	}
+
+	return result, nil
+}
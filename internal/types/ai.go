@@ -1,6 +1,9 @@
 package types
 
-import "fmt"
+import (
+	"fmt"
+	"watchAlert/internal/models"
+)
 
 type RequestAiChatContent struct {
 	// 规则名称，用来分析告警时，更明确当前是一个什么规则
@@ -11,6 +14,10 @@ type RequestAiChatContent struct {
 	Content string `json:"content" form:"content"`
 	// 重新分析，不调用缓存
 	Deep string `json:"deep" form:"deep"`
+	// 指定告警事件时结合事件上下文分析, 并按事件缓存分析结果
+	TenantId      string `json:"tenantId" form:"tenantId"`
+	FaultCenterId string `json:"faultCenterId" form:"faultCenterId"`
+	Fingerprint   string `json:"fingerprint" form:"fingerprint"`
 }
 
 func (a RequestAiChatContent) ValidateParams() error {
@@ -26,3 +33,85 @@ func (a RequestAiChatContent) ValidateParams() error {
 
 	return nil
 }
+
+// RequestAiAgent 请求以工具调用模式分析告警根因
+type RequestAiAgent struct {
+	TenantId      string `json:"tenantId"`
+	FaultCenterId string `json:"faultCenterId"`
+	Fingerprint   string `json:"fingerprint"`
+	// 补充的问题或背景信息
+	Question string `json:"question"`
+	// 最大推理步数, 为空时使用默认值
+	MaxSteps int `json:"maxSteps"`
+}
+
+func (a RequestAiAgent) ValidateParams() error {
+	if a.FaultCenterId == "" || a.Fingerprint == "" {
+		return fmt.Errorf("故障中心 ID 及告警指纹不可为空")
+	}
+	if a.MaxSteps > 20 {
+		return fmt.Errorf("最大推理步数不可超过 20")
+	}
+
+	return nil
+}
+
+// RequestAiUsageQuery 查询租户的 Token 用量
+type RequestAiUsageQuery struct {
+	TenantId string `json:"tenantId" form:"tenantId"`
+	StartAt  int64  `json:"startAt" form:"startAt"`
+	EndAt    int64  `json:"endAt" form:"endAt"`
+}
+
+type ResponseAiUsage struct {
+	List             []models.AiUsageStat `json:"list"`
+	Requests         int64                `json:"requests"`
+	PromptTokens     int64                `json:"promptTokens"`
+	CompletionTokens int64                `json:"completionTokens"`
+	TotalTokens      int64                `json:"totalTokens"`
+}
+
+// RequestAiRuleDraft 根据自然语言描述生成告警规则草稿
+type RequestAiRuleDraft struct {
+	TenantId      string `json:"tenantId"`
+	DatasourceId  string `json:"datasourceId"`
+	RuleGroupId   string `json:"ruleGroupId"`
+	FaultCenterId string `json:"faultCenterId"`
+	// 自然语言描述的告警需求
+	Description string `json:"description"`
+	// 调整草稿时传入上一次的草稿及修改意见
+	Draft    *RequestRuleCreate `json:"draft"`
+	Feedback string             `json:"feedback"`
+}
+
+func (a RequestAiRuleDraft) ValidateParams() error {
+	if a.DatasourceId == "" {
+		return fmt.Errorf("数据源 ID 不可为空")
+	}
+	if a.Description == "" && a.Feedback == "" {
+		return fmt.Errorf("告警需求描述不可为空")
+	}
+
+	return nil
+}
+
+// ResponseAiRuleDraft 规则草稿及使用数据源实际查询的校验结果
+type ResponseAiRuleDraft struct {
+	Rule        RequestRuleCreate `json:"rule"`
+	Explanation string            `json:"explanation"`
+	// 查询语句是否执行成功且规则配置有效
+	Valid            bool     `json:"valid"`
+	ValidationErrors []string `json:"validationErrors"`
+	// 查询返回的样本, 指标为时间序列的当前值, 日志为日志内容
+	MetricSamples []AiRuleDraftMetricSample `json:"metricSamples"`
+	LogSamples    []map[string]interface{}  `json:"logSamples"`
+	// 查询命中的时间序列或日志条数
+	Total int `json:"total"`
+	// 按当前数据各告警等级会触发的数量, 日志规则为 0 或 1
+	Triggered map[string]int `json:"triggered"`
+}
+
+type AiRuleDraftMetricSample struct {
+	Labels map[string]interface{} `json:"labels"`
+	Value  float64                `json:"value"`
+}
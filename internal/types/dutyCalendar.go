@@ -10,6 +10,9 @@ type RequestDutyCalendarCreate struct {
 	UserGroup  [][]models.DutyUser `json:"userGroup"`
 	DateType   string              `json:"dateType"`
 	Status     string              `json:"status" `
+	// 节假日处理方式及节假日值班人员, 为空时使用值班组的配置
+	HolidayMode       string              `json:"holidayMode"`
+	HolidayUserGroups [][]models.DutyUser `json:"holidayUserGroups"`
 }
 
 type RequestDutyCalendarUpdate struct {
@@ -25,3 +28,82 @@ type RequestDutyCalendarQuery struct {
 	DutyId   string `json:"dutyId" form:"dutyId"`
 	Time     string `json:"time" form:"time"`
 }
+
+type RequestDutyOnCallQuery struct {
+	TenantId string `json:"tenantId" form:"tenantId"`
+	DutyId   string `json:"dutyId" form:"dutyId"`
+	Time     int64  `json:"time" form:"time"` // 查询时刻, 为空时取当前时间
+}
+
+type ResponseDutyOnCall struct {
+	TimeZone string              `json:"timeZone"`
+	OnCall   []models.DutyOnCall `json:"onCall"`
+}
+
+type RequestHolidayImport struct {
+	TenantId        string   `json:"tenantId"`
+	Source          string   `json:"source"`          // 导入来源, 同一来源重复导入时整体替换
+	Content         string   `json:"content"`         // ICS 文件内容
+	WorkdayKeywords []string `json:"workdayKeywords"` // 事件标题包含这些关键字时视为调休上班, 为空时使用默认关键字
+}
+
+type ResponseHolidayImport struct {
+	Holidays int `json:"holidays"`
+	Workdays int `json:"workdays"`
+	Skipped  int `json:"skipped"` // 非全天事件不导入
+}
+
+type RequestHolidayQuery struct {
+	TenantId string `json:"tenantId" form:"tenantId"`
+	Source   string `json:"source" form:"source"`
+	Year     int    `json:"year" form:"year"`
+}
+
+type RequestCalendarFeedCreate struct {
+	TenantId string `json:"tenantId"`
+	Type     string `json:"type"`
+	DutyId   string `json:"dutyId"`
+	UserId   string `json:"userId"`
+	CreateBy string `json:"createBy"`
+}
+
+type RequestCalendarFeedQuery struct {
+	TenantId string `json:"tenantId" form:"tenantId"`
+	Token    string `json:"token" form:"token"`
+	UserId   string `json:"userId" form:"userId"`
+}
+
+type ResponseCalendarFeed struct {
+	models.DutyCalendarFeed
+	Path string `json:"path"` // 订阅地址路径
+}
+
+type RequestDutyHandoffCreate struct {
+	TenantId string `json:"tenantId"`
+	DutyId   string `json:"dutyId"`
+	Notify   bool   `json:"notify"` // 是否发送到值班组绑定的通知对象
+	CreateBy string `json:"createBy"`
+}
+
+type RequestDutyHandoffQuery struct {
+	TenantId string `json:"tenantId" form:"tenantId"`
+	ID       string `json:"id" form:"id"`
+	DutyId   string `json:"dutyId" form:"dutyId"`
+	models.Page
+}
+
+type ResponseDutyHandoffList struct {
+	List []models.DutyHandoff `json:"list"`
+	models.Page
+}
+
+type RequestOnCallAnalytics struct {
+	TenantId string `json:"tenantId" form:"tenantId"`
+	DutyId   string `json:"dutyId" form:"dutyId"` // 为空时统计租户下所有值班组
+	StartAt  int64  `json:"startAt" form:"startAt"`
+	EndAt    int64  `json:"endAt" form:"endAt"`
+	Period   string `json:"period" form:"period"`     // day, week, month, 默认 day
+	TimeZone string `json:"timeZone" form:"timeZone"` // 趋势周期的划分时区, 默认服务器时区
+	Level    int    `json:"level" form:"level"`       // 只统计指定值班层级, 为空时统计所有层级
+	SortBy   string `json:"sortBy" form:"sortBy"`     // 排行榜排序字段, 默认按通知次数
+}
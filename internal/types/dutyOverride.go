@@ -0,0 +1,59 @@
+package types
+
+import "watchAlert/internal/models"
+
+type RequestDutyOverrideCreate struct {
+	TenantId string          `json:"tenantId"`
+	DutyId   string          `json:"dutyId"`
+	Layer    string          `json:"layer"`
+	User     models.DutyUser `json:"user"`
+	StartAt  int64           `json:"startAt"`
+	EndAt    int64           `json:"endAt"`
+	Reason   string          `json:"reason"`
+	CreateBy string          `json:"createBy"`
+}
+
+type RequestDutyOverrideCancel struct {
+	TenantId   string `json:"tenantId"`
+	ID         string `json:"id"`
+	CanceledBy string `json:"canceledBy"`
+}
+
+type RequestDutyOverrideQuery struct {
+	TenantId string `json:"tenantId" form:"tenantId"`
+	DutyId   string `json:"dutyId" form:"dutyId"`
+	StartAt  int64  `json:"startAt" form:"startAt"`
+	EndAt    int64  `json:"endAt" form:"endAt"`
+}
+
+type RequestDutySwapCreate struct {
+	TenantId       string          `json:"tenantId"`
+	DutyId         string          `json:"dutyId"`
+	Layer          string          `json:"layer"`
+	Counterpart    models.DutyUser `json:"counterpart"`
+	StartAt        int64           `json:"startAt"`
+	EndAt          int64           `json:"endAt"`
+	CounterStartAt int64           `json:"counterStartAt"`
+	CounterEndAt   int64           `json:"counterEndAt"`
+	Reason         string          `json:"reason"`
+	UserId         string          `json:"userId"`
+}
+
+type RequestDutySwapAction struct {
+	TenantId string `json:"tenantId"`
+	ID       string `json:"id"`
+	UserId   string `json:"userId"`
+}
+
+type RequestDutySwapQuery struct {
+	TenantId string `json:"tenantId" form:"tenantId"`
+	DutyId   string `json:"dutyId" form:"dutyId"`
+	Status   string `json:"status" form:"status"`
+}
+
+type RequestDutyOnCallHistory struct {
+	TenantId string `json:"tenantId" form:"tenantId"`
+	DutyId   string `json:"dutyId" form:"dutyId"`
+	StartAt  int64  `json:"startAt" form:"startAt"`
+	EndAt    int64  `json:"endAt" form:"endAt"`
+}
@@ -25,6 +25,7 @@ type RequestAlertCurEventQuery struct {
 	FaultCenterId  string `json:"faultCenterId" form:"faultCenterId"`
 	Status         string `json:"status" form:"status"`
 	SortOrder      string `json:"sortOrder" form:"sortOrder"`
+	ClusterId      string `json:"clusterId" form:"clusterId"`
 	models.Page
 }
 
@@ -34,6 +35,21 @@ type ResponseAlertCurEventList struct {
 	models.Page
 }
 
+// RequestAlertFlapStateQuery 请求查询告警抖动状态
+type RequestAlertFlapStateQuery struct {
+	TenantId      string `json:"tenantId" form:"tenantId"`
+	FaultCenterId string `json:"faultCenterId" form:"faultCenterId"`
+	Fingerprint   string `json:"fingerprint" form:"fingerprint"`
+}
+
+// RequestAlertClusterQuery 请求查询活跃告警的关联聚类
+type RequestAlertClusterQuery struct {
+	TenantId      string `json:"tenantId" form:"tenantId"`
+	FaultCenterId string `json:"faultCenterId" form:"faultCenterId"`
+	// 聚类的最少告警数量, 默认 2
+	MinSize int `json:"minSize" form:"minSize"`
+}
+
 // RequestAlertHisEventQuery 请求查询历史事件
 type RequestAlertHisEventQuery struct {
 	TenantId       string `json:"tenantId" form:"tenantId"`
@@ -88,3 +104,9 @@ type RequestListEventComments struct {
 	// 告警指纹
 	Fingerprint string `json:"fingerprint" form:"fingerprint"`
 }
+
+// RequestEventGraph 获取通知附带的趋势图
+type RequestEventGraph struct {
+	// 趋势图的对象 Key
+	Key string `json:"key" form:"key"`
+}
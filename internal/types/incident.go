@@ -0,0 +1,88 @@
+package types
+
+import "watchAlert/internal/models"
+
+// RequestIncidentCreate 请求创建故障
+type RequestIncidentCreate struct {
+	TenantId      string   `json:"tenantId"`
+	Title         string   `json:"title"`
+	Description   string   `json:"description"`
+	Severity      string   `json:"severity"`
+	Commander     string   `json:"commander"`
+	Responders    []string `json:"responders"`
+	FaultCenterId string   `json:"faultCenterId"`
+	NoticeIds     []string `json:"noticeIds"`
+	Fingerprints  []string `json:"fingerprints"` // 创建时关联的告警事件
+	CreateBy      string   `json:"createBy"`
+}
+
+// RequestIncidentUpdate 请求更新故障基础信息
+type RequestIncidentUpdate struct {
+	TenantId    string   `json:"tenantId"`
+	ID          string   `json:"id"`
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Severity    string   `json:"severity"`
+	Commander   string   `json:"commander"`
+	Responders  []string `json:"responders"`
+	NoticeIds   []string `json:"noticeIds"`
+	UpdateBy    string   `json:"updateBy"`
+}
+
+// RequestIncidentQuery 请求查询故障
+type RequestIncidentQuery struct {
+	TenantId      string `json:"tenantId" form:"tenantId"`
+	ID            string `json:"id" form:"id"`
+	FaultCenterId string `json:"faultCenterId" form:"faultCenterId"`
+	Status        string `json:"status" form:"status"`
+	Query         string `json:"query" form:"query"`
+	models.Page
+}
+
+// RequestIncidentStatusChange 请求变更故障状态
+type RequestIncidentStatusChange struct {
+	TenantId string                `json:"tenantId"`
+	ID       string                `json:"id"`
+	Status   models.IncidentStatus `json:"status"`
+	Comment  string                `json:"comment"`
+	Username string                `json:"username"`
+}
+
+// RequestIncidentLinkEvents 请求关联/取消关联告警事件
+type RequestIncidentLinkEvents struct {
+	TenantId      string   `json:"tenantId"`
+	ID            string   `json:"id"`
+	FaultCenterId string   `json:"faultCenterId"` // 告警事件所在的故障中心, 为空时使用故障所属的故障中心
+	Fingerprints  []string `json:"fingerprints"`
+	Username      string   `json:"username"`
+}
+
+// RequestIncidentComment 请求添加故障评论
+type RequestIncidentComment struct {
+	TenantId string `json:"tenantId"`
+	ID       string `json:"id"`
+	Content  string `json:"content"`
+	Username string `json:"username"`
+}
+
+// RequestIncidentBroadcast 请求发送故障状态通报
+type RequestIncidentBroadcast struct {
+	TenantId  string   `json:"tenantId"`
+	ID        string   `json:"id"`
+	NoticeIds []string `json:"noticeIds"` // 为空时使用故障配置的通报对象
+	Message   string   `json:"message"`
+	Username  string   `json:"username"`
+}
+
+// ResponseIncidentList 返回故障列表
+type ResponseIncidentList struct {
+	List []models.Incident `json:"list"`
+	models.Page
+}
+
+// ResponseIncidentDetail 返回故障详情
+type ResponseIncidentDetail struct {
+	models.Incident
+	Events   []models.IncidentEvent    `json:"events"`
+	Timeline []models.IncidentTimeline `json:"timeline"`
+}
@@ -0,0 +1,58 @@
+package types
+
+// PagerDutyWebhook PagerDuty Webhook v3 推送的事件
+type PagerDutyWebhook struct {
+	Event PagerDutyWebhookEvent `json:"event"`
+}
+
+type PagerDutyWebhookEvent struct {
+	Id        string                 `json:"id"`
+	EventType string                 `json:"event_type"`
+	Agent     *PagerDutyWebhookAgent `json:"agent"`
+	Data      PagerDutyWebhookData   `json:"data"`
+}
+
+// PagerDutyWebhookAgent 触发事件的用户, Summary 为用户名称
+type PagerDutyWebhookAgent struct {
+	Id      string `json:"id"`
+	Type    string `json:"type"`
+	Summary string `json:"summary"`
+}
+
+// PagerDutyWebhookData 事件关联的故障, IncidentKey 为发送事件时的 dedup_key
+type PagerDutyWebhookData struct {
+	Id          string `json:"id"`
+	Type        string `json:"type"`
+	Status      string `json:"status"`
+	IncidentKey string `json:"incident_key"`
+	Title       string `json:"title"`
+}
+
+// OpsgenieWebhook Opsgenie Webhook 集成推送的告警操作
+type OpsgenieWebhook struct {
+	Action string               `json:"action"`
+	Alert  OpsgenieWebhookAlert `json:"alert"`
+}
+
+// OpsgenieWebhookAlert 操作的告警, Username 为操作人邮箱
+type OpsgenieWebhookAlert struct {
+	AlertId  string `json:"alertId"`
+	Alias    string `json:"alias"`
+	Message  string `json:"message"`
+	Username string `json:"username"`
+	UserId   string `json:"userId"`
+}
+
+// TencentVoiceCallback 腾讯云语音消息回调, 目前只处理按键回调
+type TencentVoiceCallback struct {
+	VoiceKeyCallback *TencentVoiceKeyCallback `json:"voicekey_callback"`
+}
+
+// TencentVoiceKeyCallback 按键回调, Ext 为呼叫时传入的 SessionContext
+type TencentVoiceKeyCallback struct {
+	CallId     string `json:"callid"`
+	Keypress   string `json:"keypress"`
+	Mobile     string `json:"mobile"`
+	NationCode string `json:"nationcode"`
+	Ext        string `json:"ext"`
+}
@@ -0,0 +1,73 @@
+package types
+
+import (
+	"fmt"
+	"watchAlert/internal/models"
+)
+
+// RequestPostmortemGenerate 请求生成复盘报告, 指定故障或故障中心及时间窗口
+type RequestPostmortemGenerate struct {
+	TenantId      string `json:"tenantId"`
+	ID            string `json:"id"` // 不为空时基于已有报告重新生成新版本
+	IncidentId    string `json:"incidentId"`
+	FaultCenterId string `json:"faultCenterId"`
+	StartAt       int64  `json:"startAt"`
+	EndAt         int64  `json:"endAt"`
+	Title         string `json:"title"`
+	Extra         string `json:"extra"` // 补充给 AI 的背景信息
+	Username      string `json:"username"`
+}
+
+func (r RequestPostmortemGenerate) ValidateParams() error {
+	if r.ID != "" || r.IncidentId != "" {
+		return nil
+	}
+	if r.FaultCenterId == "" {
+		return fmt.Errorf("请指定故障或故障中心")
+	}
+	if r.StartAt <= 0 || r.EndAt <= r.StartAt {
+		return fmt.Errorf("无效的时间窗口")
+	}
+
+	return nil
+}
+
+// RequestPostmortemUpdate 请求编辑复盘报告, 每次编辑生成新版本
+type RequestPostmortemUpdate struct {
+	TenantId string `json:"tenantId"`
+	ID       string `json:"id"`
+	Title    string `json:"title"`
+	Content  string `json:"content"`
+	Username string `json:"username"`
+}
+
+// RequestPostmortemFinalize 请求定稿复盘报告
+type RequestPostmortemFinalize struct {
+	TenantId string `json:"tenantId"`
+	ID       string `json:"id"`
+	Username string `json:"username"`
+}
+
+// RequestPostmortemQuery 请求查询复盘报告
+type RequestPostmortemQuery struct {
+	TenantId   string `json:"tenantId" form:"tenantId"`
+	ID         string `json:"id" form:"id"`
+	IncidentId string `json:"incidentId" form:"incidentId"`
+	Status     string `json:"status" form:"status"`
+	Version    int    `json:"version" form:"version"` // 为空时返回当前版本
+	Query      string `json:"query" form:"query"`
+	models.Page
+}
+
+// ResponsePostmortemList 返回复盘报告列表
+type ResponsePostmortemList struct {
+	List []models.Postmortem `json:"list"`
+	models.Page
+}
+
+// ResponsePostmortemDetail 返回复盘报告详情
+type ResponsePostmortemDetail struct {
+	models.Postmortem
+	Content  string                     `json:"content"`
+	Versions []models.PostmortemVersion `json:"versions"`
+}
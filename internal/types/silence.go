@@ -4,31 +4,35 @@ import "watchAlert/internal/models"
 
 // RequestSilenceCreate 请求创建静默规则
 type RequestSilenceCreate struct {
-	TenantId      string                `json:"tenantId"`
-	Name          string                `json:"name"`
-	Labels        []models.SilenceLabel `json:"labels" gorm:"labels;serializer:json"`
-	StartsAt      int64                 `json:"startsAt"`
-	UpdateBy      string                `json:"updateBy"`
-	EndsAt        int64                 `json:"endsAt"`
-	UpdateAt      int64                 `json:"updateAt"`
-	FaultCenterId string                `json:"faultCenterId"`
-	Comment       string                `json:"comment"`
-	Status        int                   `json:"status"` // 0 未生效, 1 进行中, 2 已失效
+	TenantId      string                   `json:"tenantId"`
+	Name          string                   `json:"name"`
+	Labels        []models.SilenceLabel    `json:"labels" gorm:"labels;serializer:json"`
+	StartsAt      int64                    `json:"startsAt"`
+	UpdateBy      string                   `json:"updateBy"`
+	EndsAt        int64                    `json:"endsAt"`
+	UpdateAt      int64                    `json:"updateAt"`
+	FaultCenterId string                   `json:"faultCenterId"`
+	Comment       string                   `json:"comment"`
+	Status        int                      `json:"status"` // 0 未生效, 1 进行中, 2 已失效
+	Type          string                   `json:"type"`   // once 单次静默, recurring 周期静默
+	Recurrence    models.SilenceRecurrence `json:"recurrence"`
 }
 
 // RequestSilenceUpdate 请求更新静默规则
 type RequestSilenceUpdate struct {
-	TenantId      string                `json:"tenantId"`
-	Name          string                `json:"name"`
-	ID            string                `json:"id"`
-	Labels        []models.SilenceLabel `json:"labels" gorm:"labels;serializer:json"`
-	StartsAt      int64                 `json:"startsAt"`
-	UpdateBy      string                `json:"updateBy"`
-	EndsAt        int64                 `json:"endsAt"`
-	UpdateAt      int64                 `json:"updateAt"`
-	FaultCenterId string                `json:"faultCenterId"`
-	Comment       string                `json:"comment"`
-	Status        int                   `json:"status"` // 0 未生效, 1 进行中, 2 已失效
+	TenantId      string                   `json:"tenantId"`
+	Name          string                   `json:"name"`
+	ID            string                   `json:"id"`
+	Labels        []models.SilenceLabel    `json:"labels" gorm:"labels;serializer:json"`
+	StartsAt      int64                    `json:"startsAt"`
+	UpdateBy      string                   `json:"updateBy"`
+	EndsAt        int64                    `json:"endsAt"`
+	UpdateAt      int64                    `json:"updateAt"`
+	FaultCenterId string                   `json:"faultCenterId"`
+	Comment       string                   `json:"comment"`
+	Status        int                      `json:"status"` // 0 未生效, 1 进行中, 2 已失效
+	Type          string                   `json:"type"`   // once 单次静默, recurring 周期静默
+	Recurrence    models.SilenceRecurrence `json:"recurrence"`
 }
 
 // RequestSilenceQuery 请求查询静默规则
@@ -41,8 +45,29 @@ type RequestSilenceQuery struct {
 	models.Page
 }
 
+// RequestSilenceOccurrences 请求查询静默规则接下来的生效时间窗口
+type RequestSilenceOccurrences struct {
+	TenantId string `json:"tenantId" form:"tenantId"`
+	ID       string `json:"id" form:"id"`
+	Count    int    `json:"count" form:"count"`
+}
+
 // ResponseSilenceList 返回静默规则列表
 type ResponseSilenceList struct {
 	List []models.AlertSilences `json:"list"`
 	models.Page
 }
+
+// RequestSilencePreview 请求预览静默规则的影响范围
+type RequestSilencePreview struct {
+	TenantId      string                `json:"tenantId"`
+	FaultCenterId string                `json:"faultCenterId"`
+	Labels        []models.SilenceLabel `json:"labels"`
+	Lookback      int64                 `json:"lookback"` // 历史告警回溯时间，单位（小时）
+}
+
+// ResponseSilencePreview 静默规则影响范围
+type ResponseSilencePreview struct {
+	ActiveEvents []models.AlertCurEvent `json:"activeEvents"` // 当前会被静默的活跃告警
+	RecentEvents []models.AlertHisEvent `json:"recentEvents"` // 回溯时间内出现过且会被静默的告警, 按指纹去重
+}
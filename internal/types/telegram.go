@@ -0,0 +1,38 @@
+package types
+
+import (
+	"encoding/json"
+	"watchAlert/internal/models"
+)
+
+// TelegramUpdate Telegram Webhook 推送的更新, 目前只处理内联按钮回调
+type TelegramUpdate struct {
+	UpdateId      int64                  `json:"update_id"`
+	CallbackQuery *TelegramCallbackQuery `json:"callback_query"`
+}
+
+type TelegramCallbackQuery struct {
+	Id      string                   `json:"id"`
+	From    TelegramUser             `json:"from"`
+	Message *TelegramCallbackMessage `json:"message"`
+	Data    string                   `json:"data"`
+}
+
+type TelegramUser struct {
+	Id        int64  `json:"id"`
+	Username  string `json:"username"`
+	FirstName string `json:"first_name"`
+}
+
+// TelegramCallbackMessage 按钮所在的消息, Entities 为消息的格式信息, 编辑消息时原样带回以保留格式
+type TelegramCallbackMessage struct {
+	MessageId   int64                       `json:"message_id"`
+	Chat        TelegramChat                `json:"chat"`
+	Text        string                      `json:"text"`
+	Entities    json.RawMessage             `json:"entities"`
+	ReplyMarkup *models.TelegramReplyMarkup `json:"reply_markup"`
+}
+
+type TelegramChat struct {
+	Id int64 `json:"id"`
+}
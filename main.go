@@ -3,6 +3,8 @@ package main
 import (
 	"net/http"
 	_ "net/http/pprof"
+	// 内置时区数据, 运行镜像缺少 zoneinfo 时值班组时区仍可解析
+	_ "time/tzdata"
 	"watchAlert/initialization"
 	"watchAlert/internal/global"
 )
@@ -0,0 +1,197 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// DefaultAgentMaxSteps 默认的最大推理步数, 每次请求模型计为一步
+	DefaultAgentMaxSteps = 6
+	// DefaultAgentToolTimeout 单次工具调用的默认超时时间
+	DefaultAgentToolTimeout = 30 * time.Second
+	// 单次工具调用结果回传给模型的最大长度
+	agentToolResultLimit = 8000
+)
+
+type (
+	// ToolCallingClient 支持工具调用的 AI 客户端
+	ToolCallingClient interface {
+		ChatWithTools(ctx context.Context, messages []*Message, tools []Tool) (*Message, error)
+	}
+
+	// AgentTool 根因分析 Agent 可调用的工具
+	AgentTool struct {
+		Name        string
+		Description string
+		Parameters  map[string]interface{}
+		// Handler 执行工具调用, arguments 为模型生成的 JSON 参数, ctx 超时或请求取消时应尽快返回
+		Handler func(ctx context.Context, arguments string) (string, error)
+		// Timeout 单次调用的超时时间, 为 0 时使用 DefaultAgentToolTimeout
+		Timeout time.Duration
+	}
+
+	// AgentStep Agent 执行过程中的一次工具调用, 作为分析结论的证据
+	AgentStep struct {
+		Step      int    `json:"step"`
+		Tool      string `json:"tool"`
+		Arguments string `json:"arguments"`
+		Result    string `json:"result"`
+		Error     string `json:"error,omitempty"`
+		Duration  int64  `json:"duration"` // 毫秒
+	}
+
+	// AgentResult Agent 分析结果
+	AgentResult struct {
+		Findings  string      `json:"findings"`
+		Steps     []AgentStep `json:"steps"`
+		UsedSteps int         `json:"usedSteps"`
+		Exhausted bool        `json:"exhausted"` // 是否因步数用尽而提前结束
+	}
+)
+
+// RunAgent 循环请求模型并执行其发起的工具调用, 直到模型给出结论或步数用尽
+func RunAgent(ctx context.Context, client ToolCallingClient, systemPrompt, task string, tools []AgentTool, maxSteps int) (AgentResult, error) {
+	var result AgentResult
+	if maxSteps <= 0 {
+		maxSteps = DefaultAgentMaxSteps
+	}
+
+	var (
+		handlers    = make(map[string]AgentTool, len(tools))
+		definitions = make([]Tool, 0, len(tools))
+		messages    = []*Message{
+			{Role: "system", Content: systemPrompt},
+			{Role: "user", Content: task},
+		}
+	)
+	for _, tool := range tools {
+		handlers[tool.Name] = tool
+		definitions = append(definitions, Tool{
+			Type: "function",
+			Function: ToolFunction{
+				Name:        tool.Name,
+				Description: tool.Description,
+				Parameters:  tool.Parameters,
+			},
+		})
+	}
+
+	for step := 1; step <= maxSteps; step++ {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		// 最后一步不再提供工具, 要求模型基于已有证据给出结论
+		stepTools := definitions
+		if step == maxSteps && maxSteps > 1 {
+			stepTools = nil
+			if len(result.Steps) > 0 {
+				result.Exhausted = true
+				messages = append(messages, &Message{
+					Role:    "user",
+					Content: "工具调用次数已用尽, 请基于以上已获取的数据直接给出根因分析结论。",
+				})
+			}
+		}
+
+		reply, err := client.ChatWithTools(ctx, messages, stepTools)
+		if err != nil {
+			return result, err
+		}
+		result.UsedSteps = step
+		messages = append(messages, reply)
+
+		if len(reply.ToolCalls) == 0 {
+			result.Findings = reply.Content
+			return result, nil
+		}
+		if step == maxSteps {
+			break
+		}
+
+		for _, call := range reply.ToolCalls {
+			record := AgentStep{
+				Step:      step,
+				Tool:      call.Function.Name,
+				Arguments: call.Function.Arguments,
+			}
+
+			start := time.Now()
+			content, err := callAgentTool(ctx, handlers, call)
+			record.Duration = time.Since(start).Milliseconds()
+			if err != nil {
+				record.Error = err.Error()
+				content = fmt.Sprintf("工具调用失败: %s", err.Error())
+			}
+			content = truncateToolResult(content)
+			record.Result = content
+			result.Steps = append(result.Steps, record)
+
+			messages = append(messages, &Message{
+				Role:       "tool",
+				ToolCallId: call.ID,
+				Content:    content,
+			})
+		}
+	}
+
+	// 最后一步仍返回工具调用时, 以已执行的证据结束
+	result.Exhausted = true
+	result.Findings = "已达到最大推理步数, 模型未给出最终结论, 请参考已执行的查询结果。"
+	return result, nil
+}
+
+func callAgentTool(ctx context.Context, handlers map[string]AgentTool, call ToolCall) (string, error) {
+	tool, ok := handlers[call.Function.Name]
+	if !ok {
+		return "", fmt.Errorf("未知的工具: %s", call.Function.Name)
+	}
+
+	arguments := call.Function.Arguments
+	if arguments == "" {
+		arguments = "{}"
+	}
+
+	timeout := tool.Timeout
+	if timeout <= 0 {
+		timeout = DefaultAgentToolTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return tool.Handler(ctx, arguments)
+}
+
+// CallWithContext 在 ctx 的约束下执行不支持取消的阻塞调用, ctx 先结束时直接返回错误, 调用结果被丢弃
+func CallWithContext(ctx context.Context, fn func() (string, error)) (string, error) {
+	type result struct {
+		content string
+		err     error
+	}
+	done := make(chan result, 1)
+	go func() {
+		content, err := fn()
+		done <- result{content, err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.content, r.err
+	case <-ctx.Done():
+		return "", fmt.Errorf("工具调用超时或已取消: %w", ctx.Err())
+	}
+}
+
+func truncateToolResult(content string) string {
+	if len(content) <= agentToolResultLimit {
+		return content
+	}
+
+	cut := agentToolResultLimit
+	for cut > 0 && !utf8.RuneStart(content[cut]) {
+		cut--
+	}
+	return content[:cut] + "\n...(结果已截断)"
+}
@@ -0,0 +1,145 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newStandInServer 模拟 OpenAI 兼容接口: 携带工具时先发起一次工具调用, 收到工具结果后返回结论
+func newStandInServer(t *testing.T, alwaysCallTool bool, requests *[]Request) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req Request
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		*requests = append(*requests, req)
+
+		last := req.Messages[len(req.Messages)-1]
+		var message map[string]interface{}
+		if len(req.Tools) > 0 && (alwaysCallTool || last.Role != "tool") {
+			message = map[string]interface{}{
+				"role":    "assistant",
+				"content": "",
+				"tool_calls": []map[string]interface{}{{
+					"id":   fmt.Sprintf("call_%d", len(*requests)),
+					"type": "function",
+					"function": map[string]interface{}{
+						"name":      "query_metrics_range",
+						"arguments": `{"promQL":"up == 0"}`,
+					},
+				}},
+			}
+		} else {
+			message = map[string]interface{}{
+				"role":    "assistant",
+				"content": "根因: 实例 node-1 宕机, 证据: " + last.Content,
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"id":      "chatcmpl-test",
+			"choices": []map[string]interface{}{{"message": message}},
+		})
+	}))
+}
+
+func newTestAgentTool(calls *[]string) AgentTool {
+	return AgentTool{
+		Name:        "query_metrics_range",
+		Description: "执行 PromQL 区间查询",
+		Parameters: map[string]interface{}{
+			"type":       "object",
+			"properties": map[string]interface{}{"promQL": map[string]interface{}{"type": "string"}},
+		},
+		Handler: func(_ context.Context, arguments string) (string, error) {
+			*calls = append(*calls, arguments)
+			return `instance="node-1" value=0`, nil
+		},
+	}
+}
+
+func TestRunAgentToolCalling(t *testing.T) {
+	var requests []Request
+	server := newStandInServer(t, false, &requests)
+	defer server.Close()
+
+	client := &AiConfig{Url: server.URL, ApiKey: "test", Model: "stand-in", Timeout: 5}
+	var calls []string
+	result, err := RunAgent(context.Background(), client, "system", "分析告警", []AgentTool{newTestAgentTool(&calls)}, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(calls) != 1 || calls[0] != `{"promQL":"up == 0"}` {
+		t.Fatalf("unexpected tool calls: %v", calls)
+	}
+	if len(result.Steps) != 1 || result.Steps[0].Tool != "query_metrics_range" || result.UsedSteps != 2 || result.Exhausted {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if !strings.Contains(result.Findings, `instance="node-1"`) {
+		t.Fatalf("findings should be based on tool result, got %q", result.Findings)
+	}
+
+	// 第二次请求需要携带 assistant 的工具调用及对应的 tool 消息
+	second := requests[1].Messages
+	if len(second) != 4 || second[2].ToolCalls[0].ID != "call_1" || second[3].ToolCallId != "call_1" {
+		t.Fatalf("unexpected conversation: %+v", second)
+	}
+}
+
+func TestRunAgentStepBudget(t *testing.T) {
+	var requests []Request
+	server := newStandInServer(t, true, &requests)
+	defer server.Close()
+
+	client := &AiConfig{Url: server.URL, ApiKey: "test", Model: "stand-in", Timeout: 5}
+	var calls []string
+	result, err := RunAgent(context.Background(), client, "system", "分析告警", []AgentTool{newTestAgentTool(&calls)}, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(requests) != 3 || len(calls) != 2 {
+		t.Fatalf("expected 3 requests and 2 tool calls, got %d / %d", len(requests), len(calls))
+	}
+	if len(requests[2].Tools) != 0 {
+		t.Fatalf("last step should not offer tools")
+	}
+	if !result.Exhausted || result.Findings == "" {
+		t.Fatalf("expected exhausted result with findings, got %+v", result)
+	}
+}
+
+func TestRunAgentToolTimeout(t *testing.T) {
+	var requests []Request
+	server := newStandInServer(t, false, &requests)
+	defer server.Close()
+
+	release := make(chan struct{})
+	defer close(release)
+	tool := newTestAgentTool(nil)
+	tool.Timeout = 50 * time.Millisecond
+	tool.Handler = func(ctx context.Context, _ string) (string, error) {
+		return CallWithContext(ctx, func() (string, error) {
+			// 模拟无响应的数据源
+			<-release
+			return "", nil
+		})
+	}
+
+	client := &AiConfig{Url: server.URL, ApiKey: "test", Model: "stand-in", Timeout: 5}
+	result, err := RunAgent(context.Background(), client, "system", "分析告警", []AgentTool{tool}, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Steps) != 1 || !strings.Contains(result.Steps[0].Error, context.DeadlineExceeded.Error()) {
+		t.Fatalf("slow tool should time out, got %+v", result.Steps)
+	}
+}
@@ -2,45 +2,113 @@ package ai
 
 import (
 	"bufio"
-	"bytes"
 	"context"
 	"fmt"
-	"github.com/bytedance/sonic"
-	"io"
 	"log"
-	"net/http"
 	"strings"
 	"watchAlert/internal/models"
 
-	"watchAlert/pkg/tools"
+	"github.com/bytedance/sonic"
 )
 
-// NewAiClient 工厂方法
+// Azure OpenAI 未指定 api-version 时使用的版本
+const defaultAzureApiVersion = "2024-06-01"
+
+// providerClient 单个模型服务的客户端
+type providerClient interface {
+	AiClient
+	ToolCallingClient
+}
+
+// NewAiClient 工厂方法, 按配置顺序创建各模型服务的客户端, 调用失败时依次降级
 func NewAiClient(config *models.AiConfig) (AiClient, error) {
-	ai := &AiConfig{
-		Url:       config.Url,
-		ApiKey:    config.AppKey,
-		MaxTokens: config.MaxTokens,
-		Model:     config.Model,
-		Timeout:   config.Timeout,
+	profiles := config.GetProfiles()
+	if len(profiles) == 0 {
+		return nil, fmt.Errorf("未配置启用的模型服务")
 	}
 
-	err := ai.Check(context.Background())
-	if err != nil {
-		return nil, err
+	client := &FallbackClient{
+		clients:        make(map[string]providerClient, len(profiles)),
+		tenantProfiles: config.TenantProfiles,
+	}
+	var errs []string
+	for i, profile := range profiles {
+		if profile.Name == "" {
+			profile.Name = fmt.Sprintf("profile-%d", i+1)
+		}
+		if _, exist := client.clients[profile.Name]; exist {
+			return nil, fmt.Errorf("模型服务名称重复: %s", profile.Name)
+		}
+
+		c, err := newProviderClient(profile, config.SystemPrompt)
+		if err == nil {
+			err = c.Check(context.Background())
+		}
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %s", profile.Name, err.Error()))
+			continue
+		}
+
+		client.order = append(client.order, profile.Name)
+		client.clients[profile.Name] = c
+	}
+
+	if len(client.order) == 0 {
+		return nil, fmt.Errorf("模型服务配置错误, %s", strings.Join(errs, "; "))
+	}
+	if len(errs) > 0 {
+		log.Printf("部分模型服务配置错误, 已跳过: %s", strings.Join(errs, "; "))
 	}
 
-	return ai, nil
+	return client, nil
 }
 
-func (o *AiConfig) ChatCompletion(_ context.Context, prompt string) (string, error) {
+func newProviderClient(profile models.AiProfile, systemPrompt string) (providerClient, error) {
+	switch profile.Provider {
+	case "", models.AiProviderOpenAI, models.AiProviderAzure:
+		return &AiConfig{
+			Name:         profile.Name,
+			Provider:     profile.Provider,
+			Url:          profile.Url,
+			ApiKey:       profile.AppKey,
+			Model:        profile.Model,
+			ApiVersion:   profile.ApiVersion,
+			Timeout:      profile.Timeout,
+			MaxTokens:    profile.MaxTokens,
+			SystemPrompt: systemPrompt,
+		}, nil
+	case models.AiProviderAnthropic:
+		return &AnthropicClient{
+			Name:         profile.Name,
+			Url:          profile.Url,
+			ApiKey:       profile.AppKey,
+			Model:        profile.Model,
+			Timeout:      profile.Timeout,
+			MaxTokens:    profile.MaxTokens,
+			SystemPrompt: systemPrompt,
+		}, nil
+	case models.AiProviderOllama:
+		return &OllamaClient{
+			Name:         profile.Name,
+			Url:          profile.Url,
+			Model:        profile.Model,
+			Timeout:      profile.Timeout,
+			MaxTokens:    profile.MaxTokens,
+			SystemPrompt: systemPrompt,
+		}, nil
+	default:
+		return nil, fmt.Errorf("不支持的模型服务类型: %s", profile.Provider)
+	}
+}
+
+func (o *AiConfig) ChatCompletion(ctx context.Context, prompt string) (string, error) {
 	// 构建请求参数
 	reqParams := Request{
 		Model: o.Model,
 		Messages: []*Message{
 			{
 				Role:    "system",
-				Content: "您是站点可靠性工程 (SRE) 可观测性监控专家、资深 DevOps 工程师、资深运维专家",
+				Content: systemPromptOrDefault(o.SystemPrompt),
 			},
 			{
 				Role:    "user",
@@ -51,94 +119,129 @@ func (o *AiConfig) ChatCompletion(_ context.Context, prompt string) (string, err
 		MaxTokens: o.MaxTokens,
 	}
 
-	bodyBytes, _ := sonic.Marshal(reqParams)
-	headers := make(map[string]string)
-	headers["Authorization"] = "Bearer " + o.ApiKey
-	response, err := tools.Post(headers, o.Url, bytes.NewReader(bodyBytes), o.Timeout)
+	result, err := o.chat(ctx, reqParams)
 	if err != nil {
 		return "", err
 	}
 
-	defer response.Body.Close()
-	if response.StatusCode != http.StatusOK {
-		errorBody, _ := io.ReadAll(response.Body)
-		var errResp Response
-		_ = sonic.Unmarshal(errorBody, &errResp)
-		return "", fmt.Errorf("API 请求错误: %d - %s", response.StatusCode, errResp.Error.Message)
+	return result.Choices[0].Message.Content, nil
+}
+
+// ChatWithTools 携带工具定义发起对话, 返回的消息可能包含工具调用
+func (o *AiConfig) ChatWithTools(ctx context.Context, messages []*Message, tools []Tool) (*Message, error) {
+	reqParams := Request{
+		Model:     o.Model,
+		Messages:  messages,
+		MaxTokens: o.MaxTokens,
+		Tools:     tools,
+	}
+
+	result, err := o.chat(ctx, reqParams)
+	if err != nil {
+		return nil, err
+	}
+
+	message := result.Choices[0].Message
+	if message.Role == "" {
+		message.Role = "assistant"
 	}
+	return &message, nil
+}
 
-	// 解析响应
+func (o *AiConfig) chat(ctx context.Context, reqParams Request) (Response, error) {
 	var result Response
-	err = tools.ParseReaderBody(response.Body, &result)
+	usage := o.usage()
+	defer func() {
+		usage.PromptTokens = result.Usage.PromptTokens
+		usage.CompletionTokens = result.Usage.CompletionTokens
+		reportUsage(ctx, usage)
+	}()
+
+	body, err := postJSON(ctx, o.endpoint(), o.headers(), reqParams, o.Timeout)
 	if err != nil {
-		return "", err
+		return result, err
+	}
+
+	// 解析响应
+	if err := sonic.Unmarshal(body, &result); err != nil {
+		return result, fmt.Errorf("解析响应失败: %s", err.Error())
 	}
 
 	// 检查有效响应
 	if len(result.Choices) == 0 {
-		return "", fmt.Errorf("无有效返回内容")
+		return result, fmt.Errorf("无有效返回内容")
 	}
 
-	return result.Choices[0].Message.Content, nil
+	usage.Success = true
+	return result, nil
 }
 
-func (o *AiConfig) StreamCompletion(ctx context.Context, prompt string) (<-chan string, error) {
+func (o *AiConfig) StreamCompletion(ctx context.Context, prompt string) (<-chan StreamEvent, error) {
 	reqParams := Request{
 		Model: o.Model,
 		Messages: []*Message{
+			{
+				Role:    "system",
+				Content: systemPromptOrDefault(o.SystemPrompt),
+			},
 			{Role: "user", Content: prompt},
 		},
-		Stream:    true,
-		MaxTokens: o.MaxTokens,
+		Stream:        true,
+		StreamOptions: &StreamOptions{IncludeUsage: true},
+		MaxTokens:     o.MaxTokens,
 	}
-	bodyBytes, _ := sonic.Marshal(reqParams)
-	headers := make(map[string]string)
-	headers["Authorization"] = "Bearer " + o.ApiKey
 
-	response, err := tools.Post(headers, o.Url, bytes.NewReader(bodyBytes), o.Timeout)
+	headers := o.headers()
+	headers["Accept"] = "text/event-stream"
+	response, err := openStream(ctx, o.endpoint(), headers, reqParams, o.Timeout)
 	if err != nil {
+		reportUsage(ctx, o.usage())
 		return nil, fmt.Errorf("流式请求失败: %w", err)
 	}
 
-	if response.StatusCode != http.StatusOK {
-		errorBody, _ := io.ReadAll(response.Body)
-		var errResp Response
-		_ = sonic.Unmarshal(errorBody, &errResp)
-		return nil, fmt.Errorf("OpenAI API错误: %d - %s", response.StatusCode, errResp.Error.Message)
-	}
-
 	// 创建流式通道
-	streamChan := make(chan string)
+	streamChan := make(chan StreamEvent)
 
 	go func() {
+		usage := o.usage()
+		defer func() { reportUsage(ctx, usage) }()
 		defer close(streamChan)
 		defer response.Body.Close()
-		select {
-		case <-ctx.Done():
-			return
-		default:
-			scanner := bufio.NewScanner(response.Body)
-			for scanner.Scan() {
-				line := scanner.Text()
-				if strings.HasPrefix(line, "data: ") {
-					content := strings.TrimPrefix(line, "data: ")
-					if strings.TrimSpace(content) == "[DONE]" {
-						continue
-					}
-
-					var chunk StreamChunk
-					if err := sonic.Unmarshal([]byte(content), &chunk); err != nil {
-						log.Printf("解析错误: %v | 内容: %s", err, content)
-						continue
-					}
-
-					// 拼接内容
-					if len(chunk.Choices) > 0 {
-						streamChan <- chunk.Choices[0].Delta.Content
-					}
-				}
+
+		scanner := bufio.NewScanner(response.Body)
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			line := scanner.Text()
+			if !strings.HasPrefix(line, "data:") {
+				continue
+			}
+
+			content := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+			if content == "[DONE]" {
+				usage.Success = true
+				sendStreamEvent(ctx, streamChan, StreamEvent{Done: true})
+				return
+			}
+
+			var chunk StreamChunk
+			if err := sonic.Unmarshal([]byte(content), &chunk); err != nil {
+				log.Printf("解析错误: %v | 内容: %s", err, content)
+				continue
+			}
+			if chunk.Usage != nil {
+				usage.PromptTokens = chunk.Usage.PromptTokens
+				usage.CompletionTokens = chunk.Usage.CompletionTokens
+			}
+
+			// 拼接内容
+			if len(chunk.Choices) == 0 || chunk.Choices[0].Delta.Content == "" {
+				continue
+			}
+			if !sendStreamEvent(ctx, streamChan, StreamEvent{Content: chunk.Choices[0].Delta.Content}) {
+				return
 			}
 		}
+		sendStreamEvent(ctx, streamChan, StreamEvent{Err: streamEndError(scanner.Err())})
 	}()
 	return streamChan, nil
 }
@@ -153,3 +256,31 @@ func (o *AiConfig) Check(_ context.Context) error {
 	}
 	return nil
 }
+
+// endpoint Azure OpenAI 的 Url 为资源地址, Model 为部署名称
+func (o *AiConfig) endpoint() string {
+	if o.Provider != models.AiProviderAzure || strings.Contains(o.Url, "/chat/completions") {
+		return o.Url
+	}
+
+	version := o.ApiVersion
+	if version == "" {
+		version = defaultAzureApiVersion
+	}
+	return fmt.Sprintf("%s/openai/deployments/%s/chat/completions?api-version=%s", strings.TrimRight(o.Url, "/"), o.Model, version)
+}
+
+func (o *AiConfig) headers() map[string]string {
+	if o.Provider == models.AiProviderAzure {
+		return map[string]string{"api-key": o.ApiKey}
+	}
+	return map[string]string{"Authorization": "Bearer " + o.ApiKey}
+}
+
+func (o *AiConfig) usage() Usage {
+	provider := o.Provider
+	if provider == "" {
+		provider = models.AiProviderOpenAI
+	}
+	return Usage{Profile: o.Name, Provider: provider, Model: o.Model}
+}
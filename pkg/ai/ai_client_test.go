@@ -2,6 +2,10 @@ package ai
 
 import (
 	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"watchAlert/internal/models"
 )
@@ -47,7 +51,73 @@ func TestOpenAiStreamCompletion(t *testing.T) {
 
 	var received []string
 	for part := range resp {
-		received = append(received, part)
+		received = append(received, part.Content)
 	}
 	t.Log("received:", received)
 }
+
+func TestStreamCompletionCancel(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, part := range []string{"磁盘", "已满"} {
+			fmt.Fprintf(w, "data: {\"choices\":[{\"delta\":{\"content\":%q}}]}\n\n", part)
+		}
+		w.(http.Flusher).Flush()
+
+		// 模拟仍在生成, 直到客户端断开
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := &AiConfig{Url: server.URL, ApiKey: "test", Model: "stand-in", Timeout: 5}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := client.StreamCompletion(ctx, "分析告警")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var received []string
+	for part := range stream {
+		if part.Done {
+			t.Fatalf("cancelled stream should not be done")
+		}
+		received = append(received, part.Content)
+		if len(received) == 2 {
+			cancel()
+		}
+	}
+	if strings.Join(received, "") != "磁盘已满" {
+		t.Fatalf("unexpected stream content: %v", received)
+	}
+}
+
+func TestStreamCompletionTruncated(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprintf(w, "data: {\"choices\":[{\"delta\":{\"content\":%q}}]}\n\n", "磁盘")
+	}))
+	defer server.Close()
+
+	client := &AiConfig{Url: server.URL, ApiKey: "test", Model: "stand-in", Timeout: 5}
+	stream, err := client.StreamCompletion(context.Background(), "分析告警")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var events []StreamEvent
+	for event := range stream {
+		events = append(events, event)
+	}
+	if len(events) != 2 || events[0].Content != "磁盘" {
+		t.Fatalf("unexpected events: %+v", events)
+	}
+	if last := events[len(events)-1]; last.Done || last.Err == nil {
+		t.Fatalf("stream without [DONE] should end with an error, got %+v", last)
+	}
+}
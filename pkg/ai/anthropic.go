@@ -0,0 +1,293 @@
+package ai
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"watchAlert/internal/models"
+
+	"github.com/bytedance/sonic"
+)
+
+const (
+	defaultAnthropicUrl       = "https://api.anthropic.com"
+	anthropicVersion          = "2023-06-01"
+	defaultAnthropicMaxTokens = 4096
+)
+
+type (
+	// AnthropicClient Anthropic Messages API 客户端
+	AnthropicClient struct {
+		Name         string
+		Url          string
+		ApiKey       string
+		Model        string
+		Timeout      int
+		MaxTokens    int
+		SystemPrompt string
+	}
+
+	anthropicRequest struct {
+		Model     string             `json:"model"`
+		System    string             `json:"system,omitempty"`
+		Messages  []anthropicMessage `json:"messages"`
+		MaxTokens int                `json:"max_tokens"`
+		Stream    bool               `json:"stream,omitempty"`
+		Tools     []anthropicTool    `json:"tools,omitempty"`
+	}
+
+	anthropicMessage struct {
+		Role    string                  `json:"role"` // user/assistant
+		Content []anthropicContentBlock `json:"content"`
+	}
+
+	anthropicContentBlock struct {
+		Type      string      `json:"type"` // text/tool_use/tool_result
+		Text      string      `json:"text,omitempty"`
+		Id        string      `json:"id,omitempty"`
+		Name      string      `json:"name,omitempty"`
+		Input     interface{} `json:"input,omitempty"`
+		ToolUseId string      `json:"tool_use_id,omitempty"`
+		Content   string      `json:"content,omitempty"`
+	}
+
+	anthropicTool struct {
+		Name        string                 `json:"name"`
+		Description string                 `json:"description"`
+		InputSchema map[string]interface{} `json:"input_schema"`
+	}
+
+	anthropicUsage struct {
+		InputTokens  int `json:"input_tokens"`
+		OutputTokens int `json:"output_tokens"`
+	}
+
+	anthropicResponse struct {
+		Content []anthropicContentBlock `json:"content"`
+		Usage   anthropicUsage          `json:"usage"`
+	}
+
+	// anthropicStreamEvent 流式事件, 文本增量在 content_block_delta, 用量在 message_start 及 message_delta
+	anthropicStreamEvent struct {
+		Type  string `json:"type"`
+		Delta struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"delta"`
+		Message struct {
+			Usage anthropicUsage `json:"usage"`
+		} `json:"message"`
+		Usage anthropicUsage `json:"usage"`
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+)
+
+func (a *AnthropicClient) ChatCompletion(ctx context.Context, prompt string) (string, error) {
+	message, err := a.ChatWithTools(ctx, []*Message{
+		{Role: "system", Content: systemPromptOrDefault(a.SystemPrompt)},
+		{Role: "user", Content: prompt},
+	}, nil)
+	if err != nil {
+		return "", err
+	}
+
+	return message.Content, nil
+}
+
+// ChatWithTools 将 OpenAI 格式的消息及工具转换为 Messages API 格式, 返回的 tool_use 转换为工具调用
+func (a *AnthropicClient) ChatWithTools(ctx context.Context, messages []*Message, tools []Tool) (*Message, error) {
+	var result anthropicResponse
+	usage := a.usage()
+	defer func() {
+		usage.PromptTokens = result.Usage.InputTokens
+		usage.CompletionTokens = result.Usage.OutputTokens
+		reportUsage(ctx, usage)
+	}()
+
+	body, err := postJSON(ctx, a.endpoint(), a.headers(), a.buildRequest(messages, tools, false), a.Timeout)
+	if err != nil {
+		return nil, err
+	}
+	if err := sonic.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("解析响应失败: %s", err.Error())
+	}
+	if len(result.Content) == 0 {
+		return nil, fmt.Errorf("无有效返回内容")
+	}
+
+	reply := &Message{Role: "assistant"}
+	for _, block := range result.Content {
+		switch block.Type {
+		case "text":
+			reply.Content += block.Text
+		case "tool_use":
+			call := ToolCall{ID: block.Id, Type: "function"}
+			call.Function.Name = block.Name
+			call.Function.Arguments, _ = sonic.MarshalString(block.Input)
+			reply.ToolCalls = append(reply.ToolCalls, call)
+		}
+	}
+
+	usage.Success = true
+	return reply, nil
+}
+
+func (a *AnthropicClient) StreamCompletion(ctx context.Context, prompt string) (<-chan StreamEvent, error) {
+	reqParams := a.buildRequest([]*Message{
+		{Role: "system", Content: systemPromptOrDefault(a.SystemPrompt)},
+		{Role: "user", Content: prompt},
+	}, nil, true)
+
+	headers := a.headers()
+	headers["Accept"] = "text/event-stream"
+	response, err := openStream(ctx, a.endpoint(), headers, reqParams, a.Timeout)
+	if err != nil {
+		reportUsage(ctx, a.usage())
+		return nil, fmt.Errorf("流式请求失败: %w", err)
+	}
+
+	streamChan := make(chan StreamEvent)
+	go func() {
+		usage := a.usage()
+		defer func() { reportUsage(ctx, usage) }()
+		defer close(streamChan)
+		defer response.Body.Close()
+
+		scanner := bufio.NewScanner(response.Body)
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			line := scanner.Text()
+			if !strings.HasPrefix(line, "data:") {
+				continue
+			}
+
+			var event anthropicStreamEvent
+			content := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+			if err := sonic.Unmarshal([]byte(content), &event); err != nil {
+				log.Printf("解析错误: %v | 内容: %s", err, content)
+				continue
+			}
+
+			switch event.Type {
+			case "message_start":
+				usage.PromptTokens = event.Message.Usage.InputTokens
+			case "message_delta":
+				usage.CompletionTokens = event.Usage.OutputTokens
+			case "message_stop":
+				usage.Success = true
+				sendStreamEvent(ctx, streamChan, StreamEvent{Done: true})
+				return
+			case "error":
+				log.Printf("Anthropic 流式响应错误: %s", event.Error.Message)
+				sendStreamEvent(ctx, streamChan, StreamEvent{Err: fmt.Errorf("流式响应错误: %s", event.Error.Message)})
+				return
+			case "content_block_delta":
+				if event.Delta.Type != "text_delta" || event.Delta.Text == "" {
+					continue
+				}
+				if !sendStreamEvent(ctx, streamChan, StreamEvent{Content: event.Delta.Text}) {
+					return
+				}
+			}
+		}
+		sendStreamEvent(ctx, streamChan, StreamEvent{Err: streamEndError(scanner.Err())})
+	}()
+	return streamChan, nil
+}
+
+func (a *AnthropicClient) Check(_ context.Context) error {
+	if a.ApiKey == "" || a.Model == "" {
+		return fmt.Errorf("Anthropic API配置错误")
+	}
+
+	if a.Timeout == 0 {
+		return fmt.Errorf("Anthropic API超时时间未设置")
+	}
+	return nil
+}
+
+// buildRequest system 消息合并到顶层 system 字段, 连续的工具结果合并为同一条 user 消息
+func (a *AnthropicClient) buildRequest(messages []*Message, tools []Tool, stream bool) anthropicRequest {
+	req := anthropicRequest{
+		Model:     a.Model,
+		MaxTokens: a.MaxTokens,
+		Stream:    stream,
+	}
+	if req.MaxTokens <= 0 {
+		req.MaxTokens = defaultAnthropicMaxTokens
+	}
+
+	var system []string
+	for _, m := range messages {
+		var (
+			role  = m.Role
+			block []anthropicContentBlock
+		)
+		switch m.Role {
+		case "system":
+			system = append(system, m.Content)
+			continue
+		case "tool":
+			role = "user"
+			block = append(block, anthropicContentBlock{Type: "tool_result", ToolUseId: m.ToolCallId, Content: m.Content})
+		case "assistant":
+			if m.Content != "" {
+				block = append(block, anthropicContentBlock{Type: "text", Text: m.Content})
+			}
+			for _, call := range m.ToolCalls {
+				input := map[string]interface{}{}
+				_ = sonic.UnmarshalString(call.Function.Arguments, &input)
+				block = append(block, anthropicContentBlock{Type: "tool_use", Id: call.ID, Name: call.Function.Name, Input: input})
+			}
+		default:
+			block = append(block, anthropicContentBlock{Type: "text", Text: m.Content})
+		}
+		if len(block) == 0 {
+			continue
+		}
+
+		if n := len(req.Messages); n > 0 && req.Messages[n-1].Role == role {
+			req.Messages[n-1].Content = append(req.Messages[n-1].Content, block...)
+			continue
+		}
+		req.Messages = append(req.Messages, anthropicMessage{Role: role, Content: block})
+	}
+	req.System = strings.Join(system, "\n\n")
+
+	for _, tool := range tools {
+		req.Tools = append(req.Tools, anthropicTool{
+			Name:        tool.Function.Name,
+			Description: tool.Function.Description,
+			InputSchema: tool.Function.Parameters,
+		})
+	}
+
+	return req
+}
+
+// endpoint Url 可配置为服务地址或完整的 /v1/messages 地址
+func (a *AnthropicClient) endpoint() string {
+	url := strings.TrimRight(a.Url, "/")
+	if url == "" {
+		url = defaultAnthropicUrl
+	}
+	if strings.HasSuffix(url, "/v1/messages") {
+		return url
+	}
+	return url + "/v1/messages"
+}
+
+func (a *AnthropicClient) headers() map[string]string {
+	return map[string]string{
+		"x-api-key":         a.ApiKey,
+		"anthropic-version": anthropicVersion,
+	}
+}
+
+func (a *AnthropicClient) usage() Usage {
+	return Usage{Profile: a.Name, Provider: models.AiProviderAnthropic, Model: a.Model}
+}
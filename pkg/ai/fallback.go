@@ -0,0 +1,99 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// FallbackClient 按主备顺序调用多个模型服务, 前一个调用失败时降级到下一个
+type FallbackClient struct {
+	order   []string
+	clients map[string]providerClient
+	// 租户使用的模型服务及顺序, 未配置的租户使用全部模型服务
+	tenantProfiles map[string][]string
+}
+
+func (f *FallbackClient) ChatCompletion(ctx context.Context, prompt string) (string, error) {
+	var content string
+	err := f.try(ctx, func(c providerClient) error {
+		var err error
+		content, err = c.ChatCompletion(ctx, prompt)
+		return err
+	})
+	return content, err
+}
+
+func (f *FallbackClient) ChatWithTools(ctx context.Context, messages []*Message, tools []Tool) (*Message, error) {
+	var reply *Message
+	err := f.try(ctx, func(c providerClient) error {
+		var err error
+		reply, err = c.ChatWithTools(ctx, messages, tools)
+		return err
+	})
+	return reply, err
+}
+
+// StreamCompletion 仅在建立流式连接失败时降级, 开始输出后不再切换
+func (f *FallbackClient) StreamCompletion(ctx context.Context, prompt string) (<-chan StreamEvent, error) {
+	var stream <-chan StreamEvent
+	err := f.try(ctx, func(c providerClient) error {
+		var err error
+		stream, err = c.StreamCompletion(ctx, prompt)
+		return err
+	})
+	return stream, err
+}
+
+// Check 租户可用的模型服务中至少一个配置正确
+func (f *FallbackClient) Check(ctx context.Context) error {
+	return f.try(ctx, func(c providerClient) error {
+		return c.Check(ctx)
+	})
+}
+
+// Profiles 获取租户按顺序使用的模型服务名称
+func (f *FallbackClient) Profiles(tenantId string) []string {
+	names, ok := f.tenantProfiles[tenantId]
+	if !ok || len(names) == 0 {
+		return f.order
+	}
+
+	var profiles []string
+	for _, name := range names {
+		if _, exist := f.clients[name]; exist {
+			profiles = append(profiles, name)
+		}
+	}
+	return profiles
+}
+
+func (f *FallbackClient) try(ctx context.Context, call func(c providerClient) error) error {
+	profiles := f.Profiles(TenantFromContext(ctx))
+	if len(profiles) == 0 {
+		return fmt.Errorf("租户未配置可用的模型服务")
+	}
+
+	var errs []string
+	for i, name := range profiles {
+		err := call(f.clients[name])
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, fmt.Sprintf("%s: %s", name, err.Error()))
+
+		// 调用方已取消时不再降级
+		if ctx.Err() != nil {
+			break
+		}
+		if i < len(profiles)-1 {
+			log.Printf("模型服务 %s 调用失败, 降级到 %s, err: %s", name, profiles[i+1], err.Error())
+		}
+	}
+
+	if len(errs) == 1 {
+		return fmt.Errorf("%s", errs[0])
+	}
+	return fmt.Errorf("所有模型服务均调用失败, %s", strings.Join(errs, "; "))
+}
@@ -0,0 +1,179 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"watchAlert/internal/models"
+)
+
+func collectUsage(t *testing.T) *[]Usage {
+	var (
+		mu     sync.Mutex
+		usages []Usage
+	)
+	RegisterUsageHook(func(_ context.Context, usage Usage) {
+		mu.Lock()
+		defer mu.Unlock()
+		usages = append(usages, usage)
+	})
+	t.Cleanup(func() { RegisterUsageHook(nil) })
+	return &usages
+}
+
+func TestFallbackClientDegrade(t *testing.T) {
+	usages := collectUsage(t)
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte(`{"error":{"message":"rate limited"}}`))
+	}))
+	defer broken.Close()
+
+	anthropic := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/messages" || r.Header.Get("x-api-key") != "sk-ant" {
+			t.Errorf("unexpected request: %s %v", r.URL.Path, r.Header)
+		}
+		var req anthropicRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if req.System != "你是值班助手" || len(req.Messages) != 1 || req.Messages[0].Role != "user" {
+			t.Errorf("unexpected request body: %+v", req)
+		}
+		_, _ = w.Write([]byte(`{"content":[{"type":"text","text":"磁盘已满"}],"usage":{"input_tokens":12,"output_tokens":5}}`))
+	}))
+	defer anthropic.Close()
+
+	client, err := NewAiClient(&models.AiConfig{
+		SystemPrompt: "你是值班助手",
+		Profiles: []models.AiProfile{
+			{Name: "primary", Url: broken.URL, AppKey: "sk", Model: "gpt-4o", Timeout: 5},
+			{Name: "backup", Provider: models.AiProviderAnthropic, Url: anthropic.URL, AppKey: "sk-ant", Model: "claude", Timeout: 5},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := client.ChatCompletion(WithTenant(context.Background(), "tid-1"), "分析告警")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if content != "磁盘已满" {
+		t.Fatalf("unexpected content: %s", content)
+	}
+
+	if len(*usages) != 2 {
+		t.Fatalf("expected 2 usage records, got %+v", *usages)
+	}
+	failed, ok := (*usages)[0], (*usages)[1]
+	if failed.Profile != "primary" || failed.Success {
+		t.Fatalf("unexpected failed usage: %+v", failed)
+	}
+	if ok.Profile != "backup" || ok.TenantId != "tid-1" || !ok.Success || ok.PromptTokens != 12 || ok.CompletionTokens != 5 {
+		t.Fatalf("unexpected usage: %+v", ok)
+	}
+}
+
+func TestFallbackClientTenantProfiles(t *testing.T) {
+	var hits []string
+	newServer := func(name string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			hits = append(hits, name)
+			fmt.Fprintf(w, `{"choices":[{"message":{"role":"assistant","content":%q}}]}`, name)
+		}))
+	}
+	a, b := newServer("a"), newServer("b")
+	defer a.Close()
+	defer b.Close()
+
+	client, err := NewAiClient(&models.AiConfig{
+		Profiles: []models.AiProfile{
+			{Name: "a", Url: a.URL, AppKey: "sk", Model: "m", Timeout: 5},
+			{Name: "b", Url: b.URL, AppKey: "sk", Model: "m", Timeout: 5},
+		},
+		TenantProfiles: map[string][]string{"tid-2": {"b"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tenant := range []string{"tid-1", "tid-2"} {
+		if _, err := client.ChatCompletion(WithTenant(context.Background(), tenant), "hi"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if strings.Join(hits, ",") != "a,b" {
+		t.Fatalf("unexpected profile selection: %v", hits)
+	}
+}
+
+func TestAnthropicToolCalling(t *testing.T) {
+	var req anthropicRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		_, _ = w.Write([]byte(`{"content":[{"type":"tool_use","id":"toolu_1","name":"query_metrics_range","input":{"promQL":"up == 0"}}],"usage":{"input_tokens":1,"output_tokens":1}}`))
+	}))
+	defer server.Close()
+
+	client := &AnthropicClient{Url: server.URL, ApiKey: "sk", Model: "claude", Timeout: 5}
+	call := ToolCall{ID: "toolu_0", Type: "function"}
+	call.Function.Name = "query_metrics_range"
+	call.Function.Arguments = `{"promQL":"up"}`
+	reply, err := client.ChatWithTools(context.Background(), []*Message{
+		{Role: "system", Content: "rca"},
+		{Role: "user", Content: "分析"},
+		{Role: "assistant", ToolCalls: []ToolCall{call}},
+		{Role: "tool", ToolCallId: "toolu_0", Content: "value=1"},
+	}, []Tool{{Type: "function", Function: ToolFunction{Name: "query_metrics_range", Parameters: map[string]interface{}{"type": "object"}}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if req.System != "rca" || len(req.Messages) != 3 || req.Messages[2].Content[0].Type != "tool_result" || len(req.Tools) != 1 {
+		t.Fatalf("unexpected request: %+v", req)
+	}
+	if len(reply.ToolCalls) != 1 || reply.ToolCalls[0].ID != "toolu_1" || reply.ToolCalls[0].Function.Arguments != `{"promQL":"up == 0"}` {
+		t.Fatalf("unexpected reply: %+v", reply)
+	}
+}
+
+func TestOllamaStreamCompletion(t *testing.T) {
+	usages := collectUsage(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/chat" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		fmt.Fprintln(w, `{"message":{"role":"assistant","content":"磁盘"},"done":false}`)
+		fmt.Fprintln(w, `{"message":{"role":"assistant","content":"已满"},"done":false}`)
+		fmt.Fprintln(w, `{"message":{"role":"assistant","content":""},"done":true,"prompt_eval_count":20,"eval_count":4}`)
+	}))
+	defer server.Close()
+
+	client := &OllamaClient{Name: "local", Url: server.URL, Model: "qwen2.5", Timeout: 5}
+	stream, err := client.StreamCompletion(context.Background(), "分析告警")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		received []string
+		done     bool
+	)
+	for part := range stream {
+		received = append(received, part.Content)
+		done = part.Done
+	}
+	if !done {
+		t.Fatalf("stream should end with done")
+	}
+	if strings.Join(received, "") != "磁盘已满" {
+		t.Fatalf("unexpected stream content: %v", received)
+	}
+	if len(*usages) != 1 || (*usages)[0].PromptTokens != 20 || (*usages)[0].CompletionTokens != 4 || !(*usages)[0].Success {
+		t.Fatalf("unexpected usage: %+v", *usages)
+	}
+}
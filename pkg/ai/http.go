@@ -0,0 +1,119 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/bytedance/sonic"
+)
+
+// 错误响应中保留的最大长度
+const errorBodyLimit = 512
+
+// postJSON 发起 JSON 请求并返回响应体, 非 200 响应时返回服务端的错误信息
+func postJSON(ctx context.Context, url string, headers map[string]string, body interface{}, timeout int) ([]byte, error) {
+	client := http.Client{
+		Timeout:   time.Duration(timeout) * time.Second,
+		Transport: newTransport(0),
+	}
+
+	response, err := doRequest(ctx, client, url, headers, body)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	return io.ReadAll(response.Body)
+}
+
+// openStream 发起流式请求, 流式响应耗时与生成长度相关, 超时仅约束等待响应头的时间
+func openStream(ctx context.Context, url string, headers map[string]string, body interface{}, timeout int) (*http.Response, error) {
+	client := http.Client{
+		Transport: newTransport(time.Duration(timeout) * time.Second),
+	}
+
+	return doRequest(ctx, client, url, headers, body)
+}
+
+func doRequest(ctx context.Context, client http.Client, url string, headers map[string]string, body interface{}) (*http.Response, error) {
+	bodyBytes, err := sonic.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	// 请求绑定 ctx, 调用方取消时(如客户端断开)立即中断上游连接
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(bodyBytes))
+	if err != nil {
+		return nil, fmt.Errorf("请求建立失败: %w", err)
+	}
+	request.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		request.Header.Set(k, v)
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("请求发送失败: %w", err)
+	}
+
+	if response.StatusCode != http.StatusOK {
+		defer response.Body.Close()
+		errorBody, _ := io.ReadAll(response.Body)
+		return nil, fmt.Errorf("API 请求错误: %d - %s", response.StatusCode, parseErrorMessage(errorBody))
+	}
+
+	return response, nil
+}
+
+func newTransport(responseHeaderTimeout time.Duration) *http.Transport {
+	return &http.Transport{
+		TLSClientConfig:       &tls.Config{InsecureSkipVerify: true},
+		Proxy:                 http.ProxyFromEnvironment,
+		ResponseHeaderTimeout: responseHeaderTimeout,
+	}
+}
+
+// parseErrorMessage 兼容各服务的错误格式: {"error":{"message":""}} 及 {"error":""}
+func parseErrorMessage(body []byte) string {
+	var errResp struct {
+		Error interface{} `json:"error"`
+	}
+	if err := sonic.Unmarshal(body, &errResp); err == nil {
+		switch e := errResp.Error.(type) {
+		case string:
+			return e
+		case map[string]interface{}:
+			if message, ok := e["message"].(string); ok {
+				return message
+			}
+		}
+	}
+
+	if len(body) > errorBodyLimit {
+		body = body[:errorBodyLimit]
+	}
+	return string(body)
+}
+
+// sendStreamEvent 写入流式事件, 调用方已取消时返回 false
+func sendStreamEvent(ctx context.Context, ch chan<- StreamEvent, event StreamEvent) bool {
+	select {
+	case ch <- event:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// streamEndError 流在收到结束标记前中断时返回的错误
+func streamEndError(err error) error {
+	if err != nil {
+		return fmt.Errorf("流式响应中断: %w", err)
+	}
+	return fmt.Errorf("流式响应未正常结束")
+}
@@ -0,0 +1,209 @@
+package ai
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"watchAlert/internal/models"
+
+	"github.com/bytedance/sonic"
+)
+
+const defaultOllamaUrl = "http://127.0.0.1:11434"
+
+type (
+	// OllamaClient Ollama 本地模型客户端, 使用 /api/chat 接口
+	OllamaClient struct {
+		Name         string
+		Url          string
+		Model        string
+		Timeout      int
+		MaxTokens    int
+		SystemPrompt string
+	}
+
+	ollamaRequest struct {
+		Model    string          `json:"model"`
+		Messages []ollamaMessage `json:"messages"`
+		Stream   bool            `json:"stream"`
+		Tools    []Tool          `json:"tools,omitempty"`
+		Options  map[string]int  `json:"options,omitempty"`
+	}
+
+	ollamaMessage struct {
+		Role      string           `json:"role"`
+		Content   string           `json:"content"`
+		ToolCalls []ollamaToolCall `json:"tool_calls,omitempty"`
+	}
+
+	ollamaToolCall struct {
+		Function struct {
+			Name      string                 `json:"name"`
+			Arguments map[string]interface{} `json:"arguments"`
+		} `json:"function"`
+	}
+
+	// ollamaResponse 非流式响应及流式响应的每一行, done 为 true 时携带用量
+	ollamaResponse struct {
+		Message         ollamaMessage `json:"message"`
+		Done            bool          `json:"done"`
+		PromptEvalCount int           `json:"prompt_eval_count"`
+		EvalCount       int           `json:"eval_count"`
+		Error           string        `json:"error"`
+	}
+)
+
+func (o *OllamaClient) ChatCompletion(ctx context.Context, prompt string) (string, error) {
+	message, err := o.ChatWithTools(ctx, []*Message{
+		{Role: "system", Content: systemPromptOrDefault(o.SystemPrompt)},
+		{Role: "user", Content: prompt},
+	}, nil)
+	if err != nil {
+		return "", err
+	}
+
+	return message.Content, nil
+}
+
+// ChatWithTools Ollama 的工具调用参数为 JSON 对象且不返回调用 ID, 按序号生成 ID
+func (o *OllamaClient) ChatWithTools(ctx context.Context, messages []*Message, tools []Tool) (*Message, error) {
+	var result ollamaResponse
+	usage := o.usage()
+	defer func() {
+		usage.PromptTokens = result.PromptEvalCount
+		usage.CompletionTokens = result.EvalCount
+		reportUsage(ctx, usage)
+	}()
+
+	body, err := postJSON(ctx, o.endpoint(), nil, o.buildRequest(messages, tools, false), o.Timeout)
+	if err != nil {
+		return nil, err
+	}
+	if err := sonic.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("解析响应失败: %s", err.Error())
+	}
+	if result.Error != "" {
+		return nil, fmt.Errorf("API 请求错误: %s", result.Error)
+	}
+
+	reply := &Message{Role: "assistant", Content: result.Message.Content}
+	for i, c := range result.Message.ToolCalls {
+		call := ToolCall{ID: fmt.Sprintf("call_%d", i+1), Type: "function"}
+		call.Function.Name = c.Function.Name
+		call.Function.Arguments, _ = sonic.MarshalString(c.Function.Arguments)
+		reply.ToolCalls = append(reply.ToolCalls, call)
+	}
+
+	usage.Success = true
+	return reply, nil
+}
+
+func (o *OllamaClient) StreamCompletion(ctx context.Context, prompt string) (<-chan StreamEvent, error) {
+	reqParams := o.buildRequest([]*Message{
+		{Role: "system", Content: systemPromptOrDefault(o.SystemPrompt)},
+		{Role: "user", Content: prompt},
+	}, nil, true)
+
+	response, err := openStream(ctx, o.endpoint(), nil, reqParams, o.Timeout)
+	if err != nil {
+		reportUsage(ctx, o.usage())
+		return nil, fmt.Errorf("流式请求失败: %w", err)
+	}
+
+	// 流式响应为每行一个 JSON 对象
+	streamChan := make(chan StreamEvent)
+	go func() {
+		usage := o.usage()
+		defer func() { reportUsage(ctx, usage) }()
+		defer close(streamChan)
+		defer response.Body.Close()
+
+		scanner := bufio.NewScanner(response.Body)
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" {
+				continue
+			}
+
+			var chunk ollamaResponse
+			if err := sonic.Unmarshal([]byte(line), &chunk); err != nil {
+				log.Printf("解析错误: %v | 内容: %s", err, line)
+				continue
+			}
+			if chunk.Error != "" {
+				log.Printf("Ollama 流式响应错误: %s", chunk.Error)
+				sendStreamEvent(ctx, streamChan, StreamEvent{Err: fmt.Errorf("流式响应错误: %s", chunk.Error)})
+				return
+			}
+
+			if chunk.Message.Content != "" {
+				if !sendStreamEvent(ctx, streamChan, StreamEvent{Content: chunk.Message.Content}) {
+					return
+				}
+			}
+			if chunk.Done {
+				usage.PromptTokens = chunk.PromptEvalCount
+				usage.CompletionTokens = chunk.EvalCount
+				usage.Success = true
+				sendStreamEvent(ctx, streamChan, StreamEvent{Done: true})
+				return
+			}
+		}
+		sendStreamEvent(ctx, streamChan, StreamEvent{Err: streamEndError(scanner.Err())})
+	}()
+	return streamChan, nil
+}
+
+func (o *OllamaClient) Check(_ context.Context) error {
+	if o.Model == "" {
+		return fmt.Errorf("Ollama 模型未设置")
+	}
+
+	if o.Timeout == 0 {
+		return fmt.Errorf("Ollama 超时时间未设置")
+	}
+	return nil
+}
+
+func (o *OllamaClient) buildRequest(messages []*Message, tools []Tool, stream bool) ollamaRequest {
+	req := ollamaRequest{
+		Model:  o.Model,
+		Stream: stream,
+		Tools:  tools,
+	}
+	if o.MaxTokens > 0 {
+		req.Options = map[string]int{"num_predict": o.MaxTokens}
+	}
+
+	for _, m := range messages {
+		message := ollamaMessage{Role: m.Role, Content: m.Content}
+		for _, call := range m.ToolCalls {
+			var c ollamaToolCall
+			c.Function.Name = call.Function.Name
+			_ = sonic.UnmarshalString(call.Function.Arguments, &c.Function.Arguments)
+			message.ToolCalls = append(message.ToolCalls, c)
+		}
+		req.Messages = append(req.Messages, message)
+	}
+
+	return req
+}
+
+// endpoint Url 可配置为服务地址或完整的 /api/chat 地址
+func (o *OllamaClient) endpoint() string {
+	url := strings.TrimRight(o.Url, "/")
+	if url == "" {
+		url = defaultOllamaUrl
+	}
+	if strings.HasSuffix(url, "/api/chat") {
+		return url
+	}
+	return url + "/api/chat"
+}
+
+func (o *OllamaClient) usage() Usage {
+	return Usage{Profile: o.Name, Provider: models.AiProviderOllama, Model: o.Model}
+}
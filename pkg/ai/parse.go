@@ -0,0 +1,17 @@
+package ai
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ExtractJSON 从模型回复中提取 JSON 对象, 兼容 Markdown 代码块及前后的说明文字
+func ExtractJSON(content string) (string, error) {
+	start := strings.Index(content, "{")
+	end := strings.LastIndex(content, "}")
+	if start < 0 || end <= start {
+		return "", fmt.Errorf("模型回复中未找到 JSON 内容")
+	}
+
+	return content[start : end+1], nil
+}
@@ -0,0 +1,18 @@
+package ai
+
+import "testing"
+
+func TestExtractJSON(t *testing.T) {
+	content := "以下是规则草稿:\n```json\n{\"ruleName\": \"CPU 使用率过高\", \"rules\": [{\"expr\": \"> 80\"}]}\n```\n请确认。"
+	got, err := ExtractJSON(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != `{"ruleName": "CPU 使用率过高", "rules": [{"expr": "> 80"}]}` {
+		t.Fatalf("unexpected json: %s", got)
+	}
+
+	if _, err := ExtractJSON("无法生成"); err == nil {
+		t.Fatal("expected error for content without json")
+	}
+}
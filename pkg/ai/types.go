@@ -10,32 +10,82 @@ type (
 		// ChatCompletion returns the completion of the given input text.
 		ChatCompletion(context.Context, string) (string, error)
 		// StreamCompletion returns a channel that streams the completion of the given input text.
-		StreamCompletion(context.Context, string) (<-chan string, error)
+		// The last event on the channel is either Done or Err; a channel closed without them was interrupted.
+		StreamCompletion(context.Context, string) (<-chan StreamEvent, error)
 		// Check checks the health of the AI chatbot client.
 		Check(context.Context) error
 	}
 
+	// AiConfig OpenAI 兼容接口客户端, Provider 为 azure 时按 Azure OpenAI 的地址及鉴权方式请求
 	AiConfig struct {
-		Url       string
-		ApiKey    string
-		Model     string
-		Timeout   int
-		Stream    bool
-		MaxTokens int
+		Name         string
+		Provider     string
+		Url          string
+		ApiKey       string
+		Model        string
+		ApiVersion   string
+		Timeout      int
+		Stream       bool
+		MaxTokens    int
+		SystemPrompt string
 	}
 
 	Request struct {
-		Model       string     `json:"model"`
-		Messages    []*Message `json:"messages"`
-		Stream      bool       `json:"stream,omitempty"`
-		MaxTokens   int        `json:"max_tokens,omitempty"`
-		Temperature float64    `json:"temperature,omitempty"`
+		Model         string         `json:"model"`
+		Messages      []*Message     `json:"messages"`
+		Stream        bool           `json:"stream,omitempty"`
+		StreamOptions *StreamOptions `json:"stream_options,omitempty"`
+		MaxTokens     int            `json:"max_tokens,omitempty"`
+		Temperature   float64        `json:"temperature,omitempty"`
+		Tools         []Tool         `json:"tools,omitempty"`
+	}
+
+	// StreamOptions 流式请求选项, IncludeUsage 为 true 时最后一个数据块返回 Token 用量
+	StreamOptions struct {
+		IncludeUsage bool `json:"include_usage"`
+	}
+
+	// TokenUsage OpenAI 兼容接口返回的 Token 用量
+	TokenUsage struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
 	}
 
 	// Message is a message
 	Message struct {
-		Role    string `json:"role"` // system/user/assistant
-		Content string `json:"content"`
+		Role       string     `json:"role"` // system/user/assistant/tool
+		Content    string     `json:"content"`
+		ToolCalls  []ToolCall `json:"tool_calls,omitempty"`   // assistant 发起的工具调用
+		ToolCallId string     `json:"tool_call_id,omitempty"` // tool 消息对应的调用 ID
+	}
+
+	// Tool 可供模型调用的工具定义
+	Tool struct {
+		Type     string       `json:"type"` // function
+		Function ToolFunction `json:"function"`
+	}
+
+	ToolFunction struct {
+		Name        string                 `json:"name"`
+		Description string                 `json:"description"`
+		Parameters  map[string]interface{} `json:"parameters"` // JSON Schema
+	}
+
+	// ToolCall 模型返回的工具调用
+	ToolCall struct {
+		ID       string `json:"id"`
+		Type     string `json:"type"`
+		Function struct {
+			Name      string `json:"name"`
+			Arguments string `json:"arguments"`
+		} `json:"function"`
+	}
+
+	// StreamEvent 流式输出的事件, 正常结束时最后一个事件 Done 为 true, 异常结束时 Err 不为空
+	StreamEvent struct {
+		Content string
+		Done    bool
+		Err     error
 	}
 
 	// StreamChunk 流式响应结构
@@ -45,16 +95,16 @@ type (
 				Content string `json:"content"`
 			} `json:"delta"`
 		} `json:"choices"`
+		Usage *TokenUsage `json:"usage"`
 	}
 
 	// Response 响应结构
 	Response struct {
 		ID      string `json:"id"`
 		Choices []struct {
-			Message struct {
-				Content string `json:"content"`
-			} `json:"message"`
+			Message Message `json:"message"`
 		} `json:"choices"`
+		Usage TokenUsage `json:"usage"`
 		Error struct {
 			Message string `json:"message"`
 		} `json:"error"`
@@ -0,0 +1,64 @@
+package ai
+
+import (
+	"context"
+	"sync"
+)
+
+// DefaultSystemPrompt 未配置系统提示词时使用的默认角色
+const DefaultSystemPrompt = "您是站点可靠性工程 (SRE) 可观测性监控专家、资深 DevOps 工程师、资深运维专家"
+
+// Usage 一次模型调用的 Token 用量
+type Usage struct {
+	TenantId         string
+	Profile          string
+	Provider         string
+	Model            string
+	PromptTokens     int
+	CompletionTokens int
+	Success          bool
+}
+
+type tenantCtxKey struct{}
+
+var (
+	usageHookMu sync.RWMutex
+	usageHook   func(context.Context, Usage)
+)
+
+// WithTenant 在 ctx 中携带租户 ID, 用于选择租户的模型服务及统计用量
+func WithTenant(ctx context.Context, tenantId string) context.Context {
+	return context.WithValue(ctx, tenantCtxKey{}, tenantId)
+}
+
+// TenantFromContext 获取 ctx 中携带的租户 ID
+func TenantFromContext(ctx context.Context) string {
+	tenantId, _ := ctx.Value(tenantCtxKey{}).(string)
+	return tenantId
+}
+
+// RegisterUsageHook 注册用量回调, 每次模型调用结束后触发
+func RegisterUsageHook(hook func(context.Context, Usage)) {
+	usageHookMu.Lock()
+	defer usageHookMu.Unlock()
+	usageHook = hook
+}
+
+func reportUsage(ctx context.Context, usage Usage) {
+	usageHookMu.RLock()
+	hook := usageHook
+	usageHookMu.RUnlock()
+	if hook == nil {
+		return
+	}
+
+	usage.TenantId = TenantFromContext(ctx)
+	hook(ctx, usage)
+}
+
+func systemPromptOrDefault(prompt string) string {
+	if prompt == "" {
+		return DefaultSystemPrompt
+	}
+	return prompt
+}
@@ -0,0 +1,43 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+	"watchAlert/internal/models"
+)
+
+// ErrNotFound 对象不存在
+var ErrNotFound = errors.New("对象不存在")
+
+var keyRe = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*(/[A-Za-z0-9][A-Za-z0-9._-]*)*$`)
+
+// Store 存放通知附带图片等文件的对象存储
+type Store interface {
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	Get(key string) ([]byte, error)
+	// Cleanup 删除早于指定时间的对象, 不支持时忽略
+	Cleanup(before time.Time) (int, error)
+}
+
+// NewStore 按趋势图配置创建存储
+func NewStore(config models.GraphConfig) (Store, error) {
+	switch config.GetStorage() {
+	case models.GraphStorageLocal:
+		return LocalStore{Dir: config.GetLocalPath()}, nil
+	case models.GraphStorageS3:
+		if config.S3.Endpoint == "" || config.S3.Bucket == "" {
+			return nil, errors.New("对象存储的 Endpoint 及 Bucket 不能为空")
+		}
+		return S3Store{Config: config.S3}, nil
+	default:
+		return nil, fmt.Errorf("未知的存储方式: %s", config.Storage)
+	}
+}
+
+// ValidKey 对象 Key 仅允许字母、数字及 . _ - /, 防止路径穿越
+func ValidKey(key string) bool {
+	return len(key) <= 256 && keyRe.MatchString(key)
+}
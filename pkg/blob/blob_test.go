@@ -0,0 +1,78 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidKey(t *testing.T) {
+	cases := []struct {
+		key   string
+		valid bool
+	}{
+		{"20240101/0a1b2c.png", true},
+		{"graph.png", true},
+		{"../etc/passwd", false},
+		{"20240101/../../secret.png", false},
+		{"/etc/passwd", false},
+		{`20240101\0a1b2c.png`, false},
+		{`..\secret.png`, false},
+		{".tmp", false},
+		{"20240101/.hidden.png", false},
+		{"20240101//0a1b2c.png", false},
+		{"", false},
+		{strings.Repeat("a", 257), false},
+	}
+	for _, c := range cases {
+		if got := ValidKey(c.key); got != c.valid {
+			t.Errorf("ValidKey(%q) = %v, want %v", c.key, got, c.valid)
+		}
+	}
+}
+
+func TestLocalStore(t *testing.T) {
+	store := LocalStore{Dir: t.TempDir()}
+	key := "20240101/0a1b2c.png"
+	data := []byte("\x89PNG")
+
+	if err := store.Put(context.Background(), key, data, "image/png"); err != nil {
+		t.Fatal(err)
+	}
+	got, err := store.Get(key)
+	if err != nil || string(got) != string(data) {
+		t.Fatalf("unexpected object: %q, %v", got, err)
+	}
+
+	// 写入完成后不应残留临时文件, 且临时文件不能通过 Key 读取
+	if _, err := os.Stat(filepath.Join(store.Dir, "20240101", "0a1b2c.png.tmp")); !os.IsNotExist(err) {
+		t.Fatalf("partial file should be renamed, got %v", err)
+	}
+	if _, err := store.Get("../" + filepath.Base(store.Dir) + "/" + key); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("path traversal should be rejected, got %v", err)
+	}
+	if _, err := store.Get("20240101/missing.png"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	if err := store.Put(context.Background(), "../escape.png", data, "image/png"); err == nil {
+		t.Fatalf("invalid key should be rejected")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := store.Put(ctx, "20240101/canceled.png", data, "image/png"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("canceled put should fail, got %v", err)
+	}
+
+	count, err := store.Cleanup(time.Now().Add(time.Minute))
+	if err != nil || count != 1 {
+		t.Fatalf("expected 1 object cleaned, got %d, %v", count, err)
+	}
+	if _, err := store.Get(key); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("object should be cleaned, got %v", err)
+	}
+}
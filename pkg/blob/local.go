@@ -0,0 +1,76 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// LocalStore 本地目录存储
+type LocalStore struct {
+	Dir string
+}
+
+func (l LocalStore) Put(ctx context.Context, key string, data []byte, _ string) error {
+	if !ValidKey(key) {
+		return fmt.Errorf("非法的对象 Key: %s", key)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	path := filepath.Join(l.Dir, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	// 先写临时文件再重命名, 避免读取到写入一半的文件
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func (l LocalStore) Get(key string) ([]byte, error) {
+	if !ValidKey(key) {
+		return nil, ErrNotFound
+	}
+
+	data, err := os.ReadFile(filepath.Join(l.Dir, filepath.FromSlash(key)))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return data, err
+}
+
+func (l LocalStore) Cleanup(before time.Time) (int, error) {
+	var count int
+	err := filepath.WalkDir(l.Dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		if info.ModTime().Before(before) {
+			if err := os.Remove(path); err == nil {
+				count++
+			}
+		}
+		return nil
+	})
+	return count, err
+}
@@ -0,0 +1,109 @@
+package blob
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+	"watchAlert/internal/models"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+)
+
+// S3Store 兼容 S3 协议的对象存储, 如 AWS S3、MinIO、阿里云 OSS、腾讯云 COS
+// 过期对象的清理依赖存储桶的生命周期规则
+type S3Store struct {
+	Config models.S3Config
+}
+
+var s3Client = &http.Client{Timeout: 30 * time.Second}
+
+func (s S3Store) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	if !ValidKey(key) {
+		return fmt.Errorf("非法的对象 Key: %s", key)
+	}
+
+	res, err := s.do(ctx, http.MethodPut, key, data, contentType)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("上传对象失败, status: %d, body: %s", res.StatusCode, string(body))
+	}
+	return nil
+}
+
+func (s S3Store) Get(key string) ([]byte, error) {
+	if !ValidKey(key) {
+		return nil, ErrNotFound
+	}
+
+	res, err := s.do(context.Background(), http.MethodGet, key, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	switch res.StatusCode {
+	case http.StatusOK:
+		return io.ReadAll(res.Body)
+	case http.StatusNotFound:
+		return nil, ErrNotFound
+	default:
+		return nil, fmt.Errorf("获取对象失败, status: %d", res.StatusCode)
+	}
+}
+
+func (s S3Store) Cleanup(time.Time) (int, error) {
+	return 0, nil
+}
+
+// objectUrl 对象地址, 默认使用 Virtual-Hosted-Style
+func (s S3Store) objectUrl(key string) string {
+	endpoint := strings.TrimSuffix(s.Config.Endpoint, "/")
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "https://" + endpoint
+	}
+	if s.Config.PathStyle {
+		return fmt.Sprintf("%s/%s/%s", endpoint, s.Config.Bucket, key)
+	}
+
+	scheme, host, _ := strings.Cut(endpoint, "://")
+	return fmt.Sprintf("%s://%s.%s/%s", scheme, s.Config.Bucket, host, key)
+}
+
+// do 发送使用 Signature V4 签名的请求
+func (s S3Store) do(ctx context.Context, method, key string, data []byte, contentType string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, s.objectUrl(key), bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	sum := sha256.Sum256(data)
+	payloadHash := hex.EncodeToString(sum[:])
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	region := s.Config.Region
+	if region == "" {
+		region = "us-east-1"
+	}
+	credentials := aws.Credentials{AccessKeyID: s.Config.AccessKeyId, SecretAccessKey: s.Config.AccessKeySecret}
+	err = v4.NewSigner().SignHTTP(ctx, credentials, req, payloadHash, "s3", region, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	return s3Client.Do(req)
+}
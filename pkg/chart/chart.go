@@ -0,0 +1,390 @@
+package chart
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultWidth  = 800
+	defaultHeight = 360
+	// 图例最多展示的曲线数量
+	maxLegendItems = 6
+	legendLineH    = 12
+	marginLeft     = 64
+	marginRight    = 20
+	marginTop      = 16
+	axisLabelH     = 16
+)
+
+var (
+	colorBackground = color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	colorAxis       = color.RGBA{R: 120, G: 120, B: 120, A: 255}
+	colorGrid       = color.RGBA{R: 230, G: 230, B: 230, A: 255}
+	colorText       = color.RGBA{R: 60, G: 60, B: 60, A: 255}
+	colorThreshold  = color.RGBA{R: 220, G: 38, B: 38, A: 255}
+	colorMarker     = color.RGBA{R: 245, G: 158, B: 11, A: 255}
+
+	palette = []color.RGBA{
+		{R: 37, G: 99, B: 235, A: 255},
+		{R: 22, G: 163, B: 74, A: 255},
+		{R: 147, G: 51, B: 234, A: 255},
+		{R: 8, G: 145, B: 178, A: 255},
+		{R: 219, G: 39, B: 119, A: 255},
+		{R: 101, G: 163, B: 13, A: 255},
+		{R: 234, G: 88, B: 12, A: 255},
+		{R: 71, G: 85, B: 105, A: 255},
+	}
+)
+
+type Point struct {
+	Time  time.Time
+	Value float64
+}
+
+// Series 一条曲线, Name 作为图例
+type Series struct {
+	Name   string
+	Points []Point
+}
+
+// Threshold 阈值线, Name 标注在线的右上方
+type Threshold struct {
+	Name  string
+	Value float64
+}
+
+// Chart 折线图, 使用内置点阵字体绘制, 文本仅支持 ASCII
+type Chart struct {
+	Width      int
+	Height     int
+	Start      time.Time
+	End        time.Time
+	Series     []Series
+	Thresholds []Threshold
+	// 竖直标记线, 如告警触发时间, 零值时不绘制
+	Marker   time.Time
+	Location *time.Location
+}
+
+// Render 渲染为 PNG
+func (c Chart) Render() ([]byte, error) {
+	if len(c.Series) == 0 {
+		return nil, errors.New("没有可绘制的数据")
+	}
+	if c.Width <= 0 {
+		c.Width = defaultWidth
+	}
+	if c.Height <= 0 {
+		c.Height = defaultHeight
+	}
+	if c.Location == nil {
+		c.Location = time.Local
+	}
+	if !c.End.After(c.Start) {
+		c.Start, c.End = c.timeRange()
+	}
+
+	legendItems := len(c.Series)
+	if legendItems > maxLegendItems {
+		legendItems = maxLegendItems + 1
+	}
+	plot := image.Rect(marginLeft, marginTop, c.Width-marginRight, c.Height-axisLabelH-legendItems*legendLineH-8)
+	if plot.Dx() < 100 || plot.Dy() < 60 {
+		return nil, fmt.Errorf("图片尺寸过小: %dx%d", c.Width, c.Height)
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, c.Width, c.Height))
+	draw.Draw(img, img.Bounds(), &image.Uniform{C: colorBackground}, image.Point{}, draw.Src)
+
+	lo, hi := c.valueRange()
+	ticks := niceTicks(lo, hi, 5)
+	lo, hi = ticks[0], ticks[len(ticks)-1]
+
+	xOf := func(t time.Time) int {
+		ratio := float64(t.Sub(c.Start)) / float64(c.End.Sub(c.Start))
+		return plot.Min.X + int(math.Round(ratio*float64(plot.Dx())))
+	}
+	yOf := func(v float64) int {
+		ratio := (v - lo) / (hi - lo)
+		return plot.Max.Y - int(math.Round(ratio*float64(plot.Dy())))
+	}
+
+	// 纵轴刻度及网格
+	for _, tick := range ticks {
+		y := yOf(tick)
+		hLine(img, plot.Min.X, plot.Max.X, y, colorGrid, 0)
+		label := FormatValue(tick)
+		drawText(img, plot.Min.X-6-textWidth(label), y-glyphHeight/2, label, colorText)
+	}
+
+	// 横轴刻度及网格
+	layout := "15:04"
+	if c.End.Sub(c.Start) > 24*time.Hour {
+		layout = "01-02 15:04"
+	}
+	for i := 0; i <= 5; i++ {
+		t := c.Start.Add(c.End.Sub(c.Start) * time.Duration(i) / 5)
+		x := xOf(t)
+		vLine(img, x, plot.Min.Y, plot.Max.Y, colorGrid, 0)
+		label := t.In(c.Location).Format(layout)
+		lx := x - textWidth(label)/2
+		if lx+textWidth(label) > c.Width-2 {
+			lx = c.Width - 2 - textWidth(label)
+		}
+		drawText(img, lx, plot.Max.Y+6, label, colorText)
+	}
+
+	hLine(img, plot.Min.X, plot.Max.X, plot.Max.Y, colorAxis, 0)
+	vLine(img, plot.Min.X, plot.Min.Y, plot.Max.Y, colorAxis, 0)
+
+	// 曲线, 相邻点间隔超过中位间隔的 3 倍视为断点
+	for i, s := range c.Series {
+		col := palette[i%len(palette)]
+		gap := medianInterval(s.Points) * 3
+		var prev *Point
+		for j := range s.Points {
+			p := s.Points[j]
+			if math.IsNaN(p.Value) || math.IsInf(p.Value, 0) {
+				prev = nil
+				continue
+			}
+			if prev != nil && (gap == 0 || p.Time.Sub(prev.Time) <= gap) {
+				thickLine(img, xOf(prev.Time), yOf(prev.Value), xOf(p.Time), yOf(p.Value), col, plot)
+			} else {
+				setClipped(img, xOf(p.Time), yOf(p.Value), col, plot)
+			}
+			prev = &s.Points[j]
+		}
+	}
+
+	// 阈值线
+	for _, th := range c.Thresholds {
+		y := yOf(th.Value)
+		hLine(img, plot.Min.X, plot.Max.X, y, colorThreshold, 6)
+		if th.Name != "" {
+			label := truncateText(th.Name, plot.Dx()/2)
+			drawText(img, plot.Max.X-textWidth(label)-2, y-glyphHeight-3, label, colorThreshold)
+		}
+	}
+
+	if !c.Marker.IsZero() && !c.Marker.Before(c.Start) && !c.Marker.After(c.End) {
+		vLine(img, xOf(c.Marker), plot.Min.Y, plot.Max.Y, colorMarker, 4)
+	}
+
+	// 图例
+	y := plot.Max.Y + axisLabelH + 6
+	for i, s := range c.Series {
+		if i == maxLegendItems {
+			drawText(img, plot.Min.X, y, fmt.Sprintf("... %d MORE", len(c.Series)-maxLegendItems), colorText)
+			break
+		}
+		col := palette[i%len(palette)]
+		for dx := 0; dx < 14; dx++ {
+			setPixel(img, plot.Min.X+dx, y+3, col)
+			setPixel(img, plot.Min.X+dx, y+4, col)
+		}
+		drawText(img, plot.Min.X+20, y, truncateText(s.Name, plot.Dx()-20), colorText)
+		y += legendLineH
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// timeRange 未指定时间范围时取所有数据点的时间范围
+func (c Chart) timeRange() (time.Time, time.Time) {
+	var start, end time.Time
+	for _, s := range c.Series {
+		for _, p := range s.Points {
+			if start.IsZero() || p.Time.Before(start) {
+				start = p.Time
+			}
+			if p.Time.After(end) {
+				end = p.Time
+			}
+		}
+	}
+	if !end.After(start) {
+		end = start.Add(time.Minute)
+	}
+	return start, end
+}
+
+// valueRange 数据及阈值的取值范围
+func (c Chart) valueRange() (float64, float64) {
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, s := range c.Series {
+		for _, p := range s.Points {
+			if math.IsNaN(p.Value) || math.IsInf(p.Value, 0) {
+				continue
+			}
+			lo, hi = math.Min(lo, p.Value), math.Max(hi, p.Value)
+		}
+	}
+	for _, th := range c.Thresholds {
+		lo, hi = math.Min(lo, th.Value), math.Max(hi, th.Value)
+	}
+	if math.IsInf(lo, 1) {
+		return 0, 1
+	}
+	if lo == hi {
+		pad := math.Max(math.Abs(lo)*0.1, 1)
+		return lo - pad, hi + pad
+	}
+	return lo, hi
+}
+
+// niceTicks 生成约 n 个步长为 1、2、5 倍数的刻度, 覆盖 [lo, hi]
+func niceTicks(lo, hi float64, n int) []float64 {
+	step := niceNum((hi - lo) / float64(n-1))
+	start := math.Floor(lo/step) * step
+	end := math.Ceil(hi/step) * step
+
+	var ticks []float64
+	for v := start; v <= end+step/2; v += step {
+		// 消除浮点累加误差
+		ticks = append(ticks, math.Round(v/step)*step)
+	}
+	if len(ticks) < 2 {
+		ticks = append(ticks, start+step)
+	}
+	return ticks
+}
+
+// niceNum 将步长取整为 1、2、5、10 乘以 10 的幂
+func niceNum(x float64) float64 {
+	exp := math.Floor(math.Log10(x))
+	f := x / math.Pow(10, exp)
+
+	var nf float64
+	switch {
+	case f < 1.5:
+		nf = 1
+	case f < 3:
+		nf = 2
+	case f < 7:
+		nf = 5
+	default:
+		nf = 10
+	}
+	return nf * math.Pow(10, exp)
+}
+
+// FormatValue 刻度值格式化, 较大的值使用 K、M、G、T 单位
+func FormatValue(v float64) string {
+	units := []struct {
+		base   float64
+		suffix string
+	}{{1e12, "T"}, {1e9, "G"}, {1e6, "M"}, {1e3, "K"}}
+
+	for _, u := range units {
+		if math.Abs(v) >= u.base {
+			return trimFloat(v/u.base) + u.suffix
+		}
+	}
+	return trimFloat(v)
+}
+
+func trimFloat(v float64) string {
+	s := strconv.FormatFloat(v, 'f', 2, 64)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	if s == "-0" {
+		return "0"
+	}
+	return s
+}
+
+// medianInterval 相邻数据点时间间隔的中位数
+func medianInterval(points []Point) time.Duration {
+	if len(points) < 2 {
+		return 0
+	}
+	intervals := make([]time.Duration, 0, len(points)-1)
+	for i := 1; i < len(points); i++ {
+		intervals = append(intervals, points[i].Time.Sub(points[i-1].Time))
+	}
+	sort.Slice(intervals, func(i, j int) bool { return intervals[i] < intervals[j] })
+	return intervals[len(intervals)/2]
+}
+
+func setPixel(img *image.RGBA, x, y int, c color.RGBA) {
+	if image.Pt(x, y).In(img.Bounds()) {
+		img.SetRGBA(x, y, c)
+	}
+}
+
+func setClipped(img *image.RGBA, x, y int, c color.RGBA, clip image.Rectangle) {
+	if x >= clip.Min.X && x <= clip.Max.X && y >= clip.Min.Y && y <= clip.Max.Y {
+		img.SetRGBA(x, y, c)
+	}
+}
+
+// hLine 水平线, dash 大于 0 时绘制虚线
+func hLine(img *image.RGBA, x0, x1, y int, c color.RGBA, dash int) {
+	for x := x0; x <= x1; x++ {
+		if dash > 0 && (x-x0)/dash%2 == 1 {
+			continue
+		}
+		setPixel(img, x, y, c)
+	}
+}
+
+// vLine 竖直线, dash 大于 0 时绘制虚线
+func vLine(img *image.RGBA, x, y0, y1 int, c color.RGBA, dash int) {
+	for y := y0; y <= y1; y++ {
+		if dash > 0 && (y-y0)/dash%2 == 1 {
+			continue
+		}
+		setPixel(img, x, y, c)
+	}
+}
+
+// thickLine 使用 Bresenham 算法绘制 2 像素宽的线段, 超出绘图区域的部分不绘制
+func thickLine(img *image.RGBA, x0, y0, x1, y1 int, c color.RGBA, clip image.Rectangle) {
+	dx, dy := abs(x1-x0), -abs(y1-y0)
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+	err := dx + dy
+	for {
+		setClipped(img, x0, y0, c, clip)
+		setClipped(img, x0, y0+1, c, clip)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := 2 * err
+		if e2 >= dy {
+			err += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			err += dx
+			y0 += sy
+		}
+	}
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,86 @@
+package chart
+
+import (
+	"bytes"
+	"image/png"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestRender(t *testing.T) {
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	var points []Point
+	for i := 0; i <= 60; i++ {
+		points = append(points, Point{Time: start.Add(time.Duration(i) * time.Minute), Value: 50 + 40*math.Sin(float64(i)/10)})
+	}
+	// 断点不影响绘制
+	points[30].Value = math.NaN()
+
+	c := Chart{
+		Series:     []Series{{Name: `{instance="10.0.0.1:9100"}`, Points: points}},
+		Thresholds: []Threshold{{Name: "P0 > 80", Value: 80}},
+		Marker:     start.Add(20 * time.Minute),
+		Location:   time.UTC,
+	}
+	data, err := c.Render()
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("decode png: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != defaultWidth || b.Dy() != defaultHeight {
+		t.Fatalf("size = %dx%d", b.Dx(), b.Dy())
+	}
+
+	var threshold, series bool
+	for y := 0; y < defaultHeight; y++ {
+		for x := 0; x < defaultWidth; x++ {
+			r, g, b, _ := img.At(x, y).RGBA()
+			switch {
+			case uint8(r>>8) == colorThreshold.R && uint8(g>>8) == colorThreshold.G && uint8(b>>8) == colorThreshold.B:
+				threshold = true
+			case uint8(r>>8) == palette[0].R && uint8(g>>8) == palette[0].G && uint8(b>>8) == palette[0].B:
+				series = true
+			}
+		}
+	}
+	if !threshold || !series {
+		t.Fatalf("threshold drawn = %v, series drawn = %v", threshold, series)
+	}
+
+	if _, err := (Chart{}).Render(); err == nil {
+		t.Fatal("expected error for empty chart")
+	}
+}
+
+func TestNiceTicks(t *testing.T) {
+	ticks := niceTicks(3, 97, 5)
+	if ticks[0] > 3 || ticks[len(ticks)-1] < 97 {
+		t.Fatalf("ticks %v do not cover range", ticks)
+	}
+	step := ticks[1] - ticks[0]
+	if step != 20 && step != 25 && step != 50 {
+		t.Fatalf("unexpected step %v", step)
+	}
+}
+
+func TestFormatValue(t *testing.T) {
+	cases := map[float64]string{
+		0:       "0",
+		0.126:   "0.13",
+		80:      "80",
+		1500:    "1.5K",
+		2.5e6:   "2.5M",
+		-3e9:    "-3G",
+		1024e12: "1024T",
+	}
+	for v, want := range cases {
+		if got := FormatValue(v); got != want {
+			t.Errorf("FormatValue(%v) = %q, want %q", v, got, want)
+		}
+	}
+}
@@ -68,6 +68,19 @@ func NewDBClient(config DBConfig) *gorm.DB {
 		&models.AiContentRecord{},
 		&models.ProbingHistory{},
 		&models.Comment{},
+		&models.Incident{},
+		&models.IncidentEvent{},
+		&models.IncidentTimeline{},
+		&models.Postmortem{},
+		&models.PostmortemVersion{},
+		&models.AiUsage{},
+		&models.DutyOverride{},
+		&models.DutySwap{},
+		&models.DutyRevision{},
+		&models.DutyHoliday{},
+		&models.DutyCalendarFeed{},
+		&models.DutyHandoff{},
+		&models.DutyPage{},
 	)
 	if err != nil {
 		logc.Error(context.Background(), err.Error())
@@ -1,19 +1,36 @@
 package client
 
 import (
+	"bytes"
+	"crypto/tls"
 	"fmt"
 	"github.com/jordan-wright/email"
+	"net/http"
 	"net/smtp"
 	"strconv"
+	"watchAlert/internal/models"
 )
 
 type EmailClient struct {
 	ServerAddr string
 	Port       int
+	Security   string
 	Email      *email.Email
 	Auth       smtp.Auth
 }
 
+// EmailMessage 多部分邮件, Text 与 Html 为同一内容的纯文本及 HTML 版本
+type EmailMessage struct {
+	To      []string
+	CC      []string
+	Subject string
+	Text    string
+	Html    string
+	Headers map[string]string
+	// 内联图片, Key 为文件名, HTML 中以 cid:文件名 引用
+	Inline map[string][]byte
+}
+
 func NewEmailClient(serverAddr, username, password string, port int) EmailClient {
 	e := email.NewEmail()
 	auth := smtp.PlainAuth("", username, password, serverAddr)
@@ -27,17 +44,57 @@ func NewEmailClient(serverAddr, username, password string, port int) EmailClient
 	}
 }
 
+// NewEmailClientWithProfile 按 SMTP 配置创建客户端
+func NewEmailClientWithProfile(profile models.EmailProfile) EmailClient {
+	c := NewEmailClient(profile.ServerAddress, profile.Email, profile.Token, profile.Port)
+	c.Security = profile.Security
+	if profile.FromName != "" {
+		c.Email.From = fmt.Sprintf("%s<%s>", profile.FromName, profile.Email)
+	}
+	return c
+}
+
 func (a EmailClient) Send(to, cc []string, subject string, msg []byte) error {
 	a.Email.To = to
 	a.Email.Cc = cc
 	a.Email.HTML = msg
 	a.Email.Subject = subject
-	port := strconv.FormatInt(int64(a.Port), 10)
-	//err := a.Email.SendWithTLS(a.ServerAddr+":"+port, a.Auth, &tls.Config{ServerName: a.ServerAddr})
-	err := a.Email.Send(a.ServerAddr+":"+port, a.Auth)
-	if err != nil {
-		return err
+
+	return a.deliver()
+}
+
+// SendMessage 发送 text/plain 与 text/html 的 multipart/alternative 邮件
+func (a EmailClient) SendMessage(msg EmailMessage) error {
+	a.Email.To = msg.To
+	a.Email.Cc = msg.CC
+	a.Email.Subject = msg.Subject
+	a.Email.Text = []byte(msg.Text)
+	a.Email.HTML = []byte(msg.Html)
+	for key, value := range msg.Headers {
+		a.Email.Headers.Set(key, value)
+	}
+	for name, data := range msg.Inline {
+		at, err := a.Email.Attach(bytes.NewReader(data), name, http.DetectContentType(data))
+		if err != nil {
+			return err
+		}
+		at.HTMLRelated = true
 	}
 
-	return nil
+	return a.deliver()
+}
+
+// deliver 按加密方式发送, 未指定时服务器支持 STARTTLS 则自动启用
+func (a EmailClient) deliver() error {
+	addr := a.ServerAddr + ":" + strconv.FormatInt(int64(a.Port), 10)
+	tlsConfig := &tls.Config{ServerName: a.ServerAddr}
+
+	switch a.Security {
+	case models.EmailSecuritySSL:
+		return a.Email.SendWithTLS(addr, a.Auth, tlsConfig)
+	case models.EmailSecurityStartTLS:
+		return a.Email.SendWithStartTLS(addr, a.Auth, tlsConfig)
+	default:
+		return a.Email.Send(addr, a.Auth)
+	}
 }
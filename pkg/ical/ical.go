@@ -0,0 +1,222 @@
+package ical
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	utcLayout   = "20060102T150405Z"
+	dateLayout  = "20060102"
+	localLayout = "20060102T150405"
+	// 内容行最大长度, 单位（字节）, 超出时折行
+	maxLineOctets = 75
+)
+
+// Event 日历事件, AllDay 为 true 时 End 为不包含的结束日期
+type Event struct {
+	UID         string
+	Summary     string
+	Description string
+	Start       time.Time
+	End         time.Time
+	AllDay      bool
+}
+
+// Calendar RFC 5545 日历
+type Calendar struct {
+	Name   string
+	Events []Event
+}
+
+// Write 按 RFC 5545 输出日历, 时间统一使用 UTC
+func (c Calendar) Write(w io.Writer, now time.Time) error {
+	bw := bufio.NewWriter(w)
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//WatchAlert//Duty Calendar//CN",
+		"CALSCALE:GREGORIAN",
+		"METHOD:PUBLISH",
+	}
+	if c.Name != "" {
+		lines = append(lines, "X-WR-CALNAME:"+escapeText(c.Name))
+	}
+
+	stamp := now.UTC().Format(utcLayout)
+	for _, e := range c.Events {
+		lines = append(lines, "BEGIN:VEVENT", "UID:"+escapeText(e.UID), "DTSTAMP:"+stamp)
+		if e.AllDay {
+			lines = append(lines,
+				"DTSTART;VALUE=DATE:"+e.Start.Format(dateLayout),
+				"DTEND;VALUE=DATE:"+e.End.Format(dateLayout),
+			)
+		} else {
+			lines = append(lines,
+				"DTSTART:"+e.Start.UTC().Format(utcLayout),
+				"DTEND:"+e.End.UTC().Format(utcLayout),
+			)
+		}
+		lines = append(lines, "SUMMARY:"+escapeText(e.Summary))
+		if e.Description != "" {
+			lines = append(lines, "DESCRIPTION:"+escapeText(e.Description))
+		}
+		lines = append(lines, "END:VEVENT")
+	}
+	lines = append(lines, "END:VCALENDAR")
+
+	for _, line := range lines {
+		if _, err := bw.WriteString(fold(line)); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// Parse 解析 ICS 内容中的事件, 不支持重复规则（RRULE）
+func Parse(r io.Reader) ([]Event, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		events  []Event
+		current *Event
+		hasEnd  bool
+	)
+	for _, line := range lines {
+		name, params, value, ok := splitLine(line)
+		if !ok {
+			continue
+		}
+
+		switch {
+		case name == "BEGIN" && strings.EqualFold(value, "VEVENT"):
+			current, hasEnd = &Event{}, false
+		case name == "END" && strings.EqualFold(value, "VEVENT"):
+			if current == nil {
+				continue
+			}
+			if current.Start.IsZero() {
+				return nil, fmt.Errorf("事件 %s 缺少 DTSTART", current.Summary)
+			}
+			if !hasEnd {
+				// 未指定结束时间的全天事件持续一天
+				current.End = current.Start
+				if current.AllDay {
+					current.End = current.Start.AddDate(0, 0, 1)
+				}
+			}
+			events = append(events, *current)
+			current = nil
+		case current == nil:
+			continue
+		case name == "UID":
+			current.UID = unescapeText(value)
+		case name == "SUMMARY":
+			current.Summary = unescapeText(value)
+		case name == "DESCRIPTION":
+			current.Description = unescapeText(value)
+		case name == "DTSTART":
+			t, allDay, err := parseTime(params, value)
+			if err != nil {
+				return nil, err
+			}
+			current.Start, current.AllDay = t, allDay
+		case name == "DTEND":
+			t, _, err := parseTime(params, value)
+			if err != nil {
+				return nil, err
+			}
+			current.End, hasEnd = t, true
+		}
+	}
+
+	return events, nil
+}
+
+func parseTime(params map[string]string, value string) (time.Time, bool, error) {
+	if params["VALUE"] == "DATE" || len(value) == len(dateLayout) {
+		t, err := time.Parse(dateLayout, value)
+		return t, true, err
+	}
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse(utcLayout, value)
+		return t, false, err
+	}
+
+	loc := time.UTC
+	if tzid := params["TZID"]; tzid != "" {
+		if l, err := time.LoadLocation(tzid); err == nil {
+			loc = l
+		}
+	}
+	t, err := time.ParseInLocation(localLayout, value, loc)
+	return t, false, err
+}
+
+// splitLine 拆分内容行为属性名、参数及值
+func splitLine(line string) (string, map[string]string, string, bool) {
+	idx := strings.Index(line, ":")
+	if idx < 0 {
+		return "", nil, "", false
+	}
+
+	parts := strings.Split(line[:idx], ";")
+	params := make(map[string]string)
+	for _, p := range parts[1:] {
+		if kv := strings.SplitN(p, "=", 2); len(kv) == 2 {
+			params[strings.ToUpper(kv[0])] = strings.Trim(kv[1], `"`)
+		}
+	}
+	return strings.ToUpper(parts[0]), params, line[idx+1:], true
+}
+
+func unfold(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, scanner.Err()
+}
+
+// fold 超长内容行按字节折行, 不拆分多字节字符
+func fold(line string) string {
+	var (
+		sb    strings.Builder
+		width int
+		limit = maxLineOctets
+	)
+	for _, r := range line {
+		size := len(string(r))
+		if width+size > limit {
+			sb.WriteString("\r\n ")
+			width = 0
+			// 续行首个空格占用一个字节
+			limit = maxLineOctets - 1
+		}
+		sb.WriteRune(r)
+		width += size
+	}
+	sb.WriteString("\r\n")
+	return sb.String()
+}
+
+func escapeText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
+
+func unescapeText(s string) string {
+	return strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n").Replace(s)
+}
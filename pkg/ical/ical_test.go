@@ -0,0 +1,71 @@
+package ical
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteAndParse(t *testing.T) {
+	start := time.Date(2025, 1, 1, 1, 0, 0, 0, time.UTC)
+	cal := Calendar{
+		Name: "值班表",
+		Events: []Event{{
+			UID:         "dt-1-primary-1735693200@watchalert",
+			Summary:     "值班: 基础设施, primary",
+			Description: strings.Repeat("夜间值班;", 20),
+			Start:       start,
+			End:         start.Add(12 * time.Hour),
+		}},
+	}
+
+	var buf bytes.Buffer
+	if err := cal.Write(&buf, start); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\r\n"), "\r\n") {
+		if len(line) > maxLineOctets {
+			t.Fatalf("line exceeds %d octets: %q", maxLineOctets, line)
+		}
+	}
+	if !strings.Contains(buf.String(), "DTSTART:20250101T010000Z\r\n") || !strings.Contains(buf.String(), `SUMMARY:值班: 基础设施\, primary`) {
+		t.Fatalf("unexpected output:\n%s", buf.String())
+	}
+
+	events, err := Parse(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].Summary != cal.Events[0].Summary || events[0].Description != cal.Events[0].Description {
+		t.Fatalf("unexpected events: %+v", events)
+	}
+	if !events[0].Start.Equal(start) || !events[0].End.Equal(start.Add(12*time.Hour)) || events[0].AllDay {
+		t.Fatalf("unexpected event time: %+v", events[0])
+	}
+}
+
+func TestParseHolidayCalendar(t *testing.T) {
+	content := "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n" +
+		"BEGIN:VEVENT\r\nDTSTART;VALUE=DATE:20251001\r\nDTEND;VALUE=DATE:20251009\r\nSUMMARY:国庆节、中秋节 假期\r\nEND:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\nDTSTART;VALUE=DATE:20250928\r\nSUMMARY:国庆节 补班\r\nEND:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\nDTSTART;TZID=Asia/Shanghai:20250101T090000\r\nDTEND;TZID=Asia/Shanghai:20250101T10\r\n 0000\r\nSUMMARY:会议\r\nEND:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+
+	events, err := Parse(strings.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 3 {
+		t.Fatalf("expected 3 events, got %+v", events)
+	}
+	if !events[0].AllDay || events[0].End.Sub(events[0].Start) != 8*24*time.Hour {
+		t.Fatalf("unexpected holiday event: %+v", events[0])
+	}
+	if !events[1].End.Equal(time.Date(2025, 9, 29, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected single day event, got %+v", events[1])
+	}
+	if events[2].AllDay || events[2].End.Sub(events[2].Start) != time.Hour || events[2].Start.UTC().Hour() != 1 {
+		t.Fatalf("unexpected timed event: %+v", events[2])
+	}
+}
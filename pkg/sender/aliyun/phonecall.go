@@ -9,6 +9,7 @@ import (
 	"github.com/zeromicro/go-zero/core/logc"
 	"go.uber.org/multierr"
 	"watchAlert/internal/ctx"
+	"watchAlert/internal/models"
 )
 
 type PhoneCall struct {
@@ -16,6 +17,7 @@ type PhoneCall struct {
 	AccessKeyId     string `json:"accessKeyId,omitempty"`
 	AccessKeySecret string `json:"accessKeySecret,omitempty"`
 	TtsCode         string `json:"ttsCode,omitempty"`
+	MaxLength       int    `json:"maxLength,omitempty"`
 	Client          *dyvmsapi.Client
 }
 
@@ -33,8 +35,10 @@ func (p *PhoneCall) CreateClient() error {
 	return nil
 }
 
-func (p *PhoneCall) Call(message string, phoneNumbers []string) error {
+// Call 拨打语音通知, 国际版单呼接口不支持按键交互, 忽略 ackToken
+func (p *PhoneCall) Call(message, ackToken string, phoneNumbers []string) error {
 	var resultError error
+	message = models.LimitTemplateParams(message, p.MaxLength)
 	for _, phoneNumber := range phoneNumbers {
 		request := &dyvmsapi.VoiceSingleCallRequest{
 			// 接收语音通知的手机号码
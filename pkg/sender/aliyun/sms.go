@@ -0,0 +1,107 @@
+package aliyun
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+	"watchAlert/internal/models"
+	"watchAlert/pkg/tools"
+)
+
+const defaultSmsEndpoint = "dysmsapi.aliyuncs.com"
+
+// Sms 阿里云短信服务, 使用 RPC 风格签名调用 SendSms
+type Sms struct {
+	Endpoint        string
+	AccessKeyId     string
+	AccessKeySecret string
+	SignName        string
+	TemplateCode    string
+	MaxLength       int
+}
+
+func (s *Sms) Send(message string, phoneNumbers []string) error {
+	endpoint := s.Endpoint
+	if endpoint == "" {
+		endpoint = defaultSmsEndpoint
+	}
+
+	params := map[string]string{
+		"AccessKeyId":      s.AccessKeyId,
+		"Action":           "SendSms",
+		"Format":           "JSON",
+		"RegionId":         "cn-hangzhou",
+		"SignatureMethod":  "HMAC-SHA1",
+		"SignatureNonce":   tools.RandId(),
+		"SignatureVersion": "1.0",
+		"Timestamp":        time.Now().UTC().Format("2006-01-02T15:04:05Z"),
+		"Version":          "2017-05-25",
+		"PhoneNumbers":     strings.Join(phoneNumbers, ","),
+		"SignName":         s.SignName,
+		"TemplateCode":     s.TemplateCode,
+		"TemplateParam":    models.LimitTemplateParams(message, s.MaxLength),
+	}
+	query := canonicalizedQuery(params)
+	signature := rpcSignature(query, s.AccessKeySecret)
+
+	res, err := tools.Get(nil, fmt.Sprintf("https://%s/?Signature=%s&%s", endpoint, percentEncode(signature), query), 10)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	bodyByte, err := io.ReadAll(res.Body)
+	if err != nil {
+		return fmt.Errorf("读取阿里云短信响应失败, err: %s", err.Error())
+	}
+
+	var response struct {
+		Code    string `json:"Code"`
+		Message string `json:"Message"`
+	}
+	if err := json.Unmarshal(bodyByte, &response); err != nil {
+		return fmt.Errorf("解析阿里云短信响应失败, status: %d", res.StatusCode)
+	}
+	if response.Code != "OK" {
+		return fmt.Errorf("%s: %s", response.Code, response.Message)
+	}
+
+	return nil
+}
+
+// canonicalizedQuery 按参数名排序并编码
+func canonicalizedQuery(params map[string]string) string {
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, percentEncode(key)+"="+percentEncode(params[key]))
+	}
+	return strings.Join(pairs, "&")
+}
+
+// rpcSignature 计算 RPC 风格签名, 签名密钥为 AccessKeySecret 加 &
+func rpcSignature(query, secret string) string {
+	stringToSign := "GET&" + percentEncode("/") + "&" + percentEncode(query)
+	mac := hmac.New(sha1.New, []byte(secret+"&"))
+	mac.Write([]byte(stringToSign))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func percentEncode(value string) string {
+	encoded := url.QueryEscape(value)
+	encoded = strings.ReplaceAll(encoded, "+", "%20")
+	encoded = strings.ReplaceAll(encoded, "*", "%2A")
+	return strings.ReplaceAll(encoded, "%7E", "~")
+}
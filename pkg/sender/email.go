@@ -3,10 +3,15 @@ package sender
 import (
 	"errors"
 	"fmt"
+	"time"
 	"watchAlert/internal/ctx"
+	"watchAlert/internal/models"
 	"watchAlert/pkg/client"
 )
 
+// emailGraphName 趋势图内联图片的文件名及 Content-ID
+const emailGraphName = "graph.png"
+
 // EmailSender 邮件发送策略
 type EmailSender struct{}
 
@@ -19,13 +24,37 @@ func (e *EmailSender) Send(params SendParams) error {
 	if err != nil {
 		return errors.New("获取 系统配置/邮箱配置 失败: " + err.Error())
 	}
-	eCli := client.NewEmailClient(setting.EmailConfig.ServerAddress, setting.EmailConfig.Email, setting.EmailConfig.Token, setting.EmailConfig.Port)
+	profile := setting.EmailConfig.GetProfile(params.Email.SmtpProfile)
+	eCli := client.NewEmailClientWithProfile(profile)
+
+	state := "报警中"
 	if params.IsRecovered {
-		params.Email.Subject = params.Email.Subject + "「已恢复」"
-	} else {
-		params.Email.Subject = params.Email.Subject + "「报警中」"
+		state = "已恢复"
+	}
+	first := params.IsFirstNotice && !params.IsRecovered
+
+	// 主题保持不变, 状态标注在正文中, 使同一事件的邮件归为同一会话
+	text, html := models.EmailBodies(params.Content)
+	text, html = models.EmailPrependState(text, html, state)
+	msg := client.EmailMessage{
+		To:      params.Email.To,
+		CC:      params.Email.CC,
+		Subject: params.Email.Subject,
+		Text:    text,
+		Html:    html,
 	}
-	err = eCli.Send(params.Email.To, params.Email.CC, params.Email.Subject, []byte(params.Content))
+	if len(params.Graph) > 0 {
+		msg.Html = models.EmailAppendImage(msg.Html, emailGraphName, "趋势图")
+		msg.Inline = map[string][]byte{emailGraphName: params.Graph}
+	}
+	// 同一事件的邮件归为同一会话
+	if params.EventId != "" {
+		msg.Subject = models.EmailThreadSubject(params.Email.Subject, first)
+		msg.Headers = models.EmailThreadHeaders(params.EventId, profile.Email, first, time.Now())
+		msg.Headers["Thread-Topic"] = params.Email.Subject
+	}
+
+	err = eCli.SendMessage(msg)
 	if err != nil {
 		return fmt.Errorf("%s, %s", err.Error(), "Content: "+params.Content)
 	}
@@ -39,6 +68,13 @@ func (e *EmailSender) Test(params SendParams) error {
 		return errors.New("获取 系统配置/邮箱配置 失败: " + err.Error())
 	}
 
-	eCli := client.NewEmailClient(setting.EmailConfig.ServerAddress, setting.EmailConfig.Email, setting.EmailConfig.Token, setting.EmailConfig.Port)
-	return eCli.Send(params.Email.To, params.Email.CC, "WatchAlert 消息测试", []byte(RobotTestContent))
+	eCli := client.NewEmailClientWithProfile(setting.EmailConfig.GetProfile(params.Email.SmtpProfile))
+	text, html := models.EmailBodies(RobotTestContent)
+	return eCli.SendMessage(client.EmailMessage{
+		To:      params.Email.To,
+		CC:      params.Email.CC,
+		Subject: "WatchAlert 消息测试",
+		Text:    text,
+		Html:    html,
+	})
 }
@@ -20,22 +20,30 @@ type (
 		EventId  string
 		RuleName string
 		Severity string
+		// 告警指纹, 用于语音通知按键认领
+		Fingerprint string
 		// 通知
 		NoticeType string
 		NoticeId   string
 		NoticeName string
 		// 恢复通知
 		IsRecovered bool
+		// 告警事件的首次通知, 邮件以此作为会话的首封邮件
+		IsFirstNotice bool
 		// hook 地址
 		Hook string
 		// 邮件
 		Email models.Email
 		// 消息
 		Content string
+		// 趋势图 PNG, 邮件以内联图片附带
+		Graph []byte
 		// 电话号码
 		PhoneNumber []string
 		// 签名
 		Sign string `json:"sign,omitempty"`
+		// 自定义 Hook 的请求配置
+		Webhook models.WebhookConfig
 	}
 
 	// SendInter 发送通知的接口
@@ -97,8 +105,18 @@ func senderFactory(noticeType string) (SendInter, error) {
 		return NewWebHookSender(), nil
 	case "PhoneCall":
 		return NewPhoneCallSender(), nil
+	case "Sms":
+		return NewSmsSender(), nil
 	case "Slack":
 		return NewSlackSender(), nil
+	case "Teams":
+		return NewTeamsSender(), nil
+	case "PagerDuty":
+		return NewPagerDutySender(), nil
+	case "Opsgenie":
+		return NewOpsgenieSender(), nil
+	case "Telegram":
+		return NewTelegramSender(), nil
 	default:
 		return nil, fmt.Errorf("无效的通知类型: %s", noticeType)
 	}
@@ -0,0 +1,148 @@
+package sender
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"watchAlert/internal/models"
+	"watchAlert/pkg/tools"
+
+	"github.com/bytedance/sonic"
+)
+
+type (
+	// PagerDutySender PagerDuty Events API v2 发送策略, Hook 为 Events API 地址（为空时使用默认地址）, Sign 为 Routing Key
+	PagerDutySender struct{}
+
+	// OpsgenieSender Opsgenie Alert API 发送策略, Hook 为 API 地址（为空时使用默认地址, EU 区域为 https://api.eu.opsgenie.com）, Sign 为 API Key
+	OpsgenieSender struct{}
+)
+
+func NewPagerDutySender() SendInter {
+	return &PagerDutySender{}
+}
+
+func NewOpsgenieSender() SendInter {
+	return &OpsgenieSender{}
+}
+
+func (p *PagerDutySender) Send(params SendParams) error {
+	var event models.PagerDutyEvent
+	if err := sonic.Unmarshal([]byte(params.Content), &event); err != nil {
+		return fmt.Errorf("解析 PagerDuty 事件失败, err: %s", err.Error())
+	}
+
+	return p.post(params, event)
+}
+
+func (p *PagerDutySender) Test(params SendParams) error {
+	// 测试事件触发后立即恢复, 避免残留未处理的事件
+	dedupKey := "watchalert-test-" + tools.RandId()
+	err := p.post(params, models.PagerDutyEvent{
+		EventAction: models.PagerDutyActionTrigger,
+		DedupKey:    dedupKey,
+		Payload: &models.PagerDutyPayload{
+			Summary:  RobotTestContent,
+			Source:   "WatchAlert",
+			Severity: "info",
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	return p.post(params, models.PagerDutyEvent{EventAction: models.PagerDutyActionResolve, DedupKey: dedupKey})
+}
+
+func (p *PagerDutySender) post(params SendParams, event models.PagerDutyEvent) error {
+	if params.Sign == "" {
+		return errors.New("未配置 PagerDuty Routing Key")
+	}
+	event.RoutingKey = params.Sign
+
+	hook := params.Hook
+	if hook == "" {
+		hook = models.PagerDutyEventsUrl
+	}
+
+	return pagingPost(nil, hook, event)
+}
+
+func (o *OpsgenieSender) Send(params SendParams) error {
+	var msg models.OpsgenieMessage
+	if err := sonic.Unmarshal([]byte(params.Content), &msg); err != nil {
+		return fmt.Errorf("解析 Opsgenie 消息失败, err: %s", err.Error())
+	}
+
+	return o.do(params, msg)
+}
+
+func (o *OpsgenieSender) Test(params SendParams) error {
+	alias := "watchalert-test-" + tools.RandId()
+	err := o.do(params, models.OpsgenieMessage{
+		Action: models.OpsgenieActionCreate,
+		Alias:  alias,
+		Alert: &models.OpsgenieAlert{
+			Message:  RobotTestContent,
+			Alias:    alias,
+			Priority: "P5",
+			Source:   "WatchAlert",
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	return o.do(params, models.OpsgenieMessage{Action: models.OpsgenieActionClose, Alias: alias, Note: "测试完成"})
+}
+
+func (o *OpsgenieSender) do(params SendParams, msg models.OpsgenieMessage) error {
+	if params.Sign == "" {
+		return errors.New("未配置 Opsgenie API Key")
+	}
+
+	base := strings.TrimSuffix(params.Hook, "/")
+	if base == "" {
+		base = models.OpsgenieApiUrl
+	}
+	headers := map[string]string{"Authorization": "GenieKey " + params.Sign}
+
+	switch msg.Action {
+	case models.OpsgenieActionCreate:
+		if msg.Alert == nil {
+			return errors.New("Opsgenie 告警内容为空")
+		}
+		return pagingPost(headers, base+"/v2/alerts", msg.Alert)
+	case models.OpsgenieActionAcknowledge, models.OpsgenieActionClose:
+		address := fmt.Sprintf("%s/v2/alerts/%s/%s?identifierType=alias", base, url.PathEscape(msg.Alias), msg.Action)
+		return pagingPost(headers, address, map[string]string{
+			"source": "WatchAlert",
+			"user":   msg.User,
+			"note":   msg.Note,
+		})
+	default:
+		return fmt.Errorf("不支持的 Opsgenie 操作: %s", msg.Action)
+	}
+}
+
+// pagingPost 请求 PagerDuty、Opsgenie 接口, 两者均为异步处理, 成功时返回 202
+func pagingPost(headers map[string]string, address string, body any) error {
+	res, err := tools.Post(headers, address, bytes.NewReader([]byte(tools.JsonMarshalToString(body))), 10)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	bodyByte, err := io.ReadAll(res.Body)
+	if err != nil {
+		return fmt.Errorf("读取响应失败, err: %s", err.Error())
+	}
+	if res.StatusCode/100 != 2 {
+		return fmt.Errorf("status: %d, body: %s", res.StatusCode, string(bodyByte))
+	}
+
+	return nil
+}
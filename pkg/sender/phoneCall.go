@@ -4,11 +4,14 @@ import (
 	"errors"
 	"fmt"
 	"watchAlert/internal/ctx"
-
+	"watchAlert/internal/models"
 	"watchAlert/pkg/sender/aliyun"
+	"watchAlert/pkg/sender/tencent"
+	"watchAlert/pkg/sender/twilio"
+	"watchAlert/pkg/utils"
 )
 
-// PhoneCallSender 邮件发送策略
+// PhoneCallSender 语音通知发送策略
 type PhoneCallSender struct{}
 
 func NewPhoneCallSender() SendInter {
@@ -20,27 +23,47 @@ func (e *PhoneCallSender) Send(params SendParams) error {
 	if err != nil {
 		return errors.New("获取系统配置失败: " + err.Error())
 	}
+	config := setting.PhoneCallConfig
+	maxLength := models.VoiceContentLimit(config.Provider, config.MaxLength)
 
 	var phoneCall PhoneCall
-	switch setting.PhoneCallConfig.Provider {
+	switch config.Provider {
 	case PROVIDER_ALIYUN:
 		aliyunPhoneCall := &aliyun.PhoneCall{
-			Endpoint:        setting.PhoneCallConfig.Endpoint,
-			AccessKeyId:     setting.PhoneCallConfig.AccessKeyId,
-			AccessKeySecret: setting.PhoneCallConfig.AccessKeySecret,
+			Endpoint:        config.Endpoint,
+			AccessKeyId:     config.AccessKeyId,
+			AccessKeySecret: config.AccessKeySecret,
+			TtsCode:         config.TtsCode,
+			MaxLength:       maxLength,
 		}
 		err := aliyunPhoneCall.CreateClient()
 		if err != nil {
-			return fmt.Errorf("创建%s语音服务客户端失败: %v\n", setting.PhoneCallConfig.Provider, err)
+			return fmt.Errorf("创建%s语音服务客户端失败: %v\n", config.Provider, err)
 		}
 
 		phoneCall = aliyunPhoneCall
 
+	case PROVIDER_TENCENT:
+		phoneCall = &tencent.PhoneCall{
+			Client:     tencent.Client{SecretId: config.AccessKeyId, SecretKey: config.AccessKeySecret, Region: config.Region},
+			AppId:      config.AppId,
+			TemplateId: config.TtsCode,
+			MaxLength:  maxLength,
+		}
+
+	case PROVIDER_TWILIO:
+		phoneCall = &twilio.PhoneCall{
+			Client:      twilio.Client{AccountSid: config.AccessKeyId, AuthToken: config.AccessKeySecret, From: config.From},
+			Language:    config.Language,
+			MaxLength:   maxLength,
+			CallbackUrl: config.CallbackUrl,
+		}
+
 	default:
-		return errors.New("未知语音服务提供商: " + setting.PhoneCallConfig.Provider)
+		return errors.New("未知语音服务提供商: " + config.Provider)
 	}
 
-	err = phoneCall.Call(params.Content, params.PhoneNumber)
+	err = phoneCall.Call(params.Content, phoneCallAckToken(params, config.CallbackUrl, config.AccessKeySecret), params.PhoneNumber)
 
 	if err != nil {
 		return errors.New("语音通知 类型报警发送失败" + err.Error())
@@ -50,3 +73,16 @@ func (e *PhoneCallSender) Send(params SendParams) error {
 }
 
 func (e *PhoneCallSender) Test(params SendParams) error { return nil }
+
+// phoneCallAckToken 生成按键认领的令牌, 仅未恢复的告警且配置了回调地址时生成, 使用服务商密钥签名
+func phoneCallAckToken(params SendParams, callbackUrl, secret string) string {
+	if callbackUrl == "" || params.IsRecovered || params.Fingerprint == "" || secret == "" {
+		return ""
+	}
+
+	token, err := utils.GenerateQuickToken(params.TenantId, params.Fingerprint, "", secret)
+	if err != nil {
+		return ""
+	}
+	return token
+}
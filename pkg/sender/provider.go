@@ -1,9 +1,16 @@
 package sender
 
 const (
-	PROVIDER_ALIYUN = "aliyun"
+	PROVIDER_ALIYUN  = "aliyun"
+	PROVIDER_TENCENT = "tencent"
+	PROVIDER_TWILIO  = "twilio"
 )
 
 type PhoneCall interface {
-	Call(message string, phoneNumbers []string) error
+	// Call 拨打语音通知, ackToken 不为空且服务商支持时, 被叫方可按 1 认领告警
+	Call(message, ackToken string, phoneNumbers []string) error
+}
+
+type Sms interface {
+	Send(message string, phoneNumbers []string) error
 }
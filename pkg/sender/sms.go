@@ -0,0 +1,66 @@
+package sender
+
+import (
+	"errors"
+	"watchAlert/internal/ctx"
+	"watchAlert/internal/models"
+	"watchAlert/pkg/sender/aliyun"
+	"watchAlert/pkg/sender/tencent"
+	"watchAlert/pkg/sender/twilio"
+)
+
+// SmsSender 短信通知发送策略
+type SmsSender struct{}
+
+func NewSmsSender() SendInter {
+	return &SmsSender{}
+}
+
+func (s *SmsSender) Send(params SendParams) error {
+	if len(params.PhoneNumber) == 0 {
+		return errors.New("未配置接收短信的手机号")
+	}
+
+	setting, err := ctx.DB.Setting().Get()
+	if err != nil {
+		return errors.New("获取 系统配置/短信配置 失败: " + err.Error())
+	}
+	config := setting.SmsConfig
+	maxLength := models.SmsContentLimit(config.Provider, config.MaxLength)
+
+	var sms Sms
+	switch config.Provider {
+	case PROVIDER_ALIYUN:
+		sms = &aliyun.Sms{
+			Endpoint:        config.Endpoint,
+			AccessKeyId:     config.AccessKeyId,
+			AccessKeySecret: config.AccessKeySecret,
+			SignName:        config.SignName,
+			TemplateCode:    config.TemplateCode,
+			MaxLength:       maxLength,
+		}
+	case PROVIDER_TENCENT:
+		sms = &tencent.Sms{
+			Client:     tencent.Client{SecretId: config.AccessKeyId, SecretKey: config.AccessKeySecret, Region: config.Region},
+			AppId:      config.AppId,
+			SignName:   config.SignName,
+			TemplateId: config.TemplateCode,
+			MaxLength:  maxLength,
+		}
+	case PROVIDER_TWILIO:
+		sms = &twilio.Sms{
+			Client:    twilio.Client{AccountSid: config.AccessKeyId, AuthToken: config.AccessKeySecret, From: config.From},
+			MaxLength: maxLength,
+		}
+	default:
+		return errors.New("未知短信服务提供商: " + config.Provider)
+	}
+
+	if err := sms.Send(params.Content, params.PhoneNumber); err != nil {
+		return errors.New("短信通知发送失败: " + err.Error())
+	}
+
+	return nil
+}
+
+func (s *SmsSender) Test(params SendParams) error { return nil }
@@ -0,0 +1,49 @@
+package sender
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"watchAlert/internal/models"
+	"watchAlert/pkg/tools"
+)
+
+type (
+	// TeamsSender Microsoft Teams 发送策略, 支持 Workflows 及 Incoming Webhook
+	TeamsSender struct{}
+)
+
+func NewTeamsSender() SendInter {
+	return &TeamsSender{}
+}
+
+func (t *TeamsSender) Send(params SendParams) error {
+	return t.post(params.Hook, params.Content)
+}
+
+func (t *TeamsSender) Test(params SendParams) error {
+	msg := models.NewTeamsTextMessage("", RobotTestContent)
+	return t.post(params.Hook, tools.JsonMarshalToString(msg))
+}
+
+func (t *TeamsSender) post(hook, content string) error {
+	res, err := tools.Post(nil, hook, bytes.NewReader([]byte(content)), 10)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	bodyByte, err := io.ReadAll(res.Body)
+	if err != nil {
+		return fmt.Errorf("读取 Teams 响应失败, err: %s", err.Error())
+	}
+
+	// Workflows 返回 202, Incoming Webhook 返回 200, 投递失败时 Incoming Webhook 仍返回 200 并在响应中说明原因
+	if res.StatusCode/100 != 2 || strings.Contains(string(bodyByte), "delivery failed") {
+		return errors.New(string(bodyByte))
+	}
+
+	return nil
+}
@@ -0,0 +1,125 @@
+package sender
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"watchAlert/internal/ctx"
+	"watchAlert/internal/models"
+	"watchAlert/pkg/tools"
+
+	"github.com/bytedance/sonic"
+)
+
+type (
+	// TelegramSender Telegram Bot 发送策略, Hook 为 Chat ID, 多个以逗号分隔
+	TelegramSender struct{}
+
+	// TelegramBot Bot API 客户端
+	TelegramBot struct {
+		ApiUrl string
+		Token  string
+	}
+
+	telegramResponse struct {
+		Ok          bool   `json:"ok"`
+		ErrorCode   int    `json:"error_code"`
+		Description string `json:"description"`
+	}
+)
+
+func NewTelegramSender() SendInter {
+	return &TelegramSender{}
+}
+
+func NewTelegramBot(config models.TelegramConfig) TelegramBot {
+	return TelegramBot{ApiUrl: config.GetApiUrl(), Token: config.BotToken}
+}
+
+func (t *TelegramSender) Send(params SendParams) error {
+	var msg models.TelegramMessage
+	if err := sonic.Unmarshal([]byte(params.Content), &msg); err != nil {
+		return fmt.Errorf("解析 Telegram 消息失败, err: %s", err.Error())
+	}
+
+	return t.send(params.Hook, msg)
+}
+
+func (t *TelegramSender) Test(params SendParams) error {
+	return t.send(params.Hook, models.TelegramMessage{Text: RobotTestContent})
+}
+
+func (t *TelegramSender) send(hook string, msg models.TelegramMessage) error {
+	setting, err := ctx.DB.Setting().Get()
+	if err != nil {
+		return errors.New("获取 系统配置/Telegram配置 失败: " + err.Error())
+	}
+	config := setting.TelegramConfig
+	if config.BotToken == "" {
+		return errors.New("未配置 Telegram Bot Token")
+	}
+
+	// 未配置回调 Webhook 时按钮无法响应, 仅保留链接按钮
+	if config.WebhookSecret == "" {
+		msg.ReplyMarkup = models.FilterTelegramKeyboard(msg.ReplyMarkup, func(button models.TelegramInlineButton) bool {
+			return button.Url != ""
+		})
+	}
+	if msg.ReplyMarkup != nil && len(msg.ReplyMarkup.InlineKeyboard) == 0 {
+		msg.ReplyMarkup = nil
+	}
+
+	bot := NewTelegramBot(config)
+	var errs []string
+	for _, chatId := range strings.Split(hook, ",") {
+		chatId = strings.TrimSpace(chatId)
+		if chatId == "" {
+			continue
+		}
+		msg.ChatId = chatId
+		if err := bot.Call("sendMessage", msg); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %s", chatId, err.Error()))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+
+	return nil
+}
+
+// Call 调用 Bot API 方法
+func (b TelegramBot) Call(method string, payload any) error {
+	url := fmt.Sprintf("%s/bot%s/%s", b.ApiUrl, b.Token, method)
+	res, err := tools.Post(nil, url, bytes.NewReader([]byte(tools.JsonMarshalToString(payload))), 10)
+	if err != nil {
+		// 请求地址中包含 Bot Token, 不返回原始错误
+		return fmt.Errorf("请求 Telegram %s 失败", method)
+	}
+	defer res.Body.Close()
+
+	bodyByte, err := io.ReadAll(res.Body)
+	if err != nil {
+		return fmt.Errorf("读取 Telegram 响应失败, err: %s", err.Error())
+	}
+
+	var response telegramResponse
+	if err := sonic.Unmarshal(bodyByte, &response); err != nil {
+		return fmt.Errorf("解析 Telegram 响应失败, status: %d", res.StatusCode)
+	}
+	if !response.Ok {
+		return fmt.Errorf("%d %s", response.ErrorCode, response.Description)
+	}
+
+	return nil
+}
+
+// AnswerCallbackQuery 应答按钮回调, text 以提示框展示给操作人
+func (b TelegramBot) AnswerCallbackQuery(callbackQueryId, text string) error {
+	return b.Call("answerCallbackQuery", map[string]any{
+		"callback_query_id": callbackQueryId,
+		"text":              text,
+	})
+}
@@ -0,0 +1,123 @@
+package tencent
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"watchAlert/pkg/tools"
+)
+
+const defaultRegion = "ap-guangzhou"
+
+// Client 腾讯云 API 3.0 客户端, 使用 TC3-HMAC-SHA256 签名
+type Client struct {
+	SecretId  string
+	SecretKey string
+	Region    string
+}
+
+type apiError struct {
+	Code    string `json:"Code"`
+	Message string `json:"Message"`
+}
+
+// Do 调用云 API, result 为 Response 字段的解析目标
+func (c Client) Do(service, host, action, version string, payload, result any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	region := c.Region
+	if region == "" {
+		region = defaultRegion
+	}
+	timestamp := time.Now().Unix()
+	headers := map[string]string{
+		"Content-Type":   "application/json; charset=utf-8",
+		"Authorization":  c.authorization(service, host, timestamp, body),
+		"X-TC-Action":    action,
+		"X-TC-Version":   version,
+		"X-TC-Region":    region,
+		"X-TC-Timestamp": strconv.FormatInt(timestamp, 10),
+	}
+
+	res, err := tools.Post(headers, "https://"+host, bytes.NewReader(body), 10)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	bodyByte, err := io.ReadAll(res.Body)
+	if err != nil {
+		return fmt.Errorf("读取腾讯云响应失败, err: %s", err.Error())
+	}
+
+	var response struct {
+		Response json.RawMessage `json:"Response"`
+	}
+	if err := json.Unmarshal(bodyByte, &response); err != nil {
+		return fmt.Errorf("解析腾讯云响应失败, status: %d", res.StatusCode)
+	}
+	var respErr struct {
+		Error *apiError `json:"Error"`
+	}
+	if err := json.Unmarshal(response.Response, &respErr); err == nil && respErr.Error != nil {
+		return fmt.Errorf("%s: %s", respErr.Error.Code, respErr.Error.Message)
+	}
+	if result != nil {
+		return json.Unmarshal(response.Response, result)
+	}
+
+	return nil
+}
+
+// authorization 生成 TC3-HMAC-SHA256 签名
+func (c Client) authorization(service, host string, timestamp int64, body []byte) string {
+	const signedHeaders = "content-type;host"
+	canonicalRequest := strings.Join([]string{
+		"POST",
+		"/",
+		"",
+		"content-type:application/json; charset=utf-8\nhost:" + host + "\n",
+		signedHeaders,
+		sha256Hex(body),
+	}, "\n")
+
+	date := time.Unix(timestamp, 0).UTC().Format("2006-01-02")
+	scope := fmt.Sprintf("%s/%s/tc3_request", date, service)
+	stringToSign := fmt.Sprintf("TC3-HMAC-SHA256\n%d\n%s\n%s", timestamp, scope, sha256Hex([]byte(canonicalRequest)))
+
+	secretDate := hmacSha256([]byte("TC3"+c.SecretKey), date)
+	secretService := hmacSha256(secretDate, service)
+	secretSigning := hmacSha256(secretService, "tc3_request")
+	signature := hex.EncodeToString(hmacSha256(secretSigning, stringToSign))
+
+	return fmt.Sprintf("TC3-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s", c.SecretId, scope, signedHeaders, signature)
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSha256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// e164 补全国家码, 未带 + 号时视为中国大陆号码
+func e164(phoneNumber string) string {
+	if strings.HasPrefix(phoneNumber, "+") {
+		return phoneNumber
+	}
+	return "+86" + phoneNumber
+}
@@ -0,0 +1,19 @@
+package tencent
+
+import "testing"
+
+// 腾讯云 API 3.0 签名方法 v3 文档中的示例
+func TestAuthorization(t *testing.T) {
+	c := Client{SecretId: "AKIDz8krbsJ5yKBZQpn74WFkmLPx3EXAMPLE", SecretKey: "Gu5t9xGARNpq86cd98joQYCN3EXAMPLE"}
+	body := []byte(`{"Limit": 1, "Filters": [{"Values": ["\u672a\u547d\u540d"], "Name": "instance-name"}]}`)
+
+	if got := sha256Hex(body); got != "35e9c5b0e3ae67532d3c9f17ead6c90222632e5b1ff7f6e89887f1398934f064" {
+		t.Fatalf("unexpected payload hash: %s", got)
+	}
+
+	want := "TC3-HMAC-SHA256 Credential=AKIDz8krbsJ5yKBZQpn74WFkmLPx3EXAMPLE/2019-02-25/cvm/tc3_request, " +
+		"SignedHeaders=content-type;host, Signature=72e494ea809ad7a8c8f7a4507b9bddcbaa8e581f516e8da2f66e2c5a96525168"
+	if got := c.authorization("cvm", "cvm.tencentcloudapi.com", 1551113065, body); got != want {
+		t.Fatalf("unexpected authorization:\n got: %s\nwant: %s", got, want)
+	}
+}
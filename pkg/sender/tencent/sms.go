@@ -0,0 +1,50 @@
+package tencent
+
+import (
+	"fmt"
+	"watchAlert/internal/models"
+
+	"go.uber.org/multierr"
+)
+
+// Sms 腾讯云短信, 模版需包含一个变量用于告警内容
+type Sms struct {
+	Client     Client
+	AppId      string
+	SignName   string
+	TemplateId string
+	MaxLength  int
+}
+
+func (s *Sms) Send(message string, phoneNumbers []string) error {
+	numbers := make([]string, 0, len(phoneNumbers))
+	for _, phoneNumber := range phoneNumbers {
+		numbers = append(numbers, e164(phoneNumber))
+	}
+
+	var result struct {
+		SendStatusSet []struct {
+			PhoneNumber string `json:"PhoneNumber"`
+			Code        string `json:"Code"`
+			Message     string `json:"Message"`
+		} `json:"SendStatusSet"`
+	}
+	err := s.Client.Do("sms", "sms.tencentcloudapi.com", "SendSms", "2021-01-11", map[string]any{
+		"PhoneNumberSet":   numbers,
+		"SmsSdkAppId":      s.AppId,
+		"SignName":         s.SignName,
+		"TemplateId":       s.TemplateId,
+		"TemplateParamSet": []string{models.LimitContent(message, s.MaxLength)},
+	}, &result)
+	if err != nil {
+		return err
+	}
+
+	var resultError error
+	for _, status := range result.SendStatusSet {
+		if status.Code != "Ok" {
+			resultError = multierr.Append(resultError, fmt.Errorf("%s: %s %s", status.PhoneNumber, status.Code, status.Message))
+		}
+	}
+	return resultError
+}
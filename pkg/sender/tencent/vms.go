@@ -0,0 +1,41 @@
+package tencent
+
+import (
+	"fmt"
+	"watchAlert/internal/ctx"
+	"watchAlert/internal/models"
+
+	"github.com/zeromicro/go-zero/core/logc"
+	"go.uber.org/multierr"
+)
+
+// PhoneCall 腾讯云语音消息（VMS）, 模版需包含一个变量用于播报告警内容
+// 按键回调需在控制台配置为 /api/v1/phone/ack/tencent, 通过 SessionContext 回传认领令牌
+type PhoneCall struct {
+	Client     Client
+	AppId      string
+	TemplateId string
+	MaxLength  int
+}
+
+func (p *PhoneCall) Call(message, ackToken string, phoneNumbers []string) error {
+	var resultError error
+	content := models.LimitContent(message, p.MaxLength)
+	for _, phoneNumber := range phoneNumbers {
+		err := p.Client.Do("vms", "vms.tencentcloudapi.com", "SendTtsVoice", "2020-09-02", map[string]any{
+			"TemplateId":       p.TemplateId,
+			"TemplateParamSet": []string{content},
+			"CalledNumber":     e164(phoneNumber),
+			"VoiceSdkAppid":    p.AppId,
+			"PlayTimes":        2,
+			"SessionContext":   ackToken,
+		}, nil)
+		if err != nil {
+			logc.Errorf(ctx.Ctx, "呼叫失败，号码：%s，原因：%s", phoneNumber, err.Error())
+			resultError = multierr.Append(resultError, fmt.Errorf("%s: %w", phoneNumber, err))
+			continue
+		}
+		logc.Info(ctx.Ctx, fmt.Sprintf("呼叫成功，号码：%s", phoneNumber))
+	}
+	return resultError
+}
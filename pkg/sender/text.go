@@ -0,0 +1,52 @@
+package sender
+
+import (
+	"fmt"
+	"html"
+	"watchAlert/internal/models"
+	"watchAlert/pkg/tools"
+)
+
+// IsPagingNotice 是否为寻呼平台, 寻呼平台按告警指纹触发及恢复事件, 不接收纯文本消息
+func IsPagingNotice(noticeType string) bool {
+	return noticeType == "PagerDuty" || noticeType == "Opsgenie"
+}
+
+// BuildTextContent 按通知类型构建纯文本消息内容, 用于非告警类的通知(如故障状态更新)
+func BuildTextContent(noticeType, title, text string) string {
+	content := text
+	if title != "" {
+		content = title + "\n" + text
+	}
+
+	switch noticeType {
+	case "FeiShu":
+		return tools.JsonMarshalToString(map[string]any{
+			"msg_type": "text",
+			"content":  map[string]any{"text": content},
+		})
+	case "DingDing", "WeChat":
+		return tools.JsonMarshalToString(map[string]any{
+			"msgtype": "text",
+			"text":    map[string]any{"content": content},
+		})
+	case "Slack":
+		return tools.JsonMarshalToString(models.SlackMsgTemplate{Text: content})
+	case "Teams":
+		return tools.JsonMarshalToString(models.NewTeamsTextMessage(title, text))
+	case "Telegram":
+		msg := models.TelegramMessage{Text: html.EscapeString(text), ParseMode: "HTML", DisableWebPagePreview: true}
+		if title != "" {
+			msg.Text = fmt.Sprintf("<b>%s</b>\n%s", html.EscapeString(title), msg.Text)
+		}
+		return tools.JsonMarshalToString(msg)
+	case "CustomHook":
+		return tools.JsonMarshalToString(map[string]any{
+			"title": title,
+			"text":  text,
+		})
+	default:
+		// Email、PhoneCall 直接使用文本内容
+		return content
+	}
+}
@@ -0,0 +1,143 @@
+package twilio
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"watchAlert/internal/ctx"
+	"watchAlert/internal/models"
+	"watchAlert/pkg/tools"
+
+	"github.com/zeromicro/go-zero/core/logc"
+	"go.uber.org/multierr"
+)
+
+const apiUrl = "https://api.twilio.com/2010-04-01"
+
+// Client Twilio REST API 客户端
+type Client struct {
+	AccountSid string
+	AuthToken  string
+	From       string
+	// ApiUrl 为空时使用 Twilio 官方地址
+	ApiUrl string
+}
+
+// PhoneCall Twilio 语音通知, 使用 TwiML <Say> 播报, 配置回调地址时通过 <Gather> 接收按键认领
+type PhoneCall struct {
+	Client      Client
+	Language    string
+	MaxLength   int
+	CallbackUrl string
+}
+
+// Sms Twilio 短信
+type Sms struct {
+	Client    Client
+	MaxLength int
+}
+
+func (p *PhoneCall) Call(message, ackToken string, phoneNumbers []string) error {
+	twiml := BuildTwiml(models.LimitContent(message, p.MaxLength), p.Language, p.gatherUrl(ackToken))
+
+	var resultError error
+	for _, phoneNumber := range phoneNumbers {
+		err := p.Client.post("Calls.json", url.Values{
+			"To":    {phoneNumber},
+			"From":  {p.Client.From},
+			"Twiml": {twiml},
+		})
+		if err != nil {
+			logc.Errorf(ctx.Ctx, "呼叫失败，号码：%s，原因：%s", phoneNumber, err.Error())
+			resultError = multierr.Append(resultError, fmt.Errorf("%s: %w", phoneNumber, err))
+			continue
+		}
+		logc.Info(ctx.Ctx, fmt.Sprintf("呼叫成功，号码：%s", phoneNumber))
+	}
+	return resultError
+}
+
+func (p *PhoneCall) gatherUrl(ackToken string) string {
+	if ackToken == "" || p.CallbackUrl == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s/api/v1/phone/ack/twilio?token=%s", strings.TrimSuffix(p.CallbackUrl, "/"), url.QueryEscape(ackToken))
+}
+
+func (s *Sms) Send(message string, phoneNumbers []string) error {
+	body := models.LimitContent(message, s.MaxLength)
+
+	var resultError error
+	for _, phoneNumber := range phoneNumbers {
+		err := s.Client.post("Messages.json", url.Values{
+			"To":   {phoneNumber},
+			"From": {s.Client.From},
+			"Body": {body},
+		})
+		if err != nil {
+			resultError = multierr.Append(resultError, fmt.Errorf("%s: %w", phoneNumber, err))
+		}
+	}
+	return resultError
+}
+
+// BuildTwiml 构建播报两遍的 TwiML, gatherUrl 不为空时提示按 1 认领
+func BuildTwiml(message, language, gatherUrl string) string {
+	if gatherUrl == "" {
+		return "<Response>" + say(message, language, 2) + "</Response>"
+	}
+
+	var action bytes.Buffer
+	_ = xml.EscapeText(&action, []byte(gatherUrl))
+	return fmt.Sprintf(`<Response><Gather numDigits="1" timeout="10" method="POST" action="%s">%s%s</Gather></Response>`,
+		action.String(), say(message, language, 2), say("认领告警请按 "+models.PhoneCallAckDigit, language, 1))
+}
+
+// AckResponse 按键回调的 TwiML 响应
+func AckResponse(message, language string) string {
+	return "<Response>" + say(message, language, 1) + "</Response>"
+}
+
+func say(message, language string, loop int) string {
+	if language == "" {
+		language = "zh-CN"
+	}
+
+	var text bytes.Buffer
+	_ = xml.EscapeText(&text, []byte(message))
+	return fmt.Sprintf(`<Say language="%s" loop="%d">%s</Say>`, language, loop, text.String())
+}
+
+func (c Client) post(resource string, form url.Values) error {
+	headers := tools.CreateBasicAuthHeader(c.AccountSid, c.AuthToken)
+	headers["Content-Type"] = "application/x-www-form-urlencoded"
+
+	baseUrl := apiUrl
+	if c.ApiUrl != "" {
+		baseUrl = strings.TrimSuffix(c.ApiUrl, "/")
+	}
+	address := fmt.Sprintf("%s/Accounts/%s/%s", baseUrl, c.AccountSid, resource)
+	res, err := tools.Post(headers, address, bytes.NewReader([]byte(form.Encode())), 10)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode/100 == 2 {
+		return nil
+	}
+
+	bodyByte, _ := io.ReadAll(res.Body)
+	var response struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(bodyByte, &response); err == nil && response.Message != "" {
+		return fmt.Errorf("%d %s", response.Code, response.Message)
+	}
+	return fmt.Errorf("status: %d, body: %s", res.StatusCode, string(bodyByte))
+}
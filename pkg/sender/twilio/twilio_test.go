@@ -0,0 +1,95 @@
+package twilio
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+type twilioRequest struct {
+	path string
+	user string
+	pass string
+	form url.Values
+}
+
+func newTwilioServer(t *testing.T, failTo string, requests *[]twilioRequest) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("parse form: %v", err)
+		}
+		user, pass, _ := r.BasicAuth()
+		*requests = append(*requests, twilioRequest{path: r.URL.Path, user: user, pass: pass, form: r.PostForm})
+
+		if r.PostForm.Get("To") == failTo {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"code":21211,"message":"Invalid 'To' Phone Number"}`))
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"sid":"CA123"}`))
+	}))
+}
+
+func TestPhoneCall(t *testing.T) {
+	var requests []twilioRequest
+	server := newTwilioServer(t, "+10000000000", &requests)
+	defer server.Close()
+
+	call := PhoneCall{
+		Client:      Client{AccountSid: "AC123", AuthToken: "token", From: "+15550000000", ApiUrl: server.URL},
+		CallbackUrl: "https://w8t.example.com/",
+	}
+	err := call.Call("CPU <90%> & rising", "ack token", []string{"+15551111111", "+10000000000"})
+	if err == nil || !strings.Contains(err.Error(), "+10000000000: 21211 Invalid 'To' Phone Number") {
+		t.Fatalf("failed number should be reported, got %v", err)
+	}
+
+	if len(requests) != 2 {
+		t.Fatalf("expected 2 calls, got %d", len(requests))
+	}
+	r := requests[0]
+	if r.path != "/Accounts/AC123/Calls.json" || r.user != "AC123" || r.pass != "token" {
+		t.Fatalf("unexpected request: %+v", r)
+	}
+	if r.form.Get("To") != "+15551111111" || r.form.Get("From") != "+15550000000" {
+		t.Fatalf("unexpected numbers: %v", r.form)
+	}
+
+	// 认领回调地址需要带上令牌, 且在 TwiML 中转义
+	twiml := r.form.Get("Twiml")
+	gather := `action="https://w8t.example.com/api/v1/phone/ack/twilio?token=ack+token"`
+	if !strings.Contains(twiml, gather) || !strings.Contains(twiml, "CPU &lt;90%&gt; &amp; rising") {
+		t.Fatalf("unexpected twiml: %s", twiml)
+	}
+}
+
+func TestPhoneCallWithoutCallback(t *testing.T) {
+	var requests []twilioRequest
+	server := newTwilioServer(t, "", &requests)
+	defer server.Close()
+
+	call := PhoneCall{Client: Client{AccountSid: "AC123", ApiUrl: server.URL}}
+	if err := call.Call("disk full", "ack-token", []string{"+15551111111"}); err != nil {
+		t.Fatal(err)
+	}
+	if twiml := requests[0].form.Get("Twiml"); strings.Contains(twiml, "<Gather") {
+		t.Fatalf("call without callback url should not gather digits: %s", twiml)
+	}
+}
+
+func TestSms(t *testing.T) {
+	var requests []twilioRequest
+	server := newTwilioServer(t, "", &requests)
+	defer server.Close()
+
+	sms := Sms{Client: Client{AccountSid: "AC123", From: "+15550000000", ApiUrl: server.URL}, MaxLength: 4}
+	if err := sms.Send("disk full", []string{"+15551111111"}); err != nil {
+		t.Fatal(err)
+	}
+	if requests[0].path != "/Accounts/AC123/Messages.json" || requests[0].form.Get("Body") != "disk" {
+		t.Fatalf("unexpected request: %+v", requests[0])
+	}
+}
@@ -2,9 +2,13 @@ package sender
 
 import (
 	"bytes"
-	"errors"
 	"fmt"
 	"io"
+	"net/http"
+	"strconv"
+	"time"
+	"watchAlert/internal/models"
+	"watchAlert/pkg/templates"
 	"watchAlert/pkg/tools"
 )
 
@@ -15,7 +19,6 @@ type (
 
 var WebhookTestContent = fmt.Sprintf(`{
   "text": "%s"
-  }
 }`, RobotTestContent)
 
 func NewWebHookSender() SendInter {
@@ -23,25 +26,80 @@ func NewWebHookSender() SendInter {
 }
 
 func (w *WebHookSender) Send(params SendParams) error {
-	return w.post(params.Hook, params.Content)
+	return w.request(params, params.Content)
 }
 
 func (w *WebHookSender) Test(params SendParams) error {
-	return w.post(params.Hook, WebhookTestContent)
+	return w.request(params, WebhookTestContent)
 }
 
-func (w *WebHookSender) post(hook, content string) error {
-	res, err := tools.Post(nil, hook, bytes.NewReader([]byte(content)), 10)
+// request 按通知对象的请求配置渲染地址、请求头及请求体, 配置签名密钥时附带时间戳及 HMAC-SHA256 签名
+func (w *WebHookSender) request(params SendParams, content string) error {
+	config := params.Webhook
+	data := templates.ParseWebhookData(content)
+	// 各告警等级路由到不同的 Hook 地址, 地址模版通过 {{ .hook }} 引用当前路由的地址
+	if m, ok := data.(map[string]interface{}); ok {
+		m["hook"] = params.Hook
+	}
+
+	url := params.Hook
+	if config.Url != "" {
+		rendered, err := templates.RenderWebhookTemplate("地址", config.Url, data)
+		if err != nil {
+			return err
+		}
+		url = rendered
+	}
+	if url == "" {
+		return fmt.Errorf("未配置 Hook 地址")
+	}
+
+	body := content
+	if config.Body != "" {
+		rendered, err := templates.RenderWebhookTemplate("请求体", config.Body, data)
+		if err != nil {
+			return err
+		}
+		body = rendered
+	}
+
+	headers := make(map[string]string, len(config.Headers)+2)
+	for _, header := range config.Headers {
+		if header.Key == "" {
+			continue
+		}
+		value, err := templates.RenderWebhookTemplate("请求头", header.Value, data)
+		if err != nil {
+			return err
+		}
+		headers[header.Key] = value
+	}
+
+	if config.SignSecret != "" {
+		timestamp := time.Now().Unix()
+		headers[models.WebhookTimestampHeader] = strconv.FormatInt(timestamp, 10)
+		headers[config.GetSignHeader()] = models.WebhookSignature(config.SignSecret, timestamp, []byte(body))
+	}
+
+	// GET、HEAD 请求未配置请求体模版时不附带请求体
+	var bodyReader io.Reader = bytes.NewReader([]byte(body))
+	method := config.GetMethod()
+	if (method == http.MethodGet || method == http.MethodHead) && config.Body == "" {
+		bodyReader = nil
+	}
+
+	res, err := tools.Request(method, headers, url, bodyReader, config.GetTimeout())
 	if err != nil {
 		return err
 	}
+	defer res.Body.Close()
 
-	if res.StatusCode != 200 {
-		bodyByte, err := io.ReadAll(res.Body)
+	if !config.IsAccepted(res.StatusCode) {
+		bodyByte, err := io.ReadAll(io.LimitReader(res.Body, 4096))
 		if err != nil {
 			return fmt.Errorf("读取 Body 失败, err: %s", err.Error())
 		}
-		return errors.New(string(bodyByte))
+		return fmt.Errorf("status: %d, body: %s", res.StatusCode, string(bodyByte))
 	}
 
 	return nil
@@ -0,0 +1,122 @@
+package sender
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"watchAlert/internal/models"
+)
+
+type webhookRequest struct {
+	method string
+	path   string
+	header http.Header
+	body   string
+}
+
+func newWebhookServer(t *testing.T, status int, requests *[]webhookRequest) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("read body: %v", err)
+		}
+		*requests = append(*requests, webhookRequest{method: r.Method, path: r.URL.RequestURI(), header: r.Header.Clone(), body: string(body)})
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte("ack"))
+	}))
+}
+
+func TestWebhookSend(t *testing.T) {
+	var requests []webhookRequest
+	server := newWebhookServer(t, http.StatusOK, &requests)
+	defer server.Close()
+
+	content := `{"alarm":{"rule_name":"cpu usage","severity":"P0"}}`
+	err := NewWebHookSender().Send(SendParams{
+		Hook:    server.URL + "/p0",
+		Content: content,
+		Webhook: models.WebhookConfig{
+			Method:     http.MethodPut,
+			Url:        "{{ .hook }}?rule={{ .alarm.rule_name | urlquery }}",
+			Headers:    []models.WebhookHeader{{Key: "X-Severity", Value: "{{ .alarm.severity | lower }}"}},
+			Body:       `{"title":{{ json .alarm.rule_name }}}`,
+			SignSecret: "secret",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(requests) != 1 {
+		t.Fatalf("expected 1 request, got %d", len(requests))
+	}
+	r := requests[0]
+	if r.method != http.MethodPut || r.path != "/p0?rule=cpu+usage" {
+		t.Fatalf("unexpected request: %s %s", r.method, r.path)
+	}
+	if r.header.Get("X-Severity") != "p0" || r.body != `{"title":"cpu usage"}` {
+		t.Fatalf("unexpected header or body: %v %s", r.header, r.body)
+	}
+
+	timestamp := r.header.Get(models.WebhookTimestampHeader)
+	signature := r.header.Get(models.DefaultWebhookSignHeader)
+	if !models.VerifyWebhookSignature("secret", timestamp, signature, []byte(r.body), time.Now(), time.Minute) {
+		t.Fatalf("invalid signature %q for timestamp %q", signature, timestamp)
+	}
+}
+
+func TestWebhookSendRoutesBySeverityHook(t *testing.T) {
+	var requests []webhookRequest
+	server := newWebhookServer(t, http.StatusOK, &requests)
+	defer server.Close()
+
+	config := models.WebhookConfig{Url: "{{ .hook }}/alert"}
+	for _, hook := range []string{server.URL + "/p0", server.URL + "/p1"} {
+		if err := NewWebHookSender().Send(SendParams{Hook: hook, Content: `{}`, Webhook: config}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(requests) != 2 || requests[0].path != "/p0/alert" || requests[1].path != "/p1/alert" {
+		t.Fatalf("each route should keep its own hook, got %+v", requests)
+	}
+	if requests[0].method != http.MethodPost || requests[0].header.Get(models.WebhookTimestampHeader) != "" {
+		t.Fatalf("default request should be an unsigned POST, got %s %v", requests[0].method, requests[0].header)
+	}
+}
+
+func TestWebhookSendAcceptedStatus(t *testing.T) {
+	cases := []struct {
+		name     string
+		status   int
+		accepted []int
+		wantErr  bool
+	}{
+		{"2xx by default", http.StatusNoContent, nil, false},
+		{"non 2xx by default", http.StatusMultipleChoices, nil, true},
+		{"configured status", http.StatusConflict, []int{http.StatusOK, http.StatusConflict}, false},
+		{"2xx outside configured status", http.StatusCreated, []int{http.StatusOK}, true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var requests []webhookRequest
+			server := newWebhookServer(t, c.status, &requests)
+			defer server.Close()
+
+			err := NewWebHookSender().Send(SendParams{
+				Hook:    server.URL,
+				Content: `{}`,
+				Webhook: models.WebhookConfig{AcceptedStatus: c.accepted},
+			})
+			if (err != nil) != c.wantErr {
+				t.Fatalf("expected error: %v, got %v", c.wantErr, err)
+			}
+			if err != nil && !strings.Contains(err.Error(), "ack") {
+				t.Fatalf("error should include the response body, got %v", err)
+			}
+		})
+	}
+}
@@ -223,6 +223,7 @@ func buildDingdingMarkdown(alert models2.AlertCurEvent, noticeTmpl models2.Notic
 			Text: "**" + Title + "**" +
 				"\n" + "\n" +
 				EventText +
+				dingdingGraph(alert) +
 				"\n" +
 				Footer,
 		},
@@ -245,6 +246,14 @@ func buildDingdingMarkdown(alert models2.AlertCurEvent, noticeTmpl models2.Notic
 	return tools.JsonMarshalToString(t)
 }
 
+// dingdingGraph 趋势图以 Markdown 图片附加在告警详情后
+func dingdingGraph(alert models2.AlertCurEvent) string {
+	if alert.GraphUrl == "" {
+		return ""
+	}
+	return fmt.Sprintf("\n\n![趋势图](%s)\n", alert.GraphUrl)
+}
+
 // buildDingdingActionCard 构建钉钉 ActionCard 消息（带快捷操作按钮）
 func buildDingdingActionCard(alert models2.AlertCurEvent, noticeTmpl models2.NoticeTemplateExample, config models2.QuickActionConfig) string {
 	// 如果告警已恢复，不显示快捷操作按钮，使用 Markdown 模式
@@ -282,7 +291,7 @@ func buildDingdingActionCard(alert models2.AlertCurEvent, noticeTmpl models2.Not
 		Msgtype: "actionCard",
 		ActionCard: &models2.ActionCard{
 			Title:          Title,
-			Text:           "#### " + Title + "\n\n" + EventText,
+			Text:           "#### " + Title + "\n\n" + EventText + dingdingGraph(alert),
 			BtnOrientation: "1", // 按钮纵向排列，移动端体验更好
 			Btns: []models2.ActionCardBtn{
 				// 认领告警按钮
@@ -70,6 +70,18 @@ func feishuTemplate(alert models.AlertCurEvent, noticeTmpl models.NoticeTemplate
 					},
 				},
 			},
+		}
+		// 飞书卡片图片需先上传获取 img_key, 趋势图以链接附带
+		if alert.GraphUrl != "" {
+			cardElements = append(cardElements, models.Elements{
+				Tag: "div",
+				Text: models.Texts{
+					Content: fmt.Sprintf("[📈 查看趋势图](%s)", alert.GraphUrl),
+					Tag:     "lark_md",
+				},
+			})
+		}
+		cardElements = append(cardElements, []models.Elements{
 			{
 				Tag: "hr",
 			},
@@ -82,7 +94,7 @@ func feishuTemplate(alert models.AlertCurEvent, noticeTmpl models.NoticeTemplate
 					},
 				},
 			},
-		}
+		}...)
 
 		// 转换cardElements为map列表
 		defaultTemplate.Card.Elements = tools.ConvertSliceToMapList(cardElements)
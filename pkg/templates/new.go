@@ -20,10 +20,18 @@ func NewTemplate(ctx *ctx.Context, alert models.AlertCurEvent, notice models.Ale
 		return Template{CardContentMsg: emailTemplate(alert, noticeTmpl)}
 	case "WeChat":
 		return Template{CardContentMsg: wechatTemplate(alert, noticeTmpl)}
-	case "PhoneCall":
+	case "PhoneCall", "Sms":
 		return Template{CardContentMsg: phoneCallTemplate(alert, noticeTmpl)}
 	case "Slack":
 		return Template{slackTemplate(alert, noticeTmpl)}
+	case "Teams":
+		return Template{CardContentMsg: teamsTemplate(alert, noticeTmpl)}
+	case "PagerDuty":
+		return Template{CardContentMsg: pagerDutyTemplate(alert, noticeTmpl)}
+	case "Opsgenie":
+		return Template{CardContentMsg: opsgenieTemplate(alert, noticeTmpl)}
+	case "Telegram":
+		return Template{CardContentMsg: telegramTemplate(alert, noticeTmpl)}
 	}
 
 	return Template{}
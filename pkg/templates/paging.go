@@ -0,0 +1,115 @@
+package templates
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"watchAlert/internal/models"
+	"watchAlert/pkg/tools"
+)
+
+// pagerDutyTemplate PagerDuty Events API v2 事件, 以告警指纹作为 dedup_key
+// 已恢复的告警发送 resolve, 已认领的告警发送 acknowledge, 其余发送 trigger
+func pagerDutyTemplate(alert models.AlertCurEvent, noticeTmpl models.NoticeTemplateExample) string {
+	event := models.PagerDutyEvent{
+		EventAction: models.PagerDutyActionTrigger,
+		DedupKey:    alert.Fingerprint,
+	}
+	switch {
+	case alert.IsRecovered:
+		event.EventAction = models.PagerDutyActionResolve
+		return tools.JsonMarshalToString(event)
+	case alert.ConfirmState.IsOk:
+		event.EventAction = models.PagerDutyActionAcknowledge
+		return tools.JsonMarshalToString(event)
+	}
+
+	details := map[string]any{
+		"event":  strings.TrimSpace(ParserTemplate("Event", alert, noticeTmpl.Template)),
+		"labels": alert.Labels,
+	}
+	if alert.Annotations != "" {
+		details["annotations"] = alert.Annotations
+	}
+
+	event.Payload = &models.PagerDutyPayload{
+		Summary:       models.TruncateRunes(pagingSummary(alert, noticeTmpl), 1024),
+		Source:        pagingSource(alert),
+		Severity:      models.PagerDutySeverity(alert.Severity),
+		Group:         alert.FaultCenter.Name,
+		Class:         alert.DatasourceType,
+		CustomDetails: details,
+	}
+	if alert.FirstTriggerTime > 0 {
+		event.Payload.Timestamp = time.Unix(alert.FirstTriggerTime, 0).Format(time.RFC3339)
+	}
+	event.Client = "WatchAlert"
+	if quickConfig := getQuickActionConfig(); quickConfig.BaseUrl != "" {
+		event.ClientUrl = buildDetailUrl(alert, quickConfig.BaseUrl)
+		event.Links = []models.PagerDutyLink{{Href: event.ClientUrl, Text: "WatchAlert 告警详情"}}
+	}
+
+	return tools.JsonMarshalToString(event)
+}
+
+// opsgenieTemplate Opsgenie 告警操作, 以告警指纹作为 alias
+// 已恢复的告警关闭, 已认领的告警认领, 其余创建告警（alias 相同的告警 Opsgenie 会去重）
+func opsgenieTemplate(alert models.AlertCurEvent, noticeTmpl models.NoticeTemplateExample) string {
+	msg := models.OpsgenieMessage{Action: models.OpsgenieActionCreate, Alias: alert.Fingerprint}
+	switch {
+	case alert.IsRecovered:
+		msg.Action = models.OpsgenieActionClose
+		msg.Note = "告警已恢复"
+		return tools.JsonMarshalToString(msg)
+	case alert.ConfirmState.IsOk:
+		msg.Action = models.OpsgenieActionAcknowledge
+		msg.User = alert.ConfirmState.ConfirmUsername
+		msg.Note = fmt.Sprintf("已由 %s 在 WatchAlert 认领", alert.ConfirmState.ConfirmUsername)
+		return tools.JsonMarshalToString(msg)
+	}
+
+	details := make(map[string]string, len(alert.Labels)+1)
+	for key, value := range alert.Labels {
+		details[key] = fmt.Sprint(value)
+	}
+	if quickConfig := getQuickActionConfig(); quickConfig.BaseUrl != "" {
+		details["watchalert_url"] = buildDetailUrl(alert, quickConfig.BaseUrl)
+	}
+
+	tags := []string{"WatchAlert", alert.Severity}
+	if alert.DatasourceType != "" {
+		tags = append(tags, alert.DatasourceType)
+	}
+
+	msg.Alert = &models.OpsgenieAlert{
+		Message:     models.TruncateRunes(pagingSummary(alert, noticeTmpl), 130),
+		Alias:       alert.Fingerprint,
+		Description: models.TruncateRunes(strings.TrimSpace(ParserTemplate("Event", alert, noticeTmpl.Template)), 15000),
+		Priority:    models.OpsgeniePriority(alert.Severity),
+		Source:      "WatchAlert",
+		Entity:      pagingSource(alert),
+		Tags:        tags,
+		Details:     details,
+	}
+
+	return tools.JsonMarshalToString(msg)
+}
+
+// pagingSummary 告警摘要, 模版未定义标题时使用规则名称
+func pagingSummary(alert models.AlertCurEvent, noticeTmpl models.NoticeTemplateExample) string {
+	summary := strings.TrimSpace(ParserTemplate("Title", alert, noticeTmpl.Template))
+	if summary == "" {
+		summary = fmt.Sprintf("[%s] %s", alert.Severity, alert.RuleName)
+	}
+	return summary
+}
+
+// pagingSource 告警来源, 优先使用 instance 等标签
+func pagingSource(alert models.AlertCurEvent) string {
+	for _, name := range []string{"instance", "host", "hostname", "pod", "service"} {
+		if value, ok := alert.Labels[name]; ok && fmt.Sprint(value) != "" {
+			return fmt.Sprint(value)
+		}
+	}
+	return "WatchAlert"
+}
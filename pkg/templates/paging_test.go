@@ -0,0 +1,48 @@
+package templates
+
+import (
+	"testing"
+	"watchAlert/internal/models"
+
+	"github.com/bytedance/sonic"
+)
+
+func TestPagingTemplates(t *testing.T) {
+	tmpl := models.NoticeTemplateExample{
+		Template: `{{ define "Title" }}[{{ .Severity }}] {{ .RuleName }}{{ end }}{{ define "Event" }}CPU 过高{{ end }}`,
+	}
+	alert := models.AlertCurEvent{
+		Fingerprint: "fp-1",
+		RuleName:    "CPU",
+		Severity:    "P0",
+		Labels:      map[string]interface{}{"instance": "node-1", "job": "node"},
+	}
+
+	var event models.PagerDutyEvent
+	if err := sonic.Unmarshal([]byte(pagerDutyTemplate(alert, tmpl)), &event); err != nil {
+		t.Fatal(err)
+	}
+	if event.EventAction != models.PagerDutyActionTrigger || event.DedupKey != "fp-1" ||
+		event.Payload.Summary != "[P0] CPU" || event.Payload.Severity != "critical" || event.Payload.Source != "node-1" {
+		t.Fatalf("unexpected trigger event: %+v %+v", event, event.Payload)
+	}
+
+	var msg models.OpsgenieMessage
+	if err := sonic.Unmarshal([]byte(opsgenieTemplate(alert, tmpl)), &msg); err != nil {
+		t.Fatal(err)
+	}
+	if msg.Action != models.OpsgenieActionCreate || msg.Alert.Alias != "fp-1" || msg.Alert.Priority != "P1" || msg.Alert.Details["job"] != "node" {
+		t.Fatalf("unexpected create message: %+v %+v", msg, msg.Alert)
+	}
+
+	// 已认领的告警同步认领, 已恢复的告警按 dedup_key / alias 恢复
+	alert.ConfirmState = models.ConfirmState{IsOk: true, ConfirmUsername: "alice"}
+	if err := sonic.Unmarshal([]byte(opsgenieTemplate(alert, tmpl)), &msg); err != nil || msg.Action != models.OpsgenieActionAcknowledge || msg.User != "alice" {
+		t.Fatalf("unexpected acknowledge message: %+v, %v", msg, err)
+	}
+	alert.IsRecovered = true
+	event = models.PagerDutyEvent{}
+	if err := sonic.Unmarshal([]byte(pagerDutyTemplate(alert, tmpl)), &event); err != nil || event.EventAction != models.PagerDutyActionResolve || event.Payload != nil {
+		t.Fatalf("unexpected resolve event: %+v, %v", event, err)
+	}
+}
@@ -9,6 +9,9 @@ func slackTemplate(alert models.AlertCurEvent, noticeTmpl models.NoticeTemplateE
 	t := models.SlackMsgTemplate{
 		Text: ParserTemplate("Event", alert, noticeTmpl.Template),
 	}
+	if alert.GraphUrl != "" {
+		t.Attachments = []models.SlackAttachment{{Fallback: "趋势图", ImageUrl: alert.GraphUrl}}
+	}
 
 	return tools.JsonMarshalToString(t)
 }
@@ -0,0 +1,147 @@
+package templates
+
+import (
+	"fmt"
+	"strings"
+	"watchAlert/internal/models"
+	"watchAlert/pkg/tools"
+	"watchAlert/pkg/utils"
+)
+
+// teamsTemplate Teams Adaptive Card 消息模版
+// 标题按告警等级着色, 值班人员以 <at> 提及, 启用快捷操作时附带认领、静默及查看详情按钮
+func teamsTemplate(alert models.AlertCurEvent, noticeTmpl models.NoticeTemplateExample) string {
+	var (
+		title    = ParserTemplate("Title", alert, noticeTmpl.Template)
+		event    = ParserTemplate("Event", alert, noticeTmpl.Template)
+		footer   = ParserTemplate("Footer", alert, noticeTmpl.Template)
+		style    = teamsSeverityStyle(alert)
+		mentions = teamsMentions(alert.DutyUser)
+	)
+
+	var mentioned bool
+	for _, mention := range mentions {
+		id := mention.Mentioned.Id
+		if strings.Contains(event, "@"+id) {
+			event = strings.ReplaceAll(event, "@"+id, mention.Text)
+			mentioned = true
+		}
+	}
+
+	items := []models.AdaptiveElement{
+		{Type: "TextBlock", Text: title, Size: "Large", Weight: "Bolder", Color: teamsTextColor(style), Wrap: true},
+	}
+	items = append(items, models.TeamsTextBlocks(event)...)
+	if !mentioned && len(mentions) > 0 {
+		texts := make([]string, 0, len(mentions))
+		for _, mention := range mentions {
+			texts = append(texts, mention.Text)
+		}
+		items = append(items, models.AdaptiveElement{Type: "TextBlock", Text: "值班人员: " + strings.Join(texts, " "), Wrap: true})
+	}
+	if alert.ConfirmState.IsOk {
+		items = append(items, models.AdaptiveElement{Type: "TextBlock", Text: fmt.Sprintf("✓ 已认领 (%s)", alert.ConfirmState.ConfirmUsername), Color: "Good", Wrap: true})
+	}
+
+	body := []models.AdaptiveElement{{Type: "Container", Style: style, Bleed: true, Items: items}}
+	if alert.GraphUrl != "" {
+		body = append(body, models.AdaptiveElement{Type: "Image", Url: alert.GraphUrl, AltText: "趋势图", Size: "Stretch"})
+	}
+	body = append(body, models.AdaptiveElement{Type: "TextBlock", Text: footer, Size: "Small", IsSubtle: true, Wrap: true})
+
+	card := models.AdaptiveCard{
+		Body:    body,
+		Actions: buildTeamsActions(alert),
+		MsTeams: models.AdaptiveCardTeams{Entities: mentions},
+	}
+
+	return tools.JsonMarshalToString(models.NewTeamsMessage(card))
+}
+
+// teamsSeverityStyle 告警等级对应的容器样式, 恢复为 good, P0 为 attention, P1 为 warning, 其余为 accent
+func teamsSeverityStyle(alert models.AlertCurEvent) string {
+	if alert.IsRecovered {
+		return "good"
+	}
+	switch alert.Severity {
+	case "P0":
+		return "attention"
+	case "P1":
+		return "warning"
+	default:
+		return "accent"
+	}
+}
+
+func teamsTextColor(style string) string {
+	switch style {
+	case "good":
+		return "Good"
+	case "attention":
+		return "Attention"
+	case "warning":
+		return "Warning"
+	default:
+		return "Accent"
+	}
+}
+
+// teamsMentions 解析值班人员的提及（@邮箱）为 Teams 提及实体
+func teamsMentions(dutyUser string) []models.TeamsMention {
+	var mentions []models.TeamsMention
+	for _, user := range strings.Fields(dutyUser) {
+		id := strings.TrimPrefix(user, "@")
+		if id == user || id == "" {
+			continue
+		}
+		mentions = append(mentions, models.TeamsMention{
+			Type:      "mention",
+			Text:      fmt.Sprintf("<at>%s</at>", id),
+			Mentioned: models.TeamsMentioned{Id: id, Name: id},
+		})
+	}
+	return mentions
+}
+
+// buildTeamsActions 构建快捷操作按钮, Adaptive Card 不支持禁用按钮, 已认领或已恢复时只保留查看详情
+func buildTeamsActions(alert models.AlertCurEvent) []models.AdaptiveAction {
+	quickConfig := getQuickActionConfig()
+	if !quickConfig.GetEnable() || quickConfig.BaseUrl == "" || quickConfig.SecretKey == "" {
+		return nil
+	}
+
+	detail := models.AdaptiveAction{Type: "Action.OpenUrl", Title: "📊 查看详情", Url: buildDetailUrl(alert, quickConfig.BaseUrl)}
+	if alert.IsRecovered || alert.ConfirmState.IsOk {
+		return []models.AdaptiveAction{detail}
+	}
+
+	token, err := utils.GenerateQuickToken(alert.TenantId, alert.Fingerprint, alert.DutyUser, quickConfig.SecretKey)
+	if err != nil {
+		return []models.AdaptiveAction{detail}
+	}
+
+	apiUrl := quickConfig.ApiUrl
+	if apiUrl == "" {
+		apiUrl = quickConfig.BaseUrl
+	}
+	action := func(title, query string) models.AdaptiveAction {
+		return models.AdaptiveAction{
+			Type:  "Action.OpenUrl",
+			Title: title,
+			Url:   fmt.Sprintf("%s/api/v1/alert/quick-action?%s&fingerprint=%s&token=%s", apiUrl, query, alert.Fingerprint, token),
+		}
+	}
+
+	return []models.AdaptiveAction{
+		action("🔔 认领告警", "action=claim"),
+		action("🕐 静默1小时", "action=silence&duration=1h"),
+		action("🕕 静默6小时", "action=silence&duration=6h"),
+		action("🕙 静默24小时", "action=silence&duration=24h"),
+		{
+			Type:  "Action.OpenUrl",
+			Title: "⚙️ 自定义静默",
+			Url:   fmt.Sprintf("%s/api/v1/alert/quick-silence?fingerprint=%s&token=%s", apiUrl, alert.Fingerprint, token),
+		},
+		detail,
+	}
+}
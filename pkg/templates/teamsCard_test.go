@@ -0,0 +1,52 @@
+package templates
+
+import (
+	"strings"
+	"testing"
+	"watchAlert/internal/models"
+
+	"github.com/bytedance/sonic"
+)
+
+func TestTeamsTemplate(t *testing.T) {
+	tmpl := models.NoticeTemplateExample{
+		Template: `{{ define "Title" }}[{{ .Severity }}] {{ .RuleName }}{{ end }}` +
+			`{{ define "Event" }}告警内容: CPU 过高` + "\n" + `值班人员: {{ .DutyUser }}{{ end }}` +
+			`{{ define "Footer" }}WatchAlert{{ end }}`,
+	}
+	alert := models.AlertCurEvent{RuleName: "CPU", Severity: "P0", DutyUser: "@alice@example.com 暂无"}
+
+	var msg models.TeamsMessage
+	if err := sonic.Unmarshal([]byte(teamsTemplate(alert, tmpl)), &msg); err != nil {
+		t.Fatal(err)
+	}
+	card := msg.Attachments[0].Content
+	if msg.Attachments[0].ContentType != models.AdaptiveCardContentType || card.Body[0].Style != "attention" {
+		t.Fatalf("unexpected card: %+v", msg)
+	}
+
+	items := card.Body[0].Items
+	if items[0].Text != "[P0] CPU" || items[0].Color != "Attention" || len(items) != 3 {
+		t.Fatalf("unexpected items: %+v", items)
+	}
+	if !strings.Contains(items[2].Text, "<at>alice@example.com</at>") {
+		t.Fatalf("expected inline mention, got %q", items[2].Text)
+	}
+	if len(card.MsTeams.Entities) != 1 || card.MsTeams.Entities[0].Mentioned.Id != "alice@example.com" {
+		t.Fatalf("unexpected mentions: %+v", card.MsTeams.Entities)
+	}
+	// 未启用快捷操作时不附带按钮
+	if len(card.Actions) != 0 {
+		t.Fatalf("unexpected actions: %+v", card.Actions)
+	}
+
+	// 附带趋势图时在详情与页脚之间插入图片
+	alert.GraphUrl = "https://w8t.example.com/api/w8t/event/graph/20240501/abc.png"
+	msg = models.TeamsMessage{}
+	if err := sonic.Unmarshal([]byte(teamsTemplate(alert, tmpl)), &msg); err != nil {
+		t.Fatal(err)
+	}
+	if body := msg.Attachments[0].Content.Body; len(body) != 3 || body[1].Type != "Image" || body[1].Url != alert.GraphUrl {
+		t.Fatalf("unexpected body: %+v", body)
+	}
+}
@@ -0,0 +1,89 @@
+package templates
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+	"watchAlert/internal/models"
+	"watchAlert/pkg/tools"
+)
+
+// Markdown 加粗转换为 HTML, 兼容为其它通知类型编写的模版
+var telegramBoldRe = regexp.MustCompile(`\*\*(.+?)\*\*`)
+
+// telegramTemplate Telegram HTML 消息模版
+// 模版内容按 HTML 转义, 值班人员保留 <a href="tg://user?id=..."> 提及, 附带认领、静默及标记已处理的回调按钮
+func telegramTemplate(alert models.AlertCurEvent, noticeTmpl models.NoticeTemplateExample) string {
+	var (
+		title  = telegramEscape(ParserTemplate("Title", alert, noticeTmpl.Template), alert.DutyUser)
+		event  = telegramEscape(ParserTemplate("Event", alert, noticeTmpl.Template), alert.DutyUser)
+		footer = telegramEscape(ParserTemplate("Footer", alert, noticeTmpl.Template), alert.DutyUser)
+	)
+
+	text := fmt.Sprintf("<b>%s</b>\n\n%s", title, event)
+	if alert.ConfirmState.IsOk {
+		text += fmt.Sprintf("\n\n✅ 已由 %s 认领", html.EscapeString(alert.ConfirmState.ConfirmUsername))
+	}
+	if footer != "" {
+		text += fmt.Sprintf("\n\n<i>%s</i>", footer)
+	}
+
+	msg := models.TelegramMessage{
+		Text:                  text,
+		ParseMode:             "HTML",
+		DisableWebPagePreview: true,
+	}
+	if keyboard := buildTelegramKeyboard(alert); len(keyboard) > 0 {
+		msg.ReplyMarkup = &models.TelegramReplyMarkup{InlineKeyboard: keyboard}
+	}
+
+	return tools.JsonMarshalToString(msg)
+}
+
+// telegramEscape 转义模版内容, 值班人员为已构建好的 HTML 提及, 不做转义
+func telegramEscape(text, dutyUser string) string {
+	text = html.EscapeString(strings.TrimSpace(text))
+	if dutyUser != "" {
+		text = strings.ReplaceAll(text, html.EscapeString(dutyUser), dutyUser)
+	}
+	return telegramBoldRe.ReplaceAllString(text, "<b>$1</b>")
+}
+
+// buildTelegramKeyboard 构建内联按钮, 已恢复的告警只保留查看详情, 已认领的告警不再展示认领按钮
+func buildTelegramKeyboard(alert models.AlertCurEvent) [][]models.TelegramInlineButton {
+	var keyboard [][]models.TelegramInlineButton
+	if !alert.IsRecovered && alert.FaultCenterId != "" {
+		callback := func(text, action string) []models.TelegramInlineButton {
+			data := models.TelegramCallbackData(action, alert.TenantId, alert.Fingerprint)
+			if data == "" {
+				return nil
+			}
+			return []models.TelegramInlineButton{{Text: text, CallbackData: data}}
+		}
+
+		var first []models.TelegramInlineButton
+		if !alert.ConfirmState.IsOk {
+			first = append(first, callback("🔔 认领告警", models.TelegramActionClaim)...)
+		}
+		first = append(first, callback("✔️ 标记已处理", models.TelegramActionResolve)...)
+
+		var silence []models.TelegramInlineButton
+		for _, duration := range []string{"1h", "6h", "24h"} {
+			silence = append(silence, callback("🔕 静默"+strings.TrimSuffix(duration, "h")+"小时", models.TelegramActionSilence+duration)...)
+		}
+
+		for _, row := range [][]models.TelegramInlineButton{first, silence} {
+			if len(row) > 0 {
+				keyboard = append(keyboard, row)
+			}
+		}
+	}
+
+	quickConfig := getQuickActionConfig()
+	if quickConfig.GetEnable() && quickConfig.BaseUrl != "" {
+		keyboard = append(keyboard, []models.TelegramInlineButton{{Text: "📊 查看详情", Url: buildDetailUrl(alert, quickConfig.BaseUrl)}})
+	}
+
+	return keyboard
+}
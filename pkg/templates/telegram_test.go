@@ -0,0 +1,57 @@
+package templates
+
+import (
+	"strings"
+	"testing"
+	"watchAlert/internal/models"
+
+	"github.com/bytedance/sonic"
+)
+
+func TestTelegramTemplate(t *testing.T) {
+	tmpl := models.NoticeTemplateExample{
+		Template: `{{ define "Title" }}[{{ .Severity }}] {{ .RuleName }}{{ end }}` +
+			`{{ define "Event" }}**状态**: value < 10 & up` + "\n" + `值班人员: {{ .DutyUser }}{{ end }}` +
+			`{{ define "Footer" }}WatchAlert{{ end }}`,
+	}
+	alert := models.AlertCurEvent{
+		TenantId:      "tid-cr2s1o5gr0ckh5hf4kc0",
+		FaultCenterId: "fc-1",
+		Fingerprint:   "9b2a6c1f0d4e8b7a3c5d2e1f0a9b8c7d",
+		RuleName:      "CPU",
+		Severity:      "P0",
+		DutyUser:      `<a href="tg://user?id=123">@alice</a>`,
+	}
+
+	var msg models.TelegramMessage
+	if err := sonic.Unmarshal([]byte(telegramTemplate(alert, tmpl)), &msg); err != nil {
+		t.Fatal(err)
+	}
+	if msg.ParseMode != "HTML" || !strings.HasPrefix(msg.Text, "<b>[P0] CPU</b>") {
+		t.Fatalf("unexpected message: %+v", msg)
+	}
+	for _, want := range []string{"<b>状态</b>: value &lt; 10 &amp; up", `<a href="tg://user?id=123">@alice</a>`, "<i>WatchAlert</i>"} {
+		if !strings.Contains(msg.Text, want) {
+			t.Fatalf("expected %q in %q", want, msg.Text)
+		}
+	}
+
+	keyboard := msg.ReplyMarkup.InlineKeyboard
+	if len(keyboard) != 2 || len(keyboard[0]) != 2 || len(keyboard[1]) != 3 {
+		t.Fatalf("unexpected keyboard: %+v", keyboard)
+	}
+	action, duration, tenantId, fingerprint, err := models.ParseTelegramCallbackData(keyboard[1][2].CallbackData)
+	if err != nil || action != models.TelegramActionSilence || duration != "24h" || tenantId != alert.TenantId || fingerprint != alert.Fingerprint {
+		t.Fatalf("unexpected callback data %q: %s %s %s %s %v", keyboard[1][2].CallbackData, action, duration, tenantId, fingerprint, err)
+	}
+
+	// 已认领的告警不再展示认领按钮, 已恢复的告警不附带回调按钮
+	alert.ConfirmState.IsOk = true
+	if got := buildTelegramKeyboard(alert); len(got[0]) != 1 || got[0][0].CallbackData[0:1] != models.TelegramActionResolve {
+		t.Fatalf("unexpected claimed keyboard: %+v", got)
+	}
+	alert.IsRecovered = true
+	if got := buildTelegramKeyboard(alert); len(got) != 0 {
+		t.Fatalf("unexpected recovered keyboard: %+v", got)
+	}
+}
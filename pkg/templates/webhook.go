@@ -0,0 +1,84 @@
+package templates
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+	"watchAlert/internal/global"
+)
+
+// webhookFuncs 自定义 Hook 模版可用的函数
+var webhookFuncs = template.FuncMap{
+	// json 将值编码为 JSON, 用于在 JSON 请求体中安全地嵌入字符串或对象
+	"json": func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"join": func(sep string, v interface{}) string {
+		items, ok := v.([]interface{})
+		if !ok {
+			return fmt.Sprint(v)
+		}
+		parts := make([]string, 0, len(items))
+		for _, item := range items {
+			parts = append(parts, fmt.Sprint(item))
+		}
+		return strings.Join(parts, sep)
+	},
+	"default": func(def, v interface{}) interface{} {
+		if v == nil || v == "" {
+			return def
+		}
+		return v
+	},
+	// formatTime 将秒级时间戳格式化为系统时间格式
+	"formatTime": func(v interface{}) string {
+		var ts int64
+		switch t := v.(type) {
+		case json.Number:
+			f, _ := t.Float64()
+			ts = int64(f)
+		case float64:
+			ts = int64(t)
+		case int64:
+			ts = t
+		case int:
+			ts = int64(t)
+		default:
+			return ""
+		}
+		return time.Unix(ts, 0).Format(global.Layout)
+	},
+}
+
+// ParseWebhookData 解析自定义 Hook 的默认内容作为模版数据, 非 JSON 内容以 {{ .content }} 引用
+func ParseWebhookData(content string) interface{} {
+	var data interface{}
+	decoder := json.NewDecoder(strings.NewReader(content))
+	// 保留整数精度, 避免时间戳等数值以科学计数法输出
+	decoder.UseNumber()
+	if err := decoder.Decode(&data); err != nil {
+		return map[string]interface{}{"content": content}
+	}
+	return data
+}
+
+// RenderWebhookTemplate 渲染自定义 Hook 的地址、请求头或请求体模版
+func RenderWebhookTemplate(name, text string, data interface{}) (string, error) {
+	tmpl, err := template.New(name).Funcs(webhookFuncs).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("解析%s模版失败: %s", name, err.Error())
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("渲染%s模版失败: %s", name, err.Error())
+	}
+	// 数据为 map, 不存在的字段渲染为空
+	return strings.ReplaceAll(buf.String(), "<no value>", ""), nil
+}
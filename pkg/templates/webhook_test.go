@@ -0,0 +1,33 @@
+package templates
+
+import (
+	"testing"
+)
+
+func TestRenderWebhookTemplate(t *testing.T) {
+	data := ParseWebhookData(`{"alarm":{"rule_name":"CPU \"high\"","severity":"P0","first_trigger_time":1700000000,"labels":{"instance":"10.0.0.1"}},"dutyUsers":[{"username":"alice"}]}`)
+
+	got, err := RenderWebhookTemplate("body", `{"title":{{ json .alarm.rule_name }},"level":"{{ lower .alarm.severity }}","ts":{{ .alarm.first_trigger_time }},"host":"{{ .alarm.labels.instance }}","missing":"{{ .alarm.labels.job }}"}`, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"title":"CPU \"high\"","level":"p0","ts":1700000000,"host":"10.0.0.1","missing":""}`
+	if got != want {
+		t.Fatalf("got %s\nwant %s", got, want)
+	}
+
+	url, err := RenderWebhookTemplate("url", `https://example.com/alert/{{ .alarm.severity }}?user={{ (index .dutyUsers 0).username }}`, data)
+	if err != nil || url != "https://example.com/alert/P0?user=alice" {
+		t.Fatalf("unexpected url %q, err: %v", url, err)
+	}
+
+	// 非 JSON 内容以 content 引用
+	text, err := RenderWebhookTemplate("body", `{{ .content }}`, ParseWebhookData("plain text"))
+	if err != nil || text != "plain text" {
+		t.Fatalf("unexpected text %q, err: %v", text, err)
+	}
+
+	if _, err := RenderWebhookTemplate("body", `{{ .alarm.`, data); err == nil {
+		t.Fatal("expected parse error")
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"encoding/base64"
 	"fmt"
 	"github.com/zeromicro/go-zero/core/logc"
+	"io"
 	"net/http"
 	"time"
 )
@@ -43,6 +44,11 @@ func Get(headers map[string]string, url string, timeout int) (*http.Response, er
 }
 
 func Post(headers map[string]string, url string, bodyReader *bytes.Reader, timeout int) (*http.Response, error) {
+	return Request(http.MethodPost, headers, url, bodyReader, timeout)
+}
+
+// Request 发送指定方法的请求, 默认 Content-Type 为 application/json, 可通过 headers 覆盖
+func Request(method string, headers map[string]string, url string, body io.Reader, timeout int) (*http.Response, error) {
 	transport := &http.Transport{
 		TLSClientConfig: &tls.Config{
 			InsecureSkipVerify: true,
@@ -55,18 +61,18 @@ func Post(headers map[string]string, url string, bodyReader *bytes.Reader, timeo
 		Transport: transport,
 	}
 
-	request, err := http.NewRequest(http.MethodPost, url, bodyReader)
+	request, err := http.NewRequest(method, url, body)
+	if err != nil {
+		logc.Error(context.Background(), fmt.Sprintf("Tools %s 请求建立失败, err: %s", method, err.Error()))
+		return nil, err
+	}
 	request.Header.Set("Content-Type", "application/json")
 	for k, v := range headers {
 		request.Header.Set(k, v)
 	}
-	if err != nil {
-		logc.Error(context.Background(), fmt.Sprintf("Tools post 请求建立失败, err: %s", err.Error()))
-		return nil, err
-	}
 	resp, err := client.Do(request)
 	if err != nil {
-		logc.Error(context.Background(), fmt.Sprintf("Tools post 请求发送失败, err: %s", err.Error()))
+		logc.Error(context.Background(), fmt.Sprintf("Tools %s 请求发送失败, err: %s", method, err.Error()))
 		return nil, err
 	}
 